@@ -204,6 +204,10 @@ export default defineConfig({
               text: 'Deno Filters',
               link: '/guide/deno-filters'
             },
+            {
+              text: 'Docker Filters',
+              link: '/guide/docker-filters'
+            },
             {
               text: 'Profile Filters',
               link: '/guide/profile-filters'
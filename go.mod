@@ -13,6 +13,7 @@ require (
 	github.com/stirante/go-simple-eval v0.0.0-20221118214627-1a818b6aab27
 	go.uber.org/zap v1.23.0
 	golang.org/x/mod v0.6.0
+	golang.org/x/net v0.1.0
 	golang.org/x/sys v0.2.0
 	muzzammil.xyz/jsonc v1.0.0
 )
@@ -50,7 +51,6 @@ require (
 	go.uber.org/multierr v1.8.0 // indirect
 	golang.org/x/crypto v0.1.0 // indirect
 	golang.org/x/exp v0.0.0-20221114191408-850992195362 // indirect
-	golang.org/x/net v0.1.0 // indirect
 	golang.org/x/oauth2 v0.0.0-20220309155454-6242fa91716a // indirect
 	golang.org/x/text v0.4.0 // indirect
 	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
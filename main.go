@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/Bedrock-OSS/go-burrito/burrito"
 	"github.com/stirante/go-simple-eval/eval"
@@ -29,12 +30,246 @@ const regolithRunDesc = `
 This command runs Regolith using the profile specified in arguments. The profile must be defined in
 the "config.json" file of the project. If the profile name is not specified, Regolith uses "default"
 profile.
+
+By default, the filters inherit all of the environment variables of Regolith. The "--isolate-env"
+flag makes the filters run with a minimal set of variables required by most of the runtimes (like
+PATH, HOME, TEMP or SYSTEMROOT) and the variables created by Regolith (FILTER_DIR, ROOT_DIR and
+DEBUG). Additional variables can be passed to the filters with the "--allow-env" flag, for example:
+"regolith run --isolate-env --allow-env JAVA_HOME,NODE_PATH".
+
+The "--dotenv" flag (or the "dotenv" property of the "regolith" object in "config.json") loads
+additional variables for the filters from the ".env" file in the root of the project, if the file
+exists. The variables from the file never override the environment variables of Regolith. The
+"--env-file <path>" flag loads the variables from a different file, which must exist. The values
+from the file are replaced with "***" in the logged output of the filters.
+
+Pressing Ctrl+C during "regolith run" lets the current filter finish, then stops the run before the
+next filter, removes the temporary files and exits with the code 130. Pressing Ctrl+C again stops
+the filter and exits immediately.
+
+The files of the data folder are linked to the temporary files instead of being copied, which makes
+preparing large data folders faster. The hardlinked files are replaced with copies before running
+the first filter that can modify the data folder. The "--no-hardlink" flag always copies the data
+folder.
+
+The "--var key=value" flag sets a variable that replaces the "${var:key}" placeholders in the
+"settings" of the filters, which lets you change the settings without editing "config.json", for
+example "regolith run --var debug=true --var version=1.2.0". The flag can be used multiple times. A
+placeholder can have a default value used when the variable is not set: "${var:debug=false}". The
+values are strings, unless a setting consists only of a "${json:key}" placeholder. Its value is
+parsed as JSON, so "true" becomes a boolean and "5" a number. Only the placeholders of the selected
+profile and the profiles that it runs are replaced.
+
+The "--clean" flag removes the temporary files and the cached outputs of the filters left by the
+previous runs before running the profile, which guarantees a clean build. Unlike "regolith clean",
+it doesn't remove the installed filters.
+
+The "--dry-run" flag checks the profile and prepares the temporary files, but instead of running the
+filters it prints their names, types, settings and working directory. The filters of the nested
+profiles are indented. The project is not exported.
+
+The "--benchmark <n>" flag runs the profile n times (after an additional warmup run, which is not
+measured) and prints the minimal, median, mean and maximal execution times of every filter, of the
+export and of the whole run. The temporary files are reset before every run. Use the "--no-export"
+flag to measure only the filters.
+
+The "--since <gitref>" flag passes the list of the source files changed since the git reference
+(including the uncommitted, the deleted and the untracked files) to the filters in the
+REGOLITH_CHANGED_FILES environment variable, as a JSON array of paths relative to the working
+directory of the filters, for example ["BP/entities/pig.json"]. The filters that support it can
+process only the changed files, the other filters process all of them. All of the source files are
+always passed to the filters.
+
+The "--profile-file <path>" flag runs a profile defined in a separate JSON file instead of a profile
+from "config.json", which is useful for experimenting without modifying the config file. The file
+contains a single profile object (with the "filters" and "export" properties) that can use the
+filters from the "filterDefinitions" list of the config and extend or run the other profiles. The
+profile is named after the path to the file, unless a profile name is specified. The name can't be
+the name of an existing profile.
+
+The "--keep-tmp" flag copies the packs and the data of the filters out of the temporary files instead
+of moving them, so the results of the filters stay in the ".regolith/tmp" folder after the run. Use
+"regolith inspect-tmp" to print the kept files.
+
+The "--filter-logs" flag saves the output of each filter to the
+".regolith/logs/<profile>/<filter>.log" file, in addition to printing it. The lines from the stderr
+are prefixed with "[stderr]". If a filter fails, the error message contains the path to its log
+file. The log files of the profile are removed at the start of every run.
+
+The "--report-changes" flag logs a summary of the changes made by each filter in the temporary
+files: the number of the added, modified and deleted files and the change of their total size, for
+example "Filter my_filter changes: 12 added, 3 modified, 0 deleted, size +1.5 MiB". It helps to find
+the filters that unexpectedly increase the size of the output. Comparing the files before and after
+every filter makes the run slower.
+
+The "--profile-report <path>" flag saves the execution times of the filters and of the export in a
+JSON file. Every execution of a filter is recorded with its ID, duration in milliseconds and exit
+status ("success" or "failure"). The report is saved even if the profile fails, so it contains the
+filters that ran before the failure.
+
+The "--max-memory <size>" flag limits the memory of the processes of the filters, so a runaway filter
+can't use up the memory of the whole system. The size uses the K, M and G units, for example "512M".
+The allocations above the limit fail, which stops most of the filters with an error. The limit
+applies to each process of the filter separately, including the processes it starts. It's only
+supported on Linux, on other systems the flag is ignored with a warning.
+
+By default, Regolith stops at the first filter that fails. The "--fail-fast=false" flag runs the
+remaining filters after a failure, which is useful for the profiles of linting filters. At the end,
+Regolith fails with an error that lists all of the failed filters. The project is not exported,
+unless the "--export-on-error" flag is used and at least one of the filters succeeded.
+
+The "--export-manifest <path>" flag saves the list of the exported files of the resource pack and
+the behavior pack together with their hashes in a JSON file. Use "regolith changelog" to compare the
+manifests of two builds.
+
+The "--export-target path=<path>" flag exports the packs to the "BP" and "RP" subdirectories of the
+given path instead of the export targets of the profile, which is useful for redirecting the output
+in CI without editing "config.json". The directory is created if it doesn't exist. Regolith checks
+if it's writable before running the filters.
+
+The "--require-clean-git" flag makes the command fail if the git repository of the project has
+uncommitted changes and lists the changed files. It's useful for release builds that should always be
+built from a commit. If the project isn't a git repository, the check is skipped with a warning.
+
+The "--summary-only" flag hides the logs of the filters that succeeded. If a filter or the export
+fails, its logs are printed in full. At the end of the run, Regolith prints a table with the
+execution time and the status of every filter and of the export, followed by the result of the run.
+It's useful for keeping the logs of CI builds short.
+
+Only one instance of Regolith can work on a project at the same time. By default, the command fails
+immediately if the project is used by another instance. The "--lock-timeout <seconds>" flag makes
+Regolith wait for the other instance to finish.
+
+The "--config <path>" flag loads the configuration from a different file than "config.json", which
+makes it possible to keep multiple configurations in one project, for example:
+"regolith run --config build-config.json dev". The same flag is supported by the other commands that
+read the config file, like "regolith install", "regolith install-all", "regolith update" and
+"regolith export".
 `
 const regolithWatchDesc = `
 This command starts Regolith in the watch mode. This mode will trigger the "regolith run" command
 every time a change in files of the project's RP, BP, or data folders is detected. "regolith watch"
 uses the same syntax as "regolith run". You can use "regolith help run" to learn more about the
 command.
+
+The "--initial-clean" flag removes the temporary files and the cached outputs of the filters left by
+the previous runs once, before the first run of the watch session, like the "--clean" flag of
+"regolith run". It's useful after switching branches, when the stale files could make the
+first build wrong. The following runs of the session are not affected.
+
+The "--watch-paths" flag limits the watcher to the listed subdirectories of the resource pack, the
+behavior pack and the data folder. The "--ignore-paths" flag accepts glob patterns of the
+directories that are not watched at all, for example "packs/data/generated" or "*_cache" (patterns
+without slashes are matched against the names of the directories). The flags override the
+"watchPaths" and "ignorePaths" properties of the "regolith" object in "config.json".
+
+The "--initial-verify" flag checks whether the installed filters match the "config.json" file
+before the first run, the same way as "regolith verify". The watch session doesn't start if any
+problems are found.
+
+By default, the profile is run once when the watch session starts. Use "--run-on-start=false" to
+skip that run and wait for the first change of the files instead.
+
+After the first run of the session, Regolith exports only the packs that changed since their previous
+export. For example, if a change of the resource pack doesn't affect the behavior pack, only the
+resource pack is exported again. Use "--always-export-all" to export all of the packs after every
+run.
+`
+const regolithExportDesc = `
+This command exports the packs from the last run of the profile again, without running the filters.
+The packs are taken from the temporary files of Regolith, so the command fails if there is no build
+to export. Run the profile with "regolith run --keep-tmp" to keep the build for this command.
+Without the "--keep-tmp" flag, "regolith run" moves the packs out of the temporary files if the
+profile has only one export target, so the command only works after the runs of the profiles with
+multiple export targets or with an archive export target ("zip" or "tar"), and after the previous
+"regolith export".
+
+The "--target <name>" flag replaces the export targets of the profile with the target of the given
+name (like "local", "development" or "zip"). The other properties of the export target of the
+profile (like "rpPath" and "bpPath") are kept.
+
+The data of the filters isn't exported, because it was already exported by the last run.
+`
+const regolithInspectTmpDesc = `
+Prints the tree of the files in the temporary files of Regolith (the ".regolith/tmp" folder) with
+their sizes. The files are kept there after running the profile with "regolith run --keep-tmp", which
+is useful for checking what exactly the filters produced.
+
+The "--depth <n>" flag limits the number of the printed levels of the tree. 0 means no limit.
+`
+const regolithMigrateDesc = `
+Upgrades the "config.json" file written for an older version of Regolith to the current format. The
+command prints every change it applies:
+- The "$schema" property is set to the schema of the current version of the config.
+- The "dataPath" property of the profiles is moved to the "regolith" object. The data path is added
+  if it's missing.
+- The "unsafe" property of the profiles, which is no longer supported, is removed.
+- The profiles without the "export" property get the "development" export target. The profiles that
+  extend other profiles are skipped because they inherit the export target.
+- The definitions of the remote filters without the "version" property get the "HEAD" version.
+
+The original file is saved next to the config file with the ".bak" extension. Running the command on
+a config that is already up to date doesn't modify anything. Note that the comments of the config
+file are not preserved.
+`
+const regolithListProfilesDesc = `
+Prints the names of the profiles defined in the "config.json" file. Profiles with the optional
+"description" property are listed together with their descriptions, which makes it easier to pick
+the right profile for "regolith run" and "regolith watch".
+`
+const regolithProfileDiffDesc = `
+Compares two profiles from the "config.json" file and prints their differences: the filters that
+exist only in one of the profiles, the filters that run in a different order, the filters with
+different settings, arguments or other properties, and the differences of the export targets. The
+profiles are compared after applying their "extends" properties, so the inherited filters are
+compared too. If a profile runs the same filter multiple times, the next occurrences of the filter
+are named with the "#2", "#3"... suffixes.
+
+Use the "--json" flag to print the differences as JSON, which is easier to process with other tools.
+`
+const regolithGraphDesc = `
+Prints a diagram of the profiles from the "config.json" file, the filters they run, the profiles
+they reference (with the profile filters or with the "extends" property), and the subfilters and the
+filter dependencies of the installed remote filters. Nothing is run or installed. The remote filters
+that are not installed are marked on the diagram and not expanded.
+
+The optional profile name limits the diagram to the profile and the profiles it references. The
+"--format" flag selects the format of the diagram: "dot" (the default) for Graphviz, or "mermaid"
+for Mermaid, which can be embedded in Markdown files. The diagram is printed to the standard output,
+unless the "--output" flag specifies a file:
+
+regolith graph default --output graph.dot
+dot -Tsvg graph.dot -o graph.svg
+`
+const regolithChangelogDesc = `
+Compares two export manifests created with "regolith run --export-manifest" and prints the files
+that were added ("+"), removed ("-") and modified ("~") between the builds. The changes are grouped
+by the pack and by the category of the file, which is the name of its top-level folder inside of the
+pack (for example "entities", "items" or "textures").
+
+The comparison only uses the hashes from the manifests, so it doesn't need the exported files. Use
+the "--output" flag to save the changes as JSON, for example to generate the release notes.
+`
+const regolithWhichDesc = `
+Prints where the filter from the "filterDefinitions" list of the "config.json" file is installed:
+the path to the filter in the filter cache, the version pinned in the config file, the version of
+the installed filter and the SHA of the commit used to install it (from the lock file). The local
+filters are not installed, so only their type is printed. Use the "--json" flag to print the
+information as JSON.
+`
+const regolithFilterInfoDesc = `
+Prints the information about a remote filter without installing it: its description, the versions
+available in its repository, the runtimes it uses (the "runWith" values of its subfilters) and the
+packages it depends on. The filter is identified the same way as in "regolith install", for example
+"name_ninja", "github.com/Bedrock-OSS/regolith-filters/name_ninja" or "name_ninja==1.0.0". The
+filter is downloaded to a temporary directory, which is removed afterwards. The "config.json" file
+and the filter cache are not modified. Use the "--json" flag to print the information as JSON.
+`
+const regolithAddProfileDesc = `
+Adds a new profile to the "config.json" file. The profile has an empty list of filters and uses the
+"development" export target, just like the "default" profile created by "regolith init". The
+command fails if the profile already exists, unless the "--force" flag is used to replace it. The
+rest of the config file keeps the order of its properties.
 `
 const regolithApplyFilter = `
 This command runs single selected filter and applies its changes to the project source files. Running
@@ -48,6 +283,32 @@ The "regolith apply-filter" command runs on a copy of the project's files and co
 project only if the filter is successful. This means that if the filter fails, the project's files
 aren't modified.
 `
+const regolithTestDesc = `
+Runs a filter on the test fixtures and compares the results with the expected outputs. The command is
+useful for the authors of the filters. The filter must be defined in the "filterDefinitions" section
+of the "config.json" file.
+
+Every subfolder of the tests folder ("tests" by default, can be changed with the "--tests" flag) with
+an "input" folder is a test fixture. The "input" folder contains the "BP", "RP" and "data" folders
+passed to the filter, and the "expected" folder contains the folders expected after running the
+filter. Only the folders that exist in the "expected" folder are compared. The filter runs on a copy
+of the files in the temporary files of Regolith, so the fixtures are never modified.
+
+The command prints whether each fixture passed or failed and the unified diffs of the files with
+unexpected content. The names of the fixtures can be passed after the name of the filter to run only
+some of them. The command fails if any of the fixtures fails.
+`
+const regolithFilterDesc = `
+Commands for managing the filter definitions of the project.
+`
+const regolithFilterRenameDesc = `
+Renames a filter definition in the "config.json" file and updates every reference to the filter in
+the profiles. The command fails if another filter definition already uses the new name.
+
+Renaming a remote filter moves its downloaded files and its entry in the lock file, and saves the
+old name in the "remoteName" property of the filter, which is used to find the filter in its
+repository. Use the "--config" flag to rename the filter in a different config file.
+`
 const regolithInstallDesc = `
 Downloads and installs Regolith filters from the internet, and adds them to the "filterDefinitions"
 list of the project's "config.json" file. This command accepts multiple arguments, each of which
@@ -92,6 +353,29 @@ Where:
 
 The "regolith install" combined with the "--force" flag can be used to change/update filters saved
 in the "config.json".
+
+The "--upgrade" flag installs the filters that are not installed yet and updates the filters that
+are already on the "filterDefinitions" list to the requested versions, so the same command works
+whether the filter is installed or not. Unlike "--force", it doesn't download the filters that are
+already up to date, and it doesn't replace the filters that are not remote filters.
+
+The "--no-config-write" flag downloads the filters into the cache without adding them to the
+"config.json" and "regolith-lock.json" files. It's useful for trying out filters without modifying
+the tracked files of the project.
+
+Instead of the filters, the arguments can be names of the filter groups from the "filterGroups"
+object of the "config.json" file. The filters of the groups are installed together with the
+versions from their definitions. If any of them fails to install, the previous versions of all of
+the filters of the groups are restored. Filter groups can't be mixed with other filters in one
+command.
+
+The "--file <path>" flag reads the filters from a requirements file, which makes installing many
+filters at once scriptable. Every line of the file uses the same syntax as the arguments of the
+command (for example "name_ninja==1.0.0"). Empty lines and lines starting with "#" are ignored. The
+filters from the file are installed together with the filters passed as arguments.
+
+If the repository of a filter has Git submodules, they're initialized after checking out the
+version of the filter. Use the "--no-submodules" flag to skip them.
 `
 const regolithInstallAllDesc = `
 This commands installs or updates all of the filters specified in the "filterDefinitions" list of
@@ -102,14 +386,69 @@ It is useful when you're starting to work on a project which already has a "conf
 a bunch of filters defined in it or when the config file was modified by someone else and you want
 to make sure that your local copies of the filters is up to date.
 
-By default, the filters that are already installed with a correct version are ignored. You can
-change that by using the "--force" flag. "regolith install-all --force" forcefully reinstalls every
-filter on the project.
+By default, the filters that are already installed with a correct version are ignored, and the
+command lists the skipped and the refreshed filters at the end. You can change that by using the
+"--force" flag. "regolith install-all --force" forcefully reinstalls every filter on the project.
+
+Every installation updates the "regolith-lock.json" file, which stores the exact versions and commit
+SHAs of the installed filters. When the lock file exists, the filters with "HEAD" or "latest"
+versions are installed using the SHAs pinned in the lock file, so every member of the team uses
+the same versions of the filters. Use the "--update" flag to ignore the pinned SHAs and install the
+newest versions instead.
+
+The "--dry-install" flag lists the filters that would be installed or reinstalled without
+modifying anything. The command exits with a non-zero status code if any filter needs to be
+installed, so it can be used in CI to fail fast when the cache is out of sync with the config.
+
+The "--no-submodules" flag skips initializing the Git submodules of the repositories of the filters.
+`
+const regolithUpdateDesc = `
+Updates the selected filters from the "filterDefinitions" list of the "config.json" file to the
+newest versions allowed by their definitions. The filters with "HEAD" or "latest" versions are
+updated even if their SHAs are pinned in the "regolith-lock.json" file, and the lock file is updated
+with the new versions.
+
+The names of the filters can be glob patterns, for example "regolith update team_*" updates every
+filter whose name starts with "team_". Remember to quote the patterns if your shell expands them.
+Patterns that don't match any filter are reported with a warning.
+
+The names of the filter groups from the "filterGroups" object of the "config.json" file can be used
+to update all of the filters of a group. The filters of the groups are updated together - if any of
+them fails to update, the previous versions of all of them are restored.
+
+The "--no-submodules" flag skips initializing the Git submodules of the repositories of the filters.
+`
+const regolithFreezeDesc = `
+Pins the remote filters with "HEAD" or "latest" versions in the "filterDefinitions" list of the
+"config.json" file to concrete versions. The "HEAD" versions are replaced with the SHAs of the
+current commits of the repositories of the filters, and the "latest" versions with the newest
+version tags of the filters. This makes the builds of the project reproducible without giving up
+the convenience of installing the filters with "HEAD" or "latest" versions.
+
+The other properties of the config file are preserved. The "regolith-lock.json" file is updated
+with the filters that are already installed with the pinned versions. Run "regolith install-all"
+afterwards to install the other pinned versions.
+
+The "--dry-run" flag prints the versions that would be pinned without modifying the config file.
+`
+const regolithVerifyDesc = `
+Checks whether the filters installed in the Regolith cache match the "filterDefinitions" list of
+the "config.json" file. The command reports the filters that are missing from the cache, the
+filters installed with a different version than required, and the orphaned folders of the filters
+that are no longer defined in the config. The "HEAD" and "latest" filters are compared with the
+versions pinned in the "regolith-lock.json" file if it exists.
+
+The command doesn't modify anything. It exits with a non-zero status code if any problems are
+found, which makes it useful in CI before running "regolith run".
 `
 const regolithInitDesc = `
 Initializes a new Regolith project in the current directory. The folder used for a new project must
 be an empty directory. This command creates "config.json" and a few empty folders to be used for
 RP, BP, data, and Regolith cache (.regolith folder).
+
+The "--template <git-url>" flag creates the project from a template repository instead. The
+repository is cloned into the current directory and its ".git" folder is removed. The template must
+contain a valid "config.json" file.
 `
 const regolithCleanDesc = `
 This command cleans the Regolith cache files for the currently opened project. With the default
@@ -134,6 +473,40 @@ protect your files.
 If you're using the "useAppData" property in your projects. It is recommended to periodically clean
 the Regolith data folder to remove the cache files of the projects that you don't work on anymore.
 You can clear caches of all projects stored in user data by using the "--user-cache" flag.
+
+The "--filter-cache" flag only removes the cached outputs of the filters with the "cacheable"
+property. Use it after changing the code of a local cacheable filter, because the cache doesn't
+track the changes of the filter's code.
+
+The "--dry-run" flag prints the paths that would be removed, with their top-level entries and
+sizes, without removing anything. It can be combined with the other flags.
+`
+const regolithUnlockDesc = `
+Removes the session lock of the project. Regolith locks the session while it runs a command that
+uses the cache of the project, to prevent multiple instances from modifying the same files. The
+locks of the processes that no longer exist are normally reclaimed automatically, but if Regolith
+crashes and the lock can't be reclaimed, the other commands keep failing with an error saying that
+another instance of Regolith is running.
+
+The command checks the process ID recorded in the lock and refuses to remove the lock if that
+process is still running. The "--force" flag removes the lock anyway.
+`
+const regolithCompletionsDesc = `
+Prints the autocompletion script of Regolith for the specified shell. The supported shells are
+bash, zsh, fish and powershell. Besides the commands and their flags, the scripts complete the
+names of the profiles for "regolith run", "regolith watch" and "regolith export" and the names of
+the filters from the "filterDefinitions" list for the commands that take a filter name, like
+"regolith update" or "regolith apply-filter". The names are read from the "config.json" file of the
+current directory when you press Tab, so they are always up to date.
+
+To load the completions in the current shell session:
+  bash:       source <(regolith completions bash)
+  zsh:        source <(regolith completions zsh)
+  fish:       regolith completions fish | source
+  powershell: regolith completions powershell | Out-String | Invoke-Expression
+
+To load them for every session, save the output of the command in the completions directory of your
+shell, for example "regolith completions fish > ~/.config/fish/completions/regolith.fish".
 `
 
 const regolithConfigDesc = `
@@ -155,6 +528,35 @@ Deleting item in a list property:               regolith config <key> --index <i
 The printing commands can take the --full flag to print configuration with the default values
 included (if they're not defined in the config file). Without the flag, the undefined properties
 will be printed as null or empty list.
+
+The "get" and "set" subcommands read and modify the "config.json" file of the project instead of
+the user configuration.
+`
+const regolithConfigGetDesc = `
+Prints the value of a property of the "config.json" file of the project. The property is selected
+with a dotted path, like "profiles.default.export.target". The paths that don't start with one of
+the top-level properties of the config file ("name", "author", "packs", "regolith", ...) are
+relative to the "regolith" object. The items of the arrays are selected with their indices, for
+example "profiles.default.filters.0.filter".
+
+The strings are printed without quotes and the other values are printed as JSON, so the output can
+be used by scripts.
+
+Use the "--config" flag to read the property from a different config file.
+`
+const regolithConfigSetDesc = `
+Sets the value of a property of the "config.json" file of the project. The property is selected
+with a dotted path, the same way as in "regolith config get". The missing objects on the path are
+created.
+
+The value is parsed as JSON if possible, so "true", "10", "null", "[1, 2]" and "{}" set a boolean, a
+number, null, an array and an object. The other values are set as strings. Use quotes to set a
+string that looks like JSON, for example '"true"'.
+
+The modified configuration is checked before saving it. If it's invalid, the command fails and the
+config file is not modified. The comments of the config file are not preserved.
+
+Use the "--config" flag to modify a different config file.
 `
 
 func main() {
@@ -164,20 +566,33 @@ func main() {
 		if regolith.Logger == nil { // Logger is nil when the command is 'help' or 'completion'
 			return
 		}
-		if err != nil {
+		if err == regolith.ErrRunCancelled {
+			regolith.Logger.Warn(err)
+			os.Exit(regolith.CancelledExitCode)
+		} else if err != nil {
 			regolith.Logger.Error(err)
 			os.Exit(1)
 		} else {
 			regolith.Logger.Info(color.GreenString("Finished"))
 		}
 	}()
-	// Schedule update status check
+	// Schedule update status check. It starts after parsing the flags,
+	// because the "--offline" flag disables it.
 	status := make(chan regolith.UpdateStatus)
-	go regolith.CheckUpdate(version, status)
+	checkingUpdate := false
+	cobra.OnInitialize(func() {
+		if !regolith.Offline {
+			checkingUpdate = true
+			go regolith.CheckUpdate(version, status)
+		}
+	})
 	defer func() {
 		if regolith.Logger == nil { // Logger is nil when the command is 'help' or 'completion'
 			return
 		}
+		if !checkingUpdate {
+			return
+		}
 		updateStatus := <-status
 		if updateStatus.Err != nil {
 			regolith.Logger.Warn("Update check failed")
@@ -197,48 +612,184 @@ func main() {
 		Long:    regolithDesc,
 		Version: version,
 	}
+	// The "completions" command replaces the default "completion" command of
+	// cobra, which is kept as an alias
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
 	subcomands := make([]*cobra.Command, 0)
 
+	// completionConfigPath returns the value of the "--config" flag of the
+	// command or an empty string if the command doesn't have this flag.
+	completionConfigPath := func(cmd *cobra.Command) string {
+		if flag := cmd.Flags().Lookup("config"); flag != nil {
+			return flag.Value.String()
+		}
+		return ""
+	}
+	// completeProfiles completes the profile name, which is the first
+	// argument of the command
+	completeProfiles := func(
+		cmd *cobra.Command, args []string, toComplete string,
+	) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return regolith.CompleteProfileNames(completionConfigPath(cmd), toComplete),
+			cobra.ShellCompDirectiveNoFileComp
+	}
+	// completeFilter completes the filter name, which is the first argument
+	// of the command
+	completeFilter := func(
+		cmd *cobra.Command, args []string, toComplete string,
+	) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return regolith.CompleteFilterNames(completionConfigPath(cmd), toComplete, false),
+			cobra.ShellCompDirectiveNoFileComp
+	}
+
 	// regolith init
+	var template string
 	cmdInit := &cobra.Command{
 		Use:   "init",
 		Short: "Initializes a Regolith project in current directory",
 		Long:  regolithInitDesc,
 		Run: func(cmd *cobra.Command, _ []string) {
-			err = regolith.Init(burrito.Debug)
+			err = regolith.Init(burrito.Debug, template)
 		},
 	}
+	cmdInit.Flags().StringVarP(
+		&template, "template", "t", "", "URL of a git repository to copy the new project from.")
 	subcomands = append(subcomands, cmdInit)
 	// regolith install
-	var force bool
+	var force, upgrade, noConfigWrite, noSubmodules bool
+	var configPath, filtersFile string
 	cmdInstall := &cobra.Command{
 		Use:   "install [filters...]",
 		Short: "Downloads and installs filters from the internet and adds them to the filterDefinitions list",
-		Long:  regolithInitDesc,
+		Long:  regolithInstallDesc,
 		Run: func(cmd *cobra.Command, filters []string) {
+			if filtersFile != "" {
+				regolith.InitLogging(burrito.Debug)
+				fileFilters, err1 := regolith.LoadFilterRequirements(filtersFile)
+				if err1 != nil {
+					err = burrito.WrapError(err1, "Failed to load the filters from the file.")
+					return
+				}
+				filters = append(filters, fileFilters...)
+			}
 			if len(filters) == 0 {
 				cmd.Help()
 				return
 			}
-			err = regolith.Install(filters, force, burrito.Debug)
+			err = regolith.Install(filters, regolith.InstallOptions{
+				ConfigPath:    configPath,
+				Force:         force,
+				Upgrade:       upgrade,
+				NoConfigWrite: noConfigWrite,
+				NoSubmodules:  noSubmodules,
+			}, burrito.Debug)
 		},
 	}
 	cmdInstall.Flags().BoolVarP(
 		&force, "force", "f", false, "Force the operation, overriding potential safeguards.")
+	cmdInstall.Flags().BoolVarP(
+		&upgrade, "upgrade", "u", false, "Update the filters that are already installed to the "+
+			"requested versions instead of failing.")
+	cmdInstall.Flags().BoolVarP(
+		&noConfigWrite, "no-config-write", "", false, "Only download the filters into the cache, "+
+			"without adding them to \"config.json\" and \"regolith-lock.json\".")
+	cmdInstall.Flags().StringVarP(
+		&filtersFile, "file", "", "", "Path to a file with the filters to install, one per line. "+
+			"Lines starting with \"#\" are comments.")
 	subcomands = append(subcomands, cmdInstall)
+	// regolith update
+	cmdUpdate := &cobra.Command{
+		Use:   "update <filters...>",
+		Short: "Updates selected filters to the newest versions allowed by the filterDefinitions list",
+		Long:  regolithUpdateDesc,
+		Run: func(cmd *cobra.Command, filters []string) {
+			if len(filters) == 0 {
+				cmd.Help()
+				return
+			}
+			err = regolith.Update(filters, noSubmodules, configPath, burrito.Debug)
+		},
+	}
+	cmdUpdate.ValidArgsFunction = func(
+		cmd *cobra.Command, args []string, toComplete string,
+	) ([]string, cobra.ShellCompDirective) {
+		// Every argument is a remote filter, skip the ones already listed
+		result := []string{}
+		filters := regolith.CompleteFilterNames(completionConfigPath(cmd), toComplete, true)
+		for _, filter := range filters {
+			listed := false
+			for _, arg := range args {
+				listed = listed || arg == filter
+			}
+			if !listed {
+				result = append(result, filter)
+			}
+		}
+		return result, cobra.ShellCompDirectiveNoFileComp
+	}
+	subcomands = append(subcomands, cmdUpdate)
 	// regolith install-all
+	var update, dryInstall bool
 	cmdInstallAll := &cobra.Command{
 		Use:   "install-all",
 		Short: "Installs all undownloaded or outdated filters defined in filterDefintions list",
 		Long:  regolithInstallAllDesc,
 		Run: func(cmd *cobra.Command, _ []string) {
-			err = regolith.InstallAll(force, burrito.Debug)
+			if dryInstall {
+				err = regolith.DryInstallAll(update, configPath, burrito.Debug)
+				return
+			}
+			err = regolith.InstallAll(regolith.InstallOptions{
+				ConfigPath:   configPath,
+				Force:        force,
+				Update:       update,
+				NoSubmodules: noSubmodules,
+			}, burrito.Debug)
 		},
 	}
 	cmdInstallAll.Flags().BoolVarP(
 		&force, "force", "f", false, "Force the operation, overriding potential safeguards.")
+	cmdInstallAll.Flags().BoolVarP(
+		&update, "update", "", false, "Ignore the SHAs pinned in the lock file and install the newest "+
+			"versions of the \"HEAD\" and \"latest\" filters.")
+	cmdInstallAll.Flags().BoolVarP(
+		&dryInstall, "dry-install", "", false, "List the filters that need to be installed without "+
+			"installing them. Exits with an error if any filter needs to be installed.")
 	subcomands = append(subcomands, cmdInstallAll)
+	// regolith freeze
+	var freezeDryRun bool
+	cmdFreeze := &cobra.Command{
+		Use:   "freeze",
+		Short: "Pins the \"HEAD\" and \"latest\" filters to concrete versions",
+		Long:  regolithFreezeDesc,
+		Run: func(cmd *cobra.Command, _ []string) {
+			err = regolith.Freeze(freezeDryRun, configPath, burrito.Debug)
+		},
+	}
+	cmdFreeze.Flags().BoolVarP(
+		&freezeDryRun, "dry-run", "", false, "Print the versions that would be pinned without "+
+			"modifying the config file.")
+	subcomands = append(subcomands, cmdFreeze)
+	// regolith verify
+	cmdVerify := &cobra.Command{
+		Use:   "verify",
+		Short: "Checks whether the installed filters match the filterDefinitions list",
+		Long:  regolithVerifyDesc,
+		Run: func(cmd *cobra.Command, _ []string) {
+			err = regolith.Verify(configPath, burrito.Debug)
+		},
+	}
+	subcomands = append(subcomands, cmdVerify)
 	// regolith run
+	var runOptions regolith.RunOptions
+	var lockTimeout int
+	var failFast bool
 	cmdRun := &cobra.Command{
 		Use:   "run [profile_name]",
 		Short: "Runs Regolith using specified profile",
@@ -248,11 +799,56 @@ func main() {
 			if len(args) != 0 {
 				profile = args[0]
 			}
-			err = regolith.Run(profile, burrito.Debug)
+			runOptions.LockTimeout = time.Duration(lockTimeout) * time.Second
+			runOptions.ContinueOnError = !failFast
+			err = regolith.Run(profile, runOptions, burrito.Debug)
 		},
 	}
+	cmdRun.Flags().BoolVarP(
+		&runOptions.DryRun, "dry-run", "", false, "Print the filters of the profile with their settings "+
+			"instead of running them. The project is not exported.")
+	cmdRun.Flags().IntVarP(
+		&runOptions.Benchmark, "benchmark", "", 0, "Run the profile the specified number of times and "+
+			"print the statistics of the execution times of the filters.")
+	cmdRun.Flags().BoolVarP(
+		&runOptions.NoExport, "no-export", "", false, "Skip exporting the project when using "+
+			"\"--benchmark\".")
+	cmdRun.Flags().StringVarP(
+		&runOptions.ExportManifest, "export-manifest", "", "", "Path to a JSON file to save the "+
+			"list of the exported files and their hashes in.")
+	cmdRun.Flags().StringVarP(
+		&runOptions.ProfileReport, "profile-report", "", "", "Path to a JSON file to save the "+
+			"execution times of the filters and the export in.")
+	cmdRun.Flags().BoolVarP(
+		&runOptions.SummaryOnly, "summary-only", "", false, "Hide the logs of the filters that "+
+			"succeeded and print a summary of the run at the end. The logs are printed if the run fails.")
+	cmdRun.Flags().BoolVarP(
+		&runOptions.RequireCleanGit, "require-clean-git", "", false, "Fail if the git repository "+
+			"of the project has uncommitted changes.")
+	cmdRun.Flags().BoolVarP(
+		&runOptions.Clean, "clean", "", false, "Remove the temporary files and the cached outputs of "+
+			"the filters before running the profile. The installed filters are kept.")
+	cmdRun.Flags().StringVarP(
+		&runOptions.ProfileFile, "profile-file", "", "", "Path to a JSON file with a profile to run "+
+			"instead of a profile from the config file. The profile can use the filter definitions of "+
+			"the config file.")
+	cmdRun.Flags().BoolVarP(
+		&runOptions.KeepTmp, "keep-tmp", "", false, "Keep the results of the filters in the "+
+			"temporary files after exporting the project.")
+	cmdRun.Flags().StringVarP(
+		&runOptions.Since, "since", "", "", "A git reference. The list of the source files changed "+
+			"since the reference is passed to the filters in the REGOLITH_CHANGED_FILES environment "+
+			"variable.")
+	cmdRun.Flags().BoolVarP(
+		&failFast, "fail-fast", "", true, "Stop at the first filter that fails. Use "+
+			"\"--fail-fast=false\" to run the remaining filters and report all of the failures at the end.")
+	cmdRun.Flags().BoolVarP(
+		&runOptions.ExportOnError, "export-on-error", "", false, "Export the results of the filters "+
+			"that succeeded when some of the filters fail with \"--fail-fast=false\".")
+	cmdRun.ValidArgsFunction = completeProfiles
 	subcomands = append(subcomands, cmdRun)
 	// regolith watch
+	var runOnStart bool
 	cmdWatch := &cobra.Command{
 		Use:   "watch [profile_name]",
 		Short: "Watches project files and automatically runs Regolith when they change",
@@ -262,10 +858,251 @@ func main() {
 			if len(args) != 0 {
 				profile = args[0]
 			}
-			err = regolith.Watch(profile, burrito.Debug)
+			runOptions.LockTimeout = time.Duration(lockTimeout) * time.Second
+			runOptions.SkipInitialRun = !runOnStart
+			err = regolith.Watch(profile, runOptions, burrito.Debug)
 		},
 	}
+	cmdWatch.Flags().BoolVarP(
+		&runOptions.InitialClean, "initial-clean", "", false, "Remove the temporary files and "+
+			"the cached outputs of the filters before starting the watch session.")
+	cmdWatch.Flags().BoolVarP(
+		&runOptions.InitialVerify, "initial-verify", "", false, "Check whether the installed filters "+
+			"match the config file before starting the watch session.")
+	cmdWatch.Flags().StringSliceVarP(
+		&runOptions.WatchPaths, "watch-paths", "", nil, "Subdirectories of the resource pack, the "+
+			"behavior pack and the data folder to watch instead of the whole folders.")
+	cmdWatch.Flags().StringSliceVarP(
+		&runOptions.IgnorePaths, "ignore-paths", "", nil, "Glob patterns of the directories that "+
+			"should not be watched.")
+	cmdWatch.Flags().BoolVarP(
+		&runOnStart, "run-on-start", "", true, "Run the profile when the watch session starts. "+
+			"Use \"--run-on-start=false\" to wait for the first change instead.")
+	cmdWatch.Flags().BoolVarP(
+		&runOptions.AlwaysExportAll, "always-export-all", "", false, "Export all of the packs after "+
+			"every run, including the packs that didn't change since the previous export.")
+	cmdWatch.ValidArgsFunction = completeProfiles
 	subcomands = append(subcomands, cmdWatch)
+	// add the flags shared by "regolith run" and "regolith watch"
+	for _, cmd := range []*cobra.Command{cmdRun, cmdWatch} {
+		cmd.Flags().BoolVarP(
+			&runOptions.IsolateEnv, "isolate-env", "", false, "Run the filters with a minimal set of "+
+				"environment variables instead of inheriting the whole environment.")
+		cmd.Flags().StringSliceVarP(
+			&runOptions.AllowedEnv, "allow-env", "", nil, "Names of additional environment variables "+
+				"passed to the filters when using \"--isolate-env\".")
+		cmd.Flags().BoolVarP(
+			&runOptions.DotEnv, "dotenv", "", false, "Load the environment variables of the filters from "+
+				"the \".env\" file in the root of the project.")
+		cmd.Flags().StringVarP(
+			&runOptions.EnvFile, "env-file", "", "", "Path to the file with the environment variables "+
+				"of the filters to load instead of the \".env\" file.")
+		cmd.Flags().IntVarP(
+			&lockTimeout, "lock-timeout", "", 0, "The number of seconds to wait for another instance of "+
+				"Regolith to release the project. 0 means no waiting.")
+		cmd.Flags().StringVarP(
+			&runOptions.ConfigPath, "config", "", "", "Path to the config file to use instead of "+
+				"\"config.json\".")
+		cmd.Flags().BoolVarP(
+			&runOptions.NoHardlink, "no-hardlink", "", false, "Copy the data folder to the temporary "+
+				"files instead of linking its files.")
+		cmd.Flags().StringArrayVarP(
+			&runOptions.Vars, "var", "", nil, "A variable in the \"key=value\" format that replaces "+
+				"the ${var:key} placeholders in the settings of the filters. Can be used multiple times.")
+		cmd.Flags().BoolVarP(
+			&runOptions.FilterLogs, "filter-logs", "", false, "Save the output of each filter to a "+
+				"separate log file in the \".regolith/logs/<profile>\" folder.")
+		cmd.Flags().BoolVarP(
+			&runOptions.ReportChanges, "report-changes", "", false, "Log the number of the files added, "+
+				"modified and deleted by each filter and the change of the total size of the files.")
+		cmd.Flags().StringVarP(
+			&runOptions.ExportTarget, "export-target", "", "", "Export the packs to the BP and RP "+
+				"subdirectories of the given path instead of the export targets of the profile. "+
+				"Uses the \"path=<path>\" format.")
+		cmd.Flags().StringVarP(
+			&runOptions.MaxMemory, "max-memory", "", "", "The memory limit of the processes of the "+
+				"filters, for example \"512M\" or \"2G\". Only supported on Linux.")
+	}
+	// regolith export
+	var exportTarget string
+	cmdExport := &cobra.Command{
+		Use:   "export [profile_name]",
+		Short: "Exports the last build of the profile without running the filters",
+		Long:  regolithExportDesc,
+		Run: func(cmd *cobra.Command, args []string) {
+			var profile string
+			if len(args) != 0 {
+				profile = args[0]
+			}
+			err = regolith.Export(profile, exportTarget, configPath, burrito.Debug)
+		},
+	}
+	cmdExport.Flags().StringVarP(
+		&exportTarget, "target", "", "", "The name of the export target that replaces the export "+
+			"targets of the profile.")
+	cmdExport.ValidArgsFunction = completeProfiles
+	// regolith inspect-tmp
+	var inspectDepth int
+	cmdInspectTmp := &cobra.Command{
+		Use:   "inspect-tmp",
+		Short: "Prints the files kept in the temporary files by \"regolith run --keep-tmp\"",
+		Long:  regolithInspectTmpDesc,
+		Run: func(cmd *cobra.Command, _ []string) {
+			err = regolith.InspectTmp(inspectDepth, burrito.Debug)
+		},
+	}
+	cmdInspectTmp.Flags().IntVarP(
+		&inspectDepth, "depth", "", 0, "The number of the printed levels of the tree. 0 means no "+
+			"limit.")
+	subcomands = append(subcomands, cmdInspectTmp)
+	// regolith migrate
+	cmdMigrate := &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrades config.json written for an older version of Regolith to the current format",
+		Long:  regolithMigrateDesc,
+		Run: func(cmd *cobra.Command, _ []string) {
+			err = regolith.Migrate(configPath, burrito.Debug)
+		},
+	}
+	subcomands = append(subcomands, cmdMigrate)
+	// regolith graph
+	var graphFormat, graphOutput string
+	cmdGraph := &cobra.Command{
+		Use:   "graph [profile_name]",
+		Short: "Prints a diagram of the profiles, the filters and their dependencies",
+		Long:  regolithGraphDesc,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) > 1 {
+				cmd.Help()
+				return
+			}
+			profile := ""
+			if len(args) == 1 {
+				profile = args[0]
+			}
+			err = regolith.Graph(
+				profile, graphFormat, graphOutput, configPath, burrito.Debug)
+		},
+	}
+	cmdGraph.Flags().StringVarP(
+		&graphFormat, "format", "", "dot", "The format of the diagram, \"dot\" (Graphviz) or "+
+			"\"mermaid\".")
+	cmdGraph.Flags().StringVarP(
+		&graphOutput, "output", "o", "", "Save the diagram to a file instead of printing it.")
+	cmdGraph.ValidArgsFunction = completeProfiles
+	subcomands = append(subcomands, cmdGraph)
+	// add the "--no-submodules" flag to the commands that download filters
+	for _, cmd := range []*cobra.Command{cmdInstall, cmdUpdate, cmdInstallAll} {
+		cmd.Flags().BoolVarP(
+			&noSubmodules, "no-submodules", "", false, "Don't initialize the Git submodules of the "+
+				"repositories of the filters.")
+	}
+	subcomands = append(subcomands, cmdExport)
+	// regolith list-profiles
+	cmdListProfiles := &cobra.Command{
+		Use:   "list-profiles",
+		Short: "Lists the profiles from config.json with their descriptions",
+		Long:  regolithListProfilesDesc,
+		Run: func(cmd *cobra.Command, _ []string) {
+			err = regolith.ListProfiles(configPath, burrito.Debug)
+		},
+	}
+	subcomands = append(subcomands, cmdListProfiles)
+	// regolith profile-diff
+	var profileDiffJson bool
+	cmdProfileDiff := &cobra.Command{
+		Use:   "profile-diff <profile_a> <profile_b>",
+		Short: "Compares the filters and the export targets of two profiles",
+		Long:  regolithProfileDiffDesc,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 2 {
+				cmd.Help()
+				return
+			}
+			err = regolith.ProfileDiff(args[0], args[1], profileDiffJson, configPath, burrito.Debug)
+		},
+		ValidArgsFunction: func(
+			cmd *cobra.Command, args []string, toComplete string,
+		) ([]string, cobra.ShellCompDirective) {
+			if len(args) >= 2 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return regolith.CompleteProfileNames(completionConfigPath(cmd), toComplete),
+				cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	cmdProfileDiff.Flags().BoolVarP(
+		&profileDiffJson, "json", "", false, "Print the differences as JSON.")
+	subcomands = append(subcomands, cmdProfileDiff)
+	// regolith changelog
+	var changelogOutput string
+	cmdChangelog := &cobra.Command{
+		Use:   "changelog <previous_manifest> <current_manifest>",
+		Short: "Compares the export manifests of two builds",
+		Long:  regolithChangelogDesc,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 2 {
+				cmd.Help()
+				return
+			}
+			err = regolith.Changelog(args[0], args[1], changelogOutput, burrito.Debug)
+		},
+	}
+	cmdChangelog.Flags().StringVarP(
+		&changelogOutput, "output", "o", "", "Path to a JSON file to save the changes in.")
+	subcomands = append(subcomands, cmdChangelog)
+	// regolith which
+	var whichJson bool
+	cmdWhich := &cobra.Command{
+		Use:   "which <filter_name>",
+		Short: "Prints the path and the version of an installed filter",
+		Long:  regolithWhichDesc,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				cmd.Help()
+				return
+			}
+			err = regolith.Which(args[0], whichJson, configPath, burrito.Debug)
+		},
+	}
+	cmdWhich.Flags().BoolVarP(
+		&whichJson, "json", "", false, "Print the information as JSON.")
+	cmdWhich.ValidArgsFunction = completeFilter
+	subcomands = append(subcomands, cmdWhich)
+	// regolith filter-info
+	var filterInfoJson bool
+	cmdFilterInfo := &cobra.Command{
+		Use:   "filter-info <filter_url>",
+		Short: "Prints the information about a remote filter without installing it",
+		Long:  regolithFilterInfoDesc,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				cmd.Help()
+				return
+			}
+			err = regolith.FilterInfo(args[0], filterInfoJson, burrito.Debug)
+		},
+	}
+	cmdFilterInfo.Flags().BoolVarP(
+		&filterInfoJson, "json", "", false, "Print the information as JSON.")
+	subcomands = append(subcomands, cmdFilterInfo)
+	// regolith add-profile
+	var forceAddProfile bool
+	cmdAddProfile := &cobra.Command{
+		Use:   "add-profile <profile_name>",
+		Short: "Adds a new profile to config.json",
+		Long:  regolithAddProfileDesc,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				cmd.Help()
+				return
+			}
+			err = regolith.AddProfile(args[0], forceAddProfile, configPath, burrito.Debug)
+		},
+	}
+	cmdAddProfile.Flags().BoolVarP(
+		&forceAddProfile, "force", "f", false, "Replace the profile if it already exists.")
+	subcomands = append(subcomands, cmdAddProfile)
 	// regolith apply-filter
 	cmdApplyFilter := &cobra.Command{
 		Use:   "apply-filter <filter_name> [filter_args...]",
@@ -278,18 +1115,59 @@ func main() {
 			}
 			filter := args[0]
 			filterArgs := args[1:] // First arg is the filter name
-			err = regolith.ApplyFilter(filter, filterArgs, burrito.Debug)
+			err = regolith.ApplyFilter(filter, filterArgs, configPath, burrito.Debug)
 		},
 	}
+	cmdApplyFilter.ValidArgsFunction = completeFilter
 	subcomands = append(subcomands, cmdApplyFilter)
+	// regolith test
+	var testsPath string
+	cmdTest := &cobra.Command{
+		Use:   "test <filter_name> [fixtures...]",
+		Short: "Runs a filter on the test fixtures and compares the results with the expected outputs",
+		Long:  regolithTestDesc,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				cmd.Help()
+				return
+			}
+			err = regolith.RunFilterTests(args[0], args[1:], testsPath, configPath, burrito.Debug)
+		},
+	}
+	cmdTest.Flags().StringVarP(
+		&testsPath, "tests", "", "tests", "Path to the folder with the test fixtures.")
+	cmdTest.ValidArgsFunction = completeFilter
+	subcomands = append(subcomands, cmdTest)
+	// regolith filter
+	cmdFilter := &cobra.Command{
+		Use:   "filter",
+		Short: "Manages the filter definitions of the project",
+		Long:  regolithFilterDesc,
+	}
+	// regolith filter rename
+	cmdFilterRename := &cobra.Command{
+		Use:   "rename <old_name> <new_name>",
+		Short: "Renames a filter definition and updates the profiles using it",
+		Long:  regolithFilterRenameDesc,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 2 {
+				cmd.Help()
+				return
+			}
+			err = regolith.RenameFilter(args[0], args[1], configPath, burrito.Debug)
+		},
+	}
+	cmdFilterRename.ValidArgsFunction = completeFilter
+	cmdFilter.AddCommand(cmdFilterRename)
+	subcomands = append(subcomands, cmdFilter)
 	// regolith clean
-	var userCache bool
+	var userCache, filterCache, dryClean bool
 	cmdClean := &cobra.Command{
 		Use:   "clean",
 		Short: "Cleans Regolith cache",
 		Long:  regolithCleanDesc,
 		Run: func(cmd *cobra.Command, _ []string) {
-			err = regolith.Clean(burrito.Debug, userCache)
+			err = regolith.Clean(burrito.Debug, userCache, filterCache, dryClean)
 		},
 	}
 
@@ -312,15 +1190,111 @@ func main() {
 	cmdConfig.Flags().BoolP("delete", "d", false, "Delete property")
 	cmdConfig.Flags().BoolP("append", "a", false, "Append value to array property")
 	cmdConfig.Flags().IntP("index", "i", -1, "The index of the array property on which to act")
+	// regolith config get
+	cmdConfigGet := &cobra.Command{
+		Use:   "get <path>",
+		Short: "Prints a property of the config.json file",
+		Long:  regolithConfigGetDesc,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				cmd.Help()
+				return
+			}
+			err = regolith.ConfigGet(args[0], configPath, burrito.Debug)
+		},
+	}
+	cmdConfig.AddCommand(cmdConfigGet)
+	// regolith config set
+	cmdConfigSet := &cobra.Command{
+		Use:   "set <path> <value>",
+		Short: "Sets a property of the config.json file",
+		Long:  regolithConfigSetDesc,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 2 {
+				cmd.Help()
+				return
+			}
+			err = regolith.ConfigSet(args[0], args[1], configPath, burrito.Debug)
+		},
+	}
+	cmdConfig.AddCommand(cmdConfigSet)
 	subcomands = append(subcomands, cmdConfig)
 
 	cmdClean.Flags().BoolVarP(
 		&userCache, "user-cache", "u", false, "Clears all caches stored in user data, instead of the cache of "+
 			"the current project")
+	cmdClean.Flags().BoolVarP(
+		&filterCache, "filter-cache", "", false, "Clears only the cached "+
+			"outputs of the cacheable filters of the current project")
+	cmdClean.Flags().BoolVarP(
+		&dryClean, "dry-run", "", false, "Print the paths that would be removed with their sizes, "+
+			"without removing anything")
 	subcomands = append(subcomands, cmdClean)
-	// add --debug flag to every command
+	// regolith unlock
+	var forceUnlock bool
+	cmdUnlock := &cobra.Command{
+		Use:   "unlock",
+		Short: "Removes a stuck session lock of the project",
+		Long:  regolithUnlockDesc,
+		Run: func(cmd *cobra.Command, _ []string) {
+			err = regolith.Unlock(forceUnlock, configPath, burrito.Debug)
+		},
+	}
+	cmdUnlock.Flags().BoolVarP(
+		&forceUnlock, "force", "f", false, "Remove the lock even if the process that holds it is "+
+			"still running.")
+	subcomands = append(subcomands, cmdUnlock)
+	// add the "--config" flag to the other commands that support it
+	for _, cmd := range []*cobra.Command{
+		cmdInstall, cmdUpdate, cmdInstallAll, cmdFreeze, cmdExport, cmdMigrate,
+		cmdGraph, cmdVerify, cmdListProfiles, cmdWhich, cmdAddProfile,
+		cmdFilterRename, cmdUnlock, cmdConfigGet, cmdConfigSet,
+		cmdProfileDiff, cmdApplyFilter, cmdTest,
+	} {
+		cmd.Flags().StringVarP(
+			&configPath, "config", "", "", "Path to the config file to use instead of "+
+				"\"config.json\".")
+	}
+	// regolith completions
+	cmdCompletions := &cobra.Command{
+		Use:       "completions <shell>",
+		Aliases:   []string{"completion"},
+		Short:     "Prints the autocompletion script for the specified shell",
+		Long:      regolithCompletionsDesc,
+		ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		Run: func(cmd *cobra.Command, args []string) {
+			switch args[0] {
+			case "bash":
+				err = rootCmd.GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				err = rootCmd.GenZshCompletion(os.Stdout)
+			case "fish":
+				err = rootCmd.GenFishCompletion(os.Stdout, true)
+			case "powershell":
+				err = rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+			}
+		},
+	}
+	subcomands = append(subcomands, cmdCompletions)
+	// add --debug, --offline, --experimental, --no-global-filters and
+	// --tmp-dir flags to every command (including the nested commands)
 	for _, cmd := range subcomands {
-		cmd.Flags().BoolVarP(&burrito.Debug, "debug", "", false, "Enables debugging")
+		cmd.PersistentFlags().BoolVarP(&burrito.Debug, "debug", "", false, "Enables debugging")
+		cmd.PersistentFlags().BoolVarP(
+			&regolith.Offline, "offline", "", false, "Forbid accessing the network. The commands "+
+				"that would need to download something fail, and the update check is skipped.")
+		cmd.PersistentFlags().BoolVarP(
+			&regolith.Experimental, "experimental", "", false,
+			"Enable the experimental features, like the \"live\" export target.")
+		cmd.PersistentFlags().BoolVarP(
+			&regolith.NoGlobalFilters, "no-global-filters", "", false,
+			"Ignore the global filter definitions from the \"regolith/filters.json\" file in the "+
+				"user config directory.")
+		cmd.PersistentFlags().StringVarP(
+			&regolith.TmpDir, "tmp-dir", "", "",
+			"The directory where the tmp directory of the project is created, for example a "+
+				"ramdisk. Overrides the REGOLITH_TMP environment variable.")
 	}
 	// Build and run CLI
 	rootCmd.AddCommand(subcomands...)
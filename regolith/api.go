@@ -0,0 +1,198 @@
+// Functions for using Regolith as a library. Unlike the functions that handle
+// the commands of the CLI, they don't depend on the current working directory
+// of the process. Instead, they take the path to the root of the project as
+// their first argument.
+//
+// The functions are not safe for concurrent use. They share the global
+// settings of the package (Logger, Offline, TmpDir, Experimental and
+// NoGlobalFilters), which are read while the function runs, and every call
+// replaces Logger with InitLogging. The programs that work with multiple
+// projects at the same time must run the functions one after another, or in
+// separate processes.
+package regolith
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// projectPath returns the path to a file of the project that doesn't depend
+// on the working directory of the process. The relative paths are joined
+// with the projectRoot. The absolute paths and the paths of the project in
+// the current working directory (the "." projectRoot used by the CLI) are
+// returned unchanged.
+func projectPath(projectRoot, path string) string {
+	if projectRoot == "" || projectRoot == "." || path == "" ||
+		filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(projectRoot, path)
+}
+
+// inProjectRoot checks whether the projectRoot is a directory and runs the
+// function with its absolute path. The working directory of the process is
+// never changed, so the functions can be used in multiple projects one after
+// another without affecting the rest of the program. The panics of the
+// function are recovered and returned as errors, so the programs that embed
+// Regolith don't crash.
+func inProjectRoot(
+	projectRoot string, f func(absRoot string) error,
+) (err error) {
+	absRoot, err := filepath.Abs(projectRoot)
+	if err != nil {
+		return burrito.WrapErrorf(err, filepathAbsError, projectRoot)
+	}
+	if stat, err := os.Stat(absRoot); err != nil {
+		return burrito.WrapErrorf(err, osStatErrorAny, absRoot)
+	} else if !stat.IsDir() {
+		return burrito.WrappedErrorf(isDirNotADirError, absRoot)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = burrito.WrappedErrorf(
+				"Unexpected error in the project.\nPath: %s\nError: %v",
+				absRoot, r)
+		}
+	}()
+	return f(absRoot)
+}
+
+// RunInProject works like Run, but runs the profile of the project from the
+// projectRoot directory.
+func RunInProject(
+	projectRoot, profileName string, options RunOptions, debug bool,
+) error {
+	return inProjectRoot(projectRoot, func(absRoot string) error {
+		return runOrWatch(absRoot, profileName, options, debug, false)
+	})
+}
+
+// WatchInProject works like Watch, but watches the project from the
+// projectRoot directory.
+func WatchInProject(
+	projectRoot, profileName string, options RunOptions, debug bool,
+) error {
+	return inProjectRoot(projectRoot, func(absRoot string) error {
+		return runOrWatch(absRoot, profileName, options, debug, true)
+	})
+}
+
+// ExportInProject works like Export, but exports the last build of the
+// project from the projectRoot directory.
+func ExportInProject(
+	projectRoot, profileName, target, configPath string, debug bool,
+) error {
+	return inProjectRoot(projectRoot, func(absRoot string) error {
+		return export(absRoot, profileName, target, configPath, debug)
+	})
+}
+
+// InstallInProject works like Install, but installs the filters to the
+// project from the projectRoot directory.
+func InstallInProject(
+	projectRoot string, filters []string, options InstallOptions, debug bool,
+) error {
+	return inProjectRoot(projectRoot, func(absRoot string) error {
+		return install(absRoot, filters, options, debug)
+	})
+}
+
+// InstallAllInProject works like InstallAll, but installs the filters of the
+// project from the projectRoot directory.
+func InstallAllInProject(
+	projectRoot string, options InstallOptions, debug bool,
+) error {
+	return inProjectRoot(projectRoot, func(absRoot string) error {
+		return installAll(absRoot, options, debug)
+	})
+}
+
+// UpdateInProject works like Update, but updates the filters of the project
+// from the projectRoot directory.
+func UpdateInProject(
+	projectRoot string, filters []string, noSubmodules bool,
+	configPath string, debug bool,
+) error {
+	return inProjectRoot(projectRoot, func(absRoot string) error {
+		return update(absRoot, filters, noSubmodules, configPath, debug)
+	})
+}
+
+// MigrateInProject works like Migrate, but migrates the config file of the
+// project from the projectRoot directory.
+func MigrateInProject(projectRoot, configPath string, debug bool) error {
+	return inProjectRoot(projectRoot, func(absRoot string) error {
+		return migrate(absRoot, configPath, debug)
+	})
+}
+
+// FreezeInProject works like Freeze, but pins the versions of the filters
+// of the project from the projectRoot directory.
+func FreezeInProject(
+	projectRoot string, dryRun bool, configPath string, debug bool,
+) error {
+	return inProjectRoot(projectRoot, func(absRoot string) error {
+		return freeze(absRoot, dryRun, configPath, debug)
+	})
+}
+
+// VerifyInProject works like Verify, but verifies the filters of the project
+// from the projectRoot directory.
+func VerifyInProject(projectRoot, configPath string, debug bool) error {
+	return inProjectRoot(projectRoot, func(absRoot string) error {
+		return verify(absRoot, configPath, debug)
+	})
+}
+
+// ApplyFilterInProject works like ApplyFilter, but applies the filter to the
+// project from the projectRoot directory.
+func ApplyFilterInProject(
+	projectRoot, filterName string, filterArgs []string, configPath string,
+	debug bool,
+) error {
+	return inProjectRoot(projectRoot, func(absRoot string) error {
+		return applyFilter(absRoot, filterName, filterArgs, configPath, debug)
+	})
+}
+
+// RunFilterTestsInProject works like RunFilterTests, but runs the tests of
+// the filter of the project from the projectRoot directory.
+func RunFilterTestsInProject(
+	projectRoot, filterName string, fixtures []string,
+	testsPath, configPath string, debug bool,
+) error {
+	return inProjectRoot(projectRoot, func(absRoot string) error {
+		return runFilterTests(
+			absRoot, filterName, fixtures, testsPath, configPath, debug)
+	})
+}
+
+// InitInProject works like Init, but creates the project in the projectRoot
+// directory.
+func InitInProject(projectRoot string, debug bool, template string) error {
+	return inProjectRoot(projectRoot, func(absRoot string) error {
+		return initProject(absRoot, debug, template)
+	})
+}
+
+// CleanInProject works like Clean, but cleans the project from the
+// projectRoot directory.
+func CleanInProject(
+	projectRoot string, debug, userCache, filterCache, dryRun bool,
+) error {
+	return inProjectRoot(projectRoot, func(absRoot string) error {
+		return cleanProject(absRoot, debug, userCache, filterCache, dryRun)
+	})
+}
+
+// UnlockInProject works like Unlock, but removes the session lock of the
+// project from the projectRoot directory.
+func UnlockInProject(
+	projectRoot string, force bool, configPath string, debug bool,
+) error {
+	return inProjectRoot(projectRoot, func(absRoot string) error {
+		return unlock(absRoot, force, configPath, debug)
+	})
+}
@@ -0,0 +1,219 @@
+// Functions used for exporting the packs into archive files.
+package regolith
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// Compression algorithms supported by the archive export targets
+const (
+	compressionNone = "none"
+	compressionGzip = "gzip"
+)
+
+// isArchiveExportTarget returns true if the export target writes the packs
+// into archive files instead of directories.
+func isArchiveExportTarget(target string) bool {
+	return target == "tar" || target == "zip"
+}
+
+// archiveExtension returns the file extension of the archives created by
+// the export target.
+func archiveExtension(exportTarget ExportTarget) string {
+	if exportTarget.Target == "zip" {
+		return ".zip"
+	}
+	if exportTarget.Compression == compressionGzip {
+		return ".tar.gz"
+	}
+	return ".tar"
+}
+
+// exportArchive writes the files from the source directory into the archive
+// file at the target path based on the settings of the export target.
+func exportArchive(source, target string, exportTarget ExportTarget) error {
+	switch exportTarget.Target {
+	case "tar":
+		return writeTarArchive(
+			source, target, exportTarget.Compression,
+			exportTarget.CompressionLevel)
+	case "zip":
+		return writeZipArchive(source, target, exportTarget.CompressionLevel)
+	}
+	return burrito.WrappedErrorf(
+		"Export target %q is not an archive export target.",
+		exportTarget.Target)
+}
+
+// walkArchiveFiles calls the walkFn for every file and directory inside the
+// source directory (excluding the source directory itself). The relPath
+// argument of the walkFn uses forward slashes. If the source directory
+// doesn't exist, the walkFn is never called.
+func walkArchiveFiles(
+	source string,
+	walkFn func(path, relPath string, info fs.FileInfo) error,
+) error {
+	if _, err := os.Stat(source); os.IsNotExist(err) {
+		return nil // Empty packs produce empty archives
+	}
+	return filepath.WalkDir(source, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return burrito.WrapErrorf(err, osStatErrorAny, path)
+		}
+		relPath, err := filepath.Rel(source, path)
+		if err != nil {
+			return burrito.WrapErrorf(err, filepathRelError, source, path)
+		}
+		if relPath == "." {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return burrito.WrapErrorf(err, osStatErrorAny, path)
+		}
+		return walkFn(path, filepath.ToSlash(relPath), info)
+	})
+}
+
+// copyFileTo copies the content of the file at the path into the writer.
+func copyFileTo(writer io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileReadError, path)
+	}
+	defer file.Close()
+	_, err = io.Copy(writer, file)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileReadError, path)
+	}
+	return nil
+}
+
+// writeTarArchive writes the files from the source directory into a tar
+// archive at the target path. The compression argument is one of
+// compressionNone or compressionGzip (empty string means compressionNone).
+// The level argument is the compression level (from 0 to 9 or -1 for the
+// default level). The modification times of the files are preserved.
+func writeTarArchive(source, target, compression string, level int) error {
+	var compress func(io.Writer) (io.WriteCloser, error)
+	switch compression {
+	case compressionGzip:
+		compress = func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriterLevel(w, level)
+		}
+	case compressionNone, "":
+	default:
+		return burrito.WrappedErrorf(
+			"Unsupported compression algorithm.\nAlgorithm: %s\n"+
+				"Supported algorithms: %s, %s",
+			compression, compressionNone, compressionGzip)
+	}
+	err := CreateDirectoryIfNotExists(filepath.Dir(target))
+	if err != nil {
+		return burrito.WrapErrorf(err, osMkdirError, filepath.Dir(target))
+	}
+	file, err := os.Create(target)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, target)
+	}
+	defer file.Close()
+	var output io.Writer = file
+	var compressor io.WriteCloser
+	if compress != nil {
+		compressor, err = compress(file)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Invalid compression level.\nLevel: %d", level)
+		}
+		output = compressor
+	}
+	tarWriter := tar.NewWriter(output)
+	err = walkArchiveFiles(source, func(path, relPath string, info fs.FileInfo) error {
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return burrito.WrapErrorf(err, osStatErrorAny, path)
+		}
+		header.Name = relPath
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return burrito.WrapErrorf(err, fileWriteError, target)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return copyFileTo(tarWriter, path)
+	})
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	if err := tarWriter.Close(); err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, target)
+	}
+	if compressor != nil {
+		if err := compressor.Close(); err != nil {
+			return burrito.WrapErrorf(err, fileWriteError, target)
+		}
+	}
+	return nil
+}
+
+// writeZipArchive writes the files from the source directory into a zip
+// archive at the target path. The level argument is the compression level
+// (from 0 to 9 or -1 for the default level). The level 0 stores the files
+// without compression. The modification times of the files are preserved.
+func writeZipArchive(source, target string, level int) error {
+	err := CreateDirectoryIfNotExists(filepath.Dir(target))
+	if err != nil {
+		return burrito.WrapErrorf(err, osMkdirError, filepath.Dir(target))
+	}
+	file, err := os.Create(target)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, target)
+	}
+	defer file.Close()
+	zipWriter := zip.NewWriter(file)
+	zipWriter.RegisterCompressor(zip.Deflate, func(w io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(w, level)
+	})
+	err = walkArchiveFiles(source, func(path, relPath string, info fs.FileInfo) error {
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return burrito.WrapErrorf(err, osStatErrorAny, path)
+		}
+		header.Name = relPath
+		if info.IsDir() {
+			header.Name += "/"
+			header.Method = zip.Store
+		} else if level == 0 {
+			header.Method = zip.Store
+		} else {
+			header.Method = zip.Deflate
+		}
+		writer, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return burrito.WrapErrorf(err, fileWriteError, target)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return copyFileTo(writer, path)
+	})
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	if err := zipWriter.Close(); err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, target)
+	}
+	return nil
+}
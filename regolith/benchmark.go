@@ -0,0 +1,140 @@
+// Functions used by the "regolith run --benchmark" command, which runs a
+// profile multiple times and reports the execution times of its filters.
+package regolith
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// benchmarkWarmupIterations is the number of the iterations of the benchmark
+// that are run before the measured iterations. Their results are discarded.
+const benchmarkWarmupIterations = 1
+
+// benchmarkSeries is a named list of the durations measured by the
+// benchmark.
+type benchmarkSeries struct {
+	Name      string
+	Durations []time.Duration
+}
+
+// BenchmarkProfile runs the profile from the context the number of times
+// specified in the Benchmark option and prints the minimal, median, mean and
+// maximal execution times of every filter, of the export and of the whole
+// run. The tmp files are prepared before every iteration. The export is
+// skipped if the NoExport option is enabled.
+func BenchmarkProfile(context RunContext) error {
+	iterations := context.Options.Benchmark
+	profile, err := context.GetProfile()
+	if err != nil {
+		return burrito.WrapErrorf(err, runContextGetProfileError)
+	}
+	series := []*benchmarkSeries{}
+	seriesByName := make(map[string]*benchmarkSeries)
+	record := func(name string, duration time.Duration) {
+		s, ok := seriesByName[name]
+		if !ok {
+			s = &benchmarkSeries{Name: name}
+			seriesByName[name] = s
+			series = append(series, s)
+		}
+		s.Durations = append(s.Durations, duration)
+	}
+	for i := -benchmarkWarmupIterations; i < iterations; i++ {
+		if i < 0 {
+			Logger.Infof("Benchmark warmup iteration %d.", i+benchmarkWarmupIterations+1)
+		} else {
+			Logger.Infof("Benchmark iteration %d of %d.", i+1, iterations)
+		}
+		dataLinked, err := setupTmpFilesImpl(
+			*context.Config, context.DotRegolithPath, !context.Options.NoHardlink)
+		if err != nil {
+			return burrito.WrapErrorf(err, setupTmpFilesError, context.DotRegolithPath)
+		}
+		context.tmpDataLinks.setLinked(dataLinked)
+		// The same filter can be used multiple times in a profile
+		iterationDurations := []benchmarkSeries{}
+		occurrences := make(map[string]int)
+		context.filterRunListener = func(
+			filterId string, duration time.Duration, _ error,
+		) {
+			occurrences[filterId]++
+			name := filterId
+			if occurrences[filterId] > 1 {
+				name = fmt.Sprintf("%s (%d)", filterId, occurrences[filterId])
+			}
+			iterationDurations = append(iterationDurations, benchmarkSeries{
+				Name: name, Durations: []time.Duration{duration}})
+		}
+		start := time.Now()
+		_, err = RunProfileImpl(context)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+		exportDuration := time.Duration(0)
+		if !context.Options.NoExport {
+			exportStart := time.Now()
+			err = ExportProject(
+				profile, context.Profile, context.Config.Name,
+				context.Config.DataPath,
+				context.DotRegolithPath)
+			if err != nil {
+				return burrito.WrapError(err, exportProjectError)
+			}
+			exportDuration = time.Since(exportStart)
+		}
+		totalDuration := time.Since(start)
+		if i < 0 {
+			continue // Discard the warmup
+		}
+		for _, d := range iterationDurations {
+			record(d.Name, d.Durations[0])
+		}
+		if !context.Options.NoExport {
+			record("[export]", exportDuration)
+		}
+		record("[total]", totalDuration)
+	}
+	fmt.Printf(
+		"\nBenchmark of the %q profile (%d iterations, the warmup "+
+			"is not included):\n",
+		context.Profile, iterations)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tMIN\tMEDIAN\tMEAN\tMAX")
+	for _, s := range series {
+		min, median, mean, max := s.stats()
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.Name, min, median, mean, max)
+	}
+	return w.Flush()
+}
+
+// stats returns the minimal, median, mean and maximal duration of the
+// series. The durations are rounded to milliseconds.
+func (s *benchmarkSeries) stats() (min, median, mean, max time.Duration) {
+	if len(s.Durations) == 0 {
+		return
+	}
+	sorted := append([]time.Duration{}, s.Durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	min = sorted[0]
+	max = sorted[len(sorted)-1]
+	if len(sorted)%2 == 1 {
+		median = sorted[len(sorted)/2]
+	} else {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+	sum := time.Duration(0)
+	for _, d := range sorted {
+		sum += d
+	}
+	mean = sum / time.Duration(len(sorted))
+	round := func(d time.Duration) time.Duration {
+		return d.Round(time.Millisecond)
+	}
+	return round(min), round(median), round(mean), round(max)
+}
@@ -0,0 +1,52 @@
+package regolith
+
+import (
+	"testing"
+	"time"
+)
+
+// TestBenchmarkSeriesStats checks whether the minimal, median, mean and
+// maximal durations of the benchmark are calculated and rounded to
+// milliseconds, including the series with even number of durations and the
+// empty series.
+func TestBenchmarkSeriesStats(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		durations              []time.Duration
+		min, median, mean, max time.Duration
+	}{
+		{nil, 0, 0, 0, 0},
+		{[]time.Duration{5 * ms}, 5 * ms, 5 * ms, 5 * ms, 5 * ms},
+		{
+			[]time.Duration{30 * ms, 10 * ms, 20 * ms},
+			10 * ms, 20 * ms, 20 * ms, 30 * ms,
+		},
+		{
+			[]time.Duration{40 * ms, 10 * ms, 20 * ms, 50 * ms},
+			10 * ms, 30 * ms, 30 * ms, 50 * ms,
+		},
+		{
+			[]time.Duration{1400 * time.Microsecond, 1600 * time.Microsecond},
+			1 * ms, 2 * ms, 2 * ms, 2 * ms,
+		},
+	}
+	for _, test := range tests {
+		durations := append([]time.Duration{}, test.durations...)
+		series := benchmarkSeries{Name: "filter", Durations: durations}
+		min, median, mean, max := series.stats()
+		if min != test.min || median != test.median || mean != test.mean ||
+			max != test.max {
+			t.Errorf(
+				"Unexpected stats of %v: %v %v %v %v, expected %v %v %v %v",
+				test.durations, min, median, mean, max,
+				test.min, test.median, test.mean, test.max)
+		}
+		// The durations are sorted on a copy
+		for i := range durations {
+			if durations[i] != test.durations[i] {
+				t.Errorf("The durations of the series were modified: %v", durations)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package regolith
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// ErrRunCancelled is returned by Run when the user cancels the run with
+// Ctrl+C. It's returned without wrapping, so it can be compared with the
+// returned error to tell the cancellation apart from a real failure.
+var ErrRunCancelled = errors.New("the run was cancelled by the user")
+
+// CancelledExitCode is the exit code used when the run is cancelled, the
+// same as the exit code of the shells for the processes stopped with
+// Ctrl+C.
+const CancelledExitCode = 130
+
+// runCancellation handles Ctrl+C during "regolith run". The first Ctrl+C
+// marks the run as cancelled, which lets the current filter finish and stops
+// the profile before the next filter. The second Ctrl+C kills the running
+// filters together with their child processes, so the run stops
+// immediately. In both cases the run returns
+// ErrRunCancelled, the process is never terminated, so the run can be used
+// as a library.
+type runCancellation struct {
+	mutex     sync.Mutex
+	cancelled bool
+	processes map[*os.Process]struct{}
+	signals   chan os.Signal
+	done      chan struct{}
+}
+
+// startRunCancellation installs the Ctrl+C handler of the run. The handler
+// must be removed with stop.
+func startRunCancellation() *runCancellation {
+	c := &runCancellation{
+		processes: make(map[*os.Process]struct{}),
+		signals:   make(chan os.Signal, 2),
+		done:      make(chan struct{}),
+	}
+	signal.Notify(c.signals, os.Interrupt)
+	go func() {
+		for {
+			select {
+			case <-c.signals:
+				c.handleInterrupt()
+			case <-c.done:
+				return
+			}
+		}
+	}()
+	return c
+}
+
+// handleInterrupt handles a single Ctrl+C.
+func (c *runCancellation) handleInterrupt() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if !c.cancelled {
+		c.cancelled = true
+		Logger.Warn(
+			"Cancelling the run after the current filter finishes. " +
+				"Press Ctrl+C again to stop immediately.")
+		return
+	}
+	Logger.Error("Stopping immediately.")
+	for process := range c.processes {
+		killProcessGroup(process)
+	}
+}
+
+// stop removes the Ctrl+C handler of the run.
+func (c *runCancellation) stop() {
+	signal.Stop(c.signals)
+	close(c.done)
+}
+
+// isCancelled returns true if the user pressed Ctrl+C. It's safe to call on
+// nil, which means that the run can't be cancelled.
+func (c *runCancellation) isCancelled() bool {
+	if c == nil {
+		return false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.cancelled
+}
+
+// startProcess starts the command of a filter in a separate process group,
+// so that the first Ctrl+C doesn't stop it. The process group is killed on
+// the second Ctrl+C. The returned function must be called after the process
+// exits. It's safe to call on nil, which starts the command normally.
+func (c *runCancellation) startProcess(cmd *exec.Cmd) (func(), error) {
+	if c == nil {
+		return func() {}, cmd.Start()
+	}
+	startInNewProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	c.processes[cmd.Process] = struct{}{}
+	c.mutex.Unlock()
+	return func() {
+		c.mutex.Lock()
+		delete(c.processes, cmd.Process)
+		c.mutex.Unlock()
+	}, nil
+}
+
+// cleanUpCancelledRun removes the tmp directory left by the cancelled run,
+// because it contains the output of only some of the filters.
+func cleanUpCancelledRun(dotRegolithPath string) error {
+	tmpPath := getTmpPath(dotRegolithPath)
+	Logger.Infof("Cleaning %q after the cancelled run...", tmpPath)
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return burrito.WrapErrorf(err, osRemoveError, tmpPath)
+	}
+	return nil
+}
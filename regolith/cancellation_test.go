@@ -0,0 +1,134 @@
+package regolith
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// newTestRunCancellation creates a runCancellation without installing the
+// Ctrl+C handler, so the tests can call handleInterrupt directly.
+func newTestRunCancellation() *runCancellation {
+	return &runCancellation{processes: make(map[*os.Process]struct{})}
+}
+
+// TestRunCancellationFirstInterrupt checks whether the first Ctrl+C marks
+// the run as cancelled without stopping the running filters.
+func TestRunCancellationFirstInterrupt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The test uses the \"sleep\" command")
+	}
+	InitLogging(false)
+	c := newTestRunCancellation()
+	if c.isCancelled() {
+		t.Fatal("The run was cancelled before the first Ctrl+C")
+	}
+	cmd := exec.Command("sleep", "1")
+	finish, err := c.startProcess(cmd)
+	if err != nil {
+		t.Skip("Unable to start the process:", err)
+	}
+	defer finish()
+	c.handleInterrupt()
+	if !c.isCancelled() {
+		t.Error("The first Ctrl+C didn't cancel the run")
+	}
+	if err := cmd.Wait(); err != nil {
+		t.Error("The first Ctrl+C stopped the process:", err)
+	}
+}
+
+// TestRunCancellationSecondInterrupt checks whether the second Ctrl+C kills
+// the running filters together with their child processes. The child
+// process of the shell keeps the output pipe open, so the pipe is closed
+// only when both processes are killed.
+func TestRunCancellationSecondInterrupt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The test uses the \"sh\" and \"sleep\" commands")
+	}
+	InitLogging(false)
+	c := newTestRunCancellation()
+	cmd := exec.Command("sh", "-c", "sleep 30 & echo started; wait")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal("Failed to get the output of the process:", err)
+	}
+	finish, err := c.startProcess(cmd)
+	if err != nil {
+		t.Skip("Unable to start the process:", err)
+	}
+	defer killProcessGroup(cmd.Process)
+	// Wait until the child process is started
+	reader := bufio.NewReader(stdout)
+	if _, err := reader.ReadString('\n'); err != nil {
+		t.Fatal("Failed to read the output of the process:", err)
+	}
+	c.handleInterrupt()
+	c.handleInterrupt()
+	closed := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, reader)
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The second Ctrl+C didn't kill the child process")
+	}
+	cmd.Wait()
+	finish()
+	if len(c.processes) != 0 {
+		t.Error("The finished process is still tracked")
+	}
+}
+
+// TestRunCancellationNil checks whether the processes can be started and
+// the cancellation can be checked without the Ctrl+C handler.
+func TestRunCancellationNil(t *testing.T) {
+	var c *runCancellation
+	if c.isCancelled() {
+		t.Error("The nil cancellation is cancelled")
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		t.Skip("Unable to get the path to the test executable:", err)
+	}
+	cmd := exec.Command(executable, "-test.run=^$")
+	finish, err := c.startProcess(cmd)
+	if err != nil {
+		t.Fatal("Failed to start the process:", err)
+	}
+	finish()
+	if err := cmd.Wait(); err != nil {
+		t.Error("The process failed:", err)
+	}
+	if cmd.SysProcAttr != nil {
+		t.Error("The process was started in a new process group")
+	}
+}
+
+// TestCleanUpCancelledRun checks whether the tmp directory with the partial
+// output of the cancelled run is removed.
+func TestCleanUpCancelledRun(t *testing.T) {
+	InitLogging(false)
+	dotRegolithPath := t.TempDir()
+	bpPath := filepath.Join(getTmpPath(dotRegolithPath), "BP")
+	if err := os.MkdirAll(bpPath, 0755); err != nil {
+		t.Fatal("Failed to create the tmp directory:", err)
+	}
+	if err := cleanUpCancelledRun(dotRegolithPath); err != nil {
+		t.Fatal("Failed to clean up the cancelled run:", err)
+	}
+	if _, err := os.Stat(getTmpPath(dotRegolithPath)); !os.IsNotExist(err) {
+		t.Error("The tmp directory wasn't removed:", err)
+	}
+	// Nothing to remove
+	if err := cleanUpCancelledRun(dotRegolithPath); err != nil {
+		t.Error("Failed to clean up without the tmp directory:", err)
+	}
+}
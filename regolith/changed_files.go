@@ -0,0 +1,104 @@
+// Functions used by the "regolith run --since" command, which passes the
+// list of the source files changed since a git reference to the filters.
+package regolith
+
+import (
+	"encoding/json"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// changedFilesEnvVar is the name of the environment variable with the JSON
+// list of the changed source files, passed to the filters by
+// "regolith run --since".
+const changedFilesEnvVar = "REGOLITH_CHANGED_FILES"
+
+// maxChangedFilesEnvLength is the maximal length of the value of the
+// REGOLITH_CHANGED_FILES variable. Longer lists are not passed to the
+// filters, because some systems limit the size of the environment
+// variables. The filters process all of the files in this case.
+const maxChangedFilesEnvLength = 32000
+
+// listChangedFiles returns the sorted list of the source files of the
+// project that changed since the git reference, including the deleted and
+// the untracked files. The paths are relative to the tmp directory and use
+// forward slashes (for example "BP/entities/pig.json"). The files outside
+// of the RP, BP and data folders are skipped.
+func listChangedFiles(projectDir, since string, config *Config) ([]string, error) {
+	if !hasGit() {
+		return nil, burrito.WrappedError(gitNotInstalledWarning)
+	}
+	commandArgs := []string{"rev-parse", "--verify", "--quiet", since + "^{commit}"}
+	cmd := exec.Command("git", commandArgs...)
+	cmd.Dir = projectDir
+	if err := cmd.Run(); err != nil {
+		return nil, burrito.WrappedErrorf(
+			"The git reference doesn't exist or the project is not a git "+
+				"repository.\nReference: %s", since)
+	}
+	paths := []string{}
+	for _, commandArgs := range [][]string{
+		{"diff", "--name-only", "--relative", "-z", since, "--"},
+		{"ls-files", "--others", "--exclude-standard", "-z"},
+	} {
+		cmd := exec.Command("git", commandArgs...)
+		cmd.Dir = projectDir
+		output, err := cmd.Output()
+		if err != nil {
+			command := "git " + strings.Join(commandArgs, " ")
+			return nil, burrito.WrapErrorf(err, execCommandError, command)
+		}
+		for _, path := range strings.Split(string(output), "\x00") {
+			if path != "" {
+				paths = append(paths, path)
+			}
+		}
+	}
+	packs := map[string]string{
+		"BP":   config.BehaviorFolder,
+		"RP":   config.ResourceFolder,
+		"data": config.DataPath,
+	}
+	changed := make(map[string]struct{})
+	for _, path := range paths {
+		for pack, folder := range packs {
+			if folder == "" {
+				continue
+			}
+			// The folders of the config can be absolute, the paths from git
+			// are relative to the project
+			if filepath.IsAbs(folder) {
+				relFolder, err := filepath.Rel(projectDir, folder)
+				if err != nil {
+					continue
+				}
+				folder = relFolder
+			}
+			prefix := filepath.ToSlash(filepath.Clean(folder)) + "/"
+			if strings.HasPrefix(path, prefix) {
+				changed[pack+"/"+strings.TrimPrefix(path, prefix)] = struct{}{}
+			}
+		}
+	}
+	result := make([]string, 0, len(changed))
+	for path := range changed {
+		result = append(result, path)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// changedFilesEnv returns the REGOLITH_CHANGED_FILES environment variable
+// in the "KEY=value" format or an empty string if the list of the changed
+// files is too long to be passed to the filters.
+func changedFilesEnv(changedFiles []string) string {
+	data, _ := json.Marshal(changedFiles) // no error
+	if len(data) > maxChangedFilesEnvLength {
+		return ""
+	}
+	return changedFilesEnvVar + "=" + string(data)
+}
@@ -0,0 +1,212 @@
+// Functions used for creating the export manifests with the
+// "regolith run --export-manifest" command and for comparing them with the
+// "regolith changelog" command.
+package regolith
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// changelogRootCategory is the name of the category of the files placed
+// directly in the root of a pack.
+const changelogRootCategory = "(root)"
+
+// exportManifestPacks is a list of the names of the directories in the tmp
+// directory that are described by the export manifest.
+var exportManifestPacks = []string{"BP", "RP"}
+
+// ExportManifest is a list of the exported files with their hashes. It's
+// used for comparing the outputs of two builds.
+type ExportManifest struct {
+	// Packs maps the names of the packs ("BP" and "RP") to the maps of the
+	// paths of their files (relative to the pack, with forward slashes) to
+	// the SHA-256 hashes of the files.
+	Packs map[string]map[string]string `json:"packs"`
+}
+
+// changelogCategory lists the changes of the files from a single category of
+// a pack. The lists are sorted.
+type changelogCategory struct {
+	Added    []string `json:"added"`
+	Removed  []string `json:"removed"`
+	Modified []string `json:"modified"`
+}
+
+// changelog maps the names of the packs to the maps of the names of the
+// categories to their changes. Only the categories with changes are listed.
+type changelog map[string]map[string]*changelogCategory
+
+// createExportManifest creates an ExportManifest from the packs in the tmp
+// directory.
+func createExportManifest(dotRegolithPath string) (*ExportManifest, error) {
+	result := &ExportManifest{Packs: make(map[string]map[string]string)}
+	for _, pack := range exportManifestPacks {
+		packPath := filepath.Join(getTmpPath(dotRegolithPath), pack)
+		files := make(map[string]string)
+		err := walkArchiveFiles(packPath, func(path, relPath string, info fs.FileInfo) error {
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+			hash := sha256.New()
+			if err := copyFileTo(hash, path); err != nil {
+				return burrito.PassError(err)
+			}
+			files[relPath] = hex.EncodeToString(hash.Sum(nil))
+			return nil
+		})
+		if err != nil {
+			return nil, burrito.WrapErrorf(err, osWalkError, packPath)
+		}
+		result.Packs[pack] = files
+	}
+	return result, nil
+}
+
+// LoadExportManifest loads an ExportManifest from a JSON file.
+func LoadExportManifest(path string) (*ExportManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, burrito.WrapErrorf(err, fileReadError, path)
+	}
+	result := &ExportManifest{}
+	err = json.Unmarshal(data, result)
+	if err != nil {
+		return nil, burrito.WrapErrorf(err, jsonUnmarshalError, path)
+	}
+	if result.Packs == nil {
+		result.Packs = make(map[string]map[string]string)
+	}
+	return result, nil
+}
+
+// Dump saves the ExportManifest to a JSON file.
+func (m *ExportManifest) Dump(path string) error {
+	data, _ := json.MarshalIndent(m, "", "\t") // no error
+	err := CreateDirectoryIfNotExists(filepath.Dir(path))
+	if err != nil {
+		return burrito.WrapErrorf(err, osMkdirError, filepath.Dir(path))
+	}
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, path)
+	}
+	return nil
+}
+
+// changelogCategoryName returns the category of the file, which is the name
+// of the top-level directory of its path inside of the pack (for example
+// "entities", "items" or "textures").
+func changelogCategoryName(relPath string) string {
+	parts := strings.SplitN(relPath, "/", 2)
+	if len(parts) == 1 {
+		return changelogRootCategory
+	}
+	return parts[0]
+}
+
+// diffExportManifests compares two export manifests and returns the added,
+// removed and modified files grouped by packs and categories.
+func diffExportManifests(previous, current *ExportManifest) changelog {
+	result := make(changelog)
+	category := func(pack, relPath string) *changelogCategory {
+		if _, ok := result[pack]; !ok {
+			result[pack] = make(map[string]*changelogCategory)
+		}
+		name := changelogCategoryName(relPath)
+		c, ok := result[pack][name]
+		if !ok {
+			c = &changelogCategory{
+				Added: []string{}, Removed: []string{}, Modified: []string{}}
+			result[pack][name] = c
+		}
+		return c
+	}
+	packs := make(map[string]struct{})
+	for pack := range previous.Packs {
+		packs[pack] = struct{}{}
+	}
+	for pack := range current.Packs {
+		packs[pack] = struct{}{}
+	}
+	for pack := range packs {
+		previousFiles := previous.Packs[pack]
+		currentFiles := current.Packs[pack]
+		for relPath, hash := range currentFiles {
+			previousHash, ok := previousFiles[relPath]
+			if !ok {
+				c := category(pack, relPath)
+				c.Added = append(c.Added, relPath)
+			} else if previousHash != hash {
+				c := category(pack, relPath)
+				c.Modified = append(c.Modified, relPath)
+			}
+		}
+		for relPath := range previousFiles {
+			if _, ok := currentFiles[relPath]; !ok {
+				c := category(pack, relPath)
+				c.Removed = append(c.Removed, relPath)
+			}
+		}
+	}
+	for _, categories := range result {
+		for _, c := range categories {
+			sort.Strings(c.Added)
+			sort.Strings(c.Removed)
+			sort.Strings(c.Modified)
+		}
+	}
+	return result
+}
+
+// String returns a human-readable summary of the changelog. The added files
+// are marked with "+", the removed files with "-" and the modified files
+// with "~".
+func (c changelog) String() string {
+	if len(c) == 0 {
+		return "No changes."
+	}
+	var builder strings.Builder
+	added, removed, modified := 0, 0, 0
+	packs := make([]string, 0, len(c))
+	for pack := range c {
+		packs = append(packs, pack)
+	}
+	sort.Strings(packs)
+	for _, pack := range packs {
+		builder.WriteString(fmt.Sprintf("%s:\n", pack))
+		names := make([]string, 0, len(c[pack]))
+		for name := range c[pack] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			category := c[pack][name]
+			builder.WriteString(fmt.Sprintf("  %s:\n", name))
+			for _, relPath := range category.Added {
+				builder.WriteString(fmt.Sprintf("    + %s\n", relPath))
+			}
+			for _, relPath := range category.Removed {
+				builder.WriteString(fmt.Sprintf("    - %s\n", relPath))
+			}
+			for _, relPath := range category.Modified {
+				builder.WriteString(fmt.Sprintf("    ~ %s\n", relPath))
+			}
+			added += len(category.Added)
+			removed += len(category.Removed)
+			modified += len(category.Modified)
+		}
+	}
+	builder.WriteString(fmt.Sprintf(
+		"Added: %d, removed: %d, modified: %d", added, removed, modified))
+	return builder.String()
+}
@@ -0,0 +1,49 @@
+// Functions used by the "regolith run --require-clean-git" command for
+// making sure that the project is built from a committed state.
+package regolith
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// checkCleanGitTree returns an error with the list of the changed files if
+// the git repository of the project has uncommitted changes. If the project
+// isn't a git repository, it only prints a warning.
+func checkCleanGitTree(projectDir string) error {
+	if !hasGit() {
+		return burrito.WrappedError(gitNotInstalledWarning)
+	}
+	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
+	cmd.Dir = projectDir
+	if output, err := cmd.Output(); err != nil ||
+		strings.TrimSpace(string(output)) != "true" {
+		Logger.Warn(
+			"The project is not a git repository. Skipping the check for " +
+				"uncommitted changes.")
+		return nil
+	}
+	commandArgs := []string{"status", "--porcelain"}
+	cmd = exec.Command("git", commandArgs...)
+	cmd.Dir = projectDir
+	output, err := cmd.Output()
+	if err != nil {
+		command := "git " + strings.Join(commandArgs, " ")
+		return burrito.WrapErrorf(err, execCommandError, command)
+	}
+	var dirtyFiles []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) != "" {
+			dirtyFiles = append(dirtyFiles, "\t"+line)
+		}
+	}
+	if len(dirtyFiles) > 0 {
+		return burrito.WrappedErrorf(
+			"The project has uncommitted changes. Commit or stash them "+
+				"before building the project with \"--require-clean-git\".\n"+
+				"Changed files:\n%s", strings.Join(dirtyFiles, "\n"))
+	}
+	return nil
+}
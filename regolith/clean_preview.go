@@ -0,0 +1,106 @@
+package regolith
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// cleanEntry is a top-level entry of a directory that would be removed by
+// "regolith clean".
+type cleanEntry struct {
+	name string
+	size int64
+}
+
+// pathSize returns the total size of the files in the path. The symbolic
+// links are not followed, because removing them doesn't remove their
+// targets.
+func pathSize(path string) (int64, error) {
+	var result int64
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			result += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, burrito.WrapErrorf(err, osWalkError, path)
+	}
+	return result, nil
+}
+
+// formatSize returns the size in bytes as a human readable string.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size) / unit
+	for _, suffix := range []string{"KiB", "MiB", "GiB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1f TiB", value)
+}
+
+// previewClean prints the paths that would be removed by "regolith clean"
+// with their top-level entries and sizes, without removing anything.
+func previewClean(paths []string) error {
+	var total int64
+	for _, path := range paths {
+		stat, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			Logger.Infof("Nothing to remove in %s", path)
+			continue
+		} else if err != nil {
+			return burrito.WrapErrorf(err, osStatErrorAny, path)
+		}
+		if !stat.IsDir() {
+			total += stat.Size()
+			Logger.Infof(
+				"Would remove %s (%s)", path, formatSize(stat.Size()))
+			continue
+		}
+		dirEntries, err := os.ReadDir(path)
+		if err != nil {
+			return burrito.WrapErrorf(err, osReadDirError, path)
+		}
+		var entries []cleanEntry
+		var pathTotal int64
+		for _, dirEntry := range dirEntries {
+			size, err := pathSize(filepath.Join(path, dirEntry.Name()))
+			if err != nil {
+				return burrito.PassError(err)
+			}
+			entries = append(entries, cleanEntry{dirEntry.Name(), size})
+			pathTotal += size
+		}
+		// The largest entries first
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].size > entries[j].size
+		})
+		Logger.Infof("Would remove %s (%s)", path, formatSize(pathTotal))
+		for _, entry := range entries {
+			Logger.Infof("\t%s (%s)", entry.name, formatSize(entry.size))
+		}
+		total += pathTotal
+	}
+	Logger.Infof(
+		"Dry run: %s would be removed. Nothing was deleted.",
+		formatSize(total))
+	return nil
+}
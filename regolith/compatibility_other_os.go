@@ -3,7 +3,13 @@
 
 package regolith
 
-import "github.com/Bedrock-OSS/go-burrito/burrito"
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
 
 // venvScriptsPath is a folder name between "venv" and "python" that leads to
 // the python executable.
@@ -21,19 +27,40 @@ func copyFileSecurityInfo(source string, target string) error {
 	return nil
 }
 
+// startInNewProcessGroup makes the command start in a new process group, so
+// it doesn't receive the Ctrl+C signals sent to Regolith by the terminal.
+func startInNewProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup kills the process started with startInNewProcessGroup
+// together with its child processes. The ID of the process group is the
+// same as the ID of the process.
+func killProcessGroup(process *os.Process) error {
+	if err := syscall.Kill(-process.Pid, syscall.SIGKILL); err != nil {
+		return process.Kill()
+	}
+	return nil
+}
+
+// createDirectoryLink creates a symbolic link to the target directory.
+func createDirectoryLink(target, link string) error {
+	return os.Symlink(target, link)
+}
+
 type DirWatcher struct{}
 
-func NewDirWatcher(path string) (*DirWatcher, error) {
+func NewDirWatcher(path string, recursive bool) (*DirWatcher, error) {
 	return nil, burrito.WrappedError(notImplementedOnThisSystemError)
 }
 
-func (d *DirWatcher) WaitForChange() error {
-	return burrito.WrappedError(notImplementedOnThisSystemError)
+func (d *DirWatcher) WaitForChange() ([]string, error) {
+	return nil, burrito.WrappedError(notImplementedOnThisSystemError)
 }
 
 func (d *DirWatcher) WaitForChangeGroup(
-	groupTimeout uint32, interruptionChannel chan string,
-	interruptionMessage string,
+	groupTimeout uint32, interruptionChannel chan watchInterruption,
+	source string,
 ) error {
 	return burrito.WrappedError(notImplementedOnThisSystemError)
 }
@@ -5,7 +5,12 @@ package regolith
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+	"unsafe"
 
 	"github.com/Bedrock-OSS/go-burrito/burrito"
 
@@ -47,92 +52,171 @@ func copyFileSecurityInfo(source string, target string) error {
 	return nil
 }
 
+// createDirectoryLink creates a symbolic link to the target directory. If
+// creating symbolic links isn't allowed (it requires the developer mode or
+// administrator privileges), it creates a directory junction instead.
+func createDirectoryLink(target, link string) error {
+	if err := os.Symlink(target, link); err == nil {
+		return nil
+	}
+	output, err := exec.Command("cmd", "/c", "mklink", "/J", link, target).CombinedOutput()
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, "Failed to create a directory junction.\nOutput: %s", output)
+	}
+	return nil
+}
+
 // DirWatcher is a struct that provides easy to use methods for watching a
-// directory for changes. It uses FindFirstChangeNotification instead of
-// ReadDirectoryChanges so it doesn't provide any information about the
-// changes, only the fact that something changed.
+// directory for changes. It uses ReadDirectoryChanges, so it reports the
+// paths to the changed files.
 //
 // Useful links:
-// https://docs.microsoft.com/en-us/windows/win32/api/fileapi/nf-fileapi-findfirstchangenotificationa
+// https://docs.microsoft.com/en-us/windows/win32/api/winbase/nf-winbase-readdirectorychangesw
 //
-// https://docs.microsoft.com/en-us/windows/win32/api/fileapi/nf-fileapi-findnextchangenotification
+// https://docs.microsoft.com/en-us/windows/win32/api/winnt/ns-winnt-file_notify_information
 //
 // https://pkg.go.dev/golang.org/x/sys@v0.0.0-20220412211240-33da011f77ad/windows
-//
-// https://docs.microsoft.com/en-us/windows/win32/api/winbase/nf-winbase-readdirectorychangesw
 type DirWatcher struct {
 	handle windows.Handle
+
+	// path is the path to the watched directory. The paths to the changed
+	// files are joined with it.
+	path string
+
+	// recursive is true if the changes in the subdirectories of the path
+	// are reported
+	recursive bool
+
+	// changes is the channel with the changes read from the directory by
+	// the readChanges goroutine.
+	changes chan dirWatcherChange
+}
+
+// dirWatcherChange is a single result of reading the changes of the
+// directory watched by the DirWatcher.
+type dirWatcherChange struct {
+	paths []string
+	err   error
 }
 
-// NewDirWatcher creates a new DirWatcher for the given path. It filters out
-// some of the less interesting events like FILE_NOTIFY_CHANGE_LAST_ACCESS.
-func NewDirWatcher(path string) (*DirWatcher, error) {
-	var notifyFilter uint32 = (windows.FILE_NOTIFY_CHANGE_FILE_NAME |
-		windows.FILE_NOTIFY_CHANGE_DIR_NAME |
-		// windows.FILE_NOTIFY_CHANGE_ATTRIBUTES |
-		// windows.FILE_NOTIFY_CHANGE_SIZE |
-		windows.FILE_NOTIFY_CHANGE_LAST_WRITE |
-		// windows.FILE_NOTIFY_CHANGE_LAST_ACCESS |
-		// windows.FILE_NOTIFY_CHANGE_SECURITY |
-		windows.FILE_NOTIFY_CHANGE_CREATION)
-	handle, err := windows.FindFirstChangeNotification(
-		path, true, notifyFilter)
+// dirWatcherNotifyFilter filters out some of the less interesting events
+// like FILE_NOTIFY_CHANGE_LAST_ACCESS.
+const dirWatcherNotifyFilter uint32 = (windows.FILE_NOTIFY_CHANGE_FILE_NAME |
+	windows.FILE_NOTIFY_CHANGE_DIR_NAME |
+	// windows.FILE_NOTIFY_CHANGE_ATTRIBUTES |
+	// windows.FILE_NOTIFY_CHANGE_SIZE |
+	windows.FILE_NOTIFY_CHANGE_LAST_WRITE |
+	// windows.FILE_NOTIFY_CHANGE_LAST_ACCESS |
+	// windows.FILE_NOTIFY_CHANGE_SECURITY |
+	windows.FILE_NOTIFY_CHANGE_CREATION)
+
+// NewDirWatcher creates a new DirWatcher for the given path. If recursive is
+// false, the changes in the subdirectories of the path are not reported.
+func NewDirWatcher(path string, recursive bool) (*DirWatcher, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
 	if err != nil {
 		return nil, err
 	}
-	return &DirWatcher{handle: handle}, nil
+	handle, err := windows.CreateFile(
+		pathPtr, windows.FILE_LIST_DIRECTORY,
+		windows.FILE_SHARE_READ|windows.FILE_SHARE_WRITE|
+			windows.FILE_SHARE_DELETE,
+		nil, windows.OPEN_EXISTING, windows.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return nil, err
+	}
+	result := &DirWatcher{
+		handle:    handle,
+		path:      path,
+		recursive: recursive,
+		changes:   make(chan dirWatcherChange),
+	}
+	go result.readChanges()
+	return result, nil
 }
 
-// WaitForChange locks the goroutine until a single change is detected. Note
-// that some changes are reported multiple times, for example saving a file
-// will cause a change to the file and a change to the directory. If you want
-// to report cases like that as one event, see WaitForChangeGroup.
-func (d *DirWatcher) WaitForChange() error {
-	_, err := windows.WaitForSingleObject(d.handle, windows.INFINITE)
-	if err != nil {
-		return err
+// readChanges reads the changes of the directory until the handle of the
+// DirWatcher is closed and sends them to the changes channel.
+func (d *DirWatcher) readChanges() {
+	buffer := make([]byte, 64*1024)
+	for {
+		var length uint32
+		err := windows.ReadDirectoryChanges(
+			d.handle, &buffer[0], uint32(len(buffer)), d.recursive,
+			dirWatcherNotifyFilter, &length, nil, 0)
+		if err != nil {
+			d.changes <- dirWatcherChange{err: err}
+			close(d.changes)
+			return
+		}
+		// The length is 0 if the buffer overflowed. The change is reported
+		// without the paths.
+		d.changes <- dirWatcherChange{
+			paths: d.parseChanges(buffer[:length])}
 	}
-	err = windows.FindNextChangeNotification(d.handle)
-	if err != nil {
-		return err
+}
+
+// parseChanges returns the paths to the changed files from the list of
+// FILE_NOTIFY_INFORMATION structures.
+func (d *DirWatcher) parseChanges(buffer []byte) []string {
+	result := []string{}
+	offset := 0
+	for offset < len(buffer) {
+		info := (*windows.FileNotifyInformation)(
+			unsafe.Pointer(&buffer[offset]))
+		name := unsafe.Slice(&info.FileName, info.FileNameLength/2)
+		result = append(
+			result, filepath.Join(d.path, windows.UTF16ToString(name)))
+		if info.NextEntryOffset == 0 {
+			break
+		}
+		offset += int(info.NextEntryOffset)
 	}
-	return nil
+	return result
+}
+
+// WaitForChange locks the goroutine until a single change is detected and
+// returns the paths to the changed files. Note that some changes are
+// reported multiple times, for example saving a file will cause a change to
+// the file and a change to the directory. If you want to report cases like
+// that as one event, see WaitForChangeGroup.
+func (d *DirWatcher) WaitForChange() ([]string, error) {
+	change, ok := <-d.changes
+	if !ok {
+		return nil, burrito.WrappedError("The watcher is closed.")
+	}
+	return change.paths, change.err
 }
 
 // WaitForChangeGroup locks a goroutine until it recives a change notification.
-// When that happens it sends the interruptionMessage to the
-// interruptionChannel.
 // Then it continues locking as long as other changes keep coming with
-// intercals less than the given timeout, to group notifications that come
-// in short intervals together.
+// intervals less than the given timeout (in milliseconds), to group
+// notifications that come in short intervals together. When the changes
+// stop, it sends the source and the paths to all of the changed files to the
+// interruptionChannel.
 func (d *DirWatcher) WaitForChangeGroup(
-	groupTimeout uint32, interruptionChannel chan string,
-	interruptionMessage string,
+	groupTimeout uint32, interruptionChannel chan watchInterruption,
+	source string,
 ) error {
-	err := d.WaitForChange()
+	paths, err := d.WaitForChange()
 	if err != nil {
 		return err
 	}
-	// Instantly report the change
-	interruptionChannel <- interruptionMessage
 	// Consume all changes for groupDelay duration
 	for {
-		event, err := windows.WaitForSingleObject(d.handle, groupTimeout)
-		if err != nil {
-			return err
-		}
-		// Possible options: WAIT_OBJECT_0, WAIT_ABANDONED, WAIT_TIMEOUT,
-		// WAIT_FAILED
-		if event == uint32(windows.WAIT_TIMEOUT) ||
-			event == uint32(windows.WAIT_ABANDONED) {
-			break
-		}
-		err = windows.FindNextChangeNotification(d.handle)
-		if err != nil {
-			return err
+		select {
+		case change, ok := <-d.changes:
+			if !ok || change.err != nil {
+				interruptionChannel <- watchInterruption{source, paths}
+				return change.err
+			}
+			paths = append(paths, change.paths...)
+		case <-time.After(time.Duration(groupTimeout) * time.Millisecond):
+			interruptionChannel <- watchInterruption{source, paths}
+			return nil
 		}
 	}
-	return nil
 }
 
 // Close closes DirWatcher handle.
@@ -169,3 +253,22 @@ func FindPreviewDir() (string, error) {
 	}
 	return result, nil
 }
+
+// startInNewProcessGroup makes the command start in a new process group, so
+// it doesn't receive the Ctrl+C signals sent to Regolith by the console.
+func startInNewProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP,
+	}
+}
+
+// killProcessGroup kills the process started with startInNewProcessGroup
+// together with its child processes.
+func killProcessGroup(process *os.Process) error {
+	err := exec.Command(
+		"taskkill", "/T", "/F", "/PID", strconv.Itoa(process.Pid)).Run()
+	if err != nil {
+		return process.Kill()
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Functions used by the shell completion scripts generated with the
+// "regolith completions" command for completing the names of the profiles
+// and the filters of the project.
+package regolith
+
+import (
+	"sort"
+	"strings"
+)
+
+// completionConfigSection returns the object from the "regolith" property
+// of the config file under the given key, or nil if it can't be loaded.
+// The completion runs silently, so the errors are ignored.
+func completionConfigSection(configPath, key string) map[string]interface{} {
+	configJson, err := LoadConfigAsMap(configPath)
+	if err != nil {
+		return nil
+	}
+	regolith, _ := configJson["regolith"].(map[string]interface{})
+	section, _ := regolith[key].(map[string]interface{})
+	return section
+}
+
+// completeKeys returns the sorted keys of the object that start with the
+// prefix and match the filter. The filter can be nil.
+func completeKeys(
+	obj map[string]interface{}, prefix string,
+	filter func(value interface{}) bool,
+) []string {
+	result := []string{}
+	for key, value := range obj {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if filter != nil && !filter(value) {
+			continue
+		}
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// CompleteProfileNames returns the sorted names of the profiles from the
+// config file that start with the prefix. The empty configPath means the
+// default "config.json" file. It returns an empty list if the config file
+// can't be loaded.
+func CompleteProfileNames(configPath, prefix string) []string {
+	return completeKeys(
+		completionConfigSection(configPath, "profiles"), prefix, nil)
+}
+
+// CompleteFilterNames returns the sorted names of the filters from the
+// "filterDefinitions" of the config file that start with the prefix. If
+// remoteOnly is true, only the remote filters (the filters with the "url"
+// property) are returned. The empty configPath means the default
+// "config.json" file. It returns an empty list if the config file can't be
+// loaded.
+func CompleteFilterNames(configPath, prefix string, remoteOnly bool) []string {
+	var filter func(value interface{}) bool
+	if remoteOnly {
+		filter = func(value interface{}) bool {
+			definition, _ := value.(map[string]interface{})
+			_, ok := definition["url"]
+			return ok
+		}
+	}
+	return completeKeys(
+		completionConfigSection(configPath, "filterDefinitions"), prefix,
+		filter)
+}
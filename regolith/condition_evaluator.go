@@ -1,7 +1,10 @@
 package regolith
 
 import (
+	"os"
+	"regexp"
 	"runtime"
+	"strings"
 
 	"github.com/Bedrock-OSS/go-burrito/burrito"
 	"github.com/stirante/go-simple-eval/eval"
@@ -12,6 +15,11 @@ func EvalCondition(condition string, ctx RunContext) (bool, error) {
 	Logger.Debugf("Evaluating condition: %s", condition)
 	t := prepareScope(ctx)
 	Logger.Debugf("Evaluation scope: %s", utils.ToString(t))
+	// The environment variables are added after logging the scope because
+	// they may contain secrets
+	env := environmentScope()
+	addMissingEnvReferences(condition, env)
+	t["env"] = env
 	e, err := eval.Eval(condition, t)
 	if err != nil {
 		return false, burrito.WrapErrorf(err, "Failed to evaluate condition: %s", condition)
@@ -34,3 +42,33 @@ func prepareScope(ctx RunContext) map[string]interface{} {
 		"filterLocation": ctx.AbsoluteLocation,
 	}
 }
+
+// envReferencePattern matches the references to the environment variables
+// in the conditions, like "env.CI". The first group is the variable name.
+var envReferencePattern = regexp.MustCompile(`\benv\s*\.\s*([A-Za-z_][A-Za-z0-9_]*)`)
+
+// environmentScope returns the environment variables of Regolith as a map
+// used for the "env" variable of the conditions.
+func environmentScope() map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, variable := range os.Environ() {
+		name, value, ok := strings.Cut(variable, "=")
+		if !ok || name == "" {
+			continue
+		}
+		result[name] = value
+	}
+	return result
+}
+
+// addMissingEnvReferences adds the environment variables referenced in the
+// condition that are not set to the env map as empty strings, so that
+// conditions like 'env.CI == "true"' evaluate to false instead of failing
+// when the variable is not set.
+func addMissingEnvReferences(condition string, env map[string]interface{}) {
+	for _, match := range envReferencePattern.FindAllStringSubmatch(condition, -1) {
+		if _, ok := env[match[1]]; !ok {
+			env[match[1]] = ""
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package regolith
 
-import "github.com/Bedrock-OSS/go-burrito/burrito"
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
 
 const StandardLibraryUrl = "github.com/Bedrock-OSS/regolith-filters"
 const ConfigFilePath = "config.json"
@@ -13,6 +19,37 @@ type Config struct {
 	Author          string `json:"author,omitempty"`
 	Packs           `json:"packs,omitempty"`
 	RegolithProject `json:"regolith,omitempty"`
+
+	// projectRoot is the root of the project that the paths of the config
+	// are relative to. The empty string means the current working directory.
+	projectRoot string
+}
+
+// setProjectRoot makes the paths of the packs, the data folder and the local
+// filters of the config relative to the projectRoot instead of the current
+// working directory.
+func (c *Config) setProjectRoot(projectRoot string) {
+	c.projectRoot = projectRoot
+	c.BehaviorFolder = projectPath(projectRoot, c.BehaviorFolder)
+	c.ResourceFolder = projectPath(projectRoot, c.ResourceFolder)
+	c.DataPath = projectPath(projectRoot, c.DataPath)
+	for _, filterDefinition := range c.FilterDefinitions {
+		setFilterProjectRoot(filterDefinition, projectRoot)
+	}
+}
+
+// projectDir returns the absolute path to the root of the project. The nil
+// config uses the working directory.
+func (c *Config) projectDir() (string, error) {
+	projectRoot := "."
+	if c != nil && c.projectRoot != "" {
+		projectRoot = c.projectRoot
+	}
+	projectDir, err := filepath.Abs(projectRoot)
+	if err != nil {
+		return "", burrito.WrapErrorf(err, filepathAbsError, projectRoot)
+	}
+	return projectDir, nil
 }
 
 // ExportTarget is a part of "config.json" that contains export information
@@ -24,6 +61,48 @@ type ExportTarget struct {
 	WorldName string `json:"worldName,omitempty"`
 	WorldPath string `json:"worldPath,omitempty"`
 	ReadOnly  bool   `json:"readOnly"` // Whether the exported files should be read-only
+
+	// Build is the Minecraft client used by the "development" export
+	// target, "release" (default) or "preview".
+	Build string `json:"build,omitempty"`
+
+	// Compression and CompressionLevel are used by the archive export
+	// targets. The compression is disabled by default. The level -1 means
+	// the default level of the compression algorithm.
+	Compression      string `json:"compression,omitempty"`
+	CompressionLevel int    `json:"compressionLevel,omitempty"`
+
+	// Command is the shell command that exports the packs, used by the
+	// "exec" export target.
+	Command string `json:"command,omitempty"`
+
+	// RegenerateUuids replaces the UUIDs of the manifests of the exported
+	// packs with the UUIDs from the mapping in the .regolith directory.
+	RegenerateUuids bool `json:"regenerateUuids,omitempty"`
+
+	// LinkPacks adds the UUID of the header of the resource pack to the
+	// dependencies of the behavior pack and vice versa.
+	LinkPacks bool `json:"linkPacks,omitempty"`
+
+	// Optimize selects the optimizations of the files of the packs applied
+	// before exporting them.
+	Optimize *ExportOptimization `json:"optimize,omitempty"`
+
+	// Layout maps the names of the packs ("BP" and "RP") to their paths
+	// relative to the root of the export target, replacing the default
+	// layout of the export target.
+	Layout map[string]string `json:"layout,omitempty"`
+
+	// Path is the path to the archive created by the "mcworld" and
+	// "mctemplate" export targets.
+	Path string `json:"path,omitempty"`
+
+	// Port and Timeout are used by the experimental "live" export target.
+	// Port is the port of the websocket server that Minecraft connects to.
+	// Timeout is the number of seconds to wait for the connection and for
+	// the response to the "/reload" command.
+	Port    int `json:"port,omitempty"`
+	Timeout int `json:"timeout,omitempty"`
 }
 
 // Packs is a part of "config.json" that points to the source behavior and
@@ -39,6 +118,17 @@ type RegolithProject struct {
 	Profiles          map[string]Profile         `json:"profiles,omitempty"`
 	FilterDefinitions map[string]FilterInstaller `json:"filterDefinitions"`
 	DataPath          string                     `json:"dataPath,omitempty"`
+	DotEnv            bool                       `json:"dotenv,omitempty"`
+
+	// FilterGroups maps the names of the filter groups to the names of
+	// their filters. The filters of a group are installed and updated
+	// together.
+	FilterGroups map[string][]string `json:"filterGroups,omitempty"`
+
+	// WatchPaths and IgnorePaths select the directories watched by
+	// "regolith watch".
+	WatchPaths  []string `json:"watchPaths,omitempty"`
+	IgnorePaths []string `json:"ignorePaths,omitempty"`
 }
 
 // ConfigFromObject creates a "Config" object from map[string]interface{}
@@ -74,11 +164,22 @@ func ConfigFromObject(obj map[string]interface{}) (*Config, error) {
 			return nil, burrito.WrappedErrorf(
 				jsonPathTypeError, "regolith", "object")
 		}
+		// The filter definitions of the project override the global ones
+		regolith, err := mergeGlobalFilterDefinitions(regolith)
+		if err != nil {
+			return nil, burrito.PassError(err)
+		}
 		regolithProject, err := RegolithProjectFromObject(regolith)
 		if err != nil {
 			return nil, burrito.WrapErrorf(err, jsonPropertyParseError, "regolith")
 		}
 		result.RegolithProject = regolithProject
+		// Merge the profiles with the profiles they extend
+		err = resolveProfileInheritance(result.Profiles)
+		if err != nil {
+			return nil, burrito.WrapErrorf(
+				err, jsonPropertyParseError, "regolith->profiles")
+		}
 	} else {
 		return nil, burrito.WrappedErrorf(jsonPropertyMissingError, "regolith")
 	}
@@ -116,6 +217,26 @@ func RegolithProjectFromObject(
 			jsonPropertyTypeError, "dataPath", "string")
 	}
 	result.DataPath = dataPath
+	// DotEnv
+	if dotEnv, ok := obj["dotenv"]; ok {
+		result.DotEnv, ok = dotEnv.(bool)
+		if !ok {
+			return result, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "dotenv", "boolean")
+		}
+	}
+	// WatchPaths (optional)
+	watchPaths, err := stringListFromObject(obj, "watchPaths")
+	if err != nil {
+		return result, burrito.PassError(err)
+	}
+	result.WatchPaths = watchPaths
+	// IgnorePaths (optional)
+	ignorePaths, err := stringListFromObject(obj, "ignorePaths")
+	if err != nil {
+		return result, burrito.PassError(err)
+	}
+	result.IgnorePaths = ignorePaths
 	// Filter definitions
 	filterDefinitions, ok := obj["filterDefinitions"].(map[string]interface{})
 	if ok { // filter definitions are optional
@@ -135,6 +256,15 @@ func RegolithProjectFromObject(
 			result.FilterDefinitions[filterDefinitionName] = filterInstaller
 		}
 	}
+	// Filter groups
+	if filterGroups, ok := obj["filterGroups"]; ok {
+		groups, err := filterGroupsFromObject(filterGroups, filterDefinitions)
+		if err != nil {
+			return result, burrito.WrapErrorf(
+				err, jsonPropertyParseError, "filterGroups")
+		}
+		result.FilterGroups = groups
+	}
 	// Profiles
 	profiles, ok := obj["profiles"].(map[string]interface{})
 	if !ok {
@@ -158,6 +288,25 @@ func RegolithProjectFromObject(
 	return result, nil
 }
 
+// ListProfiles returns a human-readable list of the profiles sorted by their
+// names. Every line contains the name of a profile and its description (if
+// the profile has one).
+func (r *RegolithProject) ListProfiles() string {
+	names := make([]string, 0, len(r.Profiles))
+	for name := range r.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = "- " + name
+		if description := r.Profiles[name].Description; description != "" {
+			lines[i] += ": " + description
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // ExportTargetFromObject creates a "ExportTarget" object from
 // map[string]interface{}
 func ExportTargetFromObject(obj map[string]interface{}) (ExportTarget, error) {
@@ -185,8 +334,148 @@ func ExportTargetFromObject(obj map[string]interface{}) (ExportTarget, error) {
 	// WorldPath - can be empty
 	worldPath, _ := obj["worldPath"].(string)
 	result.WorldPath = worldPath
+	// Path - can be empty
+	path, _ := obj["path"].(string)
+	result.Path = path
 	// ReadOnly - can be empty
 	readOnly, _ := obj["readOnly"].(bool)
 	result.ReadOnly = readOnly
+	// Build - can be empty, only used by the "development" export target
+	if build, ok := obj["build"]; ok {
+		build, ok := build.(string)
+		if !ok || (build != releaseBuild && build != previewBuild) {
+			return result, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "build",
+				"\"release\" or \"preview\" string")
+		}
+		if result.Target != "development" {
+			return result, burrito.WrappedErrorf(
+				"The \"build\" property is only supported by the "+
+					"\"development\" export target.\nTarget: %s",
+				result.Target)
+		}
+		result.Build = build
+	}
+	// Compression - can be empty, only used by the "tar" export target
+	if compression, ok := obj["compression"]; ok {
+		compression, ok := compression.(string)
+		if !ok {
+			return result, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "compression", "string")
+		}
+		if compression != compressionNone && compression != compressionGzip {
+			return result, burrito.WrappedErrorf(
+				"Unsupported compression algorithm.\nAlgorithm: %s\n"+
+					"Supported algorithms: %s, %s",
+				compression, compressionNone, compressionGzip)
+		}
+		if result.Target != "tar" {
+			return result, burrito.WrappedErrorf(
+				"The \"compression\" property is only supported by the "+
+					"\"tar\" export target.\nTarget: %s", result.Target)
+		}
+		result.Compression = compression
+	}
+	// CompressionLevel - can be empty, only used by the archive export
+	// targets
+	result.CompressionLevel = -1
+	if compressionLevel, ok := obj["compressionLevel"]; ok {
+		compressionLevel, ok := compressionLevel.(float64)
+		if !ok || compressionLevel != float64(int(compressionLevel)) ||
+			compressionLevel < 0 || compressionLevel > 9 {
+			return result, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "compressionLevel",
+				"integer from 0 to 9")
+		}
+		if !isArchiveExportTarget(result.Target) &&
+			!isWorldArchiveExportTarget(result.Target) {
+			return result, burrito.WrappedErrorf(
+				"The \"compressionLevel\" property is only supported by "+
+					"the archive export targets.\nTarget: %s", result.Target)
+		}
+		result.CompressionLevel = int(compressionLevel)
+	}
+	// Command - required by the "exec" export target
+	if command, ok := obj["command"]; ok {
+		command, ok := command.(string)
+		if !ok {
+			return result, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "command", "string")
+		}
+		result.Command = command
+	}
+	if result.Target == execExportTarget && result.Command == "" {
+		return result, burrito.WrappedErrorf(jsonPropertyMissingError, "command")
+	}
+	// RegenerateUuids - can be empty
+	if regenerateUuids, ok := obj["regenerateUuids"]; ok {
+		regenerateUuids, ok := regenerateUuids.(bool)
+		if !ok {
+			return result, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "regenerateUuids", "bool")
+		}
+		result.RegenerateUuids = regenerateUuids
+	}
+	// LinkPacks - can be empty
+	if linkPacks, ok := obj["linkPacks"]; ok {
+		linkPacks, ok := linkPacks.(bool)
+		if !ok {
+			return result, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "linkPacks", "bool")
+		}
+		result.LinkPacks = linkPacks
+	}
+	// Optimize - can be empty
+	if optimize, ok := obj["optimize"]; ok {
+		optimize, ok := optimize.(map[string]interface{})
+		if !ok {
+			return result, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "optimize", "object")
+		}
+		optimization, err := ExportOptimizationFromObject(optimize)
+		if err != nil {
+			return result, burrito.WrapErrorf(
+				err, jsonPropertyParseError, "optimize")
+		}
+		result.Optimize = &optimization
+	}
+	// Layout - can be empty
+	if layout, ok := obj["layout"]; ok {
+		layout, ok := layout.(map[string]interface{})
+		if !ok {
+			return result, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "layout", "object")
+		}
+		exportLayout, err := ExportLayoutFromObject(layout)
+		if err != nil {
+			return result, burrito.WrapErrorf(
+				err, jsonPropertyParseError, "layout")
+		}
+		result.Layout = exportLayout
+	}
+	// Port - can be empty, only used by the "live" export target
+	result.Port = defaultLivePort
+	if port, ok := obj["port"]; ok {
+		port, ok := port.(float64)
+		if !ok || port != float64(int(port)) || port < 1 || port > 65535 {
+			return result, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "port", "integer from 1 to 65535")
+		}
+		result.Port = int(port)
+	}
+	// Timeout - can be empty, only used by the "live" export target
+	result.Timeout = defaultLiveTimeout
+	if timeout, ok := obj["timeout"]; ok {
+		timeout, ok := timeout.(float64)
+		if !ok || timeout != float64(int(timeout)) || timeout < 1 {
+			return result, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "timeout", "positive integer")
+		}
+		result.Timeout = int(timeout)
+	}
+	// The paths can use the placeholders like {{.Name}}
+	if err := checkExportPathTemplates(result); err != nil {
+		return result, burrito.PassError(err)
+	}
 	return result, nil
 }
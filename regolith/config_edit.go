@@ -0,0 +1,205 @@
+// Functions used by the "regolith config get" and "regolith config set"
+// commands, which read and modify the properties of the config file.
+package regolith
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+	"go.uber.org/zap"
+)
+
+// splitConfigPath splits the dotted path to a property of the config file
+// into its keys. The paths that don't start with one of the root properties
+// of the config file are relative to the "regolith" object, so
+// "profiles.default.export.target" and
+// "regolith.profiles.default.export.target" point to the same property.
+func splitConfigPath(
+	config map[string]interface{}, path string,
+) ([]string, error) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys {
+		if key == "" {
+			return nil, burrito.WrappedErrorf(
+				"Invalid path to the property of the config file.\n"+
+					"Path: %s", path)
+		}
+	}
+	if _, ok := config[keys[0]]; ok || keys[0] == "regolith" {
+		return keys, nil
+	}
+	return append([]string{"regolith"}, keys...), nil
+}
+
+// configPathChild returns the value of the key of the object or the array.
+// The keys of the arrays are the indices of their items.
+func configPathChild(parent interface{}, key string) (interface{}, bool) {
+	switch parent := parent.(type) {
+	case map[string]interface{}:
+		child, ok := parent[key]
+		return child, ok
+	case []interface{}:
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index >= len(parent) {
+			return nil, false
+		}
+		return parent[index], true
+	}
+	return nil, false
+}
+
+// getConfigValue returns the value of the property of the config file at the
+// path.
+func getConfigValue(config map[string]interface{}, keys []string) (
+	interface{}, error,
+) {
+	var current interface{} = config
+	for i, key := range keys {
+		child, ok := configPathChild(current, key)
+		if !ok {
+			return nil, burrito.WrappedErrorf(
+				jsonPathMissingError, strings.Join(keys[:i+1], "->"))
+		}
+		current = child
+	}
+	return current, nil
+}
+
+// setConfigValue sets the value of the property of the config file at the
+// path. The missing objects on the path are created. The items of the arrays
+// can be replaced, but not added.
+func setConfigValue(
+	config map[string]interface{}, keys []string, value interface{},
+) error {
+	var current interface{} = config
+	for i, key := range keys {
+		path := strings.Join(keys[:i+1], "->")
+		last := i == len(keys)-1
+		switch parent := current.(type) {
+		case map[string]interface{}:
+			if last {
+				parent[key] = value
+				return nil
+			}
+			if _, ok := parent[key]; !ok {
+				parent[key] = make(map[string]interface{})
+			}
+			current = parent[key]
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(parent) {
+				return burrito.WrappedErrorf(
+					"The index is out of range of the array.\n"+
+						"JSON Path: %s", path)
+			}
+			if last {
+				parent[index] = value
+				return nil
+			}
+			current = parent[index]
+		default:
+			return burrito.WrappedErrorf(
+				jsonPathTypeError, strings.Join(keys[:i], "->"),
+				"object or array")
+		}
+	}
+	return nil
+}
+
+// parseConfigValue parses the value passed to "regolith config set". Valid
+// JSON values (numbers, booleans, null, arrays, objects and quoted strings)
+// are used as they are, the other values are strings.
+func parseConfigValue(value string) interface{} {
+	var result interface{}
+	if err := json.Unmarshal([]byte(value), &result); err != nil {
+		return value
+	}
+	return result
+}
+
+// ConfigGet handles the "regolith config get" command. It prints the value of
+// the property of the config file at the dotted path. The strings are
+// printed without quotes, the other values are printed as JSON. Only the
+// warnings and the errors are logged, so the output can be used by scripts.
+//
+// The "configPath" parameter is the path to the config file. The empty path
+// means "config.json".
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func ConfigGet(path, configPath string, debug bool) error {
+	InitLogging(debug)
+	// The value is usually read by scripts, the informational messages
+	// would be mixed with it
+	if !debug {
+		LoggerLevel.SetLevel(zap.WarnLevel)
+	}
+	configMap, err := LoadConfigAsMap(configPath)
+	if err != nil {
+		return burrito.WrapError(err, "Unable to load config file.")
+	}
+	keys, err := splitConfigPath(configMap, path)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	value, err := getConfigValue(configMap, keys)
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, "Failed to get the property of the config file.\nPath: %s",
+			path)
+	}
+	if value, ok := value.(string); ok {
+		fmt.Println(value)
+		return nil
+	}
+	jsonBytes, _ := json.MarshalIndent(value, "", "\t")
+	fmt.Println(string(jsonBytes))
+	return nil
+}
+
+// ConfigSet handles the "regolith config set" command. It sets the value of
+// the property of the config file at the dotted path. The modified config is
+// checked before saving it, so the command never saves an invalid config
+// file.
+//
+// The "configPath" parameter is the path to the config file. The empty path
+// means "config.json".
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func ConfigSet(path, value, configPath string, debug bool) error {
+	InitLogging(debug)
+	configMap, err := LoadConfigAsMap(configPath)
+	if err != nil {
+		return burrito.WrapError(err, "Unable to load config file.")
+	}
+	keys, err := splitConfigPath(configMap, path)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	err = setConfigValue(configMap, keys, parseConfigValue(value))
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, "Failed to set the property of the config file.\nPath: %s",
+			path)
+	}
+	_, err = ConfigFromObject(configMap)
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, "The new value makes the config file invalid, the file was "+
+				"not modified.\nPath: %s", path)
+	}
+	// Save the config file
+	jsonBytes, _ := json.MarshalIndent(configMap, "", "\t")
+	configPath = resolveConfigPath(configPath)
+	err = ioutil.WriteFile(configPath, jsonBytes, 0644)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, configPath)
+	}
+	Logger.Infof("Set the %q property of the config file.", path)
+	return nil
+}
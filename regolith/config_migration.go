@@ -0,0 +1,194 @@
+// Functions for upgrading the config files written for the older versions of
+// Regolith to the current format, used by the "regolith migrate" command.
+package regolith
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// ConfigSchemaUrl is the URL to the JSON schema of the current version of the
+// config file.
+const ConfigSchemaUrl = "https://raw.githubusercontent.com/Bedrock-OSS/regolith-schemas/main/config/v1.1.json"
+
+// defaultDataPath is the data path used by "regolith init".
+const defaultDataPath = "./packs/data"
+
+// configMigration is a single transformation of the config file. It modifies
+// the config map and returns the descriptions of the applied changes. The
+// migrations must be idempotent - applying a migration to the config which
+// is already migrated doesn't change anything.
+type configMigration func(config map[string]interface{}) ([]string, error)
+
+// configMigrations is the list of the migrations applied by
+// "regolith migrate", in order.
+var configMigrations = []configMigration{
+	migrateSchema,
+	migrateProfileDataPath,
+	migrateUnsafeProfiles,
+	migrateMissingExport,
+	migrateUnversionedFilters,
+}
+
+// migrateConfig applies all of the migrations to the config map and returns
+// the descriptions of the applied changes. An empty list means that the
+// config is already up to date.
+func migrateConfig(config map[string]interface{}) ([]string, error) {
+	result := []string{}
+	for _, migration := range configMigrations {
+		changes, err := migration(config)
+		if err != nil {
+			return nil, burrito.PassError(err)
+		}
+		result = append(result, changes...)
+	}
+	return result, nil
+}
+
+// sortedKeys returns the keys of the JSON object in alphabetical order, so
+// the migrations are applied and reported in a stable order.
+func sortedKeys(obj map[string]interface{}) []string {
+	result := make([]string, 0, len(obj))
+	for key := range obj {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// migrateSchema sets the "$schema" property to the schema of the current
+// version of the config.
+func migrateSchema(config map[string]interface{}) ([]string, error) {
+	schema, _ := config["$schema"].(string)
+	if schema == ConfigSchemaUrl {
+		return nil, nil
+	}
+	config["$schema"] = ConfigSchemaUrl
+	if schema == "" {
+		return []string{fmt.Sprintf("Added \"$schema\": %q.", ConfigSchemaUrl)}, nil
+	}
+	return []string{fmt.Sprintf(
+		"Changed \"$schema\" from %q to %q.", schema, ConfigSchemaUrl)}, nil
+}
+
+// migrateProfileDataPath moves the "dataPath" property from the profiles,
+// where it was defined in the old versions of Regolith, to the "regolith"
+// object. If the "regolith" object has no data path, the default one is
+// used.
+func migrateProfileDataPath(config map[string]interface{}) ([]string, error) {
+	regolith, ok := config["regolith"].(map[string]interface{})
+	if !ok {
+		return nil, burrito.WrappedErrorf(jsonPathMissingError, "regolith")
+	}
+	profiles, _ := regolith["profiles"].(map[string]interface{})
+	result := []string{}
+	dataPath, hasDataPath := regolith["dataPath"].(string)
+	for _, name := range sortedKeys(profiles) {
+		profile, ok := profiles[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		profileDataPath, ok := profile["dataPath"].(string)
+		if !ok {
+			continue
+		}
+		if !hasDataPath {
+			dataPath, hasDataPath = profileDataPath, true
+			regolith["dataPath"] = dataPath
+			result = append(result, fmt.Sprintf(
+				"Moved \"dataPath\" of the %q profile to the \"regolith\" "+
+					"object.", name))
+		} else if profileDataPath != dataPath {
+			return nil, burrito.WrappedErrorf(
+				"The profiles use different data paths. Only one data path "+
+					"per project is supported.\nProfile: %s\n"+
+					"Data path of the profile: %s\nData path: %s",
+				name, profileDataPath, dataPath)
+		} else {
+			result = append(result, fmt.Sprintf(
+				"Removed \"dataPath\" from the %q profile.", name))
+		}
+		delete(profile, "dataPath")
+	}
+	if !hasDataPath {
+		regolith["dataPath"] = defaultDataPath
+		result = append(result, fmt.Sprintf(
+			"Added \"dataPath\": %q to the \"regolith\" object.",
+			defaultDataPath))
+	}
+	return result, nil
+}
+
+// migrateUnsafeProfiles removes the "unsafe" property of the profiles, which
+// is no longer supported.
+func migrateUnsafeProfiles(config map[string]interface{}) ([]string, error) {
+	profiles, _ := profilesFromConfigMap(config)
+	result := []string{}
+	for _, name := range sortedKeys(profiles) {
+		profile, ok := profiles[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, ok := profile["unsafe"]; ok {
+			delete(profile, "unsafe")
+			result = append(result, fmt.Sprintf(
+				"Removed \"unsafe\" from the %q profile.", name))
+		}
+	}
+	return result, nil
+}
+
+// migrateMissingExport adds the default export target (the same as in the
+// profiles created by "regolith init") to the profiles without the "export"
+// property. The profiles that extend other profiles inherit their export
+// targets, so they're not modified.
+func migrateMissingExport(config map[string]interface{}) ([]string, error) {
+	profiles, _ := profilesFromConfigMap(config)
+	result := []string{}
+	for _, name := range sortedKeys(profiles) {
+		profile, ok := profiles[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		_, hasExport := profile["export"]
+		_, extends := profile["extends"]
+		if hasExport || extends {
+			continue
+		}
+		profile["export"] = map[string]interface{}{
+			"target":   "development",
+			"readOnly": false,
+		}
+		result = append(result, fmt.Sprintf(
+			"Added the \"development\" export target to the %q profile.",
+			name))
+	}
+	return result, nil
+}
+
+// migrateUnversionedFilters adds the "HEAD" version to the definitions of
+// the remote filters without the "version" property. The old versions of
+// Regolith always installed the latest commit of the filters.
+func migrateUnversionedFilters(config map[string]interface{}) ([]string, error) {
+	regolith, _ := config["regolith"].(map[string]interface{})
+	filterDefinitions, _ := regolith["filterDefinitions"].(map[string]interface{})
+	result := []string{}
+	for _, name := range sortedKeys(filterDefinitions) {
+		definition, ok := filterDefinitions[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		_, hasRunWith := definition["runWith"]
+		_, hasSource := definition["source"]
+		_, hasVersion := definition["version"]
+		if hasRunWith || hasSource || hasVersion {
+			continue
+		}
+		definition["version"] = "HEAD"
+		result = append(result, fmt.Sprintf(
+			"Added \"version\": \"HEAD\" to the %q filter definition.", name))
+	}
+	return result, nil
+}
@@ -13,10 +13,26 @@ import (
 	"muzzammil.xyz/jsonc"
 )
 
-// LoadConfigAsMap loads the config.json file as map[string]interface{}
-func LoadConfigAsMap() (map[string]interface{}, error) {
-	file, err := ioutil.ReadFile(ConfigFilePath)
+// resolveConfigPath returns the path to the config file. The empty path
+// means the default ConfigFilePath.
+func resolveConfigPath(configPath string) string {
+	if configPath == "" {
+		return ConfigFilePath
+	}
+	return configPath
+}
+
+// LoadConfigAsMap loads the config file from the configPath as
+// map[string]interface{}. The empty configPath loads the default config.json
+// file.
+func LoadConfigAsMap(configPath string) (map[string]interface{}, error) {
+	configPath = resolveConfigPath(configPath)
+	file, err := ioutil.ReadFile(configPath)
 	if err != nil {
+		if configPath != ConfigFilePath {
+			return nil, burrito.WrappedErrorf(
+				"Failed to open the config file.\nPath: %s", configPath)
+		}
 		return nil, burrito.WrappedError( // We don't need to pass OS error. It's confusing.
 			"Failed to open \"config.json\". This directory is not a Regolith project.\n" +
 				"Please make sure to run this command in a Regolith project directory.\n" +
@@ -25,7 +41,7 @@ func LoadConfigAsMap() (map[string]interface{}, error) {
 	var configJson map[string]interface{}
 	err = jsonc.Unmarshal(file, &configJson)
 	if err != nil {
-		return nil, burrito.WrapErrorf(err, jsonUnmarshalError, ConfigFilePath)
+		return nil, burrito.WrapErrorf(err, jsonUnmarshalError, configPath)
 	}
 	return configJson, nil
 }
@@ -79,3 +95,133 @@ func useAppDataFromConfigMap(config map[string]interface{}) (bool, error) {
 	}
 	return filterDefinitions, nil
 }
+
+// profilesFromConfigMap returns the profiles as map from the config file map,
+// without parsing it to a Config object.
+func profilesFromConfigMap(
+	config map[string]interface{},
+) (map[string]interface{}, error) {
+	regolith, ok := config["regolith"].(map[string]interface{})
+	if !ok {
+		return nil, burrito.WrappedErrorf(jsonPathMissingError, "regolith")
+	}
+	profiles, ok := regolith["profiles"].(map[string]interface{})
+	if !ok {
+		return nil, burrito.WrappedErrorf(
+			jsonPathMissingError, "regolith->profiles")
+	}
+	return profiles, nil
+}
+
+// addProfileFromFile loads the profile from the JSON file and adds it to the
+// profiles of the config file map under the given name, so it's parsed with
+// the filter definitions of the config and run like the other profiles. The
+// config file is not modified. Returns an error if the config already has a
+// profile with this name.
+func addProfileFromFile(
+	config map[string]interface{}, path, name string,
+) error {
+	profiles, err := profilesFromConfigMap(config)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	if _, ok := profiles[name]; ok {
+		return burrito.WrappedErrorf(
+			"The config file already has a profile with the name of the "+
+				"profile from the file.\nProfile: %s\nPath: %s", name, path)
+	}
+	file, err := ioutil.ReadFile(path)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileReadError, path)
+	}
+	var profile map[string]interface{}
+	err = jsonc.Unmarshal(file, &profile)
+	if err != nil {
+		return burrito.WrapErrorf(err, jsonUnmarshalError, path)
+	}
+	profiles[name] = profile
+	return nil
+}
+
+// filterGroupsFromConfigMap returns the filter groups from the config file
+// map, without parsing it to a Config object. The groups are optional, so an
+// empty map is returned if the config doesn't have them.
+func filterGroupsFromConfigMap(
+	config map[string]interface{},
+) (map[string][]string, error) {
+	regolith, ok := config["regolith"].(map[string]interface{})
+	if !ok {
+		return nil, burrito.WrappedErrorf(jsonPathMissingError, "regolith")
+	}
+	filterGroups, ok := regolith["filterGroups"]
+	if !ok {
+		return map[string][]string{}, nil
+	}
+	filterDefinitions, _ := regolith["filterDefinitions"].(map[string]interface{})
+	result, err := filterGroupsFromObject(filterGroups, filterDefinitions)
+	if err != nil {
+		return nil, burrito.WrapErrorf(
+			err, jsonPathParseError, "regolith->filterGroups")
+	}
+	return result, nil
+}
+
+// renameFilterInGroups replaces the references to the filter named oldName
+// in the filter groups from the config file map with newName. It returns
+// the number of the replaced references.
+func renameFilterInGroups(
+	config map[string]interface{}, oldName, newName string,
+) int {
+	regolith, ok := config["regolith"].(map[string]interface{})
+	if !ok {
+		return 0
+	}
+	filterGroups, ok := regolith["filterGroups"].(map[string]interface{})
+	if !ok {
+		return 0
+	}
+	count := 0
+	for _, group := range filterGroups {
+		filters, ok := group.([]interface{})
+		if !ok {
+			continue
+		}
+		for i, filter := range filters {
+			if name, ok := filter.(string); ok && name == oldName {
+				filters[i] = newName
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// renameFilterInProfiles replaces the references to the filter named oldName
+// in the "filters" lists of the profiles from the config file map with
+// newName. It returns the number of the replaced references.
+func renameFilterInProfiles(
+	profiles map[string]interface{}, oldName, newName string,
+) int {
+	count := 0
+	for _, profile := range profiles {
+		profileMap, ok := profile.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		filters, ok := profileMap["filters"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, filter := range filters {
+			filterMap, ok := filter.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if name, ok := filterMap["filter"].(string); ok && name == oldName {
+				filterMap["filter"] = newName
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,138 @@
+package regolith
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is the number of the unchanged lines printed around the
+// changed lines in the unified diffs.
+const diffContextLines = 3
+
+// diffLine is a single line of a diff. The kind is ' ' for the unchanged
+// lines, '-' for the removed lines and '+' for the added lines. fromLine
+// and toLine are the numbers of the lines of the compared texts that come
+// before the line.
+type diffLine struct {
+	kind             byte
+	text             string
+	fromLine, toLine int
+}
+
+// splitDiffLines splits the text into lines for the diff. The lines keep
+// their line endings, so the difference in the last line ending is not
+// ignored.
+func splitDiffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the shortest list of the line changes that turns the
+// lines of the first text into the lines of the second text, based on their
+// longest common subsequence.
+func diffLines(from, to []string) []diffLine {
+	// lcs[i][j] is the length of the longest common subsequence of from[i:]
+	// and to[j:]
+	lcs := make([][]int, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	result := make([]diffLine, 0, len(from)+len(to))
+	i, j := 0, 0
+	for i < len(from) || j < len(to) {
+		switch {
+		case i < len(from) && j < len(to) && from[i] == to[j]:
+			result = append(result, diffLine{' ', from[i], i, j})
+			i++
+			j++
+		case j == len(to) || (i < len(from) && lcs[i+1][j] >= lcs[i][j+1]):
+			result = append(result, diffLine{'-', from[i], i, j})
+			i++
+		default:
+			result = append(result, diffLine{'+', to[j], i, j})
+			j++
+		}
+	}
+	return result
+}
+
+// unifiedDiff returns the unified diff of two texts, using the names in the
+// headers of the diff. It returns an empty string if the texts are equal.
+func unifiedDiff(fromName, toName, from, to string) string {
+	lines := diffLines(splitDiffLines(from), splitDiffLines(to))
+	var changes []int
+	for i, line := range lines {
+		if line.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "--- %s\n+++ %s\n", fromName, toName)
+	for len(changes) > 0 {
+		// Group the changes that are close to each other into one hunk
+		last := 0
+		for last+1 < len(changes) &&
+			changes[last+1]-changes[last] <= 2*diffContextLines+1 {
+			last++
+		}
+		start := changes[0] - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		end := changes[last] + diffContextLines + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+		changes = changes[last+1:]
+		hunk := lines[start:end]
+		fromCount, toCount := 0, 0
+		for _, line := range hunk {
+			if line.kind != '+' {
+				fromCount++
+			}
+			if line.kind != '-' {
+				toCount++
+			}
+		}
+		// The empty ranges start at the line before the hunk
+		fromStart, toStart := hunk[0].fromLine, hunk[0].toLine
+		if fromCount > 0 {
+			fromStart++
+		}
+		if toCount > 0 {
+			toStart++
+		}
+		fmt.Fprintf(
+			&builder, "@@ -%d,%d +%d,%d @@\n",
+			fromStart, fromCount, toStart, toCount)
+		for _, line := range hunk {
+			builder.WriteByte(line.kind)
+			builder.WriteString(strings.TrimSuffix(line.text, "\n"))
+			builder.WriteByte('\n')
+			if !strings.HasSuffix(line.text, "\n") {
+				builder.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,150 @@
+// Functions used for loading the environment variables of the filters from
+// the ".env" file of the project.
+package regolith
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// dotEnvFileName is the name of the file in the root of the project that
+// stores the environment variables loaded with the "--dotenv" flag or the
+// "dotenv" property of the config.
+const dotEnvFileName = ".env"
+
+// redactedSecret replaces the values from the ".env" file in the logged
+// output of the filters.
+const redactedSecret = "***"
+
+// loadContextDotEnv returns the variables from the ".env" file of the
+// project, or from the file selected with the "--env-file" flag, if loading
+// the variables is enabled in the context. Unlike the default ".env" file,
+// the file from the flag must exist.
+func loadContextDotEnv(context *RunContext, projectDir string) ([]string, error) {
+	if context == nil {
+		return nil, nil
+	}
+	dotEnvPath := filepath.Join(projectDir, dotEnvFileName)
+	if context.Options.EnvFile != "" {
+		dotEnvPath = context.Options.EnvFile
+		if !filepath.IsAbs(dotEnvPath) {
+			dotEnvPath = filepath.Join(projectDir, dotEnvPath)
+		}
+		if _, err := os.Stat(dotEnvPath); err != nil {
+			return nil, burrito.WrapErrorf(err, osStatErrorAny, dotEnvPath)
+		}
+	} else if !context.Options.DotEnv && !context.Config.DotEnv {
+		return nil, nil
+	}
+	dotEnv, err := readDotEnv(dotEnvPath)
+	if err != nil {
+		return nil, burrito.WrapErrorf(
+			err, "Failed to load the environment variables.\nPath: %s",
+			dotEnvPath)
+	}
+	return dotEnv, nil
+}
+
+// minSecretLength is the minimal length of the values from the ".env" file
+// that are redacted from the logs. The shorter values (like "1" or "on")
+// are most likely not secrets, and redacting them would make the logs
+// unreadable.
+const minSecretLength = 4
+
+// dotEnvSecrets returns the values of the variables from the ".env" file (in
+// the "KEY=value" format), which must not appear in the logs.
+func dotEnvSecrets(dotEnv []string) []string {
+	result := []string{}
+	for _, variable := range dotEnv {
+		parts := strings.SplitN(variable, "=", 2)
+		if len(parts) == 2 && len(parts[1]) >= minSecretLength {
+			result = append(result, parts[1])
+		}
+	}
+	return result
+}
+
+// redactSecrets replaces the secrets in the text with asterisks.
+func redactSecrets(text string, secrets []string) string {
+	for _, secret := range secrets {
+		text = strings.ReplaceAll(text, secret, redactedSecret)
+	}
+	return text
+}
+
+// readDotEnv reads the variables from the ".env" file at the path and
+// returns them in the "KEY=value" format. Empty lines and lines starting
+// with "#" are ignored, the "export " prefix is allowed and the values can
+// be wrapped in single or double quotes. If the file doesn't exist, an empty
+// list is returned.
+func readDotEnv(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, burrito.WrapErrorf(err, osOpenError, path)
+	}
+	defer file.Close()
+	result := []string{}
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		parts := strings.SplitN(line, "=", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || name == "" {
+			return nil, burrito.WrappedErrorf(
+				"Invalid line in the %q file, expected \"KEY=value\".\n"+
+					"Path: %s\nLine: %d", dotEnvFileName, path, lineNumber)
+		}
+		value := strings.TrimSpace(parts[1])
+		if len(value) >= 2 &&
+			(value[0] == '"' || value[0] == '\'') &&
+			value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		result = append(result, fmt.Sprintf("%s=%s", name, value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, burrito.WrapErrorf(err, fileReadError, path)
+	}
+	return result, nil
+}
+
+// mergeDotEnv adds the variables from the dotEnv list to the env list,
+// skipping the ones that are already defined in env, so that the real
+// environment variables take precedence over the ".env" file. Both lists use
+// the "KEY=value" format.
+func mergeDotEnv(env []string, dotEnv []string) []string {
+	defined := make(map[string]struct{}, len(env))
+	for _, variable := range env {
+		defined[envVariableKey(variable)] = struct{}{}
+	}
+	for _, variable := range dotEnv {
+		if _, ok := defined[envVariableKey(variable)]; ok {
+			continue
+		}
+		env = append(env, variable)
+	}
+	return env
+}
+
+// envVariableKey returns the name of the variable in the "KEY=value" format
+// used for comparing the variables. The names are case-insensitive on
+// Windows.
+func envVariableKey(variable string) string {
+	name := strings.SplitN(variable, "=", 2)[0]
+	if runtime.GOOS == "windows" {
+		return strings.ToUpper(name)
+	}
+	return name
+}
@@ -0,0 +1,179 @@
+package regolith
+
+import (
+	"errors"
+	"net"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// defaultDownloadAttempts is the number of attempts of the network
+// operations used when the "download_attempts" user config property is not
+// set.
+const defaultDownloadAttempts = 3
+
+// Offline forbids the network operations. It's set by the global
+// "--offline" flag. The operations that would access the network fail with
+// the offlineModeError instead, and the operations that use only the cached
+// files work normally.
+var Offline bool
+
+// downloadRetryDelay is the delay before the second attempt of a network
+// operation. The delay is doubled after every failed attempt.
+var downloadRetryDelay = time.Second
+
+// networkErrorPatterns are the fragments of the error messages (in lower
+// case) of Git and go-getter that mean that the operation failed because of
+// a network problem that could be temporary.
+var networkErrorPatterns = []string{
+	"could not resolve host",
+	"couldn't resolve host",
+	"no such host",
+	"temporary failure in name resolution",
+	"failed to connect",
+	"connection timed out",
+	"operation timed out",
+	"connection refused",
+	"connection reset",
+	"network is unreachable",
+	"the remote end hung up unexpectedly",
+	"early eof",
+	"rpc failed",
+	"tls handshake",
+	"ssl_connect",
+	"gnutls_handshake",
+	"i/o timeout",
+	"the requested url returned error: 429",
+	"the requested url returned error: 5",
+}
+
+// sshAuthErrorPatterns are the fragments of the error messages (in lower
+// case) of Git and SSH that mean that the authentication with the SSH
+// server failed.
+var sshAuthErrorPatterns = []string{
+	"permission denied (publickey",
+	"host key verification failed",
+}
+
+// errorMessage returns the lower case message of the error, including the
+// standard error output of the failed command.
+func errorMessage(err error) string {
+	message := err.Error()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		message += "\n" + string(exitErr.Stderr)
+	}
+	return strings.ToLower(message)
+}
+
+// isNetworkError returns true if the error was caused by a network problem
+// that could be temporary. The errors like missing repositories are not
+// network errors, because retrying them wouldn't change the result.
+func isNetworkError(err error) bool {
+	if err == nil || isSshAuthError(err) {
+		return false
+	}
+	// The errors of the HTTP downloads that weren't converted to text
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) &&
+		(dnsErr.IsTemporary || dnsErr.IsTimeout || dnsErr.IsNotFound) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	message := errorMessage(err)
+	for _, pattern := range networkErrorPatterns {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSshAuthError returns true if the error was caused by a failed
+// authentication with the SSH server of a Git repository.
+func isSshAuthError(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := errorMessage(err)
+	for _, pattern := range sshAuthErrorPatterns {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// getDownloadAttempts returns the number of attempts of the network
+// operations from the user config.
+func getDownloadAttempts() (int, error) {
+	userConfig, err := getCombinedUserConfig()
+	if err != nil {
+		return 0, burrito.WrapError(err, getUserConfigError)
+	}
+	if userConfig.DownloadAttempts == nil || *userConfig.DownloadAttempts < 1 {
+		return defaultDownloadAttempts, nil
+	}
+	return *userConfig.DownloadAttempts, nil
+}
+
+// retryNetworkOperation runs the operation until it succeeds, fails with an
+// error that is not a network error, or runs out of the attempts from the
+// "download_attempts" user config property. In the offline mode, it fails
+// without running the operation. The delay between the attempts
+// grows exponentially. The description is used in the log messages and in
+// the final error.
+func retryNetworkOperation(description string, operation func() error) error {
+	if Offline {
+		return burrito.WrappedErrorf(offlineModeError, description)
+	}
+	attempts, err := getDownloadAttempts()
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	delay := downloadRetryDelay
+	for attempt := 1; ; attempt++ {
+		err = operation()
+		if isSshAuthError(err) {
+			return burrito.WrapErrorf(err, sshAuthError, description)
+		}
+		if err == nil || !isNetworkError(err) {
+			return err
+		}
+		if attempt >= attempts {
+			return burrito.WrapErrorf(
+				err, networkOperationRetryError, description, attempt)
+		}
+		Logger.Warnf(
+			"Failed to %s because of a network error (attempt %d of %d). "+
+				"Retrying in %s...", description, attempt, attempts, delay)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// gitOutputWithRetry runs a Git command that accesses a remote repository
+// and returns its output. The command is retried on the network errors.
+func gitOutputWithRetry(commandArgs ...string) ([]byte, error) {
+	var output []byte
+	command := "git " + strings.Join(commandArgs, " ")
+	err := retryNetworkOperation("run "+command, func() error {
+		var err error
+		output, err = exec.Command("git", commandArgs...).Output()
+		return err
+	})
+	if err != nil {
+		return nil, burrito.WrapErrorf(err, execCommandError, command)
+	}
+	return output, nil
+}
@@ -0,0 +1,74 @@
+package regolith
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// TestIsNetworkError checks whether the temporary network errors are
+// retried, both the typed errors of the HTTP downloads and the messages of
+// Git, and whether the other errors are not.
+func TestIsNetworkError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"nil", nil, false},
+		{"temporary DNS error",
+			&net.DNSError{Err: "server misbehaving", IsTemporary: true}, true},
+		{"DNS timeout", &net.DNSError{Err: "timeout", IsTimeout: true}, true},
+		{"no such host",
+			&net.DNSError{Err: "no such host", Name: "github.com",
+				IsNotFound: true}, true},
+		{"wrapped DNS error",
+			fmt.Errorf("download failed: %w", &net.OpError{
+				Op: "dial", Net: "tcp",
+				Err: &net.DNSError{Err: "timeout", IsTimeout: true}}), true},
+		{"connection reset",
+			&net.OpError{Op: "read", Net: "tcp",
+				Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true},
+		{"Git message",
+			errors.New("fatal: unable to access: Could not resolve host: github.com"),
+			true},
+		{"no such host message",
+			errors.New("dial tcp: lookup github.com: no such host"), true},
+		{"missing repository",
+			errors.New("remote: Repository not found."), false},
+		{"SSH authentication",
+			errors.New("git@github.com: Permission denied (publickey)."),
+			false},
+	}
+	for _, test := range tests {
+		if actual := isNetworkError(test.err); actual != test.expected {
+			t.Errorf(
+				"%s: isNetworkError(%v) = %v, expected %v",
+				test.name, test.err, actual, test.expected)
+		}
+	}
+}
+
+// TestRetryNetworkOperationOffline checks whether the network operations
+// fail immediately in the offline mode, without being run.
+func TestRetryNetworkOperationOffline(t *testing.T) {
+	InitLogging(false)
+	offline := Offline
+	Offline = true
+	defer func() { Offline = offline }()
+	called := false
+	err := retryNetworkOperation("download the filter", func() error {
+		called = true
+		return nil
+	})
+	if called {
+		t.Error("The network operation was run in the offline mode")
+	}
+	if err == nil || !strings.Contains(err.Error(), "offline mode") {
+		t.Errorf("Unexpected error of the offline mode: %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+// Functions used by the "regolith run --dry-run" command, which prints the
+// filters of a profile instead of running them.
+package regolith
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// DryRunProfile prepares the tmp files for the profile from the context and
+// prints the list of the filters that would be executed by RunProfile,
+// without running them and without exporting the project.
+func DryRunProfile(context RunContext) error {
+	err := SetupTmpFiles(
+		*context.Config, context.DotRegolithPath, !context.Options.NoHardlink)
+	if err != nil {
+		return burrito.WrapErrorf(err, setupTmpFilesError, context.DotRegolithPath)
+	}
+	Logger.Infof(
+		"Dry run of the %q profile. The filters won't be executed.",
+		context.Profile)
+	return dryRunProfileImpl(context)
+}
+
+// dryRunProfileImpl prints the filters of the profile from the context. The
+// dryRunDepth of the context is the nesting level of the profile, used for
+// the indentation of the output.
+func dryRunProfileImpl(context RunContext) error {
+	profile, err := context.GetProfile()
+	if err != nil {
+		return burrito.WrapErrorf(err, runContextGetProfileError)
+	}
+	for _, filter := range profile.Filters {
+		if err := dryRunFilter(filter, context); err != nil {
+			return burrito.PassError(err)
+		}
+	}
+	return nil
+}
+
+// dryRunFilter prints what the filter would do with the DryRun method of the
+// filters that implement FilterDryRunner, or with the generic description of
+// the other filters. The disabled filters and the filters with unmet
+// conditions are only listed.
+func dryRunFilter(filter FilterRunner, context RunContext) error {
+	disabled, err := filter.IsDisabled(context)
+	if err != nil {
+		return burrito.WrapErrorf(err, "Failed to check if filter is disabled")
+	}
+	conditionMet, err := filter.IsConditionMet(context)
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, "Failed to check the condition of the filter.\n"+
+				"Filter: %s", filter.GetId())
+	}
+	if disabled || !conditionMet {
+		skippedTag := "disabled"
+		if !disabled {
+			skippedTag = "skipped by condition"
+		}
+		fmt.Printf(
+			"%s%s [%s]\n", context.dryRunIndent(), dryRunHeader(filter),
+			skippedTag)
+		return nil
+	}
+	dryRunner, ok := filter.(FilterDryRunner)
+	if !ok {
+		printDryRunFilter(filter, context, "")
+		return nil
+	}
+	if err := dryRunner.DryRun(context); err != nil {
+		return burrito.PassError(err)
+	}
+	return nil
+}
+
+// dryRunHeader returns the first line of the description of the filter in
+// the output of the dry run.
+func dryRunHeader(filter FilterRunner) string {
+	if profileFilter, ok := filter.(*ProfileFilter); ok {
+		return "Profile: " + profileFilter.Profile
+	}
+	runWith, _ := describeFilterRunner(filter)
+	return fmt.Sprintf("Filter: %s (%s)", filter.GetId(), runWith)
+}
+
+// printDryRunFilter prints the generic description of the filter: its
+// settings, arguments and working directory. The command is printed only if
+// it's not empty.
+func printDryRunFilter(filter FilterRunner, context RunContext, command string) {
+	indent := context.dryRunIndent()
+	fmt.Printf("%s%s\n", indent, dryRunHeader(filter))
+	if _, basicFilter := describeFilterRunner(filter); basicFilter != nil {
+		if len(basicFilter.Settings) != 0 {
+			settings, _ := json.Marshal(basicFilter.Settings)
+			fmt.Printf("%s  Settings: %s\n", indent, settings)
+		}
+		if len(basicFilter.Arguments) != 0 {
+			fmt.Printf(
+				"%s  Arguments: %s\n", indent,
+				strings.Join(basicFilter.Arguments, " "))
+		}
+	}
+	if command != "" {
+		fmt.Printf("%s  Command: %s\n", indent, command)
+	}
+	fmt.Printf(
+		"%s  Working directory: %s\n", indent,
+		GetAbsoluteWorkingDirectory(context.DotRegolithPath))
+}
+
+// dryRunIndent returns the indentation of the output of the dry run for the
+// filters of the context.
+func (c *RunContext) dryRunIndent() string {
+	return strings.Repeat("  ", c.dryRunDepth)
+}
+
+// describeFilterRunner returns the name of the runtime of the filter (the
+// value of the "runWith" property of its definition, or "remote" and
+// "profile" for the remote filters and the nested profiles) and the basic
+// Filter object embedded in the filter runner.
+func describeFilterRunner(filter FilterRunner) (string, *Filter) {
+	switch f := filter.(type) {
+	case *PythonFilter:
+		return "python", &f.Filter
+	case *NodeJSFilter:
+		return "nodejs", &f.Filter
+	case *DenoFilter:
+		return "deno", &f.Filter
+	case *JavaFilter:
+		return "java", &f.Filter
+	case *DotNetFilter:
+		return "dotnet", &f.Filter
+	case *NimFilter:
+		return "nim", &f.Filter
+	case *ShellFilter:
+		return "shell", &f.Filter
+	case *ExeFilter:
+		return "exe", &f.Filter
+	case *DockerFilter:
+		return "docker", &f.Filter
+	case *RemoteFilter:
+		return "remote", &f.Filter
+	case *ProfileFilter:
+		return "profile", &f.Filter
+	}
+	return "unknown", nil
+}
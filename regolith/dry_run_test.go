@@ -0,0 +1,74 @@
+package regolith
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDryRunProfile checks the output of the dry run of a profile: the
+// commands of the filters that implement FilterDryRunner, the generic
+// description of the other filters, the skipped filters and the indentation
+// of the nested profiles.
+func TestDryRunProfile(t *testing.T) {
+	InitLogging(false)
+	dotRegolithPath := t.TempDir()
+	workingDir := GetAbsoluteWorkingDirectory(dotRegolithPath)
+	location := filepath.Join("filters", "location")
+	config := &Config{RegolithProject: RegolithProject{
+		Profiles: map[string]Profile{
+			"default": {FilterCollection: FilterCollection{
+				Filters: []FilterRunner{
+					&ShellFilter{
+						Filter: Filter{
+							Id:        "command",
+							Arguments: []string{"-a", "b"},
+							Settings:  map[string]interface{}{"key": 1},
+						},
+						Definition: ShellFilterDefinition{Command: "echo"},
+					},
+					&ExeFilter{
+						Filter:     Filter{Id: "exe"},
+						Definition: ExeFilterDefinition{Exe: "tool.exe"},
+					},
+					&PythonFilter{Filter: Filter{Id: "disabled", Disabled: true}},
+					&ProfileFilter{
+						Filter: Filter{Id: "nested"}, Profile: "nested"},
+				}}},
+			"nested": {FilterCollection: FilterCollection{
+				Filters: []FilterRunner{
+					&PythonFilter{Filter: Filter{
+						Id: "python", Arguments: []string{"-x"}}},
+				}}},
+		},
+	}}
+	expected := []string{
+		"Filter: command (shell)",
+		`  Settings: {"key":1}`,
+		"  Arguments: -a b",
+		`  Command: echo {"key":1} -a b`,
+		"  Working directory: " + workingDir,
+		"Filter: exe (exe)",
+		"  Command: " + filepath.Join(location, "tool.exe"),
+		"  Working directory: " + workingDir,
+		"Filter: disabled (python) [disabled]",
+		"Profile: nested",
+		"  Filter: python (python)",
+		"    Arguments: -x",
+		"    Working directory: " + workingDir,
+	}
+	output := captureStdout(t, func() error {
+		return dryRunProfileImpl(RunContext{
+			Profile:          "default",
+			AbsoluteLocation: location,
+			Config:           config,
+			DotRegolithPath:  dotRegolithPath,
+		})
+	})
+	actual := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf(
+			"Unexpected output of the dry run.\nExpected:\n%s\nActual:\n%s",
+			strings.Join(expected, "\n"), output)
+	}
+}
@@ -14,6 +14,9 @@ const (
 	// Error message for os.Stat failore
 	osStatErrorAny = "Failed to access file info.\nPath: %s"
 
+	// Error message for os.ReadDir failure
+	osReadDirError = "Failed to list the files of the directory.\nPath: %s"
+
 	// Error message for file or directory that doesn't exist
 	osStatErrorIsNotExist = "Path doesn't exist.\nPath: %s"
 
@@ -122,6 +125,27 @@ const (
 	gitNotInstalledWarning = "Git is not installed. Git is required to download " +
 		"filters.\n You can download Git from https://git-scm.com/downloads"
 
+	// Error used when Deno is not installed
+	denoNotInstalledError = "Deno is not installed. Deno is required to run " +
+		"Deno filters.\n You can download Deno from https://deno.land/"
+
+	// Error used when a filter URL doesn't match any of the prefixes from the
+	// "allowed_filter_sources" user config property
+	filterSourceNotAllowedError = "The filter source is not allowed by the " +
+		"\"allowed_filter_sources\" user configuration property.\n" +
+		"Filter: %s\n" +
+		"URL: %s\n" +
+		"Allowed sources:\n\t%s"
+
+	// Error used when a filter URL checked against the
+	// "allowed_filter_sources" user config property has "." or ".." path
+	// segments, which could be used for bypassing the allowed prefixes
+	filterSourceDotSegmentsError = "The filter source can't be checked with " +
+		"the \"allowed_filter_sources\" user configuration property, " +
+		"because its path contains \".\" or \"..\" segments.\n" +
+		"Filter: %s\n" +
+		"URL: %s"
+
 	// Error used when filterFromObject function fails
 	filterFromObjectError = "Failed to parse filter from JSON object."
 
@@ -131,6 +155,31 @@ const (
 	// Error used when exec.Command fails.
 	execCommandError = "Failed to execute command.\nCommand: %s"
 
+	// Error used when a network operation is forbidden by the "--offline"
+	// flag
+	offlineModeError = "Failed to %s because Regolith runs in the offline " +
+		"mode, which forbids accessing the network.\n" +
+		"Install the required filters without the \"--offline\" flag first."
+
+	// Error used when an experimental feature is used without the
+	// "--experimental" flag
+	experimentalFeatureError = "%s is an experimental feature.\n" +
+		"Use the \"--experimental\" flag to enable it."
+
+	// Error used when a network operation fails after all of the retries
+	networkOperationRetryError = "Failed to %s after %d attempts."
+
+	// Error used when Git fails to authenticate with the SSH server of a
+	// repository
+	sshAuthError = "Failed to %s because Git couldn't authenticate with " +
+		"the SSH server.\nMake sure that:\n" +
+		"\t- your SSH key is added to the SSH agent (\"ssh-add -l\" lists " +
+		"the loaded keys),\n" +
+		"\t- the key has access to the repository (for example as a deploy " +
+		"key),\n" +
+		"\t- the host is in your known_hosts file (connect to it once with " +
+		"\"ssh -T git@<host>\" to add it)."
+
 	// Error used when FilterRunner.Check method fails
 	filterRunnerCheckError = "Filter check failed.\nFilter: %s"
 
@@ -0,0 +1,58 @@
+// Functions used by the "exec" export target, which delegates the export to
+// an external command.
+package regolith
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// execExportTarget is the name of the export target that runs a command
+// instead of copying the packs.
+const execExportTarget = "exec"
+
+// runExecExport runs the command of the "exec" export target in the root of
+// the project (projectDir). The absolute paths to the behavior pack and the resource pack
+// in the tmp directory are passed to the command as the first and the second
+// argument, and as the REGOLITH_BP and REGOLITH_RP environment variables.
+func runExecExport(
+	exportTarget ExportTarget, dotRegolithPath, projectDir string,
+) error {
+	bpPath, err := filepath.Abs(filepath.Join(getTmpPath(dotRegolithPath), "BP"))
+	if err != nil {
+		return burrito.WrapErrorf(err, filepathAbsError, bpPath)
+	}
+	rpPath, err := filepath.Abs(filepath.Join(getTmpPath(dotRegolithPath), "RP"))
+	if err != nil {
+		return burrito.WrapErrorf(err, filepathAbsError, rpPath)
+	}
+	shell, arg, err := findShell()
+	if err != nil {
+		return burrito.WrapError(err, "Unable to find a valid shell.")
+	}
+	command := strings.Join([]string{
+		exportTarget.Command, quoteCommandArg(bpPath), quoteCommandArg(rpPath),
+	}, " ")
+	Logger.Infof("Exporting the packs with command: %s", exportTarget.Command)
+	err = runSubProcessWithEnv(
+		nil, shell, []string{arg, command}, projectDir, projectDir, "export",
+		[]string{
+			fmt.Sprintf("REGOLITH_BP=%s", bpPath),
+			fmt.Sprintf("REGOLITH_RP=%s", rpPath),
+		})
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, "The export command failed.\nCommand: %s",
+			exportTarget.Command)
+	}
+	return nil
+}
+
+// quoteCommandArg puts the argument of a shell command in double quotes,
+// which works both with the Unix shells and with cmd.exe.
+func quoteCommandArg(arg string) string {
+	return "\"" + strings.ReplaceAll(arg, "\"", "\\\"") + "\""
+}
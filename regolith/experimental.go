@@ -0,0 +1,16 @@
+package regolith
+
+import "github.com/Bedrock-OSS/go-burrito/burrito"
+
+// Experimental enables the features that are not stable yet. It's set by
+// the global "--experimental" flag.
+var Experimental bool
+
+// checkExperimental returns an error if the experimental features are not
+// enabled. The feature is the name of the feature used in the error message.
+func checkExperimental(feature string) error {
+	if !Experimental {
+		return burrito.WrappedErrorf(experimentalFeatureError, feature)
+	}
+	return nil
+}
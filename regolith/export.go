@@ -1,10 +1,21 @@
 package regolith
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Bedrock-OSS/go-burrito/burrito"
+	"github.com/otiai10/copy"
+)
+
+// The values of the "build" property of the "development" export target.
+const (
+	releaseBuild = "release"
+	previewBuild = "preview"
 )
 
 // GetExportPaths returns file paths for exporting behavior pack and
@@ -13,7 +24,20 @@ import (
 func GetExportPaths(
 	exportTarget ExportTarget, name string,
 ) (bpPath string, rpPath string, err error) {
-	if exportTarget.Target == "development" {
+	// "target": "development" with "build": "preview" is an alternative
+	// spelling of the "preview" export target
+	isPreview := exportTarget.Target == "preview" ||
+		(exportTarget.Target == "development" &&
+			exportTarget.Build == previewBuild)
+	// The "live" export target uses the development folders, and reloads
+	// the packs in Minecraft after exporting them
+	isLive := exportTarget.Target == liveExportTarget
+	if isLive {
+		if err := checkExperimental("The \"live\" export target"); err != nil {
+			return "", "", burrito.PassError(err)
+		}
+	}
+	if (exportTarget.Target == "development" || isLive) && !isPreview {
 		comMojang, err := FindMojangDir()
 		if err != nil {
 			return "", "", burrito.WrapError(
@@ -24,11 +48,13 @@ func GetExportPaths(
 		// I for example always name my packs "0".
 		bpPath = comMojang + "/development_behavior_packs/" + name + "_bp"
 		rpPath = comMojang + "/development_resource_packs/" + name + "_rp"
-	} else if exportTarget.Target == "preview" {
+	} else if isPreview {
 		comMojang, err := FindPreviewDir()
 		if err != nil {
 			return "", "", burrito.WrapError(
-				err, "Failed to find preview \"com.mojang\" directory.")
+				err, "Failed to find the \"com.mojang\" directory of "+
+					"Minecraft Preview. Make sure that Minecraft Preview is "+
+					"installed and was started at least once.")
 		}
 
 		// TODO - I don't like the _rp and _bp sufixes. Can we get rid of that?
@@ -75,6 +101,23 @@ func GetExportPaths(
 	} else if exportTarget.Target == "local" {
 		bpPath = "build/BP/"
 		rpPath = "build/RP/"
+	} else if exportTarget.Target == execExportTarget {
+		// The command receives the paths to the packs in the tmp directory
+		bpPath, rpPath = "", ""
+	} else if isWorldArchiveExportTarget(exportTarget.Target) {
+		// Both packs are exported into the same archive
+		bpPath, err = worldArchivePath(exportTarget, name)
+		rpPath = bpPath
+	} else if isArchiveExportTarget(exportTarget.Target) {
+		extension := archiveExtension(exportTarget)
+		bpPath = exportTarget.BpPath
+		if bpPath == "" {
+			bpPath = filepath.Join("build", name+"_bp"+extension)
+		}
+		rpPath = exportTarget.RpPath
+		if rpPath == "" {
+			rpPath = filepath.Join("build", name+"_rp"+extension)
+		}
 	} else {
 		err = burrito.WrappedErrorf(
 			"Export target %q is not valid", exportTarget.Target)
@@ -82,47 +125,454 @@ func GetExportPaths(
 	return
 }
 
+// overrideExportTarget returns a copy of the profile with its export targets
+// replaced by the export target from the "--export-target" flag of
+// "regolith run". The override uses the "key=value" format. The only
+// supported key is "path" - the path to the directory where the packs are
+// exported (to the "BP" and "RP" subdirectories, like with the "local"
+// export target). The directory is created if it doesn't exist, and it
+// must be writable. The relative path is relative to the projectRoot.
+func overrideExportTarget(
+	profile Profile, override, projectRoot string,
+) (Profile, error) {
+	key, value, ok := strings.Cut(override, "=")
+	if !ok || key != "path" || value == "" {
+		return profile, burrito.WrappedErrorf(
+			"Invalid export target override. The override must use the "+
+				"\"path=<path>\" format.\nOverride: %s", override)
+	}
+	value = projectPath(projectRoot, value)
+	err := checkWritableDir(value)
+	if err != nil {
+		return profile, burrito.WrapError(
+			err, "The path of the export target is not writable.")
+	}
+	profile.ExportTarget = ExportTarget{
+		Target:   "exact",
+		BpPath:   filepath.Join(value, "BP"),
+		RpPath:   filepath.Join(value, "RP"),
+		ReadOnly: profile.ExportTarget.ReadOnly,
+	}
+	profile.ExportTargets = nil
+	return profile, nil
+}
+
+// checkWritableDir creates the directory if it doesn't exist and checks if
+// files can be created in it.
+func checkWritableDir(path string) error {
+	err := os.MkdirAll(path, 0755)
+	if err != nil {
+		return burrito.WrapErrorf(err, osMkdirError, path)
+	}
+	file, err := ioutil.TempFile(path, ".regolith-write-test-*")
+	if err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, path)
+	}
+	file.Close()
+	err = os.Remove(file.Name())
+	if err != nil {
+		return burrito.WrapErrorf(err, osRemoveError, file.Name())
+	}
+	return nil
+}
+
+// maxParallelExports is the maximal number of the export targets that are
+// exported at the same time.
+const maxParallelExports = 4
+
+// packExport stores the export target with its behavior pack and resource
+// pack paths, and the name of the project.
+type packExport struct {
+	target ExportTarget
+	bpPath string
+	rpPath string
+	name   string
+
+	// projectDir is the absolute path to the root of the project, in which
+	// the command of the "exec" export target runs
+	projectDir string
+
+	// skipBp and skipRp are set for the packs that didn't change since
+	// their previous export in the watch session
+	skipBp bool
+	skipRp bool
+
+	// retryFileLocks enables retrying the export of a pack that fails
+	// because of a file held by another process
+	retryFileLocks bool
+}
+
+// isArchive returns true if the export target writes the packs into archive
+// files.
+func (e packExport) isArchive() bool {
+	return isArchiveExportTarget(e.target.Target) || e.isWorldArchive()
+}
+
+// isWorldArchive returns true if the export target bundles the packs into
+// a world archive.
+func (e packExport) isWorldArchive() bool {
+	return isWorldArchiveExportTarget(e.target.Target)
+}
+
+// isLive returns true if the packs are reloaded in Minecraft after the
+// export.
+func (e packExport) isLive() bool {
+	return e.target.Target == liveExportTarget
+}
+
+// isExec returns true if the export is delegated to an external command.
+func (e packExport) isExec() bool {
+	return e.target.Target == execExportTarget
+}
+
+// overlaps returns true if the exports write to the same location, which
+// means that one of the paths of the first export is equal to or contains
+// one of the paths of the second export (or the other way around).
+func (e packExport) overlaps(other packExport) bool {
+	// The exec exports don't write to any location known to Regolith
+	if e.isExec() || other.isExec() {
+		return false
+	}
+	for _, path := range []string{e.bpPath, e.rpPath} {
+		for _, otherPath := range []string{other.bpPath, other.rpPath} {
+			if isSameOrNestedPath(path, otherPath) ||
+				isSameOrNestedPath(otherPath, path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSameOrNestedPath returns true if the path is equal to the parent path or
+// if it's inside of it.
+func isSameOrNestedPath(path, parent string) bool {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	parent, err = filepath.Abs(parent)
+	if err != nil {
+		return false
+	}
+	if path == parent {
+		return true
+	}
+	return strings.HasPrefix(path, parent+string(filepath.Separator))
+}
+
+// groupPackExports splits the exports into groups that can be exported in
+// parallel. The exports that write to the same location are always in the
+// same group, so that they can be exported one after another. The order of
+// the exports in the groups is the same as in the input list.
+func groupPackExports(exports []packExport) [][]packExport {
+	groupIds := make([]int, len(exports))
+	for i := range exports {
+		groupIds[i] = i
+		for j := 0; j < i; j++ {
+			if groupIds[i] == groupIds[j] || !exports[i].overlaps(exports[j]) {
+				continue
+			}
+			// Merge the group of the current export into the group of the
+			// export it overlaps with
+			oldGroupId := groupIds[i]
+			for k := 0; k <= i; k++ {
+				if groupIds[k] == oldGroupId {
+					groupIds[k] = groupIds[j]
+				}
+			}
+		}
+	}
+	result := make([][]packExport, 0, len(exports))
+	groupIndices := make(map[int]int)
+	for i, export := range exports {
+		index, ok := groupIndices[groupIds[i]]
+		if !ok {
+			index = len(result)
+			groupIndices[groupIds[i]] = index
+			result = append(result, []packExport{})
+		}
+		result[index] = append(result[index], export)
+	}
+	return result
+}
+
+// exportPacks exports the packs from the tmp directory to a single export
+// target. If move is true, the files are moved instead of copied, which is
+// only allowed when there is no other export target that needs them.
+func exportPacks(export packExport, dotRegolithPath string, move bool) error {
+	packs := []struct {
+		name, source, target string
+		skip                 bool
+	}{
+		{"behavior pack", filepath.Join(getTmpPath(dotRegolithPath), "BP"), export.bpPath, export.skipBp},
+		{"resource pack", filepath.Join(getTmpPath(dotRegolithPath), "RP"), export.rpPath, export.skipRp},
+	}
+	if export.isExec() {
+		return runExecExport(export.target, dotRegolithPath, export.projectDir)
+	}
+	if export.isWorldArchive() {
+		Logger.Infof(
+			"Exporting the packs to \"%s\".", filepath.Clean(export.bpPath))
+		err := exportWorldArchive(
+			export.target, export.name, export.bpPath, dotRegolithPath)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to export the world.\nTarget: %s",
+				export.target.Target)
+		}
+		return nil
+	}
+	for _, pack := range packs {
+		if pack.skip {
+			Logger.Infof(
+				"The %s didn't change, skipping the export to \"%s\".",
+				pack.name, filepath.Clean(pack.target))
+			continue
+		}
+		Logger.Infof("Exporting %s to \"%s\".", pack.name, filepath.Clean(pack.target))
+		// A failed move leaves the source in place and the copies overwrite
+		// the target, so the export of the pack can be retried
+		err := retryFileOperation(
+			export.retryFileLocks, "export the "+pack.name, func() error {
+				if export.isArchive() {
+					return exportArchive(pack.source, pack.target, export.target)
+				} else if move {
+					return MoveOrCopy(
+						pack.source, pack.target, export.target.ReadOnly, true)
+				}
+				err := copy.Copy(
+					pack.source, pack.target,
+					copy.Options{PreserveTimes: false, Sync: false})
+				if err != nil {
+					return burrito.WrapErrorf(
+						err, osCopyError, pack.source, pack.target)
+				}
+				if export.target.ReadOnly {
+					makeFilesReadOnly(pack.target)
+				}
+				return nil
+			})
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to export %s.\nTarget: %s",
+				pack.name, export.target.Target)
+		}
+	}
+	return nil
+}
+
+// exportPacksParallel exports the packs to all of the export targets. The
+// targets are exported in parallel using at most maxParallelExports workers,
+// except for the targets that write to the same location, which are exported
+// one after another. The errors of all of the targets are combined into one.
+// If keepTmp is true, the packs are always copied.
+func exportPacksParallel(
+	exports []packExport, dotRegolithPath string, keepTmp bool,
+) error {
+	// Moving the files is only possible if there is only one target
+	move := len(exports) == 1 && !keepTmp
+	groups := groupPackExports(exports)
+	errs := make([]error, len(groups))
+	workers := make(chan struct{}, maxParallelExports)
+	var wg sync.WaitGroup
+	for i, group := range groups {
+		wg.Add(1)
+		go func(i int, group []packExport) {
+			defer wg.Done()
+			workers <- struct{}{}
+			defer func() { <-workers }()
+			for _, export := range group {
+				err := exportPacks(export, dotRegolithPath, move)
+				if err != nil {
+					errs[i] = burrito.PassError(err)
+					return
+				}
+			}
+		}(i, group)
+	}
+	wg.Wait()
+	var result error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if result == nil {
+			result = err
+		} else {
+			result = burrito.PassErrorHandlerError(result, err, errorConnector)
+		}
+	}
+	return result
+}
+
 // ExportProject copies files from the tmp paths (tmp/BP and tmp/RP) into
-// the project's export target. The paths are generated with GetExportPaths.
+// the project's export targets. The paths are generated with GetExportPaths,
+// after expanding their placeholders ({{.Name}}, {{.Date}} and
+// {{.Profile}}). If the profile has multiple export targets, they're
+// exported in parallel.
 func ExportProject(
-	profile Profile, name, dataPath, dotRegolithPath string,
+	profile Profile, profileName, name, dataPath, dotRegolithPath string,
 ) error {
-	// Get the expor target paths
-	exportTarget := profile.ExportTarget
-	bpPath, rpPath, err := GetExportPaths(exportTarget, name)
+	return exportProject(
+		profile, profileName, name, dataPath, dotRegolithPath, ".", false,
+		false, nil)
+}
+
+// exportProject is the implementation of ExportProject. The keepTmp argument
+// disables moving the packs and the data of the filters out of the tmp
+// directory, so they can be exported again or inspected. If exportedPacks is
+// not nil, the packs that didn't change since their previous export are not
+// exported again. The relative export paths are relative to the projectRoot.
+// The retryFileLocks argument enables retrying the removal of the old files
+// and the export of the packs when they fail because of the files held by
+// other processes.
+func exportProject(
+	profile Profile,
+	profileName, name, dataPath, dotRegolithPath, projectRoot string,
+	keepTmp, retryFileLocks bool, exportedPacks *exportedPacks,
+) error {
+	projectDir, err := filepath.Abs(projectRoot)
 	if err != nil {
-		return burrito.WrapError(
-			err, "Failed to get generate export paths.")
+		return burrito.WrapErrorf(err, filepathAbsError, projectRoot)
 	}
-
-	// Loading edited_files.json or creating empty object
-	editedFiles := LoadEditedFiles(dotRegolithPath)
-	err = editedFiles.CheckDeletionSafety(rpPath, bpPath)
+	regenerateUuids, err := regenerateUuidsEnabled(profile.allExportTargets())
 	if err != nil {
-		return burrito.WrapErrorf(
-			err,
-			"Safety mechanism stopped Regolith to protect unexpected files "+
-				"from your export targets.\n"+
-				"Did you edit the exported files manually?\n"+
-				"Please clear your export paths and try again.\n"+
-				"Resource pack export path: %s\n"+
-				"Behavior pack export path: %s",
-			rpPath, bpPath)
+		return burrito.PassError(err)
 	}
-
-	// Clearing output locations
-	// Spooky, I hope file protection works, and it won't do any damage
-	err = os.RemoveAll(bpPath)
+	linkPacks, err := sharedExportOption(
+		profile.allExportTargets(), "linkPacks",
+		func(target ExportTarget) bool { return target.LinkPacks })
 	if err != nil {
-		return burrito.WrapErrorf(
-			err, "Failed to clear behavior pack from build path %q.\n"+
-				"Are user permissions correct?", bpPath)
+		return burrito.PassError(err)
 	}
-	err = os.RemoveAll(rpPath)
+	optimization, err := exportOptimizationOf(profile.allExportTargets())
 	if err != nil {
-		return burrito.WrapErrorf(
-			err, "Failed to clear resource pack from build path %q.\n"+
-				"Are user permissions correct?", rpPath)
+		return burrito.PassError(err)
+	}
+	// Get the expor target paths
+	var exports []packExport
+	templateData := exportPathTemplateData{
+		Name: name, Date: exportDate(time.Now()), Profile: profileName}
+	for _, exportTarget := range profile.allExportTargets() {
+		exportTarget, err := expandExportPaths(exportTarget, templateData)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+		bpPath, rpPath, err := GetExportPaths(exportTarget, name)
+		if err != nil {
+			return burrito.WrapError(
+				err, "Failed to get generate export paths.")
+		}
+		bpPath, rpPath, err = applyExportLayout(exportTarget, bpPath, rpPath)
+		if err != nil {
+			return burrito.WrapError(
+				err, "Failed to apply the layout of the export target.")
+		}
+		if bpPath != "" {
+			bpPath = projectPath(projectRoot, bpPath)
+		}
+		if rpPath != "" {
+			rpPath = projectPath(projectRoot, rpPath)
+		}
+		exports = append(exports, packExport{
+			target: exportTarget, bpPath: bpPath, rpPath: rpPath, name: name,
+			projectDir: projectDir, retryFileLocks: retryFileLocks})
+	}
+	// Link the manifests of the packs in tmp before regenerating their
+	// UUIDs, so the dependencies use the regenerated UUIDs too
+	if linkPacks {
+		err = LinkPackManifests(dotRegolithPath)
+		if err != nil {
+			return burrito.WrapError(
+				err, "Failed to link the manifests of the packs.")
+		}
+	}
+	// Regenerate the UUIDs of the packs in tmp, the source files are never
+	// modified
+	if regenerateUuids {
+		err = RegenerateManifestUuids(dotRegolithPath)
+		if err != nil {
+			return burrito.WrapError(
+				err, "Failed to regenerate the UUIDs of the manifests.")
+		}
+	}
+	// Optimize the files of the packs in tmp, the source files are never
+	// modified
+	err = OptimizePacks(optimization, dotRegolithPath)
+	if err != nil {
+		return burrito.WrapError(err, "Failed to optimize the packs.")
+	}
+	// The packs are compared after linking, regenerating the UUIDs and
+	// optimizing, which modify the files
+	var packHashes map[string]string
+	if exportedPacks != nil {
+		packHashes, err = exportedPacks.markUnchangedPacks(exports, dotRegolithPath)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+	}
+
+	// Loading edited_files.json or creating empty object
+	editedFiles := LoadEditedFiles(dotRegolithPath)
+	for _, export := range exports {
+		// The archive files are overwritten and the exec exports don't use
+		// the export paths, they don't need the file protection
+		if export.isArchive() || export.isExec() {
+			continue
+		}
+		bpPath, rpPath := export.bpPath, export.rpPath
+		err := editedFiles.CheckDeletionSafety(rpPath, bpPath)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err,
+				"Safety mechanism stopped Regolith to protect unexpected files "+
+					"from your export targets.\n"+
+					"Did you edit the exported files manually?\n"+
+					"Please clear your export paths and try again.\n"+
+					"Resource pack export path: %s\n"+
+					"Behavior pack export path: %s",
+				rpPath, bpPath)
+		}
+
+		// The files exported with the "readOnly" option must be writable
+		// again before they're replaced. The unchanged packs are kept.
+		for _, pack := range []struct {
+			path string
+			skip bool
+		}{{bpPath, export.skipBp}, {rpPath, export.skipRp}} {
+			if pack.skip {
+				continue
+			}
+			err = makeFilesWritable(pack.path)
+			if err != nil {
+				return burrito.WrapErrorf(
+					err, "Failed to restore the write access to the files "+
+						"exported as read-only.\nPath: %s", pack.path)
+			}
+		}
+		// Clearing output locations
+		// Spooky, I hope file protection works, and it won't do any damage
+		if !export.skipBp {
+			err = retryFileOperation(
+				retryFileLocks, "clear the behavior pack",
+				func() error { return os.RemoveAll(bpPath) })
+			if err != nil {
+				return burrito.WrapErrorf(
+					err, "Failed to clear behavior pack from build path %q.\n"+
+						"Are user permissions correct?", bpPath)
+			}
+		}
+		if !export.skipRp {
+			err = retryFileOperation(
+				retryFileLocks, "clear the resource pack",
+				func() error { return os.RemoveAll(rpPath) })
+			if err != nil {
+				return burrito.WrapErrorf(
+					err, "Failed to clear resource pack from build path %q.\n"+
+						"Are user permissions correct?", rpPath)
+			}
+		}
 	}
 	// List the names of the filters that opt-in to the data export process
 	exportPaths := make(map[string]struct{})
@@ -154,6 +604,48 @@ func ExportProject(
 				dataPath)
 		}
 	}
+	// The files ignored by the .regolithignore file of the data folder are
+	// not in the tmp directory, they must be copied there before replacing
+	// the data of the filters
+	dataIgnore, err := loadRegolithIgnore(dataPath)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	for _, path := range paths {
+		if _, ok := exportPaths[path.Name()]; !ok {
+			continue
+		}
+		err = copyIgnoredFiles(
+			dataIgnore, filepath.Join(dataPath, path.Name()),
+			filepath.Join(getTmpPath(dotRegolithPath), "data", path.Name()))
+		if err != nil {
+			return burrito.WrapError(
+				err, "Failed to keep the ignored files of the data folder.")
+		}
+	}
+	// The data is moved to the data folder, so the data kept in the tmp
+	// directory must be exported from a copy
+	dataSourcePath := filepath.Join(getTmpPath(dotRegolithPath), "data")
+	if keepTmp && len(exportPaths) > 0 {
+		dataSourcePath = filepath.Join(dotRegolithPath, ".dataExportCopy")
+		if err := os.RemoveAll(dataSourcePath); err != nil {
+			return burrito.WrapErrorf(err, osRemoveError, dataSourcePath)
+		}
+		defer os.RemoveAll(dataSourcePath)
+		for name := range exportPaths {
+			source := filepath.Join(getTmpPath(dotRegolithPath), "data", name)
+			if _, err := os.Stat(source); os.IsNotExist(err) {
+				continue
+			}
+			target := filepath.Join(dataSourcePath, name)
+			err = copy.Copy(
+				source, target,
+				copy.Options{PreserveTimes: false, Sync: false})
+			if err != nil {
+				return burrito.WrapErrorf(err, osCopyError, source, target)
+			}
+		}
+	}
 	// Create revertible operations object
 	backupPath := filepath.Join(dotRegolithPath, ".dataBackup")
 	revertibleOps, err := NewRevertibleFsOperations(backupPath)
@@ -183,7 +675,7 @@ func ExportProject(
 			return mainError
 		}
 		// Copy data
-		sourcePath := filepath.Join(dotRegolithPath, "tmp/data", path.Name())
+		sourcePath := filepath.Join(dataSourcePath, path.Name())
 		err = revertibleOps.MoveOrCopyDir(sourcePath, targetPath)
 		if err != nil {
 			handlerError := revertibleOps.Undo()
@@ -199,24 +691,31 @@ func ExportProject(
 			return mainError
 		}
 	}
-	// Export BP
-	Logger.Infof("Exporting behavior pack to \"%s\".", bpPath)
-	err = MoveOrCopy(filepath.Join(dotRegolithPath, "tmp/BP"), bpPath, exportTarget.ReadOnly, true)
-	if err != nil {
-		return burrito.WrapError(err, "Failed to export behavior pack.")
+	// Export packs
+	err = exportPacksParallel(exports, dotRegolithPath, keepTmp)
+	if exportedPacks != nil {
+		exportedPacks.recordExports(exports, packHashes, err != nil)
 	}
-	// Export RP
-	Logger.Infof("Exporting project to \"%s\".", filepath.Clean(rpPath))
-	err = MoveOrCopy(filepath.Join(dotRegolithPath, "tmp/RP"), rpPath, exportTarget.ReadOnly, true)
 	if err != nil {
-		return burrito.WrapError(err, "Failed to export resource pack.")
+		return burrito.PassError(err)
+	}
+	for _, export := range exports {
+		// Reloading Minecraft is not needed if nothing was exported
+		if export.isLive() && !(export.skipBp && export.skipRp) {
+			reloadLiveExport(export.target)
+		}
 	}
 	// Update or create edited_files.json
-	err = editedFiles.UpdateFromPaths(rpPath, bpPath)
-	if err != nil {
-		return burrito.WrapError(
-			err,
-			"Failed to create a list of files edited by this 'regolith run'")
+	for _, export := range exports {
+		if export.isArchive() || export.isExec() {
+			continue
+		}
+		err = editedFiles.UpdateFromPaths(export.rpPath, export.bpPath)
+		if err != nil {
+			return burrito.WrapError(
+				err,
+				"Failed to create a list of files edited by this 'regolith run'")
+		}
 	}
 	err = editedFiles.Dump(dotRegolithPath)
 	if err != nil {
@@ -236,6 +735,28 @@ func ExportProject(
 func InplaceExportProject(
 	config *Config, dotRegolithPath string,
 ) error {
+	// Keep the files ignored by the .regolithignore files, which are not in
+	// the tmp directory
+	sourceDirs := [][2]string{
+		{config.ResourceFolder, filepath.Join(getTmpPath(dotRegolithPath), "RP")},
+		{config.BehaviorFolder, filepath.Join(getTmpPath(dotRegolithPath), "BP")},
+		{config.DataPath, filepath.Join(getTmpPath(dotRegolithPath), "data")},
+	}
+	for _, sourceDir := range sourceDirs {
+		source, tmp := sourceDir[0], sourceDir[1]
+		if source == "" {
+			continue
+		}
+		ignore, err := loadRegolithIgnore(source)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+		err = copyIgnoredFiles(ignore, source, tmp)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to keep the ignored files.\nPath: %s", source)
+		}
+	}
 	// Create revertible ops object
 	backupPath := filepath.Join(dotRegolithPath, ".dataBackup")
 	revertibleOps, err := NewRevertibleFsOperations(backupPath)
@@ -280,9 +801,9 @@ func InplaceExportProject(
 	}
 	// Move files from tmp to RP, BP and data
 	moveFiles := [][2]string{
-		{filepath.Join(dotRegolithPath, "tmp/RP"), config.ResourceFolder},
-		{filepath.Join(dotRegolithPath, "tmp/BP"), config.BehaviorFolder},
-		{filepath.Join(dotRegolithPath, "tmp/data"), config.DataPath},
+		{filepath.Join(getTmpPath(dotRegolithPath), "RP"), config.ResourceFolder},
+		{filepath.Join(getTmpPath(dotRegolithPath), "BP"), config.BehaviorFolder},
+		{filepath.Join(getTmpPath(dotRegolithPath), "data"), config.DataPath},
 	}
 	for _, moveFile := range moveFiles {
 		source, target := moveFile[0], moveFile[1]
@@ -0,0 +1,130 @@
+// Functions used by "regolith watch" for exporting only the packs that
+// changed since the previous export of the watch session.
+package regolith
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// exportedPacks remembers the hashes of the packs exported during the watch
+// session. The packs with the same hash as in the previous export to the
+// same location are not exported again.
+type exportedPacks struct {
+	mutex sync.Mutex
+	// hashes maps the absolute export paths of the packs to the hashes of
+	// the exported packs
+	hashes map[string]string
+}
+
+// newExportedPacks creates an empty exportedPacks.
+func newExportedPacks() *exportedPacks {
+	return &exportedPacks{hashes: make(map[string]string)}
+}
+
+// hashTmpPacks returns the hashes of the content of the packs in the tmp
+// directory, mapped by the names of the packs ("BP" and "RP").
+func hashTmpPacks(dotRegolithPath string) (map[string]string, error) {
+	manifest, err := createExportManifest(dotRegolithPath)
+	if err != nil {
+		return nil, burrito.WrapError(err, "Failed to hash the packs.")
+	}
+	result := make(map[string]string, len(manifest.Packs))
+	for pack, files := range manifest.Packs {
+		paths := make([]string, 0, len(files))
+		for path := range files {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		hash := sha256.New()
+		for _, path := range paths {
+			hash.Write([]byte(path))
+			hash.Write([]byte{0})
+			hash.Write([]byte(files[path]))
+			hash.Write([]byte{0})
+		}
+		result[pack] = hex.EncodeToString(hash.Sum(nil))
+	}
+	return result, nil
+}
+
+// isUnchanged returns true if the pack with the hash was already exported to
+// the path and the exported pack still exists.
+func (e *exportedPacks) isUnchanged(path, hash string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	e.mutex.Lock()
+	exportedHash, ok := e.hashes[absPath]
+	e.mutex.Unlock()
+	if !ok || exportedHash != hash {
+		return false
+	}
+	_, err = os.Stat(absPath)
+	return err == nil
+}
+
+// set records the hash of the pack exported to the path. The empty hash
+// removes the record, so the next export can't be skipped.
+func (e *exportedPacks) set(path, hash string) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if hash == "" {
+		delete(e.hashes, absPath)
+	} else {
+		e.hashes[absPath] = hash
+	}
+}
+
+// markUnchangedPacks sets the skipBp and skipRp flags of the exports of the
+// packs that didn't change since their previous export. Only the exports to
+// the directories can be skipped. It returns the hashes of the packs, which
+// should be recorded with recordExports after the export.
+func (e *exportedPacks) markUnchangedPacks(
+	exports []packExport, dotRegolithPath string,
+) (map[string]string, error) {
+	hashes, err := hashTmpPacks(dotRegolithPath)
+	if err != nil {
+		return nil, burrito.PassError(err)
+	}
+	for i := range exports {
+		export := &exports[i]
+		if export.isArchive() || export.isExec() {
+			continue
+		}
+		export.skipBp = e.isUnchanged(export.bpPath, hashes["BP"])
+		export.skipRp = e.isUnchanged(export.rpPath, hashes["RP"])
+	}
+	return hashes, nil
+}
+
+// recordExports records the hashes of the packs exported to the directories.
+// If the export failed, the records are removed, so the packs are exported
+// again by the next run.
+func (e *exportedPacks) recordExports(
+	exports []packExport, hashes map[string]string, failed bool,
+) {
+	for _, export := range exports {
+		if export.isArchive() || export.isExec() {
+			continue
+		}
+		if failed {
+			e.set(export.bpPath, "")
+			e.set(export.rpPath, "")
+		} else {
+			e.set(export.bpPath, hashes["BP"])
+			e.set(export.rpPath, hashes["RP"])
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package regolith
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestTmpPacks writes the files to the packs in the tmp directory. The
+// paths of the files start with the name of the pack ("BP" or "RP").
+func writeTestTmpPacks(
+	t *testing.T, dotRegolithPath string, files map[string]string,
+) {
+	for name, content := range files {
+		path := filepath.Join(getTmpPath(dotRegolithPath), filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal("Failed to create the directory of the pack:", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal("Failed to write the file of the pack:", err)
+		}
+	}
+}
+
+// TestHashTmpPacks checks whether the hash of a pack changes only when the
+// files of that pack change.
+func TestHashTmpPacks(t *testing.T) {
+	dotRegolithPath := t.TempDir()
+	writeTestTmpPacks(t, dotRegolithPath, map[string]string{
+		"BP/entities/entity.json": "{}",
+		"RP/textures/item.png":    "png",
+	})
+	first, err := hashTmpPacks(dotRegolithPath)
+	if err != nil {
+		t.Fatal("Failed to hash the packs:", err)
+	}
+	writeTestTmpPacks(t, dotRegolithPath, map[string]string{
+		"BP/entities/entity.json": `{"changed": true}`,
+	})
+	second, err := hashTmpPacks(dotRegolithPath)
+	if err != nil {
+		t.Fatal("Failed to hash the packs:", err)
+	}
+	if first["BP"] == second["BP"] {
+		t.Error("The hash of the changed behavior pack didn't change")
+	}
+	if first["RP"] != second["RP"] {
+		t.Error("The hash of the unchanged resource pack changed")
+	}
+	// Renaming a file changes the hash even if the content is the same
+	os.Rename(
+		filepath.Join(getTmpPath(dotRegolithPath), "RP", "textures", "item.png"),
+		filepath.Join(getTmpPath(dotRegolithPath), "RP", "textures", "block.png"))
+	third, err := hashTmpPacks(dotRegolithPath)
+	if err != nil {
+		t.Fatal("Failed to hash the packs:", err)
+	}
+	if second["RP"] == third["RP"] {
+		t.Error("The hash of the resource pack with a renamed file didn't change")
+	}
+}
+
+// TestExportedPacks checks which packs are skipped by the next export of
+// the watch session.
+func TestExportedPacks(t *testing.T) {
+	dotRegolithPath := t.TempDir()
+	buildPath := t.TempDir()
+	writeTestTmpPacks(t, dotRegolithPath, map[string]string{
+		"BP/entities/entity.json": "{}",
+		"RP/textures/item.png":    "png",
+	})
+	newExports := func() []packExport {
+		return []packExport{
+			{
+				target: ExportTarget{Target: "local"},
+				bpPath: filepath.Join(buildPath, "BP"),
+				rpPath: filepath.Join(buildPath, "RP"),
+			},
+			{
+				target: ExportTarget{Target: "zip"},
+				bpPath: filepath.Join(buildPath, "project.zip"),
+				rpPath: filepath.Join(buildPath, "project.zip"),
+			},
+		}
+	}
+	for _, pack := range []string{"BP", "RP", "project.zip"} {
+		if err := os.WriteFile(filepath.Join(buildPath, pack), nil, 0644); err != nil {
+			t.Fatal("Failed to create the exported pack:", err)
+		}
+	}
+	packs := newExportedPacks()
+	check := func(step string, skipBp, skipRp bool) map[string]string {
+		exports := newExports()
+		hashes, err := packs.markUnchangedPacks(exports, dotRegolithPath)
+		if err != nil {
+			t.Fatalf("%s: failed to compare the packs: %v", step, err)
+		}
+		if exports[0].skipBp != skipBp || exports[0].skipRp != skipRp {
+			t.Errorf(
+				"%s: unexpected skipped packs: BP %t, RP %t, expected BP %t, "+
+					"RP %t", step, exports[0].skipBp, exports[0].skipRp,
+				skipBp, skipRp)
+		}
+		if exports[1].skipBp || exports[1].skipRp {
+			t.Errorf("%s: the export to the archive was skipped", step)
+		}
+		return hashes
+	}
+	hashes := check("first export", false, false)
+	packs.recordExports(newExports(), hashes, false)
+	hashes = check("unchanged packs", true, true)
+	writeTestTmpPacks(t, dotRegolithPath, map[string]string{
+		"BP/entities/entity.json": `{"changed": true}`,
+	})
+	hashes = check("changed behavior pack", false, true)
+	packs.recordExports(newExports(), hashes, true)
+	hashes = check("failed export", false, false)
+	packs.recordExports(newExports(), hashes, false)
+	os.Remove(filepath.Join(buildPath, "RP"))
+	check("removed resource pack", true, false)
+}
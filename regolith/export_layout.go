@@ -0,0 +1,106 @@
+package regolith
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// exportLayoutTargets are the export targets that support the "layout"
+// property. They export the packs into directories with a common root. The
+// other export targets either set the paths of the packs explicitly ("exact")
+// or don't export them into directories.
+var exportLayoutTargets = []string{
+	"local", "development", "preview", "world", liveExportTarget,
+}
+
+// ExportLayoutFromObject creates the layout of the export target from
+// map[string]interface{}. The layout maps the names of the packs ("BP" and
+// "RP") to their paths relative to the root of the export target.
+func ExportLayoutFromObject(
+	obj map[string]interface{},
+) (map[string]string, error) {
+	result := make(map[string]string, len(obj))
+	for pack, pathObj := range obj {
+		if pack != "BP" && pack != "RP" {
+			return nil, burrito.WrappedErrorf(
+				"Invalid pack name in the layout of the export target.\n"+
+					"Pack: %s\nValid values are: BP, RP", pack)
+		}
+		path, ok := pathObj.(string)
+		if !ok {
+			return nil, burrito.WrappedErrorf(
+				jsonPropertyTypeError, pack, "string")
+		}
+		// The packs can't replace the root or escape it
+		cleanPath := filepath.Clean(filepath.FromSlash(path))
+		if path == "" || filepath.IsAbs(cleanPath) || cleanPath == "." ||
+			cleanPath == ".." ||
+			strings.HasPrefix(cleanPath, ".."+string(filepath.Separator)) {
+			return nil, burrito.WrappedErrorf(
+				"The path of the pack in the layout of the export target "+
+					"must be a relative path inside of the root of the "+
+					"export target.\nPack: %s\nPath: %s", pack, path)
+		}
+		result[pack] = cleanPath
+	}
+	return result, nil
+}
+
+// exportRoot returns the root directory of the export target, which is the
+// closest directory that contains both of the default paths of the packs.
+// For example, it's "build" for the "local" export target and the
+// "com.mojang" directory for the "development" export target.
+func exportRoot(bpPath, rpPath string) string {
+	dir := filepath.Dir(filepath.Clean(bpPath))
+	for !isSameOrNestedPath(rpPath, dir) {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return dir
+}
+
+// applyExportLayout returns the paths of the packs of the export target
+// after applying its "layout" property. The packs without a path in the
+// layout keep their default paths. The missing directories are created
+// during the export. It returns an error if the packs would be exported to
+// the same or nested directories.
+func applyExportLayout(
+	exportTarget ExportTarget, bpPath, rpPath string,
+) (string, string, error) {
+	if len(exportTarget.Layout) == 0 {
+		return bpPath, rpPath, nil
+	}
+	supported := false
+	for _, target := range exportLayoutTargets {
+		if exportTarget.Target == target {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return "", "", burrito.WrappedErrorf(
+			"The %q export target doesn't support the \"layout\" property.\n"+
+				"Supported export targets: %s", exportTarget.Target,
+			strings.Join(exportLayoutTargets, ", "))
+	}
+	root := exportRoot(bpPath, rpPath)
+	if path, ok := exportTarget.Layout["BP"]; ok {
+		bpPath = filepath.Join(root, path)
+	}
+	if path, ok := exportTarget.Layout["RP"]; ok {
+		rpPath = filepath.Join(root, path)
+	}
+	if isSameOrNestedPath(bpPath, rpPath) || isSameOrNestedPath(rpPath, bpPath) {
+		return "", "", burrito.WrappedErrorf(
+			"The layout of the export target exports the packs to "+
+				"conflicting destinations.\n"+
+				"Behavior pack path: %s\nResource pack path: %s",
+			bpPath, rpPath)
+	}
+	return bpPath, rpPath, nil
+}
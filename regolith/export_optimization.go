@@ -0,0 +1,210 @@
+package regolith
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+	"muzzammil.xyz/jsonc"
+)
+
+// optimizedFilesFileName is the name of the file in the tmp directory with
+// the list of the files that the filters already optimized. The paths are
+// relative to the tmp directory and use forward slashes, for example
+// "RP/textures/blocks/dirt.png".
+const optimizedFilesFileName = "optimized_files.json"
+
+// ExportOptimization is the "optimize" property of the export target. It
+// selects the optimizations applied to the packs in the tmp directory before
+// exporting them.
+type ExportOptimization struct {
+	// Textures recompresses the PNG files losslessly.
+	Textures bool `json:"textures,omitempty"`
+
+	// Json minifies the JSON files.
+	Json bool `json:"json,omitempty"`
+}
+
+// ExportOptimizationFromObject creates an "ExportOptimization" object from
+// map[string]interface{}
+func ExportOptimizationFromObject(
+	obj map[string]interface{},
+) (ExportOptimization, error) {
+	result := ExportOptimization{}
+	for property, value := range map[string]*bool{
+		"textures": &result.Textures,
+		"json":     &result.Json,
+	} {
+		if enabled, ok := obj[property]; ok {
+			enabled, ok := enabled.(bool)
+			if !ok {
+				return result, burrito.WrappedErrorf(
+					jsonPropertyTypeError, property, "bool")
+			}
+			*value = enabled
+		}
+	}
+	return result, nil
+}
+
+// exportOptimizationOf returns the optimizations of the export targets. The
+// packs in the tmp directory are shared by all of the export targets, so all
+// of them must use the same optimizations.
+func exportOptimizationOf(targets []ExportTarget) (ExportOptimization, error) {
+	optimizeTextures, err := sharedExportOption(
+		targets, "optimize",
+		func(target ExportTarget) bool {
+			return target.Optimize != nil && target.Optimize.Textures
+		})
+	if err != nil {
+		return ExportOptimization{}, burrito.PassError(err)
+	}
+	optimizeJson, err := sharedExportOption(
+		targets, "optimize",
+		func(target ExportTarget) bool {
+			return target.Optimize != nil && target.Optimize.Json
+		})
+	if err != nil {
+		return ExportOptimization{}, burrito.PassError(err)
+	}
+	return ExportOptimization{
+		Textures: optimizeTextures, Json: optimizeJson}, nil
+}
+
+// loadOptimizedFiles loads the list of the files that the filters marked as
+// already optimized. It returns an empty set if the file doesn't exist.
+func loadOptimizedFiles(dotRegolithPath string) (map[string]struct{}, error) {
+	result := make(map[string]struct{})
+	path := filepath.Join(getTmpPath(dotRegolithPath), optimizedFilesFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, nil
+		}
+		return nil, burrito.WrapErrorf(err, fileReadError, path)
+	}
+	var files []string
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, burrito.WrapErrorf(err, jsonUnmarshalError, path)
+	}
+	for _, file := range files {
+		result[strings.TrimPrefix(filepath.ToSlash(file), "./")] = struct{}{}
+	}
+	return result, nil
+}
+
+// OptimizePacks applies the optimizations to the files of the packs in the
+// tmp directory, the source files are never modified. The files listed in
+// the optimized_files.json file of the tmp directory are skipped. The
+// optimized files replace the original files only if they're smaller.
+func OptimizePacks(
+	optimization ExportOptimization, dotRegolithPath string,
+) error {
+	if !optimization.Textures && !optimization.Json {
+		return nil
+	}
+	optimizedFiles, err := loadOptimizedFiles(dotRegolithPath)
+	if err != nil {
+		return burrito.WrapError(
+			err, "Failed to load the list of the optimized files.")
+	}
+	tmpPath := getTmpPath(dotRegolithPath)
+	var savedBytes int64
+	optimizedCount := 0
+	for _, pack := range []string{"BP", "RP"} {
+		packPath := filepath.Join(tmpPath, pack)
+		if _, err := os.Stat(packPath); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.WalkDir(packPath, func(
+			path string, entry fs.DirEntry, err error,
+		) error {
+			if err != nil {
+				return burrito.WrapErrorf(err, osWalkError, path)
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			relPath, err := filepath.Rel(tmpPath, path)
+			if err != nil {
+				return burrito.WrapErrorf(err, filepathRelError, tmpPath, path)
+			}
+			if _, ok := optimizedFiles[filepath.ToSlash(relPath)]; ok {
+				return nil
+			}
+			var optimize func([]byte) ([]byte, error)
+			switch strings.ToLower(filepath.Ext(path)) {
+			case ".json":
+				if optimization.Json {
+					optimize = minifyJson
+				}
+			case ".png":
+				if optimization.Textures {
+					optimize = recompressPng
+				}
+			}
+			if optimize == nil {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return burrito.WrapErrorf(err, fileReadError, path)
+			}
+			optimized, err := optimize(data)
+			if err != nil {
+				// The files that can't be parsed are exported unchanged
+				Logger.Warnf(
+					"Failed to optimize the file, exporting it unchanged."+
+						"\nPath: %s\nError: %s", relPath, err.Error())
+				return nil
+			}
+			if len(optimized) >= len(data) {
+				return nil
+			}
+			if err := os.WriteFile(path, optimized, 0644); err != nil {
+				return burrito.WrapErrorf(err, fileWriteError, path)
+			}
+			savedBytes += int64(len(data) - len(optimized))
+			optimizedCount++
+			return nil
+		})
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to optimize the files of the pack.\nPack: %s",
+				pack)
+		}
+	}
+	Logger.Infof(
+		"Optimized %d files, saved %s.", optimizedCount,
+		formatSize(savedBytes))
+	return nil
+}
+
+// minifyJson removes the comments and the whitespace from the JSON file.
+func minifyJson(data []byte) ([]byte, error) {
+	var result bytes.Buffer
+	if err := json.Compact(&result, jsonc.ToJSON(data)); err != nil {
+		return nil, err
+	}
+	return result.Bytes(), nil
+}
+
+// recompressPng encodes the PNG file again with the best compression. The
+// pixels of the image don't change.
+func recompressPng(data []byte) ([]byte, error) {
+	image, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var result bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&result, image); err != nil {
+		return nil, err
+	}
+	return result.Bytes(), nil
+}
@@ -0,0 +1,96 @@
+// Functions used for expanding the placeholders in the paths of the export
+// targets, like "build/{{.Name}}_{{.Date}}/BP".
+package regolith
+
+import (
+	"bytes"
+	"text/template"
+	"time"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// exportDate is the date of the export, available in the templates of the
+// export paths as {{.Date}}. It's printed in the "2006-01-02" format. Other
+// formats can be used with the Format method, for example
+// {{.Date.Format "20060102-150405"}}.
+type exportDate time.Time
+
+func (d exportDate) String() string {
+	return time.Time(d).Format("2006-01-02")
+}
+
+// Format returns the date formatted with the layout of the time package.
+func (d exportDate) Format(layout string) string {
+	return time.Time(d).Format(layout)
+}
+
+// exportPathTemplateData is the data available in the templates of the export
+// paths.
+type exportPathTemplateData struct {
+	// Name is the name of the project from the config
+	Name string
+	// Date is the date of the export
+	Date exportDate
+	// Profile is the name of the exported profile
+	Profile string
+}
+
+// exportPathProperties returns the pointers to the properties of the export
+// target that can use the templates, mapped by the names of the properties.
+func exportPathProperties(target *ExportTarget) map[string]*string {
+	return map[string]*string{
+		"rpPath":    &target.RpPath,
+		"bpPath":    &target.BpPath,
+		"worldPath": &target.WorldPath,
+		"path":      &target.Path,
+	}
+}
+
+// executeExportPathTemplate expands the placeholders of a single property
+// with the data.
+func executeExportPathTemplate(
+	property, text string, data exportPathTemplateData,
+) (string, error) {
+	tmpl, err := template.New(property).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", burrito.WrapErrorf(err, jsonPropertyParseError, property)
+	}
+	var result bytes.Buffer
+	if err := tmpl.Execute(&result, data); err != nil {
+		return "", burrito.WrapErrorf(err, jsonPropertyParseError, property)
+	}
+	return result.String(), nil
+}
+
+// checkExportPathTemplates checks whether the templates of the export paths
+// of the export target are valid, so the typos in the placeholders are
+// reported when the config is loaded instead of during the export.
+func checkExportPathTemplates(target ExportTarget) error {
+	for property, value := range exportPathProperties(&target) {
+		// The unknown fields are reported only when the template is
+		// executed, so it's executed with empty data
+		_, err := executeExportPathTemplate(
+			property, *value, exportPathTemplateData{})
+		if err != nil {
+			return burrito.PassError(err)
+		}
+	}
+	return nil
+}
+
+// expandExportPaths returns a copy of the export target with the
+// placeholders in its export paths replaced with the data.
+func expandExportPaths(
+	target ExportTarget, data exportPathTemplateData,
+) (ExportTarget, error) {
+	for property, value := range exportPathProperties(&target) {
+		expanded, err := executeExportPathTemplate(property, *value, data)
+		if err != nil {
+			return target, burrito.WrapError(
+				err, "Failed to expand the placeholders of the export path.")
+		}
+		*value = expanded
+	}
+	return target, nil
+}
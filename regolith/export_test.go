@@ -0,0 +1,89 @@
+package regolith
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestDevelopmentExportBuild checks whether the "build" property is
+// accepted only by the "development" export target, and only with the
+// "release" and "preview" values.
+func TestDevelopmentExportBuild(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   map[string]interface{}
+		isValid  bool
+		expected string
+	}{
+		{"no build", map[string]interface{}{
+			"target": "development"}, true, ""},
+		{"release", map[string]interface{}{
+			"target": "development", "build": "release"}, true, releaseBuild},
+		{"preview", map[string]interface{}{
+			"target": "development", "build": "preview"}, true, previewBuild},
+		{"unknown build", map[string]interface{}{
+			"target": "development", "build": "beta"}, false, ""},
+		{"not a string", map[string]interface{}{
+			"target": "development", "build": true}, false, ""},
+		{"build of local target", map[string]interface{}{
+			"target": "local", "build": "preview"}, false, ""},
+	}
+	for _, test := range tests {
+		target, err := ExportTargetFromObject(test.target)
+		if test.isValid && err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+		} else if !test.isValid && err == nil {
+			t.Errorf("%s: the export target wasn't rejected", test.name)
+		} else if test.isValid && target.Build != test.expected {
+			t.Errorf(
+				"%s: unexpected build %q, expected %q",
+				test.name, target.Build, test.expected)
+		}
+	}
+}
+
+// TestDevelopmentExportPreviewPaths checks whether the "development" export
+// target with the "preview" build exports to the "com.mojang" directory of
+// Minecraft Preview, like the "preview" export target.
+func TestDevelopmentExportPreviewPaths(t *testing.T) {
+	preview := ExportTarget{Target: "development", Build: previewBuild}
+	if runtime.GOOS != "windows" {
+		// The "com.mojang" directories can't be found on this system, but
+		// the error tells which one was searched for
+		_, _, err := GetExportPaths(preview, "project")
+		if err == nil || !strings.Contains(err.Error(), "Minecraft Preview") {
+			t.Errorf("The Minecraft Preview directory wasn't used: %v", err)
+		}
+		_, _, err = GetExportPaths(ExportTarget{Target: "development"}, "project")
+		if err == nil || strings.Contains(err.Error(), "Minecraft Preview") {
+			t.Errorf("The Minecraft Preview directory was used: %v", err)
+		}
+		return
+	}
+	localAppData := t.TempDir()
+	defer os.Setenv("LOCALAPPDATA", os.Getenv("LOCALAPPDATA"))
+	os.Setenv("LOCALAPPDATA", localAppData)
+	comMojang := filepath.Join(
+		localAppData, "Packages", "Microsoft.MinecraftWindowsBeta_8wekyb3d8bbwe",
+		"LocalState", "games", "com.mojang")
+	if err := os.MkdirAll(comMojang, 0755); err != nil {
+		t.Fatal("Failed to create the com.mojang directory:", err)
+	}
+	expectedBp, expectedRp, err := GetExportPaths(
+		ExportTarget{Target: "preview"}, "project")
+	if err != nil {
+		t.Fatal("Failed to get the export paths of the preview target:", err)
+	}
+	bpPath, rpPath, err := GetExportPaths(preview, "project")
+	if err != nil {
+		t.Fatal("Failed to get the export paths:", err)
+	}
+	if bpPath != expectedBp || rpPath != expectedRp {
+		t.Errorf(
+			"Unexpected export paths %q and %q, expected %q and %q",
+			bpPath, rpPath, expectedBp, expectedRp)
+	}
+}
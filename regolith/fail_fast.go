@@ -0,0 +1,77 @@
+// Functions used by the "regolith run --fail-fast=false" command, which runs
+// all of the filters of the profile even if some of them fail, and reports
+// all of the failures at the end.
+package regolith
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// filterFailure is an error of a filter that didn't stop the run.
+type filterFailure struct {
+	filterId string
+	err      error
+}
+
+// filterFailures collects the failures of the filters of the profile and its
+// nested profiles in the "--fail-fast=false" mode. The nested profiles share
+// the collection of the profile that runs them.
+type filterFailures struct {
+	failures []filterFailure
+	// succeeded is the number of the filters that didn't fail
+	succeeded int
+}
+
+// reset clears the collection before running the profile again in the watch
+// mode.
+func (f *filterFailures) reset() {
+	if f == nil {
+		return
+	}
+	f.failures = nil
+	f.succeeded = 0
+}
+
+// err returns the error that lists all of the failures, or nil if none of the
+// filters failed.
+func (f *filterFailures) err() error {
+	if f == nil || len(f.failures) == 0 {
+		return nil
+	}
+	problems := make([]string, len(f.failures))
+	for i, failure := range f.failures {
+		problems[i] = fmt.Sprintf(
+			"- Filter: %s\n  %s", failure.filterId,
+			strings.ReplaceAll(failure.err.Error(), "\n", "\n  "))
+	}
+	return burrito.WrappedErrorf(
+		"%d of the filters failed.\n%s", len(f.failures),
+		strings.Join(problems, "\n"))
+}
+
+// recordFilterResult records the result of running the filter when the
+// context runs in the "--fail-fast=false" mode. It returns true if the error
+// of the filter was recorded and the run should continue with the next
+// filter. The cancellation of the run is never recorded.
+func (c *RunContext) recordFilterResult(filterId string, err error) bool {
+	if c.filterFailures == nil {
+		return false
+	}
+	if err == nil {
+		c.filterFailures.succeeded++
+		return true
+	}
+	if err == ErrRunCancelled || c.cancellation.isCancelled() {
+		return false
+	}
+	c.filterFailures.failures = append(
+		c.filterFailures.failures,
+		filterFailure{filterId: filterId, err: err})
+	Logger.Errorf(
+		"%sFilter %s failed, continuing with the next filter.\n%s",
+		c.logPrefix(), filterId, err.Error())
+	return true
+}
@@ -799,27 +799,69 @@ func MoveOrCopy(
 	}
 	// Make files read only if this option is selected
 	if makeReadOnly {
-		Logger.Infof("Changing the access for output path to "+
-			"read-only.\n\tPath: %s", destination)
-		err := filepath.WalkDir(destination,
-			func(s string, d fs.DirEntry, e error) error {
-
-				if e != nil {
-					// Error messag isn't important as it's not passed further
-					// in the code
-					return e
-				}
-				if !d.IsDir() {
-					os.Chmod(s, 0444)
+		makeFilesReadOnly(destination)
+	}
+	return nil
+}
+
+// makeFilesReadOnly changes the access of all of the files in the path to
+// read-only by clearing their write bits. The directories stay writable, so
+// the next export can remove the files. Failing to do so is not critical, so
+// it only logs a warning.
+func makeFilesReadOnly(path string) {
+	Logger.Infof("Changing the access for output path to "+
+		"read-only.\n\tPath: %s", path)
+	err := filepath.WalkDir(path,
+		func(s string, d fs.DirEntry, e error) error {
+
+			if e != nil {
+				// Error messag isn't important as it's not passed further
+				// in the code
+				return e
+			}
+			if !d.IsDir() {
+				info, err := d.Info()
+				if err != nil {
+					return err
 				}
-				return nil
-			})
+				os.Chmod(s, info.Mode().Perm()&^0222)
+			}
+			return nil
+		})
+	if err != nil {
+		Logger.Warnf(
+			"Failed to change access of the output path to read-only.\n"+
+				"\tPath: %s",
+			path)
+	}
+}
+
+// makeFilesWritable restores the write access of the owner to the files in
+// the path that were made read-only by makeFilesReadOnly, so they can be
+// overwritten or removed. The path that doesn't exist is ignored.
+func makeFilesWritable(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.WalkDir(path, func(s string, d fs.DirEntry, err error) error {
 		if err != nil {
-			Logger.Warnf(
-				"Failed to change access of the output path to read-only.\n"+
-					"\tPath: %s",
-				destination)
+			return burrito.WrapErrorf(err, osStatErrorAny, s)
 		}
-	}
-	return nil
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return burrito.WrapErrorf(err, osStatErrorAny, s)
+		}
+		if info.Mode().Perm()&0200 != 0 {
+			return nil
+		}
+		err = os.Chmod(s, info.Mode().Perm()|0200)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to make the file writable.\nPath: %s", s)
+		}
+		return nil
+	})
 }
@@ -1,9 +1,59 @@
 package regolith
 
-import "github.com/Bedrock-OSS/go-burrito/burrito"
+import (
+	"fmt"
+	"time"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
 
 type FilterDefinition struct {
-	Id string `json:"-"`
+	Id        string `json:"-"`
+	Cacheable bool   `json:"cacheable,omitempty"`
+
+	// Settings are the default settings of the filter. The settings of the
+	// filters in the profiles are merged with them.
+	Settings map[string]interface{} `json:"settings,omitempty"`
+
+	// Retries is the number of times the filter runs again after a failure
+	// before the run of the profile fails.
+	Retries int `json:"retries,omitempty"`
+
+	// RetryDelay is the number of seconds to wait before running the failed
+	// filter again. Nil means defaultFilterRetryDelay.
+	RetryDelay *float64 `json:"retryDelay,omitempty"`
+
+	// projectRoot is the root of the project that the paths of the local
+	// filter are relative to. The empty string means the current working
+	// directory.
+	projectRoot string
+}
+
+// setProjectRoot sets the root of the project that the paths of the filter
+// are relative to.
+func (f *FilterDefinition) setProjectRoot(projectRoot string) {
+	f.projectRoot = projectRoot
+}
+
+// installLocation returns the directory that the paths of the filter are
+// relative to when installing its dependencies. It's the directory of the
+// remote filter that uses the filter as a subfilter, or the root of the
+// project for the local filters.
+func (f *FilterDefinition) installLocation(
+	parent *RemoteFilterDefinition, dotRegolithPath string,
+) string {
+	if parent != nil {
+		return parent.GetDownloadPath(dotRegolithPath)
+	}
+	return f.projectRoot
+}
+
+// setFilterProjectRoot sets the root of the project of the filter
+// definition, if its type supports it.
+func setFilterProjectRoot(filter FilterInstaller, projectRoot string) {
+	if filter, ok := filter.(interface{ setProjectRoot(string) }); ok {
+		filter.setProjectRoot(projectRoot)
+	}
 }
 
 type Filter struct {
@@ -13,6 +63,155 @@ type Filter struct {
 	Arguments   []string               `json:"arguments,omitempty"`
 	Settings    map[string]interface{} `json:"settings,omitempty"`
 	When        string                 `json:"when,omitempty"`
+	OutputScope []string               `json:"outputScope,omitempty"`
+	Scope       []string               `json:"scope,omitempty"`
+	Packs       []string               `json:"packs,omitempty"`
+	Validate    *FilterValidation      `json:"validate,omitempty"`
+}
+
+// RunOptions is a collection of the settings of the "regolith run" and
+// "regolith watch" commands that affect the way the filters are executed.
+type RunOptions struct {
+	// ConfigPath is the path to the config file of the project. The empty
+	// path means the default "config.json" file.
+	ConfigPath string
+
+	// IsolateEnv makes the filters run with a minimal set of environment
+	// variables instead of inheriting the whole environment of Regolith.
+	IsolateEnv bool
+
+	// AllowedEnv is a list of the names of additional environment variables
+	// passed to the filters when IsolateEnv is enabled.
+	AllowedEnv []string
+
+	// NoHardlink makes Regolith copy the data folder to the tmp directory
+	// instead of linking its files.
+	NoHardlink bool
+
+	// DryRun makes Regolith print the filters of the profile instead of
+	// running them. The project is not exported.
+	DryRun bool
+
+	// Benchmark is the number of times the profile is run to measure the
+	// execution times of the filters. 0 disables the benchmark.
+	Benchmark int
+
+	// NoExport disables exporting the project in the benchmark mode.
+	NoExport bool
+
+	// DotEnv loads the environment variables of the filters from the ".env"
+	// file in the root of the project. The variables from the file don't
+	// override the real environment variables.
+	DotEnv bool
+
+	// EnvFile is the path to the file with the environment variables of the
+	// filters, used instead of the ".env" file in the root of the project.
+	// Setting it enables loading the variables like DotEnv.
+	EnvFile string
+
+	// ProfileReport is the path to the JSON file with the execution times of
+	// the filters and the export, written after running the profile. Empty
+	// string disables the report.
+	ProfileReport string
+
+	// ExportManifest is the path to the JSON file with the list of the
+	// exported files and their hashes, written before exporting the project.
+	// Empty string disables the manifest.
+	ExportManifest string
+
+	// RequireCleanGit makes Regolith refuse to run the profile if the git
+	// repository of the project has uncommitted changes.
+	RequireCleanGit bool
+
+	// Clean makes "regolith run" remove the tmp directory and the cached
+	// outputs of the filters before running the profile.
+	Clean bool
+
+	// InitialClean makes "regolith watch" remove the tmp directory, the
+	// cached outputs of the filters and the backups left by the previous
+	// runs once, before the first run of the profile.
+	InitialClean bool
+
+	// InitialVerify makes "regolith watch" check whether the installed
+	// filters match the config file once, before the first run of the
+	// profile.
+	InitialVerify bool
+
+	// SkipInitialRun makes "regolith watch" wait for the first change of the
+	// source files instead of running the profile when the session starts.
+	SkipInitialRun bool
+
+	// WatchPaths limits "regolith watch" to the listed subdirectories of
+	// the RP, BP and data folders. It overrides the "watchPaths" property
+	// of the config.
+	WatchPaths []string
+
+	// IgnorePaths is a list of glob patterns of the directories that are not
+	// watched by "regolith watch". It overrides the "ignorePaths" property
+	// of the config.
+	IgnorePaths []string
+
+	// SummaryOnly hides the logs of the filters that succeeded and prints a
+	// short summary of the run at the end. The logs are printed if the run
+	// fails.
+	SummaryOnly bool
+
+	// LockTimeout is the maximal time of waiting for the session lock
+	// held by another instance of Regolith. 0 means no waiting.
+	LockTimeout time.Duration
+
+	// Vars is the list of the variables in the "key=value" format, which
+	// replace the ${var:key} placeholders in the settings of the filters.
+	Vars []string
+
+	// ExportTarget overrides the export targets of the profile. It uses the
+	// "path=<path>" format. The empty string uses the export targets from
+	// the config.
+	ExportTarget string
+
+	// Since is a git reference. When it's set, "regolith run" passes the
+	// list of the source files changed since the reference to the filters
+	// in the REGOLITH_CHANGED_FILES environment variable.
+	Since string
+
+	// KeepTmp makes Regolith copy the packs and the data of the filters out
+	// of the tmp directory instead of moving them, so the results of the
+	// filters can be inspected after the run.
+	KeepTmp bool
+
+	// FilterLogs makes Regolith save the output of each filter to the
+	// logs/<profile>/<filter>.log file in the .regolith directory, in
+	// addition to printing it.
+	FilterLogs bool
+
+	// ReportChanges makes Regolith log the number of the files added,
+	// modified and deleted by each filter and the change of the total size
+	// of the files.
+	ReportChanges bool
+
+	// ProfileFile is the path to a JSON file with a profile that is run
+	// instead of a profile from the config file. The profile uses the
+	// filter definitions of the config file. Empty string disables it.
+	ProfileFile string
+
+	// ContinueOnError makes Regolith run the remaining filters of the
+	// profile after a filter fails and report all of the failures at the
+	// end, instead of stopping at the first failure.
+	ContinueOnError bool
+
+	// ExportOnError makes Regolith export the results of the filters that
+	// succeeded when some of the filters failed with ContinueOnError.
+	ExportOnError bool
+
+	// AlwaysExportAll makes "regolith watch" export all of the packs after
+	// every run, including the packs that didn't change since the previous
+	// export.
+	AlwaysExportAll bool
+
+	// MaxMemory is the limit of the memory of the processes of the filters,
+	// for example "512M". The empty string means no limit. It's only
+	// supported on Linux.
+	MaxMemory string
 }
 
 type RunContext struct {
@@ -21,13 +220,101 @@ type RunContext struct {
 	Profile          string
 	Parent           *RunContext
 	DotRegolithPath  string
+	Options          RunOptions
 
 	// interruptionChannel is a channel that is used to notify about changes
 	// in the sourec files, in order to trigger a restart of the program in
-	// the watch mode. The string send to the channel is the name of the source
-	// of the change ("rp", "bp" or "data"), which may be used to handle
-	// some interuptions differently.
-	interruptionChannel chan string
+	// the watch mode. The messages sent to the channel contain the name of
+	// the source of the change ("rp", "bp" or "data"), which may be used to
+	// handle some interuptions differently, and the paths to the changed
+	// files.
+	interruptionChannel chan watchInterruption
+
+	// filterRunListener is called after running each filter of the profile
+	// and the filters of its nested profiles. It's used for collecting the
+	// statistics of the filters. Can be nil.
+	filterRunListener func(filterId string, duration time.Duration, err error)
+
+	// exportListener is called by RunProfile after exporting the project.
+	// It's used for collecting the statistics of the export. Can be nil.
+	exportListener func(duration time.Duration, err error)
+
+	// visitedProfiles is the set of the names of the profiles that run the
+	// nested profiles of this context. It's used for detecting the infinite
+	// recursion of the nested profiles. Can be nil.
+	visitedProfiles map[string]struct{}
+
+	// cancellation handles Ctrl+C during "regolith run". Can be nil, which
+	// means that Ctrl+C stops Regolith immediately.
+	cancellation *runCancellation
+
+	// dryRunDepth is the nesting level of the filters of the context in the
+	// output of "regolith run --dry-run" (the nested profiles and the
+	// subfilters of the remote filters are indented).
+	dryRunDepth int
+
+	// logDepth is the nesting level of the profile of the context, used for
+	// indenting the log messages about running the filters of the nested
+	// profiles.
+	logDepth int
+
+	// changedFiles is the list of the source files changed since the git
+	// reference from the "--since" flag, passed to the filters in the
+	// REGOLITH_CHANGED_FILES environment variable. Nil means that the list
+	// is unknown and the filters should process all of the files.
+	changedFiles []string
+
+	// filterLog is the log file of the filter that runs in the context,
+	// which receives the output of its subprocesses with the
+	// "--filter-logs" flag. Can be nil.
+	filterLog *filterLog
+
+	// filterFailures collects the errors of the filters that failed in the
+	// "--fail-fast=false" mode. Nil means that the run stops at the first
+	// failure.
+	filterFailures *filterFailures
+
+	// filterWarnings counts the warnings that the filters reported in the
+	// warnings.json file, for the summary at the end of the run. Can be nil.
+	filterWarnings *filterWarnings
+
+	// exportedPacks remembers the packs exported during the watch session,
+	// so the packs that didn't change are not exported again. Nil means
+	// that all of the packs are always exported.
+	exportedPacks *exportedPacks
+
+	// tmpDataLinks remembers whether the data folder in the tmp directory
+	// contains hardlinks to the data folder of the project, which must be
+	// copied before running a filter that can modify them. Nil means that
+	// the links are always checked.
+	tmpDataLinks *tmpDataLinks
+
+	// retryFileLocks enables retrying the preparation of the tmp directory
+	// and the file operations of the export when they fail because of the
+	// files held by other processes. It's used in the watch mode, a single
+	// run fails fast.
+	retryFileLocks bool
+
+	// maxMemory is the limit of the memory of the processes of the filters
+	// in bytes, from the "--max-memory" flag. 0 means no limit.
+	maxMemory uint64
+
+	// runSummary collects the results and buffers the logs of the filters
+	// in the "--summary-only" mode. Nil means that the logs are printed
+	// immediately.
+	runSummary *runSummary
+}
+
+// childContext returns a copy of the context for the filters nested in the
+// filter of the context (the filters of the nested profiles and the
+// subfilters of the remote filters). Every field is copied, so the nested
+// filters share the cancellation, the logs and the other state of the run.
+// The callers change only the fields that differ for the nested filters.
+// The nested filters are indented in the output of "regolith run --dry-run".
+func (c *RunContext) childContext() RunContext {
+	child := *c
+	child.dryRunDepth++
+	return child
 }
 
 // GetProfile returns the Profile structure from the context.
@@ -35,11 +322,49 @@ func (c *RunContext) GetProfile() (Profile, error) {
 	profile, ok := c.Config.Profiles[c.Profile]
 	if !ok {
 		return Profile{}, burrito.WrappedErrorf("Profile with specified name doesn't exist.\n"+
-			"Profile name: %s", c.Profile)
+			"Profile name: %s\nAvailable profiles:\n%s",
+			c.Profile, c.Config.ListProfiles())
 	}
 	return profile, nil
 }
 
+// isProfileVisited returns true if the profile is the profile of the
+// context or if it runs the profile of the context as a nested profile.
+func (c *RunContext) isProfileVisited(profile string) bool {
+	if c.Profile == profile {
+		return true
+	}
+	_, ok := c.visitedProfiles[profile]
+	return ok
+}
+
+// withVisitedProfile returns a copy of the visitedProfiles set extended with
+// the profile. The set of the context is not modified.
+func (c *RunContext) withVisitedProfile(profile string) map[string]struct{} {
+	result := make(map[string]struct{}, len(c.visitedProfiles)+1)
+	for visited := range c.visitedProfiles {
+		result[visited] = struct{}{}
+	}
+	result[profile] = struct{}{}
+	return result
+}
+
+// ProjectMetadata is a read-only snapshot of the metadata of the project
+// from "config.json", which is passed to the filters.
+type ProjectMetadata struct {
+	Name   string
+	Author string
+}
+
+// GetProjectMetadata returns a copy of the metadata of the project from the
+// config of the context.
+func (c *RunContext) GetProjectMetadata() ProjectMetadata {
+	if c.Config == nil {
+		return ProjectMetadata{}
+	}
+	return ProjectMetadata{Name: c.Config.Name, Author: c.Config.Author}
+}
+
 // IsWatchMode returns a value that shows whether the context is in the
 // watch mode.
 func (c *RunContext) IsInWatchMode() bool {
@@ -56,19 +381,29 @@ func (c *RunContext) StartWatchingSourceFiles() error {
 	if c.interruptionChannel != nil {
 		return burrito.WrappedError("Files are already being watched.")
 	}
-	rpWatcher, err := NewDirWatcher(c.Config.ResourceFolder)
-	if err != nil {
-		return burrito.WrapError(err, "Could not create resource pack watcher.")
+	watchPaths := c.Options.WatchPaths
+	if len(watchPaths) == 0 {
+		watchPaths = c.Config.WatchPaths
 	}
-	bpWatcher, err := NewDirWatcher(c.Config.BehaviorFolder)
-	if err != nil {
-		return burrito.WrapError(err, "Could not create behavior pack watcher.")
+	ignorePaths := c.Options.IgnorePaths
+	if len(ignorePaths) == 0 {
+		ignorePaths = c.Config.IgnorePaths
 	}
-	dataWatcher, err := NewDirWatcher(c.Config.DataPath)
+	dirs, err := resolveWatchedDirs(c.Config, watchPaths, ignorePaths)
 	if err != nil {
-		return burrito.WrapError(err, "Could not create data watcher.")
+		return burrito.WrapError(err, "Could not select the watched directories.")
+	}
+	watchers := make([]*DirWatcher, len(dirs))
+	for i, dir := range dirs {
+		Logger.Debugf(
+			"Watching %q (recursive: %t).", dir.path, dir.recursive)
+		watchers[i], err = NewDirWatcher(dir.path, dir.recursive)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Could not create watcher.\nPath: %s", dir.path)
+		}
 	}
-	c.interruptionChannel = make(chan string)
+	c.interruptionChannel = make(chan watchInterruption)
 	yieldChanges := func(
 		watcher *DirWatcher, sourceName string,
 	) {
@@ -80,16 +415,19 @@ func (c *RunContext) StartWatchingSourceFiles() error {
 			}
 		}
 	}
-	go yieldChanges(rpWatcher, "rp")
-	go yieldChanges(bpWatcher, "bp")
-	go yieldChanges(dataWatcher, "data")
+	for i, dir := range dirs {
+		go yieldChanges(watchers[i], dir.source)
+	}
 	return nil
 }
 
 // AwaitInterruption locks the goroutine with the interruption channel until
-// the Config is interrupted and returns the interruption message.
+// the Config is interrupted, logs the changed files and returns the source of
+// the interruption.
 func (c *RunContext) AwaitInterruption() string {
-	return <-c.interruptionChannel
+	interruption := <-c.interruptionChannel
+	interruption.log()
+	return interruption.source
 }
 
 // IsInterrupted returns true if there is a message on the interruptionChannel
@@ -100,20 +438,78 @@ func (c *RunContext) IsInterrupted(ignoredSourece ...string) bool {
 		return false
 	}
 	select {
-	case source := <-c.interruptionChannel:
+	case interruption := <-c.interruptionChannel:
 		for _, ignored := range ignoredSourece {
-			if ignored == source {
+			if ignored == interruption.source {
 				return false
 			}
 		}
+		interruption.log()
 		return true
 	default:
 		return false
 	}
 }
 
-func FilterDefinitionFromObject(id string) *FilterDefinition {
-	return &FilterDefinition{Id: id}
+func FilterDefinitionFromObject(id string, obj map[string]interface{}) *FilterDefinition {
+	// Cacheable
+	cacheable, _ := obj["cacheable"].(bool)
+	// Settings - can be empty
+	settings, _ := obj["settings"].(map[string]interface{})
+	result := &FilterDefinition{Id: id, Cacheable: cacheable, Settings: settings}
+	// Retries - can be empty
+	if retries, ok := obj["retries"].(float64); ok && retries > 0 {
+		result.Retries = int(retries)
+	}
+	// RetryDelay - can be empty
+	if retryDelay, ok := obj["retryDelay"].(float64); ok && retryDelay >= 0 {
+		result.RetryDelay = &retryDelay
+	}
+	return result
+}
+
+// IsCacheable returns whether the outputs of the filter can be cached and
+// reused when the filter runs again with the same input.
+func (f *FilterDefinition) IsCacheable() bool {
+	return f.Cacheable
+}
+
+// DefaultSettings returns the default settings of the filter from its
+// definition.
+func (f *FilterDefinition) DefaultSettings() map[string]interface{} {
+	return f.Settings
+}
+
+// RetryPolicy returns the number of the retries of the failed filter and
+// the delay between them.
+func (f *FilterDefinition) RetryPolicy() (int, time.Duration) {
+	if f.RetryDelay == nil {
+		return f.Retries, defaultFilterRetryDelay
+	}
+	return f.Retries, time.Duration(*f.RetryDelay * float64(time.Second))
+}
+
+// mergeSettings returns the result of merging the override settings into
+// the base settings. The objects are merged recursively, and the other
+// values (including the arrays) of the override replace the values of the
+// base. The input maps are not modified.
+func mergeSettings(
+	base, override map[string]interface{},
+) map[string]interface{} {
+	result := make(map[string]interface{}, len(base)+len(override))
+	for key, value := range base {
+		result[key] = value
+	}
+	for key, value := range override {
+		baseObj, ok1 := result[key].(map[string]interface{})
+		overrideObj, ok2 := value.(map[string]interface{})
+		if ok1 && ok2 {
+			result[key] = mergeSettings(baseObj, overrideObj)
+		} else {
+			result[key] = value
+		}
+	}
+	return result
 }
 
 func filterFromObject(obj map[string]interface{}) (*Filter, error) {
@@ -159,6 +555,18 @@ func filterFromObject(obj map[string]interface{}) (*Filter, error) {
 		}
 	}
 	filter.When = when.(string)
+	// OutputScope, Packs and Scope
+	if err := filterScopesFromObject(filter, obj); err != nil {
+		return nil, burrito.PassError(err)
+	}
+	// Validate
+	if validate, ok := obj["validate"]; ok {
+		validation, err := filterValidationFromObject(validate)
+		if err != nil {
+			return nil, burrito.PassError(err)
+		}
+		filter.Validate = validation
+	}
 
 	// Id
 	idObj, ok := obj["filter"]
@@ -173,10 +581,81 @@ func filterFromObject(obj map[string]interface{}) (*Filter, error) {
 	return filter, nil
 }
 
+// filterScopesFromObject parses the "outputScope", "packs" and "scope"
+// properties of the filter from the JSON object. They're shared by all kinds
+// of the filters in the profiles, including the nested profiles.
+func filterScopesFromObject(filter *Filter, obj map[string]interface{}) error {
+	// OutputScope
+	if outputScope, ok := obj["outputScope"]; ok {
+		outputScope, ok := outputScope.([]interface{})
+		if !ok {
+			return burrito.WrappedErrorf(
+				jsonPropertyTypeError, "outputScope", "array")
+		}
+		for i, pack := range outputScope {
+			pack, ok := pack.(string)
+			if !ok || !isOutputScopePack(pack) {
+				return burrito.WrappedErrorf(
+					jsonPropertyTypeError, fmt.Sprintf("outputScope->%d", i),
+					"\"RP\", \"BP\" or \"data\"")
+			}
+			filter.OutputScope = append(filter.OutputScope, pack)
+		}
+	}
+	// Packs
+	if packs, ok := obj["packs"]; ok {
+		packs, ok := packs.([]interface{})
+		if !ok {
+			return burrito.WrappedErrorf(
+				jsonPropertyTypeError, "packs", "array")
+		}
+		for i, pack := range packs {
+			pack, ok := pack.(string)
+			if !ok || !isOutputScopePack(pack) {
+				return burrito.WrappedErrorf(
+					jsonPropertyTypeError, fmt.Sprintf("packs->%d", i),
+					"\"RP\", \"BP\" or \"data\"")
+			}
+			filter.Packs = append(filter.Packs, pack)
+		}
+	}
+	// Scope
+	if scope, ok := obj["scope"]; ok {
+		scope, ok := scope.([]interface{})
+		if !ok {
+			return burrito.WrappedErrorf(
+				jsonPropertyTypeError, "scope", "array")
+		}
+		for i, pattern := range scope {
+			pattern, ok := pattern.(string)
+			if !ok {
+				return burrito.WrappedErrorf(
+					jsonPropertyTypeError, fmt.Sprintf("scope->%d", i),
+					"string")
+			}
+			filter.Scope = append(filter.Scope, pattern)
+		}
+		if _, err := parseScopePatterns(filter.Scope); err != nil {
+			return burrito.WrapErrorf(
+				err, jsonPropertyParseError, "scope")
+		}
+	}
+	return nil
+}
+
 type FilterInstaller interface {
 	InstallDependencies(parent *RemoteFilterDefinition, dotRegolithPath string) error
 	Check(context RunContext) error
 	CreateFilterRunner(runConfiguration map[string]interface{}) (FilterRunner, error)
+	IsCacheable() bool
+
+	// DefaultSettings returns the settings from the filter definition,
+	// which are merged with the settings of the filters in the profiles.
+	DefaultSettings() map[string]interface{}
+
+	// RetryPolicy returns the number of the retries of the failed filter
+	// and the delay between them.
+	RetryPolicy() (int, time.Duration)
 }
 
 type FilterRunner interface {
@@ -190,12 +669,20 @@ type FilterRunner interface {
 	// disabled it always returns false.
 	Run(context RunContext) (bool, error)
 
-	// IsDisabled returns whether the filter is disabled.
+	// IsDisabled returns whether the filter is disabled with the "disabled"
+	// property.
 	IsDisabled(ctx RunContext) (bool, error)
 
+	// IsConditionMet returns whether the "when" condition of the filter
+	// evaluates to true. Filters without a condition always meet it.
+	IsConditionMet(ctx RunContext) (bool, error)
+
 	// GetId returns the id of the filter.
 	GetId() string
 
+	// GetSettings returns the settings of the filter.
+	GetSettings() map[string]interface{}
+
 	// Check checks whether the requirements of the filter are met. For
 	// example, a Python filter requires Python to be installed.
 	Check(context RunContext) error
@@ -203,6 +690,33 @@ type FilterRunner interface {
 	// IsUsingDataExport returns whether the filter wahts its data to be
 	// exported back to the data folder after running the profile.
 	IsUsingDataExport(dotRegolithPath string) (bool, error)
+
+	// GetOutputScope returns the list of the packs ("RP", "BP" or "data")
+	// that the filter is allowed to modify. An empty list means that the
+	// filter can modify all of them.
+	GetOutputScope() []string
+
+	// GetPacks returns the list of the packs ("RP", "BP" or "data") that
+	// are present in the tmp directory while the filter runs. An empty list
+	// means all of the packs.
+	GetPacks() []string
+
+	// GetScope returns the patterns of the files of the packs that are
+	// passed to the filter. An empty list means that the filter gets all of
+	// the files.
+	GetScope() []string
+
+	// GetValidation returns the checks of the output of the filter from the
+	// "validate" property. Can be nil.
+	GetValidation() *FilterValidation
+}
+
+// FilterDryRunner is an optional interface of the FilterRunners that can
+// describe what they would do in "regolith run --dry-run" mode. The filters
+// that don't implement it are described with their settings and arguments.
+type FilterDryRunner interface {
+	// DryRun prints what the filter would do, without running it.
+	DryRun(context RunContext) error
 }
 
 func (f *Filter) CopyArguments(parent *RemoteFilter) {
@@ -225,18 +739,39 @@ func (f *Filter) GetId() string {
 	return f.Id
 }
 
-func (f *Filter) IsDisabled(ctx RunContext) (bool, error) {
-	if f.Disabled {
+func (f *Filter) GetSettings() map[string]interface{} {
+	return f.Settings
+}
+
+func (f *Filter) GetOutputScope() []string {
+	return f.OutputScope
+}
+
+func (f *Filter) GetPacks() []string {
+	return f.Packs
+}
+
+func (f *Filter) GetScope() []string {
+	return f.Scope
+}
+
+func (f *Filter) GetValidation() *FilterValidation {
+	return f.Validate
+}
+
+func (f *Filter) IsDisabled(_ RunContext) (bool, error) {
+	return f.Disabled, nil
+}
+
+func (f *Filter) IsConditionMet(ctx RunContext) (bool, error) {
+	if f.When == "" {
 		return true, nil
 	}
-	if f.When != "" {
-		condition, err := EvalCondition(f.When, ctx)
-		if err != nil {
-			return false, burrito.WrapError(err, "Could not evaluate condition.")
-		}
-		return !condition, nil
+	condition, err := EvalCondition(f.When, ctx)
+	if err != nil {
+		return false, burrito.WrapError(err, "Could not evaluate condition.")
 	}
-	return false, nil
+	return condition, nil
 }
 
 func (f *Filter) IsUsingDataExport(_ string) (bool, error) {
@@ -312,6 +847,15 @@ func FilterInstallerFromObject(id string, obj map[string]interface{}) (FilterIns
 				"Unable to create exe filter from %q filter definition.", id)
 		}
 		return filter, nil
+	case "docker":
+		filter, err := DockerFilterDefinitionFromObject(id, obj)
+		if err != nil {
+			return nil, burrito.WrapErrorf(
+				err,
+				"Unable to create Docker filter from %q filter definition.",
+				id)
+		}
+		return filter, nil
 	case "":
 		filter, err := RemoteFilterDefinitionFromObject(id, obj)
 		if err != nil {
@@ -326,7 +870,8 @@ func FilterInstallerFromObject(id string, obj map[string]interface{}) (FilterIns
 		"Invalid runWith value filter definition.\n"+
 			"Filter: %s\n"+
 			"Value: %s\n"+
-			"Valid values: java, dotnet, nim, deno, nodejs, python, shell, exe",
+			"Valid values: java, dotnet, nim, deno, nodejs, python, shell, exe, "+
+			"docker",
 		runWith, id)
 }
 
@@ -335,7 +880,13 @@ func FilterRunnerFromObjectAndDefinitions(
 ) (FilterRunner, error) {
 	profile, ok := obj["profile"].(string)
 	if ok {
-		return &ProfileFilter{Profile: profile}, nil
+		profileFilter, err := profileFilterFromObject(profile, obj)
+		if err != nil {
+			return nil, burrito.WrapErrorf(
+				err, "Failed to parse the nested profile filter.\nProfile: %s",
+				profile)
+		}
+		return profileFilter, nil
 	}
 	filterObj, ok := obj["filter"]
 	if !ok {
@@ -346,6 +897,19 @@ func FilterRunnerFromObjectAndDefinitions(
 		return nil, burrito.WrappedErrorf(jsonPropertyTypeError, "filter", "string")
 	}
 	if filterDefinition, ok := filterDefinitions[filter]; ok {
+		// The settings of the profile are merged with the default settings
+		// from the filter definition
+		if defaults := filterDefinition.DefaultSettings(); len(defaults) != 0 {
+			overrides, ok := obj["settings"].(map[string]interface{})
+			if _, hasSettings := obj["settings"]; !hasSettings || ok {
+				runConfiguration := make(map[string]interface{}, len(obj))
+				for key, value := range obj {
+					runConfiguration[key] = value
+				}
+				runConfiguration["settings"] = mergeSettings(defaults, overrides)
+				obj = runConfiguration
+			}
+		}
 		filterRunner, err := filterDefinition.CreateFilterRunner(obj)
 		if err != nil {
 			return nil, burrito.WrapErrorf(err, createFilterRunnerError, filter)
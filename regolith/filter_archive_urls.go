@@ -0,0 +1,182 @@
+// Functions used for installing the remote filters from the URLs of zip and
+// tar archives, which are downloaded over HTTP instead of cloned with Git.
+package regolith
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+	getter "github.com/hashicorp/go-getter"
+)
+
+// filterArchiveExtensions are the extensions of the archives that can be
+// used as the URLs of the remote filters. The longer extensions go first.
+var filterArchiveExtensions = []string{
+	"tar.gz", "tar.bz2", "tar.xz", "tar.zst", "tgz", "tbz2", "txz", "tzst",
+	"tar", "zip",
+}
+
+// archiveHashPrefix is the prefix of the versions of the filters installed
+// from the archives. The version of such filter is the SHA-256 hash of the
+// content of the archive.
+const archiveHashPrefix = "sha256:"
+
+// filterArchiveExtension returns the extension of the archive that the URL of
+// a filter points to, or an empty string if the URL is not an HTTP(S) URL of
+// an archive.
+func filterArchiveExtension(url string) string {
+	lower := strings.ToLower(url)
+	if !strings.HasPrefix(lower, "http://") &&
+		!strings.HasPrefix(lower, "https://") {
+		return ""
+	}
+	if i := strings.IndexAny(lower, "?#"); i != -1 {
+		lower = lower[:i]
+	}
+	for _, extension := range filterArchiveExtensions {
+		if strings.HasSuffix(lower, "."+extension) {
+			return extension
+		}
+	}
+	return ""
+}
+
+// isArchiveFilterUrl returns true if the filter is downloaded from an
+// archive instead of a Git repository.
+func isArchiveFilterUrl(url string) bool {
+	return filterArchiveExtension(url) != ""
+}
+
+// archiveFilterName returns the name of the filter from the URL of its
+// archive, which is the name of the archive without the extension.
+func archiveFilterName(url string) string {
+	extension := filterArchiveExtension(url)
+	if i := strings.IndexAny(url, "?#"); i != -1 {
+		url = url[:i]
+	}
+	name := path.Base(url)
+	return name[:len(name)-len(extension)-1]
+}
+
+// isArchiveHash returns true if the version of a filter is the hash of the
+// content of its archive.
+func isArchiveHash(version string) bool {
+	return strings.HasPrefix(version, archiveHashPrefix)
+}
+
+// fetchFilterArchive downloads the archive of a filter to a temporary file.
+// It returns the path to the file and the hash of its content. The caller is
+// responsible for removing the file.
+func fetchFilterArchive(url string) (string, string, error) {
+	var archivePath, hash string
+	err := retryNetworkOperation("download filter archive "+url, func() error {
+		file, err := os.CreateTemp("", "regolith-filter-*."+filterArchiveExtension(url))
+		if err != nil {
+			return burrito.WrapError(err, "Failed to create a temporary file.")
+		}
+		defer file.Close()
+		err = func() error {
+			response, err := http.Get(url)
+			if err != nil {
+				return err
+			}
+			defer response.Body.Close()
+			if response.StatusCode != http.StatusOK {
+				// The message is similar to the errors of Git, so the
+				// server errors are retried
+				return burrito.WrappedErrorf(
+					"The requested URL returned error: %d",
+					response.StatusCode)
+			}
+			hasher := sha256.New()
+			_, err = io.Copy(io.MultiWriter(file, hasher), response.Body)
+			if err != nil {
+				return err
+			}
+			hash = archiveHashPrefix + hex.EncodeToString(hasher.Sum(nil))
+			return nil
+		}()
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+			return err
+		}
+		archivePath = file.Name()
+		return nil
+	})
+	if err != nil {
+		return "", "", burrito.PassError(err)
+	}
+	return archivePath, hash, nil
+}
+
+// archiveFilterHash downloads the archive of a filter and returns the hash of
+// its content. It's used for pinning the version of the filter.
+func archiveFilterHash(url string) (string, error) {
+	archivePath, hash, err := fetchFilterArchive(url)
+	if err != nil {
+		return "", burrito.PassError(err)
+	}
+	os.Remove(archivePath)
+	return hash, nil
+}
+
+// downloadArchiveFilter downloads the archive of a filter and extracts it to
+// the downloadPath. If the version is a hash, the hash of the downloaded
+// archive must match it. The filter.json file must be in the root of the
+// archive or in its only directory. It returns the hash of the archive.
+func downloadArchiveFilter(url, version, downloadPath string) (string, error) {
+	archivePath, hash, err := fetchFilterArchive(url)
+	if err != nil {
+		return "", burrito.PassError(err)
+	}
+	defer os.Remove(archivePath)
+	if isArchiveHash(version) && hash != version {
+		return "", burrito.WrappedErrorf(
+			"The hash of the downloaded archive doesn't match the version of "+
+				"the filter.\nURL: %s\nExpected hash: %s\nActual hash: %s",
+			url, version, hash)
+	}
+	// The archive is extracted next to the download path, so it can be
+	// renamed instead of copied
+	extractPath := downloadPath + ".extract"
+	if err := os.RemoveAll(extractPath); err != nil {
+		return "", burrito.WrapErrorf(err, osRemoveError, extractPath)
+	}
+	defer os.RemoveAll(extractPath)
+	decompressor := getter.Decompressors[filterArchiveExtension(url)]
+	err = decompressor.Decompress(extractPath, archivePath, true, 0)
+	if err != nil {
+		return "", burrito.WrapErrorf(
+			err, "Failed to extract the archive of the filter.\nURL: %s", url)
+	}
+	root := extractPath
+	if _, err := os.Stat(filepath.Join(root, "filter.json")); err != nil {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			return "", burrito.WrapErrorf(err, osReadDirError, root)
+		}
+		if len(entries) == 1 && entries[0].IsDir() {
+			root = filepath.Join(root, entries[0].Name())
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "filter.json")); err != nil {
+		return "", burrito.WrappedErrorf(
+			"The archive doesn't contain the \"filter.json\" file in its "+
+				"root or in its only directory.\nURL: %s", url)
+	}
+	if err := os.RemoveAll(downloadPath); err != nil {
+		return "", burrito.WrapErrorf(err, osRemoveError, downloadPath)
+	}
+	if err := os.Rename(root, downloadPath); err != nil {
+		return "", burrito.WrapErrorf(err, osRenameError, root, downloadPath)
+	}
+	return hash, nil
+}
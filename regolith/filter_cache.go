@@ -0,0 +1,224 @@
+// Functions used for caching the outputs of the filters with the "cacheable"
+// property.
+package regolith
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+	"github.com/otiai10/copy"
+)
+
+// filterCacheDir is the name of the directory in the .regolith directory
+// that stores the cached outputs of the filters.
+const filterCacheDir = "filter-cache"
+
+// defaultFilterCacheSize is the maximal size of the filter cache in
+// megabytes used when the "filter_cache_size" user config property is not
+// set.
+const defaultFilterCacheSize = 1024
+
+// hashFilterInput returns a hash of everything that affects the output of the
+// filter: its ID, its definition, its settings and arguments and the content
+// of the tmp directory. The code of the filter is not included, so changing
+// the code of a local filter requires clearing the cache with
+// "regolith clean --filter-cache".
+func hashFilterInput(
+	filter FilterRunner, definition FilterInstaller, dotRegolithPath string,
+) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(filter.GetId()))
+	hash.Write([]byte{0})
+	definitionJson, err := json.Marshal(definition)
+	if err != nil {
+		return "", burrito.WrapErrorf(
+			err, "Failed to serialize the filter definition.\nFilter: %s",
+			filter.GetId())
+	}
+	hash.Write(definitionJson)
+	hash.Write([]byte{0})
+	if _, basicFilter := describeFilterRunner(filter); basicFilter != nil {
+		settingsJson, err := json.Marshal(basicFilter.Settings)
+		if err != nil {
+			return "", burrito.WrapErrorf(
+				err, "Failed to serialize the filter settings.\nFilter: %s",
+				filter.GetId())
+		}
+		hash.Write(settingsJson)
+		hash.Write([]byte{0})
+		for _, arg := range basicFilter.Arguments {
+			hash.Write([]byte(arg))
+			hash.Write([]byte{0})
+		}
+	}
+	tmpPath := getTmpPath(dotRegolithPath)
+	err = filepath.WalkDir(tmpPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return burrito.WrapErrorf(err, osWalkError, tmpPath)
+		}
+		relPath, err := filepath.Rel(tmpPath, path)
+		if err != nil {
+			return burrito.WrapErrorf(err, filepathRelError, tmpPath, path)
+		}
+		hash.Write([]byte(filepath.ToSlash(relPath)))
+		hash.Write([]byte{0})
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return burrito.WrapErrorf(err, osOpenError, path)
+		}
+		defer file.Close()
+		if _, err := io.Copy(hash, file); err != nil {
+			return burrito.WrapErrorf(err, fileReadError, path)
+		}
+		hash.Write([]byte{0})
+		return nil
+	})
+	if err != nil {
+		return "", burrito.PassError(err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// restoreFilterCache replaces the content of the tmp directory with the
+// cached output of a filter with the given input hash. It returns false if
+// the cache doesn't have an entry for the hash.
+func restoreFilterCache(hash, dotRegolithPath string) (bool, error) {
+	cachePath := filepath.Join(dotRegolithPath, filterCacheDir, hash)
+	if _, err := os.Stat(cachePath); os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, burrito.WrapErrorf(err, osStatErrorAny, cachePath)
+	}
+	tmpPath := getTmpPath(dotRegolithPath)
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return false, burrito.WrapErrorf(err, osRemoveError, tmpPath)
+	}
+	if err := copy.Copy(cachePath, tmpPath); err != nil {
+		return false, burrito.WrapErrorf(err, osCopyError, cachePath, tmpPath)
+	}
+	// The modification time of the entry marks its last use for the eviction
+	now := time.Now()
+	if err := os.Chtimes(cachePath, now, now); err != nil {
+		Logger.Debugf("Failed to update the time of the cache entry %q: %s",
+			cachePath, err)
+	}
+	return true, nil
+}
+
+// storeFilterCache saves the content of the tmp directory as the output of
+// a filter with the given input hash. The files are copied to a temporary
+// location first, so that an interrupted copy never leaves an incomplete
+// cache entry.
+func storeFilterCache(hash, dotRegolithPath string) error {
+	cachePath := filepath.Join(dotRegolithPath, filterCacheDir, hash)
+	partialPath := cachePath + ".partial"
+	if err := os.RemoveAll(partialPath); err != nil {
+		return burrito.WrapErrorf(err, osRemoveError, partialPath)
+	}
+	tmpPath := getTmpPath(dotRegolithPath)
+	if err := copy.Copy(tmpPath, partialPath); err != nil {
+		return burrito.WrapErrorf(err, osCopyError, tmpPath, partialPath)
+	}
+	if err := os.RemoveAll(cachePath); err != nil {
+		return burrito.WrapErrorf(err, osRemoveError, cachePath)
+	}
+	if err := os.Rename(partialPath, cachePath); err != nil {
+		return burrito.WrapErrorf(err, osRenameError, partialPath, cachePath)
+	}
+	now := time.Now()
+	if err := os.Chtimes(cachePath, now, now); err != nil {
+		Logger.Debugf("Failed to update the time of the cache entry %q: %s",
+			cachePath, err)
+	}
+	maxSize, err := getFilterCacheSize()
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	return evictFilterCache(dotRegolithPath, maxSize, hash)
+}
+
+// getFilterCacheSize returns the maximal size of the filter cache in bytes
+// from the user config.
+func getFilterCacheSize() (int64, error) {
+	userConfig, err := getCombinedUserConfig()
+	if err != nil {
+		return 0, burrito.WrapError(err, getUserConfigError)
+	}
+	size := defaultFilterCacheSize
+	if userConfig.FilterCacheSize != nil && *userConfig.FilterCacheSize > 0 {
+		size = *userConfig.FilterCacheSize
+	}
+	return int64(size) * 1024 * 1024, nil
+}
+
+// filterCacheEntry is a cached output of a filter, used for evicting the
+// old entries from the filter cache.
+type filterCacheEntry struct {
+	path     string
+	size     int64
+	lastUsed time.Time
+}
+
+// evictFilterCache removes the least recently used entries of the filter
+// cache until its total size is not larger than maxSize bytes. The entry
+// with the keepHash is never removed, so the output that was just stored
+// stays in the cache even if it's larger than the limit.
+func evictFilterCache(dotRegolithPath string, maxSize int64, keepHash string) error {
+	cachePath := filepath.Join(dotRegolithPath, filterCacheDir)
+	dirEntries, err := os.ReadDir(cachePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return burrito.WrapErrorf(err, osReadDirError, cachePath)
+	}
+	var entries []filterCacheEntry
+	var totalSize int64
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if !dirEntry.IsDir() || strings.HasSuffix(name, ".partial") {
+			continue
+		}
+		entryPath := filepath.Join(cachePath, name)
+		info, err := dirEntry.Info()
+		if err != nil {
+			return burrito.WrapErrorf(err, osStatErrorAny, entryPath)
+		}
+		size, err := pathSize(entryPath)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+		totalSize += size
+		if name == keepHash {
+			continue
+		}
+		entries = append(entries, filterCacheEntry{
+			path: entryPath, size: size, lastUsed: info.ModTime()})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].lastUsed.Before(entries[j].lastUsed)
+	})
+	for _, entry := range entries {
+		if totalSize <= maxSize {
+			break
+		}
+		Logger.Debugf("Removing the old filter cache entry %q.", entry.path)
+		if err := os.RemoveAll(entry.path); err != nil {
+			return burrito.WrapErrorf(err, osRemoveError, entry.path)
+		}
+		totalSize -= entry.size
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package regolith
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestEvictFilterCache checks whether the least recently used entries of the
+// filter cache are removed until the cache fits the size limit, and whether
+// the entry that was just stored is kept.
+func TestEvictFilterCache(t *testing.T) {
+	InitLogging(false)
+	dotRegolithPath := t.TempDir()
+	cachePath := filepath.Join(dotRegolithPath, filterCacheDir)
+	now := time.Now()
+	// The entries have 10 bytes each, "new" is the most recently used one
+	// except for "kept", which is the oldest one but was just stored
+	entries := map[string]time.Duration{
+		"kept": 4 * time.Hour, "old": 3 * time.Hour, "middle": 2 * time.Hour,
+		"new": time.Hour,
+	}
+	for name, age := range entries {
+		entryPath := filepath.Join(cachePath, name)
+		if err := os.MkdirAll(entryPath, 0755); err != nil {
+			t.Fatal("Failed to create the cache entry:", err)
+		}
+		err := os.WriteFile(
+			filepath.Join(entryPath, "file.txt"), []byte("0123456789"), 0644)
+		if err != nil {
+			t.Fatal("Failed to create the cache entry:", err)
+		}
+		if err := os.Chtimes(entryPath, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal("Failed to set the time of the cache entry:", err)
+		}
+	}
+	if err := evictFilterCache(dotRegolithPath, 25, "kept"); err != nil {
+		t.Fatal("Failed to evict the filter cache:", err)
+	}
+	expected := map[string]bool{
+		"kept": true, "old": false, "middle": false, "new": true}
+	for name, exists := range expected {
+		_, err := os.Stat(filepath.Join(cachePath, name))
+		if actual := err == nil; actual != exists {
+			t.Errorf(
+				"The %q entry exists: %v, expected: %v", name, actual, exists)
+		}
+	}
+}
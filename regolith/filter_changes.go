@@ -0,0 +1,89 @@
+// Functions used by the "regolith run --report-changes" command, which
+// reports how each filter changed the files in the tmp directory.
+package regolith
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// tmpFileState is the state of a file of the tmp directory saved in a
+// tmpSnapshot.
+type tmpFileState struct {
+	hash string
+	size int64
+}
+
+// tmpSnapshot maps the paths of the files of the tmp directory (relative
+// to the tmp directory, with forward slashes) to their states.
+type tmpSnapshot map[string]tmpFileState
+
+// filterChanges is a summary of the changes of the files of the tmp
+// directory made by a filter.
+type filterChanges struct {
+	added, modified, deleted int
+	// sizeDelta is the difference between the total size of the files
+	// after and before running the filter
+	sizeDelta int64
+}
+
+// snapshotTmpFiles returns the snapshot of the files of the tmp directory.
+func snapshotTmpFiles(dotRegolithPath string) (tmpSnapshot, error) {
+	tmpPath := getTmpPath(dotRegolithPath)
+	result := make(tmpSnapshot)
+	err := walkArchiveFiles(tmpPath, func(path, relPath string, info fs.FileInfo) error {
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		hash := sha256.New()
+		if err := copyFileTo(hash, path); err != nil {
+			return burrito.PassError(err)
+		}
+		result[relPath] = tmpFileState{
+			hash: hex.EncodeToString(hash.Sum(nil)),
+			size: info.Size(),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, burrito.WrapErrorf(err, osWalkError, tmpPath)
+	}
+	return result, nil
+}
+
+// diffTmpSnapshots returns the summary of the changes between the snapshots
+// of the tmp directory taken before and after running a filter.
+func diffTmpSnapshots(before, after tmpSnapshot) filterChanges {
+	result := filterChanges{}
+	for path, state := range after {
+		previous, ok := before[path]
+		if !ok {
+			result.added++
+		} else if previous.hash != state.hash {
+			result.modified++
+		}
+		result.sizeDelta += state.size
+	}
+	for path, state := range before {
+		if _, ok := after[path]; !ok {
+			result.deleted++
+		}
+		result.sizeDelta -= state.size
+	}
+	return result
+}
+
+// String returns the summary of the changes as a human readable string.
+func (c filterChanges) String() string {
+	sign, size := "+", c.sizeDelta
+	if size < 0 {
+		sign, size = "-", -size
+	}
+	return fmt.Sprintf(
+		"%d added, %d modified, %d deleted, size %s%s",
+		c.added, c.modified, c.deleted, sign, formatSize(size))
+}
@@ -2,8 +2,10 @@ package regolith
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/Bedrock-OSS/go-burrito/burrito"
@@ -12,6 +14,10 @@ import (
 type DenoFilterDefinition struct {
 	FilterDefinition
 	Script string `json:"script,omitempty"`
+
+	// Permissions is the list of the additional permission flags of Deno
+	// (like "--allow-run=git") used for running the filter.
+	Permissions []string `json:"permissions,omitempty"`
 }
 
 type DenoFilter struct {
@@ -20,7 +26,7 @@ type DenoFilter struct {
 }
 
 func DenoFilterDefinitionFromObject(id string, obj map[string]interface{}) (*DenoFilterDefinition, error) {
-	filter := &DenoFilterDefinition{FilterDefinition: *FilterDefinitionFromObject(id)}
+	filter := &DenoFilterDefinition{FilterDefinition: *FilterDefinitionFromObject(id, obj)}
 	scriptObj, ok := obj["script"]
 	if !ok {
 		return nil, burrito.WrappedErrorf(jsonPropertyMissingError, "script")
@@ -31,43 +37,88 @@ func DenoFilterDefinitionFromObject(id string, obj map[string]interface{}) (*Den
 			jsonPropertyTypeError, "script", "string")
 	}
 	filter.Script = script
+	if permissionsObj, ok := obj["permissions"]; ok {
+		permissions, ok := permissionsObj.([]interface{})
+		if !ok {
+			return nil, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "permissions", "array")
+		}
+		for i, permissionObj := range permissions {
+			permission, ok := permissionObj.(string)
+			if !ok {
+				return nil, burrito.WrappedErrorf(
+					jsonPropertyTypeError, fmt.Sprintf("permissions->%d", i),
+					"string")
+			}
+			if !strings.HasPrefix(permission, "--allow-") {
+				return nil, burrito.WrappedErrorf(
+					"The permissions of the Deno filters must be Deno "+
+						"permission flags starting with \"--allow-\".\n"+
+						"Permission: %s", permission)
+			}
+			filter.Permissions = append(filter.Permissions, permission)
+		}
+	}
 	return filter, nil
 }
 
+// denoCacheDir is the path to the directory in the .regolith directory used
+// as the DENO_DIR of the Deno filters. Deno stores the downloaded
+// dependencies there.
+const denoCacheDir = "cache/deno"
+
+// denoEnv returns the environment variables used for running Deno, which
+// point the dependency cache of Deno to the .regolith directory.
+func denoEnv(dotRegolithPath string) ([]string, error) {
+	denoDir, err := filepath.Abs(filepath.Join(dotRegolithPath, denoCacheDir))
+	if err != nil {
+		return nil, burrito.WrapErrorf(err, filepathAbsError, denoDir)
+	}
+	return []string{fmt.Sprintf("DENO_DIR=%s", denoDir)}, nil
+}
+
+// hasDeno returns whether Deno is installed or not.
+func hasDeno() bool {
+	_, err := exec.LookPath("deno")
+	return err == nil
+}
+
 func (f *DenoFilter) run(context RunContext) error {
-	// Run filter
-	if len(f.Settings) == 0 {
-		err := RunSubProcess(
-			"deno",
-			append([]string{
-				"run", "--allow-all",
-				context.AbsoluteLocation + string(os.PathSeparator) +
-					f.Definition.Script},
-				f.Arguments...,
-			),
-			context.AbsoluteLocation,
-			GetAbsoluteWorkingDirectory(context.DotRegolithPath),
-			ShortFilterName(f.Id),
-		)
-		if err != nil {
-			return burrito.WrapError(err, runSubProcessError)
-		}
-	} else {
+	env, err := denoEnv(context.DotRegolithPath)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	// The filter can read the files of the filter and the tmp directory but
+	// can only write to the tmp directory. The network is allowed, because
+	// the filters commonly import their dependencies from URLs. Other
+	// permissions must be listed in the "permissions" of the filter.
+	workingDir := GetAbsoluteWorkingDirectory(context.DotRegolithPath)
+	args := []string{
+		"run",
+		"--allow-read=" + workingDir + "," + context.AbsoluteLocation,
+		"--allow-write=" + workingDir,
+		"--allow-env",
+		"--allow-net",
+	}
+	args = append(args, f.Definition.Permissions...)
+	args = append(args,
+		context.AbsoluteLocation+string(os.PathSeparator)+f.Definition.Script)
+	if len(f.Settings) != 0 {
 		jsonSettings, _ := json.Marshal(f.Settings)
-		err := RunSubProcess(
-			"deno",
-			append([]string{
-				"run",
-				context.AbsoluteLocation + string(os.PathSeparator) +
-					f.Definition.Script,
-				string(jsonSettings)}, f.Arguments...),
-			context.AbsoluteLocation,
-			GetAbsoluteWorkingDirectory(context.DotRegolithPath),
-			ShortFilterName(f.Id),
-		)
-		if err != nil {
-			return burrito.WrapError(err, runSubProcessError)
-		}
+		args = append(args, string(jsonSettings))
+	}
+	// Run filter
+	err = runSubProcessWithEnv(
+		&context,
+		"deno",
+		append(args, f.Arguments...),
+		context.AbsoluteLocation,
+		workingDir,
+		ShortFilterName(f.Id),
+		env,
+	)
+	if err != nil {
+		return burrito.WrapError(err, runSubProcessError)
 	}
 	return nil
 }
@@ -92,11 +143,8 @@ func (f *DenoFilterDefinition) CreateFilterRunner(runConfiguration map[string]in
 }
 
 func (f *DenoFilterDefinition) Check(context RunContext) error {
-	_, err := exec.LookPath("deno")
-	if err != nil {
-		return burrito.WrapError(
-			err, "Deno not found, download and install it from"+
-				" https://deno.land/")
+	if !hasDeno() {
+		return burrito.WrappedError(denoNotInstalledError)
 	}
 	cmd, err := exec.Command("deno", "--version").Output()
 	if err != nil {
@@ -107,9 +155,36 @@ func (f *DenoFilterDefinition) Check(context RunContext) error {
 	return nil
 }
 
+// InstallDependencies downloads the dependencies of the script into the
+// Deno cache in the .regolith directory, so that the filter doesn't have to
+// download them on its first run.
 func (f *DenoFilterDefinition) InstallDependencies(
 	parent *RemoteFilterDefinition, dotRegolithPath string,
 ) error {
+	if !hasDeno() {
+		Logger.Warn(denoNotInstalledError)
+		return nil
+	}
+	installLocation := f.installLocation(parent, dotRegolithPath)
+	joinedPath := filepath.Join(installLocation, f.Script)
+	scriptPath, err := filepath.Abs(joinedPath)
+	if err != nil {
+		return burrito.WrapErrorf(err, filepathAbsError, joinedPath)
+	}
+	env, err := denoEnv(dotRegolithPath)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	Logger.Infof("Downloading dependencies for %s...", f.Id)
+	err = runSubProcessWithEnv(
+		nil, "deno", []string{"cache", scriptPath}, filepath.Dir(scriptPath),
+		filepath.Dir(scriptPath), ShortFilterName(f.Id), env)
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, "Failed to run Deno and download dependencies."+
+				"\nFilter name: %s", f.Id)
+	}
+	Logger.Infof("Dependencies for %s installed successfully", f.Id)
 	return nil
 }
 
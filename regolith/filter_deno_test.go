@@ -0,0 +1,42 @@
+package regolith
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestDenoFilterPermissions checks whether the additional permissions of the
+// Deno filters are loaded from their definitions and whether the values
+// that are not Deno permission flags are rejected.
+func TestDenoFilterPermissions(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions interface{}
+		expected    []string
+		valid       bool
+	}{
+		{"no permissions", nil, nil, true},
+		{"permission flags",
+			[]interface{}{"--allow-run=git", "--allow-sys"},
+			[]string{"--allow-run=git", "--allow-sys"}, true},
+		{"not a list", "--allow-all", nil, false},
+		{"not a string", []interface{}{1.0}, nil, false},
+		{"not a permission flag", []interface{}{"--reload"}, nil, false},
+	}
+	for _, test := range tests {
+		obj := map[string]interface{}{"runWith": "deno", "script": "main.ts"}
+		if test.permissions != nil {
+			obj["permissions"] = test.permissions
+		}
+		filter, err := DenoFilterDefinitionFromObject("filter", obj)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(filter.Permissions, test.expected) {
+			t.Errorf(
+				"%s: unexpected permissions %v, expected %v",
+				test.name, filter.Permissions, test.expected)
+		}
+	}
+}
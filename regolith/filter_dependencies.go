@@ -0,0 +1,300 @@
+// Functions for resolving and installing the remote filters that the
+// installed remote filters depend on.
+package regolith
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// filterDependenciesKey is the property of the filter.json file with the
+// list of the remote filters required by the filter. The items of the list
+// use the same format as the arguments of the "regolith install" command.
+const filterDependenciesKey = "filterDependencies"
+
+// filterDependencyResolver installs the filters from the filter definitions
+// map together with the remote filters they depend on. The dependencies are
+// added to the filterDefinitions map, so the caller can save them in the
+// config and in the lock file.
+type filterDependencyResolver struct {
+	// filterDefinitions is the map of all of the filters to install. The
+	// definitions already on the map take precedence over the dependencies
+	// with the same name.
+	filterDefinitions map[string]FilterInstaller
+
+	// dependencies maps the names of the installed remote filters to the
+	// names of the filters they depend on.
+	dependencies map[string][]string
+
+	// installed is the set of the filters that are already installed.
+	installed map[string]struct{}
+
+	// refreshed and skipped are the names of the remote filters that were
+	// downloaded and the names of the remote filters that were already up
+	// to date.
+	refreshed, skipped []string
+
+	force, noSubmodules       bool
+	dataPath, dotRegolithPath string
+}
+
+func newFilterDependencyResolver(
+	filterDefinitions map[string]FilterInstaller, force, noSubmodules bool,
+	dataPath, dotRegolithPath string,
+) *filterDependencyResolver {
+	return &filterDependencyResolver{
+		filterDefinitions: filterDefinitions,
+		dependencies:      make(map[string][]string),
+		installed:         make(map[string]struct{}),
+		force:             force,
+		noSubmodules:      noSubmodules,
+		dataPath:          dataPath,
+		dotRegolithPath:   dotRegolithPath,
+	}
+}
+
+// install installs the filter and recursively installs the remote filters
+// it depends on.
+func (r *filterDependencyResolver) install(name string) error {
+	if _, ok := r.installed[name]; ok {
+		return nil
+	}
+	r.installed[name] = struct{}{}
+	filterDefinition := r.filterDefinitions[name]
+	Logger.Infof("Checking %q filter...", name)
+	remoteFilter, ok := filterDefinition.(*RemoteFilterDefinition)
+	if !ok {
+		// Non-remote filters must always update their dependencies.
+		// TODO - add option to track if the filter already installed
+		// its dependencies.
+		Logger.Infof("Installing %q filter dependencies...", name)
+		err := filterDefinition.InstallDependencies(nil, r.dotRegolithPath)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err,
+				"Failed to install dependencies of the filter.\nFilter: %s.",
+				name)
+		}
+		return nil
+	}
+	// Download the remote filter, and its dependencies
+	updated, err := remoteFilter.update(
+		r.force, r.noSubmodules, r.dotRegolithPath)
+	if err != nil {
+		return burrito.WrapErrorf(err, remoteFilterDownloadError, name)
+	}
+	if updated {
+		r.refreshed = append(r.refreshed, name)
+	} else {
+		r.skipped = append(r.skipped, name)
+	}
+	// Copy the data of the remote filter to the data path
+	remoteFilter.CopyFilterData(r.dataPath, r.dotRegolithPath)
+
+	// Install the remote filters required by the filter
+	dependencies, err := remoteFilter.filterDependencies(r.dotRegolithPath)
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, "Failed to resolve the filter dependencies.\nFilter: %s",
+			name)
+	}
+	for _, dependency := range dependencies {
+		r.dependencies[name] = append(r.dependencies[name], dependency.name)
+		existing, ok := r.filterDefinitions[dependency.name]
+		if ok {
+			err := checkDependencyConflict(name, dependency, existing)
+			if err != nil {
+				return burrito.PassError(err)
+			}
+		} else {
+			dependencyDefinition, err := FilterDefinitionFromTheInternet(
+				dependency.url, dependency.name, dependency.version)
+			if err != nil {
+				return burrito.WrapErrorf(
+					err, "Unable to resolve the filter dependency.\n"+
+						"Filter: %s\nDependency: %s", name, dependency.raw)
+			}
+			if dependency.version == "HEAD" || dependency.version == "latest" {
+				dependencyDefinition.Version = dependency.version
+			}
+			r.filterDefinitions[dependency.name] = dependencyDefinition
+		}
+		err = r.install(dependency.name)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+	}
+	return nil
+}
+
+// checkDependencyConflict checks if the dependency of the filter can be
+// satisfied by the filter definition that is already on the list. The
+// filters with the same name but from different repositories are a
+// conflict. If only the versions are different, the version of the existing
+// definition is used.
+func checkDependencyConflict(
+	name string, dependency *parsedInstallFilterArg, existing FilterInstaller,
+) error {
+	remoteFilter, ok := existing.(*RemoteFilterDefinition)
+	if !ok {
+		return burrito.WrappedErrorf(
+			"The filter depends on a remote filter, but the filter with the "+
+				"same name is not a remote filter.\n"+
+				"Filter: %s\nDependency: %s", name, dependency.raw)
+	}
+	if remoteFilter.isLocalRegistry() {
+		return nil
+	}
+	if remoteFilter.Url != dependency.url {
+		return burrito.WrappedErrorf(
+			"The filter depends on a filter from a different repository than "+
+				"the installed filter with the same name.\n"+
+				"Filter: %s\nDependency: %s\nInstalled filter URL: %s",
+			name, dependency.raw, remoteFilter.Url)
+	}
+	if dependency.version != "" && dependency.version != remoteFilter.Version {
+		Logger.Warnf(
+			"Filter %q requires version %q of %q filter. Using version %q.",
+			name, dependency.version, dependency.name, remoteFilter.Version)
+	}
+	return nil
+}
+
+// findCycle returns the names of the filters that form a circular
+// dependency, starting and ending with the same filter, or nil if there are
+// no cycles.
+func (r *filterDependencyResolver) findCycle() []string {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int)
+	var stack []string
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			for i, stackName := range stack {
+				if stackName == name {
+					return append(append([]string{}, stack[i:]...), name)
+				}
+			}
+		}
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, dependency := range r.dependencies[name] {
+			if cycle := visit(dependency); cycle != nil {
+				return cycle
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+		return nil
+	}
+	names := make([]string, 0, len(r.dependencies))
+	for name := range r.dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if cycle := visit(name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// checkCycles returns an error if the resolved filters have a circular
+// dependency.
+func (r *filterDependencyResolver) checkCycles() error {
+	if cycle := r.findCycle(); cycle != nil {
+		return burrito.WrappedErrorf(
+			"Circular dependency between the filters: %s",
+			strings.Join(cycle, " -> "))
+	}
+	return nil
+}
+
+// logSummary logs the names of the remote filters that were downloaded and
+// the names of the remote filters that were skipped because they were
+// already up to date.
+func (r *filterDependencyResolver) logSummary() {
+	if len(r.skipped) > 0 {
+		sort.Strings(r.skipped)
+		Logger.Infof(
+			"Skipped %d unchanged filters: %s", len(r.skipped),
+			strings.Join(r.skipped, ", "))
+	}
+	if len(r.refreshed) > 0 {
+		sort.Strings(r.refreshed)
+		Logger.Infof(
+			"Refreshed %d filters: %s", len(r.refreshed),
+			strings.Join(r.refreshed, ", "))
+	}
+}
+
+// logTree logs the tree of the dependencies of the filters from the roots
+// list at the debug level. Nothing is logged if none of the filters has
+// dependencies.
+func (r *filterDependencyResolver) logTree(roots []string) {
+	if len(r.dependencies) == 0 {
+		return
+	}
+	var builder strings.Builder
+	var write func(name string, depth int)
+	write = func(name string, depth int) {
+		builder.WriteString(fmt.Sprintf(
+			"\n%s%s", strings.Repeat("  ", depth), name))
+		for _, dependency := range r.dependencies[name] {
+			write(dependency, depth+1)
+		}
+	}
+	for _, root := range roots {
+		write(root, 0)
+	}
+	Logger.Debugf("Resolved filter dependencies:%s", builder.String())
+}
+
+// filterDependencies returns the list of the remote filters from the
+// "filterDependencies" property of the filter.json file of the installed
+// filter.
+func (f *RemoteFilterDefinition) filterDependencies(
+	dotRegolithPath string,
+) ([]*parsedInstallFilterArg, error) {
+	filterJson, err := f.LoadFilterJson(dotRegolithPath)
+	if err != nil {
+		return nil, burrito.WrapErrorf(
+			err, "Could not load filter.json for %q filter.", f.Id)
+	}
+	dependenciesObj, ok := filterJson[filterDependenciesKey]
+	if !ok {
+		return nil, nil
+	}
+	dependencies, ok := dependenciesObj.([]interface{})
+	if !ok {
+		return nil, burrito.WrappedErrorf(
+			jsonPathTypeError, filterDependenciesKey, "array")
+	}
+	if len(dependencies) == 0 {
+		return nil, nil
+	}
+	args := make([]string, len(dependencies))
+	for i, dependency := range dependencies {
+		args[i], ok = dependency.(string)
+		if !ok {
+			return nil, burrito.WrappedErrorf(
+				jsonPathTypeError,
+				fmt.Sprintf("%s->%d", filterDependenciesKey, i), "string")
+		}
+	}
+	result, err := parseInstallFilterArgs(args)
+	if err != nil {
+		return nil, burrito.PassError(err)
+	}
+	return result, nil
+}
@@ -0,0 +1,114 @@
+package regolith
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestFilterDependencyCycles checks whether the circular dependencies
+// between the filters are found, starting from the first filter in the
+// alphabetical order, and whether they're reported with an error.
+func TestFilterDependencyCycles(t *testing.T) {
+	InitLogging(false)
+	tests := []struct {
+		name         string
+		dependencies map[string][]string
+		cycle        []string
+	}{
+		{"no dependencies", map[string][]string{}, nil},
+		{
+			"shared dependency",
+			map[string][]string{"a": {"b", "c"}, "b": {"c"}, "c": {}},
+			nil,
+		},
+		{
+			"self dependency",
+			map[string][]string{"a": {"a"}},
+			[]string{"a", "a"},
+		},
+		{
+			"cycle",
+			map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"a"}},
+			[]string{"a", "b", "c", "a"},
+		},
+		{
+			"cycle after a dependency",
+			map[string][]string{
+				"a": {"b"}, "b": {"c"}, "c": {"d"}, "d": {"c"}},
+			[]string{"c", "d", "c"},
+		},
+	}
+	for _, test := range tests {
+		resolver := &filterDependencyResolver{dependencies: test.dependencies}
+		cycle := resolver.findCycle()
+		if !reflect.DeepEqual(cycle, test.cycle) {
+			t.Errorf(
+				"%s: unexpected cycle.\nExpected: %v\nActual: %v",
+				test.name, test.cycle, cycle)
+		}
+		err := resolver.checkCycles()
+		if test.cycle == nil {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %s", test.name, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: the circular dependency wasn't reported", test.name)
+		} else if !strings.Contains(
+			err.Error(), strings.Join(test.cycle, " -> ")) {
+			t.Errorf(
+				"%s: the error doesn't list the cycle: %s", test.name, err)
+		}
+	}
+}
+
+// TestFilterDependencyResolverSkipped checks whether the remote filters
+// that are already installed in the pinned versions are skipped, together
+// with the up to date filters they depend on.
+func TestFilterDependencyResolverSkipped(t *testing.T) {
+	InitLogging(false)
+	dotRegolithPath := t.TempDir()
+	writeTestFilterJson(t, dotRegolithPath, "tagged", map[string]interface{}{
+		"filters": []interface{}{}, "version": "1.2.3",
+		filterDependenciesKey: []interface{}{
+			"github.com/owner/repo/pinned==" + testInstalledSha}})
+	writeTestFilterJson(t, dotRegolithPath, "pinned", map[string]interface{}{
+		"filters": []interface{}{}, "version": "1.0.0",
+		installedShaKey: testInstalledSha})
+	filterDefinitions := map[string]FilterInstaller{
+		"tagged": &RemoteFilterDefinition{
+			FilterDefinition: FilterDefinition{Id: "tagged"},
+			Url:              "github.com/owner/repo",
+			Version:          "1.2.3",
+		},
+		"pinned": &RemoteFilterDefinition{
+			FilterDefinition: FilterDefinition{Id: "pinned"},
+			Url:              "github.com/owner/repo",
+			Version:          testInstalledSha,
+		},
+	}
+	resolver := newFilterDependencyResolver(
+		filterDefinitions, false, false, t.TempDir(), dotRegolithPath)
+	for _, name := range []string{"pinned", "tagged"} {
+		if err := resolver.install(name); err != nil {
+			t.Fatalf("Failed to install %q: %s", name, err)
+		}
+	}
+	if expected := []string{"pinned", "tagged"}; !reflect.DeepEqual(
+		resolver.skipped, expected) {
+		t.Errorf(
+			"Unexpected skipped filters.\nExpected: %v\nActual: %v",
+			expected, resolver.skipped)
+	}
+	if len(resolver.refreshed) != 0 {
+		t.Errorf("Unexpected refreshed filters: %v", resolver.refreshed)
+	}
+	expected := map[string][]string{"tagged": {"pinned"}}
+	if !reflect.DeepEqual(resolver.dependencies, expected) {
+		t.Errorf(
+			"Unexpected dependencies.\nExpected: %v\nActual: %v",
+			expected, resolver.dependencies)
+	}
+}
@@ -0,0 +1,231 @@
+package regolith
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// The paths inside of the container where the directories used by the
+// filter are mounted.
+const (
+	dockerWorkingDir = "/regolith/tmp"
+	dockerFilterDir  = "/regolith/filter"
+	dockerProjectDir = "/regolith/project"
+)
+
+type DockerFilterDefinition struct {
+	FilterDefinition
+	// Image is the name of the Docker image used to run the filter.
+	Image string `json:"image,omitempty"`
+
+	// Command is the shell command run inside of the container. If it's
+	// empty, the container runs the default command of the image.
+	Command string `json:"command,omitempty"`
+}
+
+type DockerFilter struct {
+	Filter
+	Definition DockerFilterDefinition `json:"definition,omitempty"`
+}
+
+func DockerFilterDefinitionFromObject(
+	id string, obj map[string]interface{},
+) (*DockerFilterDefinition, error) {
+	filter := &DockerFilterDefinition{
+		FilterDefinition: *FilterDefinitionFromObject(id, obj)}
+	imageObj, ok := obj["image"]
+	if !ok {
+		return nil, burrito.WrappedErrorf(jsonPropertyMissingError, "image")
+	}
+	image, ok := imageObj.(string)
+	if !ok || image == "" {
+		return nil, burrito.WrappedErrorf(
+			jsonPropertyTypeError, "image", "non-empty string")
+	}
+	filter.Image = image
+	if commandObj, ok := obj["command"]; ok {
+		command, ok := commandObj.(string)
+		if !ok {
+			return nil, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "command", "string")
+		}
+		filter.Command = command
+	}
+	return filter, nil
+}
+
+func (f *DockerFilter) Run(context RunContext) (bool, error) {
+	if err := f.run(f.Settings, context); err != nil {
+		return false, burrito.PassError(err)
+	}
+	return context.IsInterrupted(), nil
+}
+
+func (f *DockerFilterDefinition) CreateFilterRunner(
+	runConfiguration map[string]interface{},
+) (FilterRunner, error) {
+	basicFilter, err := filterFromObject(runConfiguration)
+	if err != nil {
+		return nil, burrito.WrapError(err, filterFromObjectError)
+	}
+	filter := &DockerFilter{
+		Filter:     *basicFilter,
+		Definition: *f,
+	}
+	return filter, nil
+}
+
+func (f *DockerFilterDefinition) InstallDependencies(
+	*RemoteFilterDefinition, string,
+) error {
+	return nil
+}
+
+func (f *DockerFilterDefinition) Check(context RunContext) error {
+	_, err := exec.LookPath("docker")
+	if err != nil {
+		return burrito.WrapError(
+			err,
+			"Docker not found, download and install it from"+
+				" https://docs.docker.com/get-docker/")
+	}
+	// The client can be installed without a running daemon
+	cmd, err := exec.Command(
+		"docker", "version", "--format", "{{.Server.Version}}").Output()
+	if err != nil {
+		return burrito.WrapError(
+			err, "Failed to connect to the Docker daemon. Is it running?")
+	}
+	Logger.Debugf(
+		"Found Docker version %s.", strings.Trim(string(cmd), " \n\t"))
+	return nil
+}
+
+func (f *DockerFilter) Check(context RunContext) error {
+	return f.Definition.Check(context)
+}
+
+func (f *DockerFilter) run(
+	settings map[string]interface{},
+	context RunContext,
+) error {
+	args, err := f.dockerRunArgs(settings, context)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	// The memory of the container is limited with the "--memory" flag,
+	// the docker command itself doesn't need the limit
+	dockerContext := context
+	dockerContext.maxMemory = 0
+	err = RunSubProcess(
+		&dockerContext, "docker", args, context.AbsoluteLocation,
+		GetAbsoluteWorkingDirectory(context.DotRegolithPath),
+		ShortFilterName(f.Id))
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return burrito.WrapErrorf(
+				err, "The Docker container of the filter failed.\n"+
+					"Image: %s\nExit code: %d",
+				f.Definition.Image, exitErr.ExitCode())
+		}
+		return burrito.WrapErrorf(
+			err, "Failed to run the Docker container of the filter.\n"+
+				"Image: %s", f.Definition.Image)
+	}
+	return nil
+}
+
+// DryRun prints the "docker run" command that would run the filter.
+func (f *DockerFilter) DryRun(context RunContext) error {
+	args, err := f.dockerRunArgs(f.Settings, context)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	printDryRunFilter(
+		f, context, strings.Join(append([]string{"docker"}, args...), " "))
+	return nil
+}
+
+// dockerRunArgs returns the arguments of the "docker run" command that runs
+// the filter. The tmp directory is mounted as the working directory of the
+// container, and the directory of the filter and the project are mounted
+// read-only. The FILTER_DIR and ROOT_DIR environment variables point to the
+// paths inside of the container. On Linux and macOS, the container runs as
+// the current user, so the files created by the filter don't belong to root.
+func (f *DockerFilter) dockerRunArgs(
+	settings map[string]interface{}, context RunContext,
+) ([]string, error) {
+	projectDir, err := context.Config.projectDir()
+	if err != nil {
+		return nil, burrito.PassError(err)
+	}
+	args := []string{
+		"run", "--rm",
+		"-v", GetAbsoluteWorkingDirectory(context.DotRegolithPath) + ":" +
+			dockerWorkingDir,
+		"-v", context.AbsoluteLocation + ":" + dockerFilterDir + ":ro",
+		"-v", projectDir + ":" + dockerProjectDir + ":ro",
+		"-w", dockerWorkingDir,
+		"-e", "FILTER_DIR=" + dockerFilterDir,
+		"-e", "ROOT_DIR=" + dockerProjectDir,
+	}
+	if runtime.GOOS != "windows" {
+		args = append(
+			args, "--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()))
+	}
+	// The limit of "--max-memory" must be applied to the container, the
+	// docker command only starts it
+	if context.maxMemory > 0 {
+		args = append(
+			args, "--memory", strconv.FormatUint(context.maxMemory, 10))
+	}
+	// The variables are copied from the environment of the docker command,
+	// which is created the same way as for the other filters
+	envNames, err := dockerEnvNames(&context, projectDir)
+	if err != nil {
+		return nil, burrito.PassError(err)
+	}
+	for _, name := range envNames {
+		args = append(args, "-e", name)
+	}
+	args = append(args, f.Definition.Image)
+	var filterArgs []string
+	if len(settings) != 0 {
+		jsonSettings, _ := json.Marshal(settings)
+		filterArgs = append(filterArgs, string(jsonSettings))
+	}
+	filterArgs = append(filterArgs, f.Arguments...)
+	if f.Definition.Command != "" {
+		// The settings and the arguments are passed to the command as the
+		// positional parameters of the shell, so they don't need escaping
+		args = append(
+			args, "sh", "-c", f.Definition.Command+` "$@"`, "sh")
+	}
+	return append(args, filterArgs...), nil
+}
+
+// dockerEnvNames returns the names of the environment variables of the
+// filter passed to the container: the variables from the .env file and the
+// variables set by Regolith. The other variables of the host, like PATH,
+// would break the environment of the image.
+func dockerEnvNames(context *RunContext, projectDir string) ([]string, error) {
+	dotEnv, err := loadContextDotEnv(context, projectDir)
+	if err != nil {
+		return nil, burrito.PassError(err)
+	}
+	// The variables that are not set are skipped by Docker
+	names := []string{
+		"DEBUG", "REGOLITH_PROJECT_NAME", "REGOLITH_PROJECT_AUTHOR",
+		changedFilesEnvVar}
+	for _, variable := range dotEnv {
+		names = append(names, strings.SplitN(variable, "=", 2)[0])
+	}
+	return names, nil
+}
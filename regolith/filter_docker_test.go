@@ -0,0 +1,112 @@
+package regolith
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestDockerFilterDefinitionFromObject checks whether the "image" property
+// is required and the "command" property is optional.
+func TestDockerFilterDefinitionFromObject(t *testing.T) {
+	definition, err := DockerFilterDefinitionFromObject(
+		"filter", map[string]interface{}{
+			"runWith": "docker", "image": "python:3.12",
+			"command": "python main.py"})
+	if err != nil {
+		t.Fatal("Failed to parse the filter definition:", err)
+	}
+	if definition.Image != "python:3.12" ||
+		definition.Command != "python main.py" {
+		t.Errorf("Unexpected filter definition: %+v", definition)
+	}
+	for name, obj := range map[string]map[string]interface{}{
+		"missing image":   {"runWith": "docker"},
+		"empty image":     {"runWith": "docker", "image": ""},
+		"invalid image":   {"runWith": "docker", "image": 1.0},
+		"invalid command": {"runWith": "docker", "image": "python", "command": []interface{}{"python"}},
+	} {
+		if _, err := DockerFilterDefinitionFromObject("filter", obj); err == nil {
+			t.Errorf("%s: the filter definition wasn't rejected", name)
+		}
+	}
+}
+
+// TestDockerRunArgs checks the arguments of the "docker run" command: the
+// mounted directories, the memory limit, the passed environment variables
+// and the settings and the arguments of the filter.
+func TestDockerRunArgs(t *testing.T) {
+	InitLogging(false)
+	projectDir := t.TempDir()
+	dotEnvPath := filepath.Join(projectDir, ".env")
+	if err := os.WriteFile(dotEnvPath, []byte("API_KEY=secret-value\n"), 0644); err != nil {
+		t.Fatal("Failed to create the .env file:", err)
+	}
+	config := &Config{}
+	config.setProjectRoot(projectDir)
+	context := RunContext{
+		AbsoluteLocation: filepath.Join(projectDir, "filters", "docker"),
+		Config:           config,
+		DotRegolithPath:  filepath.Join(projectDir, ".regolith"),
+		Options:          RunOptions{EnvFile: ".env"},
+		maxMemory:        1024,
+	}
+	filter := &DockerFilter{
+		Filter: Filter{
+			Id:        "docker",
+			Arguments: []string{"--verbose"},
+			Settings:  map[string]interface{}{"scale": 2.0},
+		},
+		Definition: DockerFilterDefinition{
+			Image: "python:3.12", Command: "python main.py"},
+	}
+	args, err := filter.dockerRunArgs(filter.Settings, context)
+	if err != nil {
+		t.Fatal("Failed to create the arguments:", err)
+	}
+	joined := strings.Join(args, " ")
+	for _, expected := range []string{
+		"run --rm -v " + GetAbsoluteWorkingDirectory(context.DotRegolithPath) +
+			":" + dockerWorkingDir,
+		"-v " + context.AbsoluteLocation + ":" + dockerFilterDir + ":ro",
+		"-v " + projectDir + ":" + dockerProjectDir + ":ro",
+		"-w " + dockerWorkingDir,
+		"-e FILTER_DIR=" + dockerFilterDir,
+		"-e ROOT_DIR=" + dockerProjectDir,
+		"--memory 1024",
+		"-e API_KEY",
+		"-e " + changedFilesEnvVar,
+	} {
+		if !strings.Contains(joined, expected) {
+			t.Errorf("The arguments don't contain %q:\n%s", expected, joined)
+		}
+	}
+	if strings.Contains(joined, "secret-value") {
+		t.Errorf("The value of the variable was passed as argument:\n%s", joined)
+	}
+	// The image is followed by the command and its positional parameters
+	expectedEnd := []string{
+		"python:3.12", "sh", "-c", `python main.py "$@"`, "sh",
+		`{"scale":2}`, "--verbose"}
+	if len(args) < len(expectedEnd) ||
+		!reflect.DeepEqual(args[len(args)-len(expectedEnd):], expectedEnd) {
+		t.Errorf("Unexpected end of the arguments:\n%q", args)
+	}
+	// Without the command and the settings, the default command of the
+	// image gets only the arguments
+	filter.Definition.Command = ""
+	context.maxMemory = 0
+	args, err = filter.dockerRunArgs(nil, context)
+	if err != nil {
+		t.Fatal("Failed to create the arguments:", err)
+	}
+	expectedEnd = []string{"python:3.12", "--verbose"}
+	if !reflect.DeepEqual(args[len(args)-len(expectedEnd):], expectedEnd) {
+		t.Errorf("Unexpected end of the arguments:\n%q", args)
+	}
+	if strings.Contains(strings.Join(args, " "), "--memory") {
+		t.Errorf("The memory was limited without the limit:\n%q", args)
+	}
+}
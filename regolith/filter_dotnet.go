@@ -19,7 +19,7 @@ type DotNetFilter struct {
 }
 
 func DotNetFilterDefinitionFromObject(id string, obj map[string]interface{}) (*DotNetFilterDefinition, error) {
-	filter := &DotNetFilterDefinition{FilterDefinition: *FilterDefinitionFromObject(id)}
+	filter := &DotNetFilterDefinition{FilterDefinition: *FilterDefinitionFromObject(id, obj)}
 	pathObj, ok := obj["path"]
 	if !ok {
 		return nil, burrito.WrappedErrorf(jsonPropertyMissingError, "path")
@@ -42,6 +42,7 @@ func (f *DotNetFilter) run(context RunContext) error {
 	// Run the filter
 	if len(f.Settings) == 0 {
 		err := RunSubProcess(
+			&context,
 			"dotnet",
 			append(
 				[]string{
@@ -60,6 +61,7 @@ func (f *DotNetFilter) run(context RunContext) error {
 	} else {
 		jsonSettings, _ := json.Marshal(f.Settings)
 		err := RunSubProcess(
+			&context,
 			"dotnet",
 			append(
 				[]string{
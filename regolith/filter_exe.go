@@ -3,6 +3,7 @@ package regolith
 import (
 	"encoding/json"
 	"path/filepath"
+	"strings"
 
 	"github.com/Bedrock-OSS/go-burrito/burrito"
 )
@@ -21,7 +22,7 @@ func ExeFilterDefinitionFromObject(
 	id string, obj map[string]interface{},
 ) (*ExeFilterDefinition, error) {
 	filter := &ExeFilterDefinition{
-		FilterDefinition: *FilterDefinitionFromObject(id)}
+		FilterDefinition: *FilterDefinitionFromObject(id, obj)}
 	exeObj, ok := obj["exe"]
 	if !ok {
 		return nil, burrito.WrappedErrorf(jsonPropertyMissingError, "exe")
@@ -77,13 +78,13 @@ func (f *ExeFilter) run(
 ) error {
 	var err error = nil
 	if len(settings) == 0 {
-		err = executeExeFile(f.Id,
+		err = executeExeFile(&context, f.Id,
 			f.Definition.Exe,
 			f.Arguments, context.AbsoluteLocation,
 			GetAbsoluteWorkingDirectory(context.DotRegolithPath))
 	} else {
 		jsonSettings, _ := json.Marshal(settings)
-		err = executeExeFile(f.Id,
+		err = executeExeFile(&context, f.Id,
 			f.Definition.Exe,
 			append([]string{string(jsonSettings)}, f.Arguments...),
 			context.AbsoluteLocation, GetAbsoluteWorkingDirectory(
@@ -96,12 +97,26 @@ func (f *ExeFilter) run(
 	return nil
 }
 
-func executeExeFile(id string,
+// DryRun prints the path to the executable of the filter with the arguments
+// that would be passed to it.
+func (f *ExeFilter) DryRun(context RunContext) error {
+	command := []string{
+		filepath.Join(context.AbsoluteLocation, f.Definition.Exe)}
+	if len(f.Settings) != 0 {
+		jsonSettings, _ := json.Marshal(f.Settings)
+		command = append(command, string(jsonSettings))
+	}
+	command = append(command, f.Arguments...)
+	printDryRunFilter(f, context, strings.Join(command, " "))
+	return nil
+}
+
+func executeExeFile(context *RunContext, id string,
 	exe string, args []string, filterDir string, workingDir string,
 ) error {
 	exe = filepath.Join(filterDir, exe)
-	Logger.Debugf("Running exe file %s:", exe)
-	err := RunSubProcess(exe, args, filterDir, workingDir, id)
+	context.logger().Debugf("Running exe file %s:", exe)
+	err := RunSubProcess(context, exe, args, filterDir, workingDir, id)
 	if err != nil {
 		return burrito.WrapErrorf(err, runSubProcessError)
 	}
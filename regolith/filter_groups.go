@@ -0,0 +1,170 @@
+// Functions used for handling the filter groups - named sets of filter
+// definitions that are installed and updated together.
+package regolith
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// filterGroupsFromObject creates a map of the names of the filter groups to
+// the names of their filters from the value of the "filterGroups" property
+// of the config. Every filter of a group must be on the filterDefinitions
+// list and the names of the groups can't be used by the filter definitions.
+func filterGroupsFromObject(
+	obj interface{}, filterDefinitions map[string]interface{},
+) (map[string][]string, error) {
+	groups, ok := obj.(map[string]interface{})
+	if !ok {
+		return nil, burrito.WrappedErrorf(
+			jsonPropertyTypeError, "filterGroups", "object")
+	}
+	result := make(map[string][]string, len(groups))
+	for groupName, group := range groups {
+		if _, ok := filterDefinitions[groupName]; ok {
+			return nil, burrito.WrappedErrorf(
+				"The name of the filter group is already used by a filter "+
+					"definition.\nGroup: %s", groupName)
+		}
+		filters, ok := group.([]interface{})
+		if !ok {
+			return nil, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "filterGroups->"+groupName, "array")
+		}
+		names := make([]string, 0, len(filters))
+		for i, filter := range filters {
+			name, ok := filter.(string)
+			if !ok {
+				return nil, burrito.WrappedErrorf(
+					jsonPropertyTypeError,
+					fmt.Sprintf("filterGroups->%s->%d", groupName, i), "string")
+			}
+			if _, ok := filterDefinitions[name]; !ok {
+				return nil, burrito.WrappedErrorf(
+					"The filter group uses a filter that is not on the "+
+						"filter definitions list.\nGroup: %s\nFilter: %s",
+					groupName, name)
+			}
+			names = append(names, name)
+		}
+		result[groupName] = names
+	}
+	return result, nil
+}
+
+// expandFilterGroups replaces the names of the filter groups on the names
+// list with the names of the filters of these groups. The other names are
+// left unchanged. The second returned value is true if any of the names was
+// a name of a group.
+func expandFilterGroups(
+	names []string, filterGroups map[string][]string,
+) ([]string, bool) {
+	result := make([]string, 0, len(names))
+	usesGroups := false
+	for _, name := range names {
+		if group, ok := filterGroups[name]; ok {
+			Logger.Infof("Selected filter group %q: %v", name, group)
+			result = append(result, group...)
+			usesGroups = true
+		} else {
+			result = append(result, name)
+		}
+	}
+	return result, usesGroups
+}
+
+// installFiltersAtomically works like installFilters, but if installing any
+// of the filters fails, the previously installed versions of all of the
+// remote filters are restored, so that the filters are never left at
+// mismatched versions. The remote filters are always downloaded again.
+func installFiltersAtomically(
+	filterDefinitions map[string]FilterInstaller, force, noSubmodules bool,
+	dataPath, dotRegolithPath string,
+) error {
+	backupPath := filepath.Join(dotRegolithPath, ".filterBackup")
+	revertibleOps, err := NewRevertibleFsOperations(backupPath)
+	if err != nil {
+		return burrito.WrapErrorf(err, newRevertibleFsOperationsError, backupPath)
+	}
+	downloadPaths := []string{}
+	// rollback removes the new versions of the filters and restores the
+	// old ones
+	rollback := func(mainError error) error {
+		Logger.Warn("Restoring the previous versions of the filters...")
+		for _, downloadPath := range downloadPaths {
+			if err := os.RemoveAll(downloadPath); err != nil {
+				return burrito.PassErrorHandlerError(
+					mainError, burrito.WrapErrorf(
+						err, osRemoveError, downloadPath),
+					errorConnector)
+			}
+		}
+		if handlerError := revertibleOps.Undo(); handlerError != nil {
+			return burrito.WrapErrorHandlerError(
+				mainError, handlerError, errorConnector, fsUndoError)
+		}
+		if handlerError := revertibleOps.Close(); handlerError != nil {
+			return burrito.PassErrorHandlerError(
+				mainError, handlerError, errorConnector)
+		}
+		return mainError
+	}
+	// Move the installed remote filters to the backup directory
+	for _, filterDefinition := range filterDefinitions {
+		remoteFilter, ok := filterDefinition.(*RemoteFilterDefinition)
+		// The filters from the local registry are only linked, backing them
+		// up would move the files from the local path
+		if !ok || remoteFilter.isLocalRegistry() {
+			continue
+		}
+		downloadPath := remoteFilter.GetDownloadPath(dotRegolithPath)
+		if _, err := os.Stat(downloadPath); os.IsNotExist(err) {
+			continue
+		}
+		downloadPaths = append(downloadPaths, downloadPath)
+		err = revertibleOps.DeleteDir(downloadPath)
+		if err != nil {
+			return rollback(burrito.WrapErrorf(
+				err, "Failed to back up the installed filter.\nFilter: %s",
+				remoteFilter.Id))
+		}
+	}
+	err = installFilters(
+		filterDefinitions, force, noSubmodules, dataPath, dotRegolithPath)
+	if err != nil {
+		return rollback(burrito.PassError(err))
+	}
+	if err := revertibleOps.Close(); err != nil {
+		return burrito.PassError(err)
+	}
+	return nil
+}
+
+// installFilterGroups installs the filters of the filter groups atomically,
+// using the versions from the filter definitions of the config file, and
+// updates the lock file from the lockFilePath (unless noConfigWrite is true).
+func installFilterGroups(
+	filterDefinitions map[string]FilterInstaller,
+	force, noConfigWrite, noSubmodules bool,
+	dataPath, lockFilePath, dotRegolithPath string,
+) error {
+	err := installFiltersAtomically(
+		filterDefinitions, force, noSubmodules, dataPath, dotRegolithPath)
+	if err != nil {
+		return burrito.WrapError(err, "Failed to install the filter groups.")
+	}
+	if noConfigWrite {
+		return nil
+	}
+	err = updateLockFile(
+		filterDefinitions, false, lockFilePath, dotRegolithPath)
+	if err != nil {
+		return burrito.WrapError(
+			err, "Successfully installed the filter groups but failed to "+
+				"update the lock file.")
+	}
+	return nil
+}
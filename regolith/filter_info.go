@@ -0,0 +1,265 @@
+package regolith
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// remoteFilterInfo describes a remote filter before installing it. It's the
+// output of the "regolith filter-info" command.
+type remoteFilterInfo struct {
+	// Filter is the name of the filter.
+	Filter string `json:"filter"`
+
+	// Url is the URL of the repository of the filter.
+	Url string `json:"url"`
+
+	// Version is the version of the filter that was inspected.
+	Version string `json:"version"`
+
+	// Description is the "description" property from the "filter.json"
+	// file of the filter.
+	Description string `json:"description,omitempty"`
+
+	// Versions is the list of the versions of the filter available in its
+	// repository, sorted from the oldest to the newest.
+	Versions []string `json:"versions"`
+
+	// Runtimes is the list of the "runWith" values of the subfilters.
+	Runtimes []string `json:"runtimes"`
+
+	// Dependencies is the list of the packages required by the subfilters
+	// (from the requirements.txt and package.json files).
+	Dependencies []string `json:"dependencies"`
+
+	// ExportData is the "exportData" property from the "filter.json" file.
+	ExportData bool `json:"exportData"`
+}
+
+// fetchRemoteFilterInfo downloads the filter to a temporary directory and
+// returns the information about it. It's the read-only variant of
+// FilterDefinitionFromTheInternet, which doesn't modify the project or the
+// filter cache. The temporary directory is removed afterwards.
+func fetchRemoteFilterInfo(
+	url, name, version string,
+) (*remoteFilterInfo, error) {
+	if !hasGit() {
+		return nil, burrito.WrappedError(gitNotInstalledWarning)
+	}
+	filterDefinition, err := FilterDefinitionFromTheInternet(url, name, version)
+	if err != nil {
+		return nil, burrito.WrapError(
+			err, "Failed to get the filter definition.")
+	}
+	result := &remoteFilterInfo{
+		Filter:       name,
+		Url:          url,
+		Version:      filterDefinition.Version,
+		Versions:     []string{},
+		Runtimes:     []string{},
+		Dependencies: []string{},
+	}
+	tags, err := ListRemoteFilterTags(url, name)
+	if err != nil {
+		return nil, burrito.WrapErrorf(
+			err, "Failed to list the versions of the filter.\nURL: %s", url)
+	}
+	for _, tag := range tags {
+		result.Versions = append(result.Versions, trimFilterPrefix(tag, name))
+	}
+	ref, err := GetRemoteFilterDownloadRef(url, name, filterDefinition.Version)
+	if err != nil {
+		return nil, burrito.WrapErrorf(
+			err, getRemoteFilterDownloadRefError, url, name,
+			filterDefinition.Version)
+	}
+	tmpDir, err := os.MkdirTemp("", "regolith-filter-info")
+	if err != nil {
+		return nil, burrito.WrapError(
+			err, "Failed to create a temporary directory.")
+	}
+	defer os.RemoveAll(tmpDir)
+	filterPath := filepath.Join(tmpDir, name)
+	getterUrl := filterGetterUrl(url, name, ref)
+	err = retryNetworkOperation("download filter "+name, func() error {
+		_, err := getRemoteFilter(filterPath, getterUrl, false)
+		return err
+	})
+	if err != nil {
+		return nil, burrito.WrapErrorf(
+			err, "Could not download filter from %s.\n"+
+				"Does that filter exist?", getterUrl)
+	}
+	err = result.readFilterJson(filterPath)
+	if err != nil {
+		return nil, burrito.PassError(err)
+	}
+	return result, nil
+}
+
+// readFilterJson fills the information from the "filter.json" file and the
+// dependency files of the filter downloaded to the filterPath.
+func (i *remoteFilterInfo) readFilterJson(filterPath string) error {
+	path := filepath.Join(filterPath, "filter.json")
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return burrito.WrappedErrorf(readFilterJsonError, path)
+	}
+	var filterJson map[string]interface{}
+	err = json.Unmarshal(file, &filterJson)
+	if err != nil {
+		return burrito.WrapErrorf(err, jsonUnmarshalError, path)
+	}
+	i.Description, _ = filterJson["description"].(string)
+	i.ExportData, _ = filterJson["exportData"].(bool)
+	filters, ok := filterJson["filters"].([]interface{})
+	if !ok {
+		return extraFilterJsonErrorInfo(
+			path, burrito.WrappedErrorf(jsonPathTypeError, "filters", "array"))
+	}
+	runtimes := make(map[string]struct{})
+	for j, filter := range filters {
+		filter, ok := filter.(map[string]interface{})
+		jsonPath := fmt.Sprintf("filters->%d", j) // Used for error messages
+		if !ok {
+			return extraFilterJsonErrorInfo(
+				path, burrito.WrappedErrorf(jsonPathTypeError, jsonPath, "object"))
+		}
+		runWith, _ := filter["runWith"].(string)
+		if _, ok := runtimes[runWith]; !ok && runWith != "" {
+			runtimes[runWith] = struct{}{}
+			i.Runtimes = append(i.Runtimes, runWith)
+		}
+		filterInstaller, err := FilterInstallerFromObject(
+			fmt.Sprintf("%v:subfilter%v", i.Filter, j), filter)
+		if err != nil {
+			return extraFilterJsonErrorInfo(
+				path, burrito.WrapErrorf(err, jsonPathParseError, jsonPath))
+		}
+		dependencies, err := subfilterDependencies(filterPath, filterInstaller)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+		i.Dependencies = append(i.Dependencies, dependencies...)
+	}
+	return nil
+}
+
+// subfilterDependencies returns the packages from the requirements.txt file
+// of the Python subfilters and from the package.json file of the Node.js
+// subfilters. The other subfilters have no dependencies.
+func subfilterDependencies(
+	filterPath string, filterInstaller FilterInstaller,
+) ([]string, error) {
+	switch f := filterInstaller.(type) {
+	case *PythonFilterDefinition:
+		requirementsPath := filepath.Join(
+			filterPath, filepath.Dir(f.Script), "requirements.txt")
+		if f.Requirements != "" {
+			requirementsPath = filepath.Join(filterPath, f.Requirements)
+		}
+		return readRequirementsTxt(requirementsPath)
+	case *NodeJSFilterDefinition:
+		packagePath := filepath.Join(
+			filterPath, filepath.Dir(f.Script), "package.json")
+		if f.Requirements != "" {
+			packagePath = filepath.Join(
+				filterPath, f.Requirements, "package.json")
+		}
+		return readPackageJsonDependencies(packagePath)
+	}
+	return nil, nil
+}
+
+// readRequirementsTxt returns the requirements from the requirements.txt file
+// without the comments and the empty lines. A missing file means no
+// requirements.
+func readRequirementsTxt(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, burrito.WrapErrorf(err, fileReadError, path)
+	}
+	defer file.Close()
+	var result []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		result = append(result, "python: "+line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, burrito.WrapErrorf(err, fileReadError, path)
+	}
+	return result, nil
+}
+
+// readPackageJsonDependencies returns the "dependencies" from the
+// package.json file, sorted by name. A missing file means no dependencies.
+func readPackageJsonDependencies(path string) ([]string, error) {
+	file, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, burrito.WrapErrorf(err, fileReadError, path)
+	}
+	var packageJson struct {
+		Dependencies map[string]string `json:"dependencies"`
+	}
+	err = json.Unmarshal(file, &packageJson)
+	if err != nil {
+		return nil, burrito.WrapErrorf(err, jsonUnmarshalError, path)
+	}
+	var result []string
+	for name, version := range packageJson.Dependencies {
+		result = append(result, fmt.Sprintf("nodejs: %s@%s", name, version))
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// String returns a human readable description of the filter.
+func (i remoteFilterInfo) String() string {
+	list := func(values []string) string {
+		if len(values) == 0 {
+			return "none"
+		}
+		return strings.Join(values, ", ")
+	}
+	lines := []string{
+		fmt.Sprintf("Filter: %s", i.Filter),
+		fmt.Sprintf("URL: %s", i.Url),
+		fmt.Sprintf("Version: %s", i.Version),
+	}
+	if i.Description != "" {
+		lines = append(lines, fmt.Sprintf("Description: %s", i.Description))
+	}
+	lines = append(lines,
+		fmt.Sprintf("Available versions: %s", list(i.Versions)),
+		fmt.Sprintf("Runtimes: %s", list(i.Runtimes)),
+		fmt.Sprintf("Exports data: %t", i.ExportData))
+	if len(i.Dependencies) == 0 {
+		return strings.Join(append(lines, "Dependencies: none"), "\n")
+	}
+	lines = append(lines, "Dependencies:")
+	for _, dependency := range i.Dependencies {
+		lines = append(lines, "\t"+dependency)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Json returns the filter information as indented JSON.
+func (i remoteFilterInfo) Json() string {
+	data, _ := json.MarshalIndent(i, "", "\t") // no error
+	return string(data)
+}
@@ -0,0 +1,131 @@
+package regolith
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTestFilterFiles creates the files of a filter in a temporary
+// directory and returns the path to it.
+func writeTestFilterFiles(t *testing.T, files map[string]string) string {
+	filterPath := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(filterPath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal("Failed to create the directory of the filter:", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal("Failed to write the file of the filter:", err)
+		}
+	}
+	return filterPath
+}
+
+// TestReadFilterJsonInfo checks whether the description, the runtimes and
+// the dependencies of the subfilters are read from the files of the filter.
+func TestReadFilterJsonInfo(t *testing.T) {
+	InitLogging(false)
+	filterPath := writeTestFilterFiles(t, map[string]string{
+		"filter.json": `{
+			"description": "Generates the entities.",
+			"exportData": true,
+			"filters": [
+				{"runWith": "python", "script": "python/main.py"},
+				{"runWith": "python", "script": "other/main.py"},
+				{"runWith": "nodejs", "script": "node/main.js"},
+				{"runWith": "shell", "command": "echo done"}
+			]
+		}`,
+		"python/requirements.txt": "# Comment\n\nrequests==2.31.0\n  pyyaml\n",
+		"node/package.json": `{
+			"dependencies": {"lodash": "^4.17.21", "chalk": "5.3.0"}
+		}`,
+	})
+	info := &remoteFilterInfo{
+		Filter: "generator", Runtimes: []string{}, Dependencies: []string{}}
+	if err := info.readFilterJson(filterPath); err != nil {
+		t.Fatal("Failed to read the filter.json file:", err)
+	}
+	if info.Description != "Generates the entities." || !info.ExportData {
+		t.Errorf(
+			"Unexpected description %q or exportData %t",
+			info.Description, info.ExportData)
+	}
+	expectedRuntimes := []string{"python", "nodejs", "shell"}
+	if !reflect.DeepEqual(info.Runtimes, expectedRuntimes) {
+		t.Errorf(
+			"Unexpected runtimes.\nExpected: %v\nActual: %v",
+			expectedRuntimes, info.Runtimes)
+	}
+	expectedDependencies := []string{
+		"python: requests==2.31.0", "python: pyyaml",
+		"nodejs: chalk@5.3.0", "nodejs: lodash@^4.17.21",
+	}
+	if !reflect.DeepEqual(info.Dependencies, expectedDependencies) {
+		t.Errorf(
+			"Unexpected dependencies.\nExpected: %v\nActual: %v",
+			expectedDependencies, info.Dependencies)
+	}
+}
+
+// TestReadFilterJsonInfoErrors checks whether the missing and invalid
+// filter.json files are reported.
+func TestReadFilterJsonInfoErrors(t *testing.T) {
+	InitLogging(false)
+	for name, files := range map[string]map[string]string{
+		"missing filter.json": {},
+		"invalid JSON":        {"filter.json": "{"},
+		"no filters":          {"filter.json": `{"description": "Filter"}`},
+		"invalid subfilter":   {"filter.json": `{"filters": ["python"]}`},
+	} {
+		info := &remoteFilterInfo{Filter: "filter"}
+		if err := info.readFilterJson(writeTestFilterFiles(t, files)); err == nil {
+			t.Errorf("%s: the filter.json file was read", name)
+		}
+	}
+}
+
+// TestRemoteFilterInfoOutput checks the human readable and the JSON output
+// of the "regolith filter-info" command.
+func TestRemoteFilterInfoOutput(t *testing.T) {
+	info := remoteFilterInfo{
+		Filter:       "generator",
+		Url:          "github.com/Bedrock-OSS/regolith-filters",
+		Version:      "1.1.0",
+		Versions:     []string{"1.0.0", "1.1.0"},
+		Runtimes:     []string{"python"},
+		Dependencies: []string{},
+	}
+	output := info.String()
+	for _, line := range []string{
+		"Filter: generator",
+		"Version: 1.1.0",
+		"Available versions: 1.0.0, 1.1.0",
+		"Runtimes: python",
+		"Exports data: false",
+		"Dependencies: none",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("The output doesn't contain %q:\n%s", line, output)
+		}
+	}
+	if strings.Contains(output, "Description") {
+		t.Errorf("The output contains the empty description:\n%s", output)
+	}
+	info.Dependencies = []string{"python: pyyaml"}
+	if output := info.String(); !strings.Contains(output, "Dependencies:\n\tpython: pyyaml") {
+		t.Errorf("The dependencies aren't listed:\n%s", output)
+	}
+	var parsed remoteFilterInfo
+	if err := json.Unmarshal([]byte(info.Json()), &parsed); err != nil {
+		t.Fatal("Failed to parse the JSON output:", err)
+	}
+	if !reflect.DeepEqual(parsed, info) {
+		t.Errorf(
+			"Unexpected JSON output.\nExpected: %+v\nActual: %+v", info, parsed)
+	}
+}
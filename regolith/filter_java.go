@@ -20,7 +20,7 @@ type JavaFilter struct {
 }
 
 func JavaFilterDefinitionFromObject(id string, obj map[string]interface{}) (*JavaFilterDefinition, error) {
-	filter := &JavaFilterDefinition{FilterDefinition: *FilterDefinitionFromObject(id)}
+	filter := &JavaFilterDefinition{FilterDefinition: *FilterDefinitionFromObject(id, obj)}
 	var path string
 	pathObj, ok := obj["path"]
 	if !ok {
@@ -54,6 +54,7 @@ func (f *JavaFilter) run(context RunContext) error {
 	// Run the filter
 	if len(f.Settings) == 0 {
 		err := RunSubProcess(
+			&context,
 			"java",
 			append(
 				[]string{
@@ -72,6 +73,7 @@ func (f *JavaFilter) run(context RunContext) error {
 	} else {
 		jsonSettings, _ := json.Marshal(f.Settings)
 		err := RunSubProcess(
+			&context,
 			"java",
 			append(
 				[]string{
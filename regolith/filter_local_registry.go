@@ -0,0 +1,109 @@
+// Functions used by the filters from the local registry, which are linked
+// into the filter cache from a local path instead of being downloaded.
+package regolith
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// localRegistrySource is the value of the "source" property of the filter
+// definitions that use a filter from a local path.
+const localRegistrySource = "local-registry"
+
+// localRegistryFilterDefinitionFromObject fills the result with the
+// properties of the filter definition that uses the local registry. The
+// version is optional because the linked filter is always up to date.
+func localRegistryFilterDefinitionFromObject(
+	result *RemoteFilterDefinition, obj map[string]interface{},
+) (*RemoteFilterDefinition, error) {
+	result.Source = localRegistrySource
+	pathObj, ok := obj["path"]
+	if !ok {
+		return nil, burrito.WrappedErrorf(jsonPropertyMissingError, "path")
+	}
+	path, ok := pathObj.(string)
+	if !ok {
+		return nil, burrito.WrappedErrorf(jsonPropertyTypeError, "path", "string")
+	}
+	result.Path = path
+	result.Version, _ = obj["version"].(string)
+	result.VenvSlot, _ = obj["venvSlot"].(int) // default venvSlot is 0
+	return result, nil
+}
+
+// isLocalRegistry returns true if the filter is linked from the local
+// registry instead of being downloaded.
+func (f *RemoteFilterDefinition) isLocalRegistry() bool {
+	return f.Source == localRegistrySource
+}
+
+// linkLocalRegistry links the filter from the local registry into its
+// download path, replacing the previous installation of the filter. It
+// returns false if the filter was already linked to the same path.
+func (f *RemoteFilterDefinition) linkLocalRegistry(
+	dotRegolithPath string,
+) (bool, error) {
+	target, err := filepath.Abs(f.Path)
+	if err != nil {
+		return false, burrito.WrapErrorf(err, filepathAbsError, f.Path)
+	}
+	filterJsonPath := filepath.Join(target, "filter.json")
+	if _, err := os.Stat(filterJsonPath); err != nil {
+		return false, burrito.WrapErrorf(
+			err, "The filter from the local registry has no filter.json "+
+				"file.\nFilter: %s\nPath: %s", f.Id, filterJsonPath)
+	}
+	downloadPath := f.GetDownloadPath(dotRegolithPath)
+	linkTarget, err := os.Readlink(downloadPath)
+	if err == nil && filepath.Clean(linkTarget) == target {
+		return false, nil
+	}
+	// Remove the previous installation. If it's a link, only the link is
+	// removed.
+	err = os.RemoveAll(downloadPath)
+	if err != nil {
+		return false, burrito.WrapErrorf(err, osRemoveError, downloadPath)
+	}
+	err = CreateDirectoryIfNotExists(filepath.Dir(downloadPath))
+	if err != nil {
+		return false, burrito.WrapErrorf(
+			err, osMkdirError, filepath.Dir(downloadPath))
+	}
+	err = createDirectoryLink(target, downloadPath)
+	if err != nil {
+		return false, burrito.WrapErrorf(
+			err, "Failed to link the filter from the local registry.\n"+
+				"Filter: %s\nPath: %s\nLink: %s", f.Id, target, downloadPath)
+	}
+	Logger.Infof(
+		"Filter %q linked from the local registry: %s", f.Id, target)
+	return true, nil
+}
+
+// updateLocalRegistry is the equivalent of the Update method for the
+// filters from the local registry. The filter is never downloaded, its
+// dependencies are installed only when the link is created or when the
+// force flag is set.
+func (f *RemoteFilterDefinition) updateLocalRegistry(
+	force bool, dotRegolithPath string,
+) (bool, error) {
+	linked, err := f.linkLocalRegistry(dotRegolithPath)
+	if err != nil {
+		return false, burrito.PassError(err)
+	}
+	if !linked && !force {
+		Logger.Infof(
+			"Filter %q is linked from the local registry, it's always up "+
+				"to date.", f.Id)
+		return false, nil
+	}
+	err = f.InstallDependencies(f, dotRegolithPath)
+	if err != nil {
+		return false, burrito.PassError(err)
+	}
+	Logger.Infof("Filter %q installed from the local registry.", f.Id)
+	return true, nil
+}
@@ -0,0 +1,98 @@
+// Functions used by the "regolith run --filter-logs" command, which saves
+// the output of each filter to a separate log file.
+package regolith
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// filterLogsDir is the name of the directory in the .regolith directory
+// with the log files of the filters.
+const filterLogsDir = "logs"
+
+// filterLog is a log file with the output of a filter. The output of the
+// subprocesses of the filter is written to it line by line, in addition to
+// the main log.
+type filterLog struct {
+	// path is the path to the log file.
+	path string
+
+	// mutex synchronizes writing the stdout and the stderr of the filter,
+	// which are read by separate goroutines.
+	mutex sync.Mutex
+	file  *os.File
+}
+
+// logFileName replaces the characters that can't be used in the names of
+// the files (for example the slashes in the paths to the files used as the
+// names of the profiles with "--profile-file") with underscores.
+func logFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// profileLogsPath returns the path to the directory with the log files of
+// the filters of the profile.
+func profileLogsPath(dotRegolithPath, profile string) string {
+	return filepath.Join(dotRegolithPath, filterLogsDir, logFileName(profile))
+}
+
+// clearProfileLogs removes the log files of the filters of the profile left
+// by the previous run, so the logs don't grow and don't mix the runs.
+func clearProfileLogs(dotRegolithPath, profile string) error {
+	path := profileLogsPath(dotRegolithPath, profile)
+	if err := os.RemoveAll(path); err != nil {
+		return burrito.WrapErrorf(err, osRemoveError, path)
+	}
+	return nil
+}
+
+// openFilterLog creates the log file of the filter of the profile. An
+// existing log file is truncated.
+func openFilterLog(dotRegolithPath, profile, filterId string) (*filterLog, error) {
+	dir := profileLogsPath(dotRegolithPath, profile)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, burrito.WrapErrorf(err, osMkdirError, dir)
+	}
+	path := filepath.Join(dir, logFileName(filterId)+".log")
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, burrito.WrapErrorf(err, fileWriteError, path)
+	}
+	return &filterLog{path: path, file: file}, nil
+}
+
+// writeLine writes a line of the output of the filter to the log file. The
+// lines from the stderr are prefixed with "[stderr]". Calling it on a nil
+// filterLog does nothing. The errors are ignored, because the output is
+// always printed to the main log too.
+func (l *filterLog) writeLine(line string, isStderr bool) {
+	if l == nil {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if isStderr {
+		fmt.Fprintf(l.file, "[stderr] %s\n", line)
+	} else {
+		fmt.Fprintln(l.file, line)
+	}
+}
+
+// Close closes the log file.
+func (l *filterLog) Close() error {
+	if err := l.file.Close(); err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, l.path)
+	}
+	return nil
+}
@@ -27,7 +27,7 @@ type NimFilter struct {
 func NimFilterDefinitionFromObject(
 	id string, obj map[string]interface{},
 ) (*NimFilterDefinition, error) {
-	filter := &NimFilterDefinition{FilterDefinition: *FilterDefinitionFromObject(id)}
+	filter := &NimFilterDefinition{FilterDefinition: *FilterDefinitionFromObject(id, obj)}
 	scriptObj, ok := obj["script"]
 	if !ok {
 		return nil, burrito.WrappedErrorf(jsonPropertyMissingError, "script")
@@ -55,6 +55,7 @@ func (f *NimFilter) run(context RunContext) error {
 	// Run filter
 	if len(f.Settings) == 0 {
 		err := RunSubProcess(
+			&context,
 			"nim",
 			append([]string{
 				"-r", "c", "--hints:off", "--warnings:off", "--mm:orc",
@@ -71,6 +72,7 @@ func (f *NimFilter) run(context RunContext) error {
 	} else {
 		jsonSettings, _ := json.Marshal(f.Settings)
 		err := RunSubProcess(
+			&context,
 			"nim",
 			append([]string{
 				"-r", "c", "--hints:off", "--warnings:off", "--mm:orc",
@@ -111,11 +113,7 @@ func (f *NimFilterDefinition) CreateFilterRunner(runConfiguration map[string]int
 func (f *NimFilterDefinition) InstallDependencies(
 	parent *RemoteFilterDefinition, dotRegolithPath string,
 ) error {
-	installLocation := ""
-	// Install dependencies
-	if parent != nil {
-		installLocation = parent.GetDownloadPath(dotRegolithPath)
-	}
+	installLocation := f.installLocation(parent, dotRegolithPath)
 	Logger.Infof("Downloading dependencies for %s...", f.Id)
 	var requirementsPath string
 	if f.Requirements == "" {
@@ -138,7 +136,7 @@ func (f *NimFilterDefinition) InstallDependencies(
 	if hasNimble(requirementsPath) {
 		Logger.Info("Installing nim dependencies...")
 		err := RunSubProcess(
-			"nimble", []string{"install", "-d", "-y"}, requirementsPath, requirementsPath, ShortFilterName(f.Id))
+			nil, "nimble", []string{"install", "-d", "-y"}, requirementsPath, requirementsPath, ShortFilterName(f.Id))
 		if err != nil {
 			return burrito.WrapErrorf(
 				err, "Failed to run nimble to install dependencies of a filter.\n"+
@@ -26,7 +26,7 @@ type NodeJSFilter struct {
 }
 
 func NodeJSFilterDefinitionFromObject(id string, obj map[string]interface{}) (*NodeJSFilterDefinition, error) {
-	filter := &NodeJSFilterDefinition{FilterDefinition: *FilterDefinitionFromObject(id)}
+	filter := &NodeJSFilterDefinition{FilterDefinition: *FilterDefinitionFromObject(id, obj)}
 	scriptObj, ok := obj["script"]
 	if !ok {
 		return nil, burrito.WrappedErrorf(jsonPropertyMissingError, "script")
@@ -54,6 +54,7 @@ func (f *NodeJSFilter) run(context RunContext) error {
 	// Run filter
 	if len(f.Settings) == 0 {
 		err := RunSubProcess(
+			&context,
 			"node",
 			append([]string{
 				context.AbsoluteLocation + string(os.PathSeparator) +
@@ -70,6 +71,7 @@ func (f *NodeJSFilter) run(context RunContext) error {
 	} else {
 		jsonSettings, _ := json.Marshal(f.Settings)
 		err := RunSubProcess(
+			&context,
 			"node",
 			append([]string{
 				context.AbsoluteLocation + string(os.PathSeparator) +
@@ -106,11 +108,7 @@ func (f *NodeJSFilterDefinition) CreateFilterRunner(runConfiguration map[string]
 }
 
 func (f *NodeJSFilterDefinition) InstallDependencies(parent *RemoteFilterDefinition, dotRegolithPath string) error {
-	installLocation := ""
-	// Install dependencies
-	if parent != nil {
-		installLocation = parent.GetDownloadPath(dotRegolithPath)
-	}
+	installLocation := f.installLocation(parent, dotRegolithPath)
 	Logger.Infof("Downloading dependencies for %s...", f.Id)
 	var requirementsPath string
 	if f.Requirements == "" {
@@ -131,7 +129,7 @@ func (f *NodeJSFilterDefinition) InstallDependencies(parent *RemoteFilterDefinit
 	}
 	if hasPackageJson(requirementsPath) {
 		Logger.Info("Installing npm dependencies...")
-		err := RunSubProcess("npm", []string{"i", "--no-fund", "--no-audit"}, requirementsPath, requirementsPath, ShortFilterName(f.Id))
+		err := RunSubProcess(nil, "npm", []string{"i", "--no-fund", "--no-audit"}, requirementsPath, requirementsPath, ShortFilterName(f.Id))
 		if err != nil {
 			return burrito.WrapErrorf(
 				err, "Failed to run npm and install dependencies."+
@@ -1,28 +1,105 @@
 package regolith
 
-import "github.com/Bedrock-OSS/go-burrito/burrito"
+import (
+	"fmt"
+	"reflect"
 
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// ProfileFilter is a filter that runs another profile from the same config.
+// The "arguments" and "settings" of the filter are passed to the filters of
+// the nested profile.
 type ProfileFilter struct {
 	Filter
 	Profile string `json:"-"`
 }
 
+// profileFilterFromObject creates a ProfileFilter that runs the profile with
+// the optional "arguments" and "settings" from the JSON object. The
+// "outputScope", "packs" and "scope" properties limit the files available to
+// all filters of the nested profile.
+func profileFilterFromObject(
+	profile string, obj map[string]interface{},
+) (*ProfileFilter, error) {
+	result := &ProfileFilter{Profile: profile}
+	if arguments, ok := obj["arguments"]; ok {
+		arguments, ok := arguments.([]interface{})
+		if !ok {
+			return nil, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "arguments", "array")
+		}
+		for i, argument := range arguments {
+			argument, ok := argument.(string)
+			if !ok {
+				return nil, burrito.WrappedErrorf(
+					jsonPropertyTypeError, fmt.Sprintf("arguments->%d", i),
+					"string")
+			}
+			result.Arguments = append(result.Arguments, argument)
+		}
+	}
+	if settings, ok := obj["settings"]; ok {
+		settings, ok := settings.(map[string]interface{})
+		if !ok {
+			return nil, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "settings", "object")
+		}
+		result.Settings = settings
+	}
+	if err := filterScopesFromObject(&result.Filter, obj); err != nil {
+		return nil, burrito.PassError(err)
+	}
+	return result, nil
+}
+
 func (f *ProfileFilter) Run(context RunContext) (bool, error) {
-	Logger.Infof("Running %q nested profile...", f.Profile)
-	return RunProfileImpl(RunContext{
-		Profile:             f.Profile,
-		AbsoluteLocation:    context.AbsoluteLocation,
-		Config:              context.Config,
-		Parent:              &context,
-		interruptionChannel: context.interruptionChannel,
-		DotRegolithPath:     context.DotRegolithPath,
-	})
+	// The Check method should find the circular dependencies before running
+	// the profile, this is the last line of defense against infinite
+	// recursion
+	if context.isProfileVisited(f.Profile) {
+		return false, burrito.WrappedErrorf(
+			"Found circular dependency in the profile.\nProfile: %s",
+			f.Profile)
+	}
+	config, err := f.overrideConfig(context.Config)
+	if err != nil {
+		return false, burrito.PassError(err)
+	}
+	context.logInfof("Running %q nested profile...", f.Profile)
+	return RunProfileImpl(f.nestedContext(context, config))
+}
+
+// nestedContext returns the context of the nested profile with the config
+// overridden by the filter.
+func (f *ProfileFilter) nestedContext(
+	context RunContext, config *Config,
+) RunContext {
+	result := context.childContext()
+	result.Profile = f.Profile
+	result.Config = config
+	result.Parent = &context
+	result.visitedProfiles = context.withVisitedProfile(context.Profile)
+	result.logDepth++
+	// The log file of the profile filter is not used by the filters of the
+	// nested profile
+	result.filterLog = nil
+	return result
+}
+
+// DryRun prints the filters of the nested profile.
+func (f *ProfileFilter) DryRun(context RunContext) error {
+	config, err := f.overrideConfig(context.Config)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	fmt.Printf("%s%s\n", context.dryRunIndent(), dryRunHeader(f))
+	return dryRunProfileImpl(f.nestedContext(context, config))
 }
 
 func (f *ProfileFilter) Check(context RunContext) error {
 	// Check if the profile exists
-	profile, ok := context.Config.Profiles[f.Profile]
-	if !ok {
+	if _, ok := context.Config.Profiles[f.Profile]; !ok {
 		return burrito.WrappedErrorf("Profile not found.\nProfile: %s", f.Profile)
 	}
 	// Check if the profile we're nesting wasn't already nested
@@ -35,7 +112,90 @@ func (f *ProfileFilter) Check(context RunContext) error {
 		}
 		parent = parent.Parent
 	}
+	config, err := f.overrideConfig(context.Config)
+	if err != nil {
+		return burrito.PassError(err)
+	}
 	return CheckProfileImpl(
-		profile, f.Profile, *context.Config, &context,
+		config.Profiles[f.Profile], f.Profile, *config, &context,
 		context.DotRegolithPath)
 }
+
+// hasOverrides returns true if the filter passes any arguments or settings to
+// the filters of the nested profile.
+func (f *ProfileFilter) hasOverrides() bool {
+	return len(f.Arguments) != 0 || len(f.Settings) != 0
+}
+
+// overrideConfig returns a copy of the config in which the filters of the
+// nested profile have the arguments and settings of the ProfileFilter
+// applied with CopyArguments. The original config is not modified, because
+// the same profile can be used in other places without the overrides.
+func (f *ProfileFilter) overrideConfig(config *Config) (*Config, error) {
+	if !f.hasOverrides() {
+		return config, nil
+	}
+	profile, ok := config.Profiles[f.Profile]
+	if !ok {
+		return nil, burrito.WrappedErrorf(
+			"Profile not found.\nProfile: %s", f.Profile)
+	}
+	filters := make([]FilterRunner, len(profile.Filters))
+	for i, filter := range profile.Filters {
+		filters[i] = cloneFilterRunner(filter)
+		filters[i].CopyArguments(f.argumentsParent(filter))
+	}
+	profile.Filters = filters
+	result := *config
+	result.Profiles = make(map[string]Profile, len(config.Profiles))
+	for name, p := range config.Profiles {
+		result.Profiles[name] = p
+	}
+	result.Profiles[f.Profile] = profile
+	return &result, nil
+}
+
+// argumentsParent returns the RemoteFilter used as the parent in
+// CopyArguments to pass the arguments and settings of the ProfileFilter to
+// the filter. The settings of the filter are merged with the settings of the
+// ProfileFilter, which take priority.
+func (f *ProfileFilter) argumentsParent(filter FilterRunner) *RemoteFilter {
+	settings := make(map[string]interface{})
+	for key, value := range filter.GetSettings() {
+		settings[key] = value
+	}
+	for key, value := range f.Settings {
+		settings[key] = value
+	}
+	if len(settings) == 0 {
+		settings = nil
+	}
+	result := &RemoteFilter{
+		Filter: Filter{Arguments: f.Arguments, Settings: settings},
+	}
+	// CopyArguments of the Python filters copies the venv slot as well
+	if pythonFilter, ok := filter.(*PythonFilter); ok {
+		result.Definition.VenvSlot = pythonFilter.Definition.VenvSlot
+	}
+	return result
+}
+
+// cloneFilterRunner returns a shallow copy of the filter runner with its own
+// copy of the arguments, so the copy can be modified with CopyArguments
+// without affecting the original.
+func cloneFilterRunner(filter FilterRunner) FilterRunner {
+	value := reflect.ValueOf(filter)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return filter
+	}
+	result := reflect.New(value.Elem().Type())
+	result.Elem().Set(value.Elem())
+	arguments := result.Elem().FieldByName("Arguments")
+	if arguments.IsValid() && arguments.Kind() == reflect.Slice {
+		argumentsCopy := reflect.MakeSlice(
+			arguments.Type(), arguments.Len(), arguments.Len())
+		reflect.Copy(argumentsCopy, arguments)
+		arguments.Set(argumentsCopy)
+	}
+	return result.Interface().(FilterRunner)
+}
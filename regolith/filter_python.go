@@ -28,7 +28,7 @@ type PythonFilter struct {
 }
 
 func PythonFilterDefinitionFromObject(id string, obj map[string]interface{}) (*PythonFilterDefinition, error) {
-	filter := &PythonFilterDefinition{FilterDefinition: *FilterDefinitionFromObject(id)}
+	filter := &PythonFilterDefinition{FilterDefinition: *FilterDefinitionFromObject(id, obj)}
 	scripObj, ok := obj["script"]
 	if !ok {
 		return nil, burrito.WrappedErrorf(jsonPropertyMissingError, "script")
@@ -77,7 +77,7 @@ func (f *PythonFilter) run(context RunContext) error {
 		if err != nil {
 			return burrito.WrapError(err, "Failed to resolve venv path.")
 		}
-		Logger.Debug("Running Python filter using venv: ", venvPath)
+		context.logDebugf("Running Python filter using venv: %s", venvPath)
 		pythonCommand = filepath.Join(
 			venvPath, venvScriptsPath, "python"+exeSuffix)
 	}
@@ -92,7 +92,7 @@ func (f *PythonFilter) run(context RunContext) error {
 		)
 	}
 	err = RunSubProcess(
-		pythonCommand, args, context.AbsoluteLocation,
+		&context, pythonCommand, args, context.AbsoluteLocation,
 		GetAbsoluteWorkingDirectory(context.DotRegolithPath),
 		ShortFilterName(f.Id))
 	if err != nil {
@@ -123,11 +123,7 @@ func (f *PythonFilterDefinition) CreateFilterRunner(runConfiguration map[string]
 func (f *PythonFilterDefinition) InstallDependencies(
 	parent *RemoteFilterDefinition, dotRegolithPath string,
 ) error {
-	installLocation := ""
-	// Install dependencies
-	if parent != nil {
-		installLocation = parent.GetDownloadPath(dotRegolithPath)
-	}
+	installLocation := f.installLocation(parent, dotRegolithPath)
 	Logger.Infof("Downloading dependencies for %s...", f.Id)
 	joinedPath := filepath.Join(installLocation, f.Script)
 	scriptPath, err := filepath.Abs(joinedPath)
@@ -160,7 +156,7 @@ func (f *PythonFilterDefinition) InstallDependencies(
 		}
 		// Create the "venv"
 		err = RunSubProcess(
-			pythonCommand, []string{"-m", "venv", venvPath}, filterPath, "", ShortFilterName(f.Id))
+			nil, pythonCommand, []string{"-m", "venv", venvPath}, filterPath, "", ShortFilterName(f.Id))
 		if err != nil {
 			return burrito.WrapError(err, "Failed to create venv.")
 		}
@@ -168,6 +164,7 @@ func (f *PythonFilterDefinition) InstallDependencies(
 		venvPythonCommand := filepath.Join(
 			venvPath, venvScriptsPath, "python"+exeSuffix)
 		err = RunSubProcess(
+			nil,
 			venvPythonCommand,
 			[]string{"-m", "pip", "install", "--upgrade", "pip"},
 			filterPath, "", ShortFilterName(f.Id))
@@ -178,6 +175,7 @@ func (f *PythonFilterDefinition) InstallDependencies(
 		Logger.Info("Installing pip dependencies...")
 		requirementsFolder := filepath.Dir(requirementsFile)
 		err = RunSubProcess(
+			nil,
 			filepath.Join(venvPath, venvScriptsPath, "pip"+exeSuffix),
 			[]string{"install", "-r", filepath.Base(requirementsFile)}, requirementsFolder,
 			requirementsFolder, ShortFilterName(f.Id))
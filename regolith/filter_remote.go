@@ -8,11 +8,12 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/Bedrock-OSS/go-burrito/burrito"
 
-	"github.com/hashicorp/go-getter"
 	"github.com/otiai10/copy"
+	"golang.org/x/mod/semver"
 )
 
 type RemoteFilterDefinition struct {
@@ -22,6 +23,26 @@ type RemoteFilterDefinition struct {
 	// RemoteFilters can propagate some of the properties unique to other types
 	// of filers (like Python's venvSlot).
 	VenvSlot int `json:"venvSlot,omitempty"`
+
+	// Source is "local-registry" for the filters linked from a local path
+	// instead of being downloaded. Empty for the filters from the internet.
+	Source string `json:"source,omitempty"`
+	// Path is the path to the filter linked from the local registry,
+	// relative to the root of the project.
+	Path string `json:"path,omitempty"`
+	// RemoteName is the name of the filter in its repository, set when the
+	// filter is renamed with "regolith rename-filter". Empty means the
+	// name of the filter definition.
+	RemoteName string `json:"remoteName,omitempty"`
+}
+
+// remoteName returns the name of the filter in its repository, which is
+// used for finding its folder, its version tags and its data folder.
+func (f *RemoteFilterDefinition) remoteName() string {
+	if f.RemoteName != "" {
+		return f.RemoteName
+	}
+	return f.Id
 }
 
 type RemoteFilter struct {
@@ -30,7 +51,19 @@ type RemoteFilter struct {
 }
 
 func RemoteFilterDefinitionFromObject(id string, obj map[string]interface{}) (*RemoteFilterDefinition, error) {
-	result := &RemoteFilterDefinition{FilterDefinition: *FilterDefinitionFromObject(id)}
+	result := &RemoteFilterDefinition{FilterDefinition: *FilterDefinitionFromObject(id, obj)}
+	if sourceObj, ok := obj["source"]; ok {
+		source, ok := sourceObj.(string)
+		if !ok {
+			return nil, burrito.WrappedErrorf(jsonPropertyTypeError, "source", "string")
+		}
+		if source != localRegistrySource {
+			return nil, burrito.WrappedErrorf(
+				"Unknown filter source %q. The only supported value of the "+
+					"\"source\" property is %q.", source, localRegistrySource)
+		}
+		return localRegistryFilterDefinitionFromObject(result, obj)
+	}
 	url, ok := obj["url"].(string)
 	if !ok {
 		result.Url = StandardLibraryUrl
@@ -47,12 +80,20 @@ func RemoteFilterDefinitionFromObject(id string, obj map[string]interface{}) (*R
 	}
 	result.Version = version
 	result.VenvSlot, _ = obj["venvSlot"].(int) // default venvSlot is 0
+	if remoteNameObj, ok := obj["remoteName"]; ok {
+		remoteName, ok := remoteNameObj.(string)
+		if !ok {
+			return nil, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "remoteName", "string")
+		}
+		result.RemoteName = remoteName
+	}
 
 	return result, nil
 }
 
 func (f *RemoteFilter) run(context RunContext) error {
-	Logger.Debugf("RunRemoteFilter \"%s\"", f.Definition.Url)
+	context.logDebugf("RunRemoteFilter \"%s\"", f.Definition.Url)
 	if !f.IsCached(context.DotRegolithPath) {
 		return burrito.WrappedErrorf(
 			"Filter is not downloaded. "+
@@ -60,25 +101,17 @@ func (f *RemoteFilter) run(context RunContext) error {
 				"regolith install %s", f.Id)
 	}
 
-	version, err := f.GetCachedVersion(context.DotRegolithPath)
+	// The filters from the local registry are always up to date
+	if !f.Definition.isLocalRegistry() {
+		if err := f.checkCachedVersion(context.DotRegolithPath); err != nil {
+			return burrito.PassError(err)
+		}
+	}
+
+	err := f.mergeFilterData(context.DotRegolithPath)
 	if err != nil {
 		return burrito.WrapErrorf(
-			err, "Failed check the version of the filter in cache."+
-				"\nFilter: %s\n"+
-				"You can try to force reinstallation fo the filter using command:"+
-				"regolith install --force %s", f.Id, f.Id)
-	}
-	if f.Definition.Version != "HEAD" && f.Definition.Version != "latest" && f.Definition.Version != *version {
-		return burrito.WrappedErrorf(
-			"Filter version saved in cache doesn't match the version declared"+
-				" in the config file.\n"+
-				"Filter: %s\n"+
-				"Installed version: %s\n"+
-				"Required version: %s\n"+
-				"You update all of the filters by running:\n"+
-				"regolith install-all",
-			// id, cached, required
-			f.Id, *version, f.Definition.Version)
+			err, "Failed to copy the data of the filter to the tmp directory.")
 	}
 
 	path := f.GetDownloadPath(context.DotRegolithPath)
@@ -88,24 +121,31 @@ func (f *RemoteFilter) run(context RunContext) error {
 		return burrito.WrapErrorf(err, remoteFilterSubfilterCollectionError)
 	}
 	for i, filter := range filterCollection.Filters {
-		runContext := RunContext{
-			Config:           context.Config,
-			AbsoluteLocation: absolutePath,
-			Profile:          context.Profile,
-			Parent:           context.Parent,
-			DotRegolithPath:  context.DotRegolithPath,
-		}
+		runContext := f.subfilterContext(context, absolutePath)
 		// Disabled filters are skipped
 		disabled, err := filter.IsDisabled(runContext)
 		if err != nil {
 			return burrito.WrapErrorf(err, "Failed to check if filter is disabled")
 		}
 		if disabled {
-			Logger.Infof(
+			context.logInfof(
 				"The %s subfilter of \"%s\" filter is disabled, skipping.",
 				nth(i), f.Id)
 			continue
 		}
+		conditionMet, err := filter.IsConditionMet(runContext)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to check the condition of the %s subfilter.",
+				nth(i))
+		}
+		if !conditionMet {
+			context.logInfof(
+				"The %s subfilter of \"%s\" filter skipped by condition, "+
+					"its \"when\" expression is false.",
+				nth(i), f.Id)
+			continue
+		}
 		// Overwrite the venvSlot with the parent value
 		// TODO - remote filters can contain multiple filters, the interruption
 		// chceck should be performed after every subfilter
@@ -119,6 +159,73 @@ func (f *RemoteFilter) run(context RunContext) error {
 	return nil
 }
 
+// DryRun prints the remote filter and the subfilters from its filter.json
+// file.
+func (f *RemoteFilter) DryRun(context RunContext) error {
+	if !f.IsCached(context.DotRegolithPath) {
+		return burrito.WrappedErrorf(
+			"Filter is not downloaded. "+
+				"You can download filter files using command:\n"+
+				"regolith install %s", f.Id)
+	}
+	printDryRunFilter(f, context, "")
+	absolutePath, _ := filepath.Abs(f.GetDownloadPath(context.DotRegolithPath))
+	filterCollection, err := f.subfilterCollection(context.DotRegolithPath)
+	if err != nil {
+		return burrito.WrapErrorf(err, remoteFilterSubfilterCollectionError)
+	}
+	for i, filter := range filterCollection.Filters {
+		err := dryRunFilter(
+			filter, f.subfilterContext(context, absolutePath))
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to dry run the filter.\nFilter: %s",
+				NiceSubfilterName(f.Id, i))
+		}
+	}
+	return nil
+}
+
+// subfilterContext returns the context of the subfilters of the remote
+// filter, which run in the absolutePath directory of the downloaded filter.
+func (f *RemoteFilter) subfilterContext(
+	context RunContext, absolutePath string,
+) RunContext {
+	result := context.childContext()
+	result.AbsoluteLocation = absolutePath
+	// The subfilters don't check the interruptions. The remote filter checks
+	// them after running all of the subfilters, so the subfilters must not
+	// receive them from the channel.
+	result.interruptionChannel = nil
+	return result
+}
+
+// checkCachedVersion returns an error if the version of the filter saved in
+// the cache doesn't match the version from the config file.
+func (f *RemoteFilter) checkCachedVersion(dotRegolithPath string) error {
+	version, err := f.GetCachedVersion(dotRegolithPath)
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, "Failed check the version of the filter in cache."+
+				"\nFilter: %s\n"+
+				"You can try to force reinstallation fo the filter using command:"+
+				"regolith install --force %s", f.Id, f.Id)
+	}
+	if f.Definition.Version != "HEAD" && f.Definition.Version != "latest" && f.Definition.Version != *version {
+		return burrito.WrappedErrorf(
+			"Filter version saved in cache doesn't match the version declared"+
+				" in the config file.\n"+
+				"Filter: %s\n"+
+				"Installed version: %s\n"+
+				"Required version: %s\n"+
+				"You update all of the filters by running:\n"+
+				"regolith install-all",
+			// id, cached, required
+			f.Id, *version, f.Definition.Version)
+	}
+	return nil
+}
+
 func (f *RemoteFilter) Run(context RunContext) (bool, error) {
 	if err := f.run(context); err != nil {
 		return false, burrito.PassError(err)
@@ -178,6 +285,19 @@ func (f *RemoteFilterDefinition) InstallDependencies(_ *RemoteFilterDefinition,
 			return extraFilterJsonErrorInfo(
 				path, burrito.WrapErrorf(err, jsonPathParseError, jsonPath))
 		}
+		// Remote subfilters must follow the same source policy as the
+		// filters installed directly
+		if remote, ok := filterInstaller.(*RemoteFilterDefinition); ok &&
+			!remote.isLocalRegistry() {
+			err = checkFilterSourceAllowed(remote.Url, remote.Id)
+			if err != nil {
+				return burrito.WrapErrorf(
+					err, "The %s subfilter is not allowed.\n"+
+						"Filter configuration file: %s\n"+
+						"JSON path: %s",
+					nth(i), path, jsonPath)
+			}
+		}
 		err = filterInstaller.InstallDependencies(f, dotRegolithPath)
 		if err != nil {
 			// This is not parsing error so extraErrorInfo is not necessary
@@ -236,7 +356,7 @@ func (f *RemoteFilterDefinition) CopyFilterData(dataPath string, dotRegolithPath
 	// Additionally, if the remote data path doesn't exist, we don't need
 	// to do anything
 	remoteDataPath := path.Join(f.GetDownloadPath(dotRegolithPath), "data")
-	localDataPath := path.Join(dataPath, f.Id)
+	localDataPath := path.Join(dataPath, f.remoteName())
 	if _, err := os.Stat(localDataPath); err == nil {
 		Logger.Warnf(
 			"Filter already has data in its data folder.\n"+
@@ -268,6 +388,47 @@ func (f *RemoteFilterDefinition) CopyFilterData(dataPath string, dotRegolithPath
 	}
 }
 
+// mergeFilterData copies the files from the "data" folder of a filter with
+// the "exportData" property to the data folder of the filter in the tmp
+// directory ("tmp/data/<filter>"), so the filter can always read its bundled
+// resources. The files that are already in the tmp directory come from the
+// data folder of the project and are never overwritten.
+func (f *RemoteFilter) mergeFilterData(dotRegolithPath string) error {
+	exportData, err := f.IsUsingDataExport(dotRegolithPath)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	if !exportData {
+		return nil
+	}
+	filterDataPath := filepath.Join(f.GetDownloadPath(dotRegolithPath), "data")
+	if _, err := os.Stat(filterDataPath); os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return burrito.WrapErrorf(err, osStatErrorAny, filterDataPath)
+	}
+	tmpDataPath := filepath.Join(
+		getTmpPath(dotRegolithPath), "data", f.Definition.remoteName())
+	err = copy.Copy(filterDataPath, tmpDataPath, copy.Options{
+		PreserveTimes: false,
+		Sync:          false,
+		Skip: func(src string) (bool, error) {
+			relPath, err := filepath.Rel(filterDataPath, src)
+			if err != nil {
+				return false, burrito.WrapErrorf(
+					err, filepathRelError, filterDataPath, src)
+			}
+			// The directories are merged, only the files are skipped
+			info, err := os.Stat(filepath.Join(tmpDataPath, relPath))
+			return err == nil && !info.IsDir(), nil
+		},
+	})
+	if err != nil {
+		return burrito.WrapErrorf(err, osCopyError, filterDataPath, tmpDataPath)
+	}
+	return nil
+}
+
 // GetDownloadPath returns the path location where the filter can be found.
 func (f *RemoteFilter) GetDownloadPath(dotRegolithPath string) string {
 	return filepath.Join(filepath.Join(dotRegolithPath, "cache/filters"), f.Id)
@@ -337,8 +498,17 @@ func (f *RemoteFilter) IsUsingDataExport(dotRegolithPath string) (bool, error) {
 func FilterDefinitionFromTheInternet(
 	url, name, version string,
 ) (*RemoteFilterDefinition, error) {
-	var err error
+	err := checkFilterSourceAllowed(url, name)
+	if err != nil {
+		return nil, burrito.PassError(err)
+	}
 	if version == "" { // "" locks the version to the latest
+		if Offline {
+			return nil, burrito.WrappedErrorf(
+				"The version of the filter must be specified in the offline "+
+					"mode, because finding the newest version requires "+
+					"accessing the network.\nFilter: %s", name)
+		}
 		version, err = GetRemoteFilterDownloadRef(url, name, version)
 		if err != nil {
 			return nil, burrito.WrappedErrorf(
@@ -355,8 +525,12 @@ func FilterDefinitionFromTheInternet(
 
 // Download
 func (i *RemoteFilterDefinition) Download(
-	isForced bool, dotRegolithPath string,
+	isForced, noSubmodules bool, dotRegolithPath string,
 ) error {
+	if i.isLocalRegistry() {
+		_, err := i.linkLocalRegistry(dotRegolithPath)
+		return burrito.PassError(err)
+	}
 	if _, err := os.Stat(i.GetDownloadPath(dotRegolithPath)); err == nil {
 		if !isForced {
 			Logger.Warnf(
@@ -370,33 +544,58 @@ func (i *RemoteFilterDefinition) Download(
 		}
 	}
 
-	Logger.Infof("Downloading filter %s...", i.Id)
-
-	// Download the filter using Git Getter
-	if !hasGit() {
-		return burrito.WrappedError(gitNotInstalledWarning)
-	}
-	repoVersion, err := GetRemoteFilterDownloadRef(i.Url, i.Id, i.Version)
+	err := checkFilterSourceAllowed(i.Url, i.remoteName())
 	if err != nil {
-		return burrito.WrapErrorf(
-			err, getRemoteFilterDownloadRefError, i.Url, i.Id, i.Version)
+		return burrito.PassError(err)
 	}
-	url := fmt.Sprintf("%s//%s?ref=%s", i.Url, i.Id, repoVersion)
-	downloadPath := i.GetDownloadPath(dotRegolithPath)
 
-	_, err = os.Stat(downloadPath)
-	downloadPathIsNew := os.IsNotExist(err)
-	err = getter.Get(downloadPath, url)
-	if err != nil {
-		if downloadPathIsNew { // Remove the path created by getter
-			os.Remove(downloadPath)
+	Logger.Infof("Downloading filter %s...", i.Id)
+	downloadPath := i.GetDownloadPath(dotRegolithPath)
+	var repoVersion, sha string
+	if isArchiveFilterUrl(i.Url) {
+		// The archives are downloaded over HTTP, they don't need Git
+		repoVersion, err = downloadArchiveFilter(i.Url, i.Version, downloadPath)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Could not download filter from %s.", i.Url)
+		}
+		sha = repoVersion // The archives are identified by their hashes
+	} else {
+		// Download the filter using Git Getter
+		if !hasGit() {
+			return burrito.WrappedError(gitNotInstalledWarning)
+		}
+		repoVersion, err = GetRemoteFilterDownloadRef(
+			i.Url, i.remoteName(), i.Version)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, getRemoteFilterDownloadRefError, i.Url, i.remoteName(),
+				i.Version)
+		}
+		url := filterGetterUrl(i.Url, i.remoteName(), repoVersion)
+
+		_, err = os.Stat(downloadPath)
+		downloadPathIsNew := os.IsNotExist(err)
+		err = retryNetworkOperation("download filter "+i.Id, func() error {
+			var err error
+			sha, err = getRemoteFilter(downloadPath, url, noSubmodules)
+			if err != nil && downloadPathIsNew { // Remove the path created by getter
+				os.RemoveAll(downloadPath)
+			}
+			return err
+		})
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Could not download filter from %s.\n"+
+					"Does that filter exist?", url)
 		}
-		return burrito.WrapErrorf(
-			err, "Could not download filter from %s.\n"+
-				"Does that filter exist?", url)
 	}
 	// Save the version of the filter we downloaded
-	i.SaveVerssionInfo(trimFilterPrefix(repoVersion, i.Id), dotRegolithPath)
+	i.SaveVerssionInfo(
+		trimFilterPrefix(repoVersion, i.remoteName()), dotRegolithPath)
+	if sha != "" {
+		i.saveInstalledSha(sha, dotRegolithPath)
+	}
 	// Remove 'test' folder, which we never want to use (saves space on disk)
 	testFolder := path.Join(downloadPath, "test")
 	if _, err := os.Stat(testFolder); err == nil {
@@ -407,6 +606,22 @@ func (i *RemoteFilterDefinition) Download(
 	return nil
 }
 
+// filterGetterUrl returns the URL used by go-getter to download the folder of
+// the filter from the given Git reference of its repository.
+func filterGetterUrl(url, name, ref string) string {
+	// The filters of the monorepos are in a subfolder of the repository
+	repositoryUrl, folder := splitFilterUrl(url)
+	if isSshUrl(repositoryUrl) {
+		repositoryUrl = "git::" + sshGetterUrl(repositoryUrl)
+	} else if len(strings.Split(repositoryUrl, "/")) > 3 {
+		// The go-getter detectors assume that the repository is in the first
+		// three parts of the URL, which isn't true for the GitLab subgroups
+		repositoryUrl = "git::" + gitRemoteUrl(repositoryUrl)
+	}
+	return fmt.Sprintf(
+		"%s//%s?ref=%s", repositoryUrl, path.Join(folder, name), ref)
+}
+
 // SaveVersionInfo saves puts the specified version string into the
 // filter.json of the remote fileter.
 func (i *RemoteFilterDefinition) SaveVerssionInfo(version, dotRegolithPath string) error {
@@ -426,6 +641,39 @@ func (i *RemoteFilterDefinition) SaveVerssionInfo(version, dotRegolithPath strin
 	return nil
 }
 
+// saveInstalledSha saves the SHA of the downloaded commit into the
+// filter.json of the remote filter, so the lock file can be updated without
+// accessing the repository.
+func (i *RemoteFilterDefinition) saveInstalledSha(sha, dotRegolithPath string) error {
+	filterJsonMap, err := i.LoadFilterJson(dotRegolithPath)
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, "Could not load filter.json for \"%s\" filter.", i.Id)
+	}
+	filterJsonMap[installedShaKey] = sha
+	filterJson, _ := json.MarshalIndent(filterJsonMap, "", "\t") // no error
+	filterJsonPath := path.Join(i.GetDownloadPath(dotRegolithPath), "filter.json")
+	err = os.WriteFile(filterJsonPath, filterJson, 0644)
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, "Unable to write \"filter.json\" for %q filter.", i.Id)
+	}
+	return nil
+}
+
+// installedSha returns the SHA of the installed commit of the filter, saved
+// in its filter.json file by Download. It returns an empty string for the
+// filters downloaded before the SHAs were saved.
+func (f *RemoteFilterDefinition) installedSha(dotRegolithPath string) (string, error) {
+	filterJsonMap, err := f.LoadFilterJson(dotRegolithPath)
+	if err != nil {
+		return "", burrito.WrapErrorf(
+			err, "Could not load filter.json for %q filter.", f.Id)
+	}
+	sha, _ := filterJsonMap[installedShaKey].(string)
+	return sha, nil
+}
+
 // LoadFilterJson loads the filter.json file of the remote filter to a map.
 func (f *RemoteFilterDefinition) LoadFilterJson(dotRegolithPath string) (map[string]interface{}, error) {
 	downloadPath := f.GetDownloadPath(dotRegolithPath)
@@ -456,37 +704,93 @@ func (f *RemoteFilterDefinition) InstalledVersion(dotRegolithPath string) (strin
 	return versionStr, nil
 }
 
-func (f *RemoteFilterDefinition) Update(force bool, dotRegolithPath string) error {
+// Update downloads the filter and installs its dependencies, unless the
+// installed version of the filter already matches the version from the
+// filter definition. The force argument disables the check.
+func (f *RemoteFilterDefinition) Update(
+	force, noSubmodules bool, dotRegolithPath string,
+) error {
+	_, err := f.update(force, noSubmodules, dotRegolithPath)
+	return err
+}
+
+// update is the implementation of Update. It returns true if the filter was
+// downloaded, or false if the installed filter was up to date.
+func (f *RemoteFilterDefinition) update(
+	force, noSubmodules bool, dotRegolithPath string,
+) (bool, error) {
+	if f.isLocalRegistry() {
+		return f.updateLocalRegistry(force, dotRegolithPath)
+	}
 	installedVersion, err := f.InstalledVersion(dotRegolithPath)
-	installedVersion = trimFilterPrefix(installedVersion, f.Id)
+	installedVersion = trimFilterPrefix(installedVersion, f.remoteName())
 	if err != nil {
 		Logger.Warnf("Unable to get installed version of filter %q.", f.Id)
 	}
-	version, err := GetRemoteFilterDownloadRef(f.Url, f.Id, f.Version)
-	if err != nil {
-		return burrito.WrapErrorf(
-			err, getRemoteFilterDownloadRefError, f.Url, f.Id, f.Version)
-	}
-	version = trimFilterPrefix(version, f.Id)
-	if installedVersion != version || force {
-		Logger.Infof(
-			"Updating filter %q to new version: %q->%q.",
-			f.Id, installedVersion, version)
-		err = f.Download(true, dotRegolithPath)
-		if err != nil {
-			return burrito.PassError(err)
-		}
-		err = f.InstallDependencies(f, dotRegolithPath)
+	var version string
+	if Offline && installedVersion != "" && !force &&
+		(f.Version == "HEAD" || f.Version == "latest") {
+		// Finding the newest version requires accessing the network, so
+		// the installed version is used in the offline mode
+		version = installedVersion
+	} else {
+		version, err = GetRemoteFilterDownloadRef(
+			f.Url, f.remoteName(), f.Version)
 		if err != nil {
-			return burrito.PassError(err)
+			return false, burrito.WrapErrorf(
+				err, getRemoteFilterDownloadRefError, f.Url, f.remoteName(),
+				f.Version)
 		}
-		Logger.Infof("Filter %q updated successfully.", f.Id)
-	} else {
+		version = trimFilterPrefix(version, f.remoteName())
+	}
+	if !force && installedVersion != version &&
+		f.isInstalledCommit(installedVersion, version, dotRegolithPath) {
+		// The filter was installed from a tag, and is now pinned to the SHA
+		// of the same commit (for example by the lock file)
+		installedVersion = version
+	}
+	if installedVersion == version && !force {
 		Logger.Infof(
 			"Filter %q is up to date. Installed version: %q.",
 			f.Id, installedVersion)
+		return false, nil
 	}
-	return nil
+	Logger.Infof(
+		"Updating filter %q to new version: %q->%q.",
+		f.Id, installedVersion, version)
+	err = f.Download(true, noSubmodules, dotRegolithPath)
+	if err != nil {
+		return false, burrito.PassError(err)
+	}
+	err = f.InstallDependencies(f, dotRegolithPath)
+	if err != nil {
+		return false, burrito.PassError(err)
+	}
+	Logger.Infof("Filter %q updated successfully.", f.Id)
+	return true, nil
+}
+
+// isInstalledCommit returns true if the version is the SHA of the commit
+// of the installed version of the filter. The installed version is
+// usually a semantic version. Its SHA is read from the installed filter, or
+// resolved with the remote repository for the filters downloaded before the
+// SHAs were saved. Any errors mean that the versions are different.
+func (f *RemoteFilterDefinition) isInstalledCommit(
+	installedVersion, version, dotRegolithPath string,
+) bool {
+	if installedVersion == "" || !shaPattern.MatchString(version) ||
+		shaPattern.MatchString(installedVersion) {
+		return false
+	}
+	if sha, err := f.installedSha(dotRegolithPath); err == nil && sha != "" {
+		return sha == version
+	}
+	ref := installedVersion
+	if semver.IsValid("v" + installedVersion) {
+		ref = f.remoteName() + "-" + installedVersion
+	}
+	sha, err := GetRemoteFilterSha(f.Url, ref)
+	return err == nil && sha == version
 }
 
 // GetDownloadPath returns the path location where the filter can be found.
@@ -0,0 +1,134 @@
+package regolith
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The SHAs of the commits used by the tests of the remote filters.
+var (
+	testInstalledSha = strings.Repeat("a", 40)
+	testOtherSha     = strings.Repeat("b", 40)
+)
+
+// writeTestFilterJson creates the filter.json file of the installed remote
+// filter in the cache of the filters.
+func writeTestFilterJson(
+	t *testing.T, dotRegolithPath, id string, filterJson map[string]interface{},
+) {
+	filterPath := filepath.Join(dotRegolithPath, "cache/filters", id)
+	if err := os.MkdirAll(filterPath, 0755); err != nil {
+		t.Fatal("Failed to create the installed filter:", err)
+	}
+	data, _ := json.Marshal(filterJson)
+	err := os.WriteFile(filepath.Join(filterPath, "filter.json"), data, 0644)
+	if err != nil {
+		t.Fatal("Failed to create the installed filter:", err)
+	}
+}
+
+// TestIsInstalledCommit checks whether the filters installed from a tag are
+// recognized as up to date when they're pinned to the SHA of the installed
+// commit saved in their filter.json files.
+func TestIsInstalledCommit(t *testing.T) {
+	InitLogging(false)
+	dotRegolithPath := t.TempDir()
+	filter := &RemoteFilterDefinition{
+		FilterDefinition: FilterDefinition{Id: "name_ninja"},
+		Url:              "github.com/owner/repo",
+	}
+	writeTestFilterJson(t, dotRegolithPath, "name_ninja", map[string]interface{}{
+		"filters": []interface{}{}, "version": "1.2.3",
+		installedShaKey: testInstalledSha})
+	tests := []struct {
+		installedVersion string
+		version          string
+		expected         bool
+	}{
+		{"1.2.3", testInstalledSha, true},
+		{"1.2.3", testOtherSha, false},
+		{"", testInstalledSha, false},
+		{"1.2.3", "1.2.3", false},
+		{testOtherSha, testInstalledSha, false},
+	}
+	for _, test := range tests {
+		actual := filter.isInstalledCommit(
+			test.installedVersion, test.version, dotRegolithPath)
+		if actual != test.expected {
+			t.Errorf(
+				"isInstalledCommit(%q, %q) = %v, expected %v",
+				test.installedVersion, test.version, actual, test.expected)
+		}
+	}
+}
+
+// TestUpdateOffline checks whether the installed filters are up to date in
+// the offline mode when their versions don't require accessing the network,
+// and whether the filters that would have to be downloaded fail.
+func TestUpdateOffline(t *testing.T) {
+	InitLogging(false)
+	offline := Offline
+	Offline = true
+	defer func() { Offline = offline }()
+	dotRegolithPath := t.TempDir()
+	writeTestFilterJson(t, dotRegolithPath, "name_ninja", map[string]interface{}{
+		"filters": []interface{}{}, "version": "1.2.3",
+		installedShaKey: testInstalledSha})
+	tests := []struct {
+		version string
+		success bool
+	}{
+		{"HEAD", true},
+		{"latest", true},
+		{"1.2.3", true},
+		{testInstalledSha, true},
+		{"2.0.0", false},
+		{testOtherSha, false},
+	}
+	for _, test := range tests {
+		filter := &RemoteFilterDefinition{
+			FilterDefinition: FilterDefinition{Id: "name_ninja"},
+			Url:              "github.com/owner/repo",
+			Version:          test.version,
+		}
+		updated, err := filter.update(false, false, dotRegolithPath)
+		if !test.success {
+			if err == nil {
+				t.Errorf("%s: the update didn't fail", test.version)
+			} else if !strings.Contains(err.Error(), "offline mode") {
+				t.Errorf("%s: unexpected error: %s", test.version, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: the update failed: %s", test.version, err)
+		} else if updated {
+			t.Errorf("%s: the installed filter was downloaded", test.version)
+		}
+	}
+}
+
+// TestFilterDefinitionFromTheInternetOffline checks whether the version of
+// the filter is required in the offline mode.
+func TestFilterDefinitionFromTheInternetOffline(t *testing.T) {
+	InitLogging(false)
+	offline, userConfig := Offline, cachedCombinedUserConfig
+	Offline, cachedCombinedUserConfig = true, NewUserConfig()
+	defer func() { Offline, cachedCombinedUserConfig = offline, userConfig }()
+	_, err := FilterDefinitionFromTheInternet(
+		"github.com/owner/repo", "name_ninja", "")
+	if err == nil {
+		t.Error("The filter without a version was resolved in the offline mode")
+	}
+	filter, err := FilterDefinitionFromTheInternet(
+		"github.com/owner/repo", "name_ninja", "1.2.3")
+	if err != nil {
+		t.Fatal("Failed to resolve the filter with a version:", err)
+	}
+	if filter.Version != "1.2.3" {
+		t.Errorf("Unexpected version of the filter: %q", filter.Version)
+	}
+}
@@ -0,0 +1,96 @@
+// Functions used for running the filters again after they fail, configured
+// with the "retries" and "retryDelay" properties of the filter definitions.
+package regolith
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+	"github.com/otiai10/copy"
+)
+
+// defaultFilterRetryDelay is the delay between the attempts of a failed
+// filter used when its definition doesn't have the "retryDelay" property.
+const defaultFilterRetryDelay = time.Second
+
+// filterRetryBackupName is the name of the directory next to the tmp
+// directory that stores the files from before the first attempt of the
+// filter.
+const filterRetryBackupName = ".retryBackup"
+
+// backupFilterInput copies the content of the tmp directory to the backup
+// directory, so every attempt of the filter starts with the same files.
+func backupFilterInput(dotRegolithPath string) error {
+	tmpPath := getTmpPath(dotRegolithPath)
+	backupPath := filepath.Join(
+		getTmpRoot(dotRegolithPath), filterRetryBackupName)
+	if err := os.RemoveAll(backupPath); err != nil {
+		return burrito.WrapErrorf(err, osRemoveError, backupPath)
+	}
+	if err := copy.Copy(tmpPath, backupPath); err != nil {
+		return burrito.WrapErrorf(err, osCopyError, tmpPath, backupPath)
+	}
+	return nil
+}
+
+// restoreFilterInput replaces the content of the tmp directory, which could
+// be partially modified by the failed attempt of the filter, with the backup
+// from before the first attempt.
+func restoreFilterInput(dotRegolithPath string) error {
+	tmpPath := getTmpPath(dotRegolithPath)
+	backupPath := filepath.Join(
+		getTmpRoot(dotRegolithPath), filterRetryBackupName)
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return burrito.WrapErrorf(err, osRemoveError, tmpPath)
+	}
+	if err := copy.Copy(backupPath, tmpPath); err != nil {
+		return burrito.WrapErrorf(err, osCopyError, backupPath, tmpPath)
+	}
+	return nil
+}
+
+// runFilterWithRetries runs the filter and, if it fails, runs it again up
+// to the number of the retries from its definition. Before every retry, the
+// tmp directory is restored to the state from before the first attempt. The
+// filters without retries run only once. The problems found by the Check
+// functions are reported before running the profile, so they're never
+// retried.
+func runFilterWithRetries(
+	filter FilterRunner, context RunContext, retries int, delay time.Duration,
+) (bool, error) {
+	if retries <= 0 {
+		return filter.Run(context)
+	}
+	if err := backupFilterInput(context.DotRegolithPath); err != nil {
+		return false, burrito.WrapErrorf(
+			err, "Failed to back up the input of the filter.\nFilter: %s",
+			filter.GetId())
+	}
+	defer func() {
+		backupPath := filepath.Join(
+			getTmpRoot(context.DotRegolithPath), filterRetryBackupName)
+		if err := os.RemoveAll(backupPath); err != nil {
+			Logger.Warnf(
+				"Failed to remove the backup of the input of the filter.\n"+
+					"Path: %s", backupPath)
+		}
+	}()
+	for attempt := 1; ; attempt++ {
+		interrupted, err := filter.Run(context)
+		if err == nil || interrupted || attempt > retries ||
+			context.cancellation.isCancelled() {
+			return interrupted, err
+		}
+		context.logWarnf(
+			"Filter %s failed (attempt %d of %d). Retrying in %s...\n%s",
+			filter.GetId(), attempt, retries+1, delay, err.Error())
+		time.Sleep(delay)
+		if err := restoreFilterInput(context.DotRegolithPath); err != nil {
+			return false, burrito.WrapErrorf(
+				err, "Failed to restore the input of the filter.\n"+
+					"Filter: %s", filter.GetId())
+		}
+	}
+}
@@ -3,6 +3,7 @@ package regolith
 import (
 	"encoding/json"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/Bedrock-OSS/go-burrito/burrito"
@@ -11,6 +12,11 @@ import (
 type ShellFilterDefinition struct {
 	FilterDefinition
 	Command string `json:"command,omitempty"`
+
+	// Script is an alternative to the Command. It's a path to a script
+	// (.ps1, .bat, .cmd, .sh or .bash) run with the interpreter that matches
+	// its extension.
+	Script string `json:"script,omitempty"`
 }
 
 type ShellFilter struct {
@@ -22,7 +28,24 @@ func ShellFilterDefinitionFromObject(
 	id string, obj map[string]interface{},
 ) (*ShellFilterDefinition, error) {
 	filter := &ShellFilterDefinition{
-		FilterDefinition: *FilterDefinitionFromObject(id)}
+		FilterDefinition: *FilterDefinitionFromObject(id, obj)}
+	if scriptObj, ok := obj["script"]; ok {
+		script, ok := scriptObj.(string)
+		if !ok {
+			return nil, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "script", "string")
+		}
+		if _, ok := obj["command"]; ok {
+			return nil, burrito.WrappedError(
+				"The \"command\" and \"script\" properties can't be used " +
+					"together.")
+		}
+		if _, err := findScriptInterpreters(script); err != nil {
+			return nil, burrito.PassError(err)
+		}
+		filter.Script = script
+		return filter, nil
+	}
 	commandObj, ok := obj["command"]
 	if !ok {
 		return nil, burrito.WrapErrorf(nil, jsonPropertyMissingError, "command")
@@ -61,6 +84,14 @@ func (f *ShellFilterDefinition) InstallDependencies(*RemoteFilterDefinition, str
 }
 
 func (f *ShellFilterDefinition) Check(context RunContext) error {
+	if f.Script != "" {
+		interpreter, _, err := findScriptInterpreter(f.Script)
+		if err != nil {
+			return burrito.WrapError(err, "Shell requirements check failed")
+		}
+		Logger.Debugf("Using interpreter: %s", interpreter)
+		return nil
+	}
 	shell, _, err := findShell()
 	if err != nil {
 		return burrito.WrapError(err, "Shell requirements check failed")
@@ -76,19 +107,36 @@ func (f *ShellFilter) Check(context RunContext) error {
 var shells = [][]string{
 	{"powershell", "-command"}, {"cmd", "/k"}, {"bash", "-c"}, {"sh", "-c"}}
 
+// scriptInterpreters maps the extensions of the scripts supported by the
+// "script" property of the shell filters to the commands that can run them,
+// in the order of preference. The path to the script is appended to the
+// command.
+var scriptInterpreters = map[string][][]string{
+	".ps1": {
+		{"pwsh", "-NoProfile", "-ExecutionPolicy", "Bypass", "-File"},
+		{"powershell", "-NoProfile", "-ExecutionPolicy", "Bypass", "-File"}},
+	".bat":  {{"cmd", "/c"}},
+	".cmd":  {{"cmd", "/c"}},
+	".sh":   {{"sh"}, {"bash"}},
+	".bash": {{"bash"}},
+}
+
 func (f *ShellFilter) run(
 	settings map[string]interface{},
 	context RunContext,
 ) error {
+	if f.Definition.Script != "" {
+		return f.runScript(settings, context)
+	}
 	var err error = nil
 	if len(settings) == 0 {
-		err = executeCommand(f.Id,
+		err = executeCommand(&context, f.Id,
 			f.Definition.Command,
 			f.Arguments, context.AbsoluteLocation,
 			GetAbsoluteWorkingDirectory(context.DotRegolithPath))
 	} else {
 		jsonSettings, _ := json.Marshal(settings)
-		err = executeCommand(f.Id,
+		err = executeCommand(&context, f.Id,
 			f.Definition.Command,
 			append([]string{string(jsonSettings)}, f.Arguments...),
 			context.AbsoluteLocation,
@@ -100,16 +148,76 @@ func (f *ShellFilter) run(
 	return nil
 }
 
-func executeCommand(id string,
+// DryRun prints the shell command of the filter, or the script of the filter
+// with the interpreter that would run it.
+func (f *ShellFilter) DryRun(context RunContext) error {
+	var command []string
+	if f.Definition.Script != "" {
+		interpreter, interpreterArgs, err := findScriptInterpreter(
+			f.Definition.Script)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+		command = append(append([]string{interpreter}, interpreterArgs...),
+			filepath.Join(context.AbsoluteLocation, f.Definition.Script))
+	} else {
+		command = []string{f.Definition.Command}
+	}
+	if len(f.Settings) != 0 {
+		jsonSettings, _ := json.Marshal(f.Settings)
+		command = append(command, string(jsonSettings))
+	}
+	command = append(command, f.Arguments...)
+	printDryRunFilter(f, context, strings.Join(command, " "))
+	return nil
+}
+
+// runScript runs the script of the filter with the interpreter that matches
+// its extension. The settings (as JSON) and the arguments of the filter are
+// passed to the script.
+func (f *ShellFilter) runScript(
+	settings map[string]interface{}, context RunContext,
+) error {
+	interpreter, interpreterArgs, err := findScriptInterpreter(
+		f.Definition.Script)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	scriptPath := filepath.Join(context.AbsoluteLocation, f.Definition.Script)
+	args := append(interpreterArgs, scriptPath)
+	if len(settings) != 0 {
+		jsonSettings, _ := json.Marshal(settings)
+		args = append(args, string(jsonSettings))
+	}
+	args = append(args, f.Arguments...)
+	err = RunSubProcess(
+		&context, interpreter, args, context.AbsoluteLocation,
+		GetAbsoluteWorkingDirectory(context.DotRegolithPath),
+		ShortFilterName(f.Id))
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return burrito.WrapErrorf(
+				err, "The script of the filter failed.\n"+
+					"Script: %s\nExit code: %d",
+				f.Definition.Script, exitErr.ExitCode())
+		}
+		return burrito.WrapErrorf(
+			err, "Failed to run the script of the filter.\nScript: %s",
+			f.Definition.Script)
+	}
+	return nil
+}
+
+func executeCommand(context *RunContext, id string,
 	command string, args []string, filterDir string, workingDir string,
 ) error {
 	joined := strings.Join(append([]string{command}, args...), " ")
-	Logger.Debugf("Executing command: %s", joined)
+	context.logger().Debugf("Executing command: %s", joined)
 	shell, arg, err := findShell()
 	if err != nil {
 		return burrito.WrapError(err, "Unable to find a valid shell.")
 	}
-	err = RunSubProcess(shell, []string{arg, joined}, filterDir, workingDir, ShortFilterName(id))
+	err = RunSubProcess(context, shell, []string{arg, joined}, filterDir, workingDir, ShortFilterName(id))
 	if err != nil {
 		return burrito.WrapError(err, runSubProcessError)
 	}
@@ -125,3 +233,37 @@ func findShell() (string, string, error) {
 	}
 	return "", "", burrito.WrappedError("Unable to find a valid shell.")
 }
+
+// findScriptInterpreters returns the commands that can run the script based
+// on its extension.
+func findScriptInterpreters(script string) ([][]string, error) {
+	extension := strings.ToLower(filepath.Ext(script))
+	interpreters, ok := scriptInterpreters[extension]
+	if !ok {
+		return nil, burrito.WrappedErrorf(
+			"Unsupported extension of the script.\n"+
+				"Script: %s\n"+
+				"Supported extensions: .ps1, .bat, .cmd, .sh, .bash", script)
+	}
+	return interpreters, nil
+}
+
+// findScriptInterpreter returns the first installed interpreter that can run
+// the script and the arguments that go before the path to the script.
+func findScriptInterpreter(script string) (string, []string, error) {
+	interpreters, err := findScriptInterpreters(script)
+	if err != nil {
+		return "", nil, burrito.PassError(err)
+	}
+	names := make([]string, len(interpreters))
+	for i, interpreter := range interpreters {
+		if _, err := exec.LookPath(interpreter[0]); err == nil {
+			return interpreter[0], append([]string{}, interpreter[1:]...), nil
+		}
+		names[i] = interpreter[0]
+	}
+	return "", nil, burrito.WrappedErrorf(
+		"Unable to find an interpreter for the script.\n"+
+			"Script: %s\nInterpreters: %s",
+		script, strings.Join(names, ", "))
+}
@@ -0,0 +1,73 @@
+package regolith
+
+import (
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// normalizeFilterSource returns the source URL in a form that can be compared
+// with the prefixes from the "allowed_filter_sources" user config property.
+// The scheme, trailing slashes and letter case are ignored. The SSH URLs
+// are converted to the "<host>/<path>" form, so "git@github.com:org/repo"
+// matches "github.com/org/repo".
+func normalizeFilterSource(source string) string {
+	source = strings.ToLower(strings.TrimSpace(source))
+	if isSshUrl(source) {
+		prefix, path := splitSshUrl(source)
+		source = sshUrlHost(prefix) + "/" + path
+	}
+	for _, scheme := range []string{"https://", "http://"} {
+		source = strings.TrimPrefix(source, scheme)
+	}
+	// The "//" separator of the repositories with longer paths is not a
+	// part of the path
+	for strings.Contains(source, "//") {
+		source = strings.ReplaceAll(source, "//", "/")
+	}
+	return strings.TrimRight(source, "/")
+}
+
+// checkFilterSourceAllowed checks if the filter from the given repository URL
+// and filter name can be downloaded according to the "allowed_filter_sources"
+// property of the user config. An empty list allows all sources.
+func checkFilterSourceAllowed(url, name string) error {
+	userConfig, err := getCombinedUserConfig()
+	if err != nil {
+		return burrito.WrapError(err, getUserConfigError)
+	}
+	if len(userConfig.AllowedFilterSources) == 0 {
+		return nil
+	}
+	source := normalizeFilterSource(url + "/" + name)
+	if hasDotSegments(source) {
+		return burrito.WrappedErrorf(filterSourceDotSegmentsError, name, url)
+	}
+	for _, allowed := range userConfig.AllowedFilterSources {
+		prefix := normalizeFilterSource(allowed)
+		if prefix == "" {
+			continue
+		}
+		// The prefix must end at a path separator to avoid matching
+		// "github.com/org-other" with "github.com/org"
+		if source == prefix || strings.HasPrefix(source, prefix+"/") {
+			return nil
+		}
+	}
+	return burrito.WrappedErrorf(
+		filterSourceNotAllowedError, name, url,
+		strings.Join(userConfig.AllowedFilterSources, "\n\t"))
+}
+
+// hasDotSegments returns true if the normalized source URL has "." or ".."
+// path segments. The prefixes of the "allowed_filter_sources" user config
+// property are compared as text, so "github.com/org/../evil/repo" would
+// match "github.com/org".
+func hasDotSegments(source string) bool {
+	for _, segment := range strings.Split(source, "/") {
+		if segment == "." || segment == ".." {
+			return true
+		}
+	}
+	return false
+}
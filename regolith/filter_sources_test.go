@@ -0,0 +1,78 @@
+package regolith
+
+import "testing"
+
+// TestNormalizeFilterSource checks whether the scheme, the letter case, the
+// trailing slashes and the "//" separator are ignored, and whether the SSH
+// URLs are converted to the form of the HTTPS URLs.
+func TestNormalizeFilterSource(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"github.com/org/repo", "github.com/org/repo"},
+		{"https://github.com/org/repo", "github.com/org/repo"},
+		{"http://github.com/org/repo", "github.com/org/repo"},
+		{"  GitHub.com/Org/Repo/  ", "github.com/org/repo"},
+		{"git@github.com:org/repo", "github.com/org/repo"},
+		{"ssh://git@github.com/org/repo", "github.com/org/repo"},
+		{"gitlab.com/group/subgroup/repo//filters",
+			"gitlab.com/group/subgroup/repo/filters"},
+		{"github.com/org/../evil/repo", "github.com/org/../evil/repo"},
+	}
+	for _, test := range tests {
+		if actual := normalizeFilterSource(test.source); actual != test.expected {
+			t.Errorf(
+				"normalizeFilterSource(%q) = %q, expected %q",
+				test.source, actual, test.expected)
+		}
+	}
+}
+
+// TestCheckFilterSourceAllowed checks whether the filters are allowed only
+// from the sources that start with the prefixes from the
+// "allowed_filter_sources" user config property, and whether the URLs with
+// the "." and ".." path segments can't bypass the check.
+func TestCheckFilterSourceAllowed(t *testing.T) {
+	InitLogging(false)
+	userConfig := cachedCombinedUserConfig
+	defer func() { cachedCombinedUserConfig = userConfig }()
+	cachedCombinedUserConfig = NewUserConfig()
+	cachedCombinedUserConfig.AllowedFilterSources = []string{
+		"https://github.com/Org/", "gitlab.com/group/subgroup"}
+	tests := []struct {
+		url     string
+		name    string
+		allowed bool
+	}{
+		{"github.com/org/repo", "filter", true},
+		{"GitHub.com/ORG/repo/", "filter", true},
+		{"git@github.com:org/repo", "filter", true},
+		{"ssh://git@github.com/org/repo", "filter", true},
+		{"github.com/org-other/repo", "filter", false},
+		{"github.com/other/repo", "filter", false},
+		{"gitlab.com/group/subgroup/repo//", "filter", true},
+		{"gitlab.com/group/subgroup/repo//filters", "filter", true},
+		{"gitlab.com/group/subgroup-other/repo//", "filter", false},
+		{"github.com/org/../evil/repo", "filter", false},
+		{"github.com/org/./repo", "filter", false},
+		{"git@github.com:org/../evil/repo", "filter", false},
+		{"github.com/org/repo", "..", false},
+	}
+	for _, test := range tests {
+		err := checkFilterSourceAllowed(test.url, test.name)
+		if test.allowed && err != nil {
+			t.Errorf(
+				"The filter %q from %q wasn't allowed: %s",
+				test.name, test.url, err)
+		} else if !test.allowed && err == nil {
+			t.Errorf(
+				"The filter %q from %q was allowed", test.name, test.url)
+		}
+	}
+	// An empty list allows all sources
+	cachedCombinedUserConfig.AllowedFilterSources = nil
+	if err := checkFilterSourceAllowed("github.com/other/repo", "filter"); err != nil {
+		t.Error("The filter wasn't allowed without a list of sources:", err)
+	}
+}
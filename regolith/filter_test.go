@@ -0,0 +1,95 @@
+package regolith
+
+import (
+	"reflect"
+	"testing"
+)
+
+// newTestChildContext returns a context with the fields shared with the
+// nested filters set.
+func newTestChildContext() RunContext {
+	return RunContext{
+		AbsoluteLocation:    "project",
+		Config:              &Config{},
+		Profile:             "default",
+		DotRegolithPath:     ".regolith",
+		Options:             RunOptions{FilterLogs: true},
+		interruptionChannel: make(chan watchInterruption),
+		visitedProfiles:     map[string]struct{}{"parent": {}},
+		cancellation:        &runCancellation{},
+		dryRunDepth:         1,
+		logDepth:            2,
+		changedFiles:        []string{"BP/entity.json"},
+		filterFailures:      &filterFailures{},
+		filterWarnings:      &filterWarnings{},
+		exportedPacks:       newExportedPacks(),
+		tmpDataLinks:        &tmpDataLinks{},
+		retryFileLocks:      true,
+		maxMemory:           1024,
+		runSummary:          &runSummary{},
+	}
+}
+
+// TestChildContext checks whether the child context copies every field of
+// the context and increases the indentation of the dry run.
+func TestChildContext(t *testing.T) {
+	context := newTestChildContext()
+	child := context.childContext()
+	if child.dryRunDepth != context.dryRunDepth+1 {
+		t.Errorf("Unexpected dry run depth: %d", child.dryRunDepth)
+	}
+	child.dryRunDepth = context.dryRunDepth
+	if !reflect.DeepEqual(child, context) {
+		t.Errorf(
+			"The child context doesn't match the context.\n"+
+				"Expected: %+v\nActual: %+v", context, child)
+	}
+}
+
+// TestNestedContexts checks whether the contexts of the nested profiles and
+// the subfilters of the remote filters keep the state of the run, like the
+// cancellation and the indentation of the logs.
+func TestNestedContexts(t *testing.T) {
+	context := newTestChildContext()
+	config := &Config{}
+	profileFilter := &ProfileFilter{Profile: "nested"}
+	nested := profileFilter.nestedContext(context, config)
+	if nested.Profile != "nested" || nested.Config != config {
+		t.Errorf(
+			"The nested profile has unexpected profile or config: %q",
+			nested.Profile)
+	}
+	if nested.Parent == nil || nested.Parent.Profile != "default" {
+		t.Error("The parent of the nested profile is not the context")
+	}
+	if !nested.isProfileVisited("parent") || !nested.isProfileVisited("default") {
+		t.Error("The nested profile doesn't know the visited profiles")
+	}
+	if nested.logDepth != 3 || nested.dryRunDepth != 2 {
+		t.Errorf(
+			"Unexpected depths of the nested profile: log %d, dry run %d",
+			nested.logDepth, nested.dryRunDepth)
+	}
+	if nested.cancellation != context.cancellation ||
+		nested.interruptionChannel != context.interruptionChannel {
+		t.Error("The nested profile doesn't share the state of the run")
+	}
+	remoteFilter := &RemoteFilter{}
+	subfilter := remoteFilter.subfilterContext(context, "filter")
+	if subfilter.AbsoluteLocation != "filter" {
+		t.Errorf(
+			"Unexpected location of the subfilter: %q",
+			subfilter.AbsoluteLocation)
+	}
+	if subfilter.cancellation != context.cancellation ||
+		subfilter.logDepth != context.logDepth ||
+		subfilter.runSummary != context.runSummary {
+		t.Error("The subfilter doesn't share the state of the run")
+	}
+	if subfilter.interruptionChannel != nil {
+		t.Error("The subfilter can receive the interruptions")
+	}
+	if subfilter.dryRunDepth != 2 {
+		t.Errorf("Unexpected dry run depth: %d", subfilter.dryRunDepth)
+	}
+}
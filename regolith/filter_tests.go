@@ -0,0 +1,201 @@
+// Functions used by the "regolith test" command, which runs a filter on the
+// test fixtures and compares the results with their expected outputs.
+package regolith
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// The directories of a test fixture with the files passed to the filter and
+// with the files expected after running the filter.
+const (
+	fixtureInputDir    = "input"
+	fixtureExpectedDir = "expected"
+)
+
+// fixturePacks is the list of the directories of the inputs and the
+// expected outputs of the test fixtures, named the same as their
+// directories in the tmp directory.
+var fixturePacks = []string{"BP", "RP", "data"}
+
+// listFilterTestFixtures returns the sorted names of the test fixtures from
+// the testsPath directory. Every subdirectory of testsPath with the "input"
+// directory is a fixture. If the names list isn't empty, only the fixtures
+// from the list are returned.
+func listFilterTestFixtures(testsPath string, names []string) ([]string, error) {
+	entries, err := os.ReadDir(testsPath)
+	if err != nil {
+		return nil, burrito.WrapErrorf(err, osReadDirError, testsPath)
+	}
+	fixtures := make(map[string]struct{})
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		inputPath := filepath.Join(testsPath, entry.Name(), fixtureInputDir)
+		if stat, err := os.Stat(inputPath); err != nil || !stat.IsDir() {
+			continue
+		}
+		fixtures[entry.Name()] = struct{}{}
+	}
+	if len(names) == 0 {
+		for name := range fixtures {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+	for _, name := range names {
+		if _, ok := fixtures[name]; !ok {
+			return nil, burrito.WrappedErrorf(
+				"The test fixture doesn't exist or has no %q directory.\n"+
+					"Fixture: %s\nTests path: %s",
+				fixtureInputDir, name, testsPath)
+		}
+	}
+	return names, nil
+}
+
+// runFilterTest runs the filter on the input files of the test fixture and
+// compares the results with the expected files of the fixture. Only the
+// directories that exist in the "expected" directory of the fixture are
+// compared. It returns the list of the differences, which is empty if the
+// test passed.
+func runFilterTest(
+	filterRunner FilterRunner, config Config, context RunContext,
+	fixturePath string,
+) ([]string, error) {
+	inputPaths := make(map[string]string, len(fixturePacks))
+	for _, pack := range fixturePacks {
+		path := filepath.Join(fixturePath, fixtureInputDir, pack)
+		if _, err := os.Stat(path); err == nil {
+			inputPaths[pack] = path
+		}
+	}
+	// The fixture replaces the source files of the project
+	config.BehaviorFolder = inputPaths["BP"]
+	config.ResourceFolder = inputPaths["RP"]
+	config.DataPath = inputPaths["data"]
+	context.Config = &config
+	err := SetupTmpFiles(config, context.DotRegolithPath, false)
+	if err != nil {
+		return nil, burrito.WrapErrorf(
+			err, setupTmpFilesError, context.DotRegolithPath)
+	}
+	_, err = filterRunner.Run(context)
+	if err != nil {
+		return nil, burrito.WrapErrorf(
+			err, filterRunnerRunError, filterRunner.GetId())
+	}
+	result := []string{}
+	for _, pack := range fixturePacks {
+		expectedPath := filepath.Join(fixturePath, fixtureExpectedDir, pack)
+		if _, err := os.Stat(expectedPath); os.IsNotExist(err) {
+			continue
+		}
+		actualPath := filepath.Join(getTmpPath(context.DotRegolithPath), pack)
+		differences, err := compareDirs(expectedPath, actualPath, pack)
+		if err != nil {
+			return nil, burrito.PassError(err)
+		}
+		result = append(result, differences...)
+	}
+	return result, nil
+}
+
+// listDirFiles returns the set of the paths of the files in the directory,
+// relative to the directory and using forward slashes. The directory that
+// doesn't exist is treated as empty.
+func listDirFiles(dir string) (map[string]struct{}, error) {
+	result := make(map[string]struct{})
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return result, nil
+	}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return burrito.WrapErrorf(err, osStatErrorAny, path)
+		}
+		if d.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return burrito.WrapErrorf(err, filepathRelError, dir, path)
+		}
+		result[filepath.ToSlash(relPath)] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, burrito.PassError(err)
+	}
+	return result, nil
+}
+
+// compareDirs compares the files of the expected directory with the files
+// of the actual directory and returns the descriptions of the differences.
+// The text files with different content are described with unified diffs.
+// The paths in the descriptions start with the prefix.
+func compareDirs(expected, actual, prefix string) ([]string, error) {
+	expectedFiles, err := listDirFiles(expected)
+	if err != nil {
+		return nil, burrito.PassError(err)
+	}
+	actualFiles, err := listDirFiles(actual)
+	if err != nil {
+		return nil, burrito.PassError(err)
+	}
+	paths := make([]string, 0, len(expectedFiles)+len(actualFiles))
+	for path := range expectedFiles {
+		paths = append(paths, path)
+	}
+	for path := range actualFiles {
+		if _, ok := expectedFiles[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	result := []string{}
+	for _, path := range paths {
+		name := prefix + "/" + path
+		_, inExpected := expectedFiles[path]
+		_, inActual := actualFiles[path]
+		if !inActual {
+			result = append(result, "Missing file: "+name)
+			continue
+		}
+		if !inExpected {
+			result = append(result, "Unexpected file: "+name)
+			continue
+		}
+		expectedPath := filepath.Join(expected, filepath.FromSlash(path))
+		expectedData, err := os.ReadFile(expectedPath)
+		if err != nil {
+			return nil, burrito.WrapErrorf(err, fileReadError, expectedPath)
+		}
+		actualPath := filepath.Join(actual, filepath.FromSlash(path))
+		actualData, err := os.ReadFile(actualPath)
+		if err != nil {
+			return nil, burrito.WrapErrorf(err, fileReadError, actualPath)
+		}
+		if bytes.Equal(expectedData, actualData) {
+			continue
+		}
+		if !utf8.Valid(expectedData) || !utf8.Valid(actualData) {
+			result = append(result, "Binary files differ: "+name)
+			continue
+		}
+		diff := unifiedDiff(
+			"expected/"+name, "actual/"+name,
+			string(expectedData), string(actualData))
+		result = append(result, strings.TrimSuffix(diff, "\n"))
+	}
+	return result, nil
+}
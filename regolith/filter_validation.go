@@ -0,0 +1,177 @@
+// Functions used for validating the output of a filter with the "validate"
+// property, right after the filter runs.
+package regolith
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+	"muzzammil.xyz/jsonc"
+)
+
+// FilterValidation is the "validate" property of a filter. It describes the
+// checks of the files in the tmp directory that run after the filter.
+type FilterValidation struct {
+	// Json is a list of glob patterns (with the syntax of path.Match) of the
+	// files that must be valid JSON. The patterns are matched against the
+	// paths relative to the tmp directory (like "BP/items/*.json") and
+	// against the names of the files (like "*.json"). Comments are allowed.
+	Json []string `json:"json,omitempty"`
+
+	// Exists is a list of the paths relative to the tmp directory that must
+	// exist.
+	Exists []string `json:"exists,omitempty"`
+
+	// Command is a shell command that runs in the tmp directory. The
+	// validation fails if it exits with a non-zero exit code.
+	Command string `json:"command,omitempty"`
+}
+
+// filterValidationFromObject creates a FilterValidation from the value of
+// the "validate" property of a filter.
+func filterValidationFromObject(obj interface{}) (*FilterValidation, error) {
+	validateObj, ok := obj.(map[string]interface{})
+	if !ok {
+		return nil, burrito.WrappedErrorf(
+			jsonPropertyTypeError, "validate", "object")
+	}
+	stringList := func(name string) ([]string, error) {
+		listObj, ok := validateObj[name]
+		if !ok {
+			return nil, nil
+		}
+		list, ok := listObj.([]interface{})
+		if !ok {
+			return nil, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "validate->"+name, "array")
+		}
+		result := make([]string, len(list))
+		for i, item := range list {
+			item, ok := item.(string)
+			if !ok {
+				return nil, burrito.WrappedErrorf(
+					jsonPropertyTypeError,
+					fmt.Sprintf("validate->%s->%d", name, i), "string")
+			}
+			result[i] = item
+		}
+		return result, nil
+	}
+	result := &FilterValidation{}
+	var err error
+	result.Json, err = stringList("json")
+	if err != nil {
+		return nil, burrito.PassError(err)
+	}
+	for i, pattern := range result.Json {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, burrito.WrapErrorf(
+				err, "Invalid glob pattern.\nProperty: validate->json->%d\n"+
+					"Pattern: %s", i, pattern)
+		}
+	}
+	result.Exists, err = stringList("exists")
+	if err != nil {
+		return nil, burrito.PassError(err)
+	}
+	if command, ok := validateObj["command"]; ok {
+		command, ok := command.(string)
+		if !ok {
+			return nil, burrito.WrappedErrorf(
+				jsonPropertyTypeError, "validate->command", "string")
+		}
+		result.Command = command
+	}
+	return result, nil
+}
+
+// matchesJsonPattern returns true if the file from the path relative to the
+// tmp directory must be valid JSON.
+func (v *FilterValidation) matchesJsonPattern(relPath string) bool {
+	slashPath := filepath.ToSlash(relPath)
+	for _, pattern := range v.Json {
+		if ok, _ := path.Match(pattern, slashPath); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(slashPath)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate runs the checks of the validation on the tmp directory. It
+// returns an error that lists all of the problems found by the checks.
+func (v *FilterValidation) Validate(
+	context *RunContext, dotRegolithPath string,
+) error {
+	tmpPath, err := filepath.Abs(getTmpPath(dotRegolithPath))
+	if err != nil {
+		return burrito.WrapErrorf(err, filepathAbsError, tmpPath)
+	}
+	problems := []string{}
+	// Exists
+	for _, p := range v.Exists {
+		if _, err := os.Stat(filepath.Join(tmpPath, p)); os.IsNotExist(err) {
+			problems = append(problems, fmt.Sprintf("Missing file: %s", p))
+		} else if err != nil {
+			return burrito.WrapErrorf(err, osStatErrorAny, p)
+		}
+	}
+	// JSON
+	if len(v.Json) != 0 {
+		err = filepath.WalkDir(tmpPath, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return burrito.WrapErrorf(err, osWalkError, tmpPath)
+			}
+			if d.IsDir() {
+				return nil
+			}
+			relPath, err := filepath.Rel(tmpPath, p)
+			if err != nil {
+				return burrito.WrapErrorf(err, filepathRelError, tmpPath, p)
+			}
+			if !v.matchesJsonPattern(relPath) {
+				return nil
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return burrito.WrapErrorf(err, fileReadError, p)
+			}
+			var value interface{}
+			if err := jsonc.Unmarshal(data, &value); err != nil {
+				problems = append(problems, fmt.Sprintf(
+					"Invalid JSON: %s (%s)", filepath.ToSlash(relPath), err))
+			}
+			return nil
+		})
+		if err != nil {
+			return burrito.PassError(err)
+		}
+	}
+	// Command
+	if v.Command != "" {
+		shell, arg, err := findShell()
+		if err != nil {
+			return burrito.WrapError(err, "Unable to find a valid shell.")
+		}
+		err = RunSubProcess(
+			context, shell, []string{arg, v.Command}, tmpPath, tmpPath,
+			"validate")
+		if err != nil {
+			problems = append(problems, fmt.Sprintf(
+				"The validation command failed: %s (%s)", v.Command, err))
+		}
+	}
+	if len(problems) != 0 {
+		return burrito.WrappedErrorf(
+			"Found %d problems:\n%s", len(problems),
+			strings.Join(problems, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Functions used for collecting the warnings that the filters report in the
+// warnings.json file.
+package regolith
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// filterWarningsFileName is the name of the file in the tmp directory where
+// the filters write their warnings. Regolith removes the file after every
+// filter, so each filter starts without it.
+const filterWarningsFileName = "warnings.json"
+
+// FilterWarning is a warning reported by a filter in the warnings.json file.
+// The file is a JSON array of warnings, which are either strings with the
+// messages or objects with the "message" and the optional "file" and "line"
+// properties.
+type FilterWarning struct {
+	Message string `json:"message"`
+
+	// File is the path to the file that the warning is about, relative to
+	// the working directory of the filter, for example
+	// "BP/entities/pig.json".
+	File string `json:"file,omitempty"`
+
+	// Line is the line of the file that the warning is about. 0 means that
+	// the warning is about the whole file.
+	Line int `json:"line,omitempty"`
+}
+
+// String returns the message of the warning with its location.
+func (w FilterWarning) String() string {
+	if w.File == "" {
+		return w.Message
+	}
+	if w.Line == 0 {
+		return fmt.Sprintf("%s (%s)", w.Message, w.File)
+	}
+	return fmt.Sprintf("%s (%s:%d)", w.Message, w.File, w.Line)
+}
+
+// filterWarnings counts the warnings reported by the filters of the profile
+// and its nested profiles. The nested profiles share the counter of the
+// profile that runs them.
+type filterWarnings struct {
+	// counts maps the ids of the filters to the numbers of their warnings
+	counts map[string]int
+}
+
+// newFilterWarnings creates an empty filterWarnings counter.
+func newFilterWarnings() *filterWarnings {
+	return &filterWarnings{counts: make(map[string]int)}
+}
+
+// reset clears the counter before running the profile again in the watch
+// mode.
+func (w *filterWarnings) reset() {
+	if w == nil {
+		return
+	}
+	w.counts = make(map[string]int)
+}
+
+// logSummary logs the number of the warnings reported by each filter. Nothing
+// is logged if there were no warnings.
+func (w *filterWarnings) logSummary() {
+	if w == nil || len(w.counts) == 0 {
+		return
+	}
+	total := 0
+	lines := make([]string, 0, len(w.counts))
+	for filterId, count := range w.counts {
+		total += count
+		lines = append(lines, fmt.Sprintf("\t- %s: %d", filterId, count))
+	}
+	sort.Strings(lines)
+	Logger.Warnf(
+		"The filters reported %d warnings:\n%s", total,
+		strings.Join(lines, "\n"))
+}
+
+// loadFilterWarnings loads the warnings from the warnings.json file in the
+// tmp directory. It returns nil if the file doesn't exist.
+func loadFilterWarnings(path string) ([]FilterWarning, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, burrito.WrapErrorf(err, fileReadError, path)
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, burrito.WrapErrorf(err, jsonUnmarshalError, path)
+	}
+	result := make([]FilterWarning, 0, len(items))
+	for i, item := range items {
+		var message string
+		if err := json.Unmarshal(item, &message); err == nil {
+			result = append(result, FilterWarning{Message: message})
+			continue
+		}
+		var warning FilterWarning
+		if err := json.Unmarshal(item, &warning); err != nil || warning.Message == "" {
+			return nil, burrito.WrappedErrorf(
+				jsonPathTypeError, fmt.Sprintf("%d", i),
+				"string or object with the \"message\" string")
+		}
+		result = append(result, warning)
+	}
+	return result, nil
+}
+
+// collectFilterWarnings logs the warnings that the filter wrote to the
+// warnings.json file and removes the file. The warnings are counted for the
+// summary of the run. Invalid files are reported with a warning, they don't
+// stop the run.
+func (c *RunContext) collectFilterWarnings(filterId string) {
+	path := filepath.Join(getTmpPath(c.DotRegolithPath), filterWarningsFileName)
+	warnings, err := loadFilterWarnings(path)
+	if err != nil {
+		c.logWarnf(
+			"Failed to load the warnings of the filter %s.\n%s",
+			filterId, err.Error())
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		c.logWarnf(
+			"Failed to remove the warnings of the filter %s.\n%s",
+			filterId, err.Error())
+	}
+	for _, warning := range warnings {
+		c.logWarnf("[%s] %s", filterId, warning)
+	}
+	if c.filterWarnings != nil && len(warnings) != 0 {
+		c.filterWarnings.counts[filterId] += len(warnings)
+	}
+}
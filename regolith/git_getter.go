@@ -0,0 +1,101 @@
+package regolith
+
+import (
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+	getter "github.com/hashicorp/go-getter"
+)
+
+// gitGetter is the go-getter Getter used for downloading the remote filters
+// from Git repositories. Unlike the default GitGetter, it initializes the
+// submodules only if the repository has a .gitmodules file, and it can skip
+// them completely.
+type gitGetter struct {
+	getter.GitGetter
+
+	// noSubmodules disables initializing the submodules of the repository.
+	noSubmodules bool
+
+	// sha is the SHA of the commit checked out by the last call of Get.
+	sha string
+}
+
+// Get clones the repository from the URL to the dst path and checks out the
+// reference from the "ref" query parameter. The submodules are initialized
+// after the checkout, so they match the pinned commit of the repository.
+func (g *gitGetter) Get(dst string, u *url.URL) error {
+	query := u.Query()
+	ref := query.Get("ref")
+	query.Del("ref")
+	source := *u
+	source.RawQuery = query.Encode()
+	err := runGitCommand("", "clone", "--quiet", source.String(), dst)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	if ref != "" {
+		err = runGitCommand(dst, "checkout", "--quiet", ref)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+	}
+	// The checkout is a temporary directory when only a folder of the
+	// repository is used, so its SHA must be read now
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = dst
+	output, err := cmd.Output()
+	if err != nil {
+		return burrito.WrapErrorf(err, execCommandError, "git rev-parse HEAD")
+	}
+	g.sha = strings.TrimSpace(string(output))
+	if g.noSubmodules {
+		return nil
+	}
+	gitModulesPath := filepath.Join(dst, ".gitmodules")
+	if _, err := os.Stat(gitModulesPath); os.IsNotExist(err) {
+		return nil
+	}
+	Logger.Debugf("Initializing the submodules of %s...", source.String())
+	err = runGitCommand(dst, "submodule", "update", "--init", "--recursive")
+	if err != nil {
+		return burrito.WrapError(
+			err, "Failed to initialize the submodules of the repository.")
+	}
+	return nil
+}
+
+// runGitCommand runs a git command in the dir directory. The output of the
+// command is included in the error message.
+func runGitCommand(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, execCommandError+"\nOutput: %s",
+			"git "+strings.Join(args, " "), strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// getRemoteFilter downloads the files from the go-getter URL to the dst path
+// using the gitGetter for the Git repositories. It returns the SHA of the
+// downloaded commit, or an empty string if the files weren't downloaded
+// with Git.
+func getRemoteFilter(
+	dst, getterUrl string, noSubmodules bool,
+) (sha string, err error) {
+	getters := make(map[string]getter.Getter, len(getter.Getters))
+	for scheme, g := range getter.Getters {
+		getters[scheme] = g
+	}
+	gitGetter := &gitGetter{noSubmodules: noSubmodules}
+	getters["git"] = gitGetter
+	err = getter.Get(dst, getterUrl, getter.WithGetters(getters))
+	return gitGetter.sha, err
+}
@@ -0,0 +1,60 @@
+package regolith
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGetRemoteFilterSha checks whether downloading a folder of a Git
+// repository returns the SHA of the checked out commit, without accessing
+// the repository again. The repository is a local one, so the test works
+// without the network.
+func TestGetRemoteFilterSha(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git is not installed")
+	}
+	repository := t.TempDir()
+	filterPath := filepath.Join(repository, "filters", "hello")
+	if err := os.MkdirAll(filterPath, 0755); err != nil {
+		t.Fatal("Failed to create the filter directory:", err)
+	}
+	err := os.WriteFile(
+		filepath.Join(filterPath, "filter.json"), []byte("{}"), 0644)
+	if err != nil {
+		t.Fatal("Failed to create the filter.json file:", err)
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com",
+			"commit", "--quiet", "-m", "Add the filter"},
+	} {
+		if err := runGitCommand(repository, args...); err != nil {
+			t.Fatal("Failed to create the repository:", err)
+		}
+	}
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = repository
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatal("Failed to get the SHA of the commit:", err)
+	}
+	expected := strings.TrimSpace(string(output))
+
+	dst := filepath.Join(t.TempDir(), "hello")
+	sha, err := getRemoteFilter(
+		dst, "git::file://"+filepath.ToSlash(repository)+"//filters/hello",
+		true)
+	if err != nil {
+		t.Fatal("Failed to download the filter:", err)
+	}
+	if sha != expected {
+		t.Errorf("Unexpected SHA of the filter: %q, expected %q", sha, expected)
+	}
+	if _, err := os.Stat(filepath.Join(dst, "filter.json")); err != nil {
+		t.Error("The filter wasn't downloaded:", err)
+	}
+}
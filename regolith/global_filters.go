@@ -0,0 +1,98 @@
+// Functions used for loading the global filter definitions, which are shared
+// by all of the projects of the user.
+package regolith
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// NoGlobalFilters disables merging the global filter definitions into the
+// filter definitions of the project. It's set by the global
+// "--no-global-filters" flag.
+var NoGlobalFilters bool
+
+// getGlobalFiltersPath returns the path to the file with the global filter
+// definitions. On Linux, it's "~/.config/regolith/filters.json".
+func getGlobalFiltersPath() (string, error) {
+	userConfig, err := os.UserConfigDir()
+	if err != nil {
+		return "", burrito.WrapError(
+			err, "Failed to get the user config directory.")
+	}
+	return filepath.Join(userConfig, "regolith", "filters.json"), nil
+}
+
+// loadGlobalFilterDefinitions returns the "filterDefinitions" object of the
+// file with the global filter definitions. It returns nil if the file doesn't
+// exist or if the global filters are disabled.
+func loadGlobalFilterDefinitions() (map[string]interface{}, error) {
+	if NoGlobalFilters {
+		return nil, nil
+	}
+	path, err := getGlobalFiltersPath()
+	if err != nil {
+		return nil, burrito.PassError(err)
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, burrito.WrapErrorf(err, fileReadError, path)
+	}
+	var globalFilters map[string]interface{}
+	if err := json.Unmarshal(data, &globalFilters); err != nil {
+		return nil, burrito.WrapErrorf(err, jsonUnmarshalError, path)
+	}
+	filterDefinitions, ok := globalFilters["filterDefinitions"]
+	if !ok {
+		return nil, nil
+	}
+	result, ok := filterDefinitions.(map[string]interface{})
+	if !ok {
+		return nil, burrito.WrapErrorf(
+			burrito.WrappedErrorf(
+				jsonPropertyTypeError, "filterDefinitions", "object"),
+			"Invalid global filter definitions.\nPath: %s", path)
+	}
+	return result, nil
+}
+
+// mergeGlobalFilterDefinitions returns a copy of the "regolith" object of the
+// config with the global filter definitions added to its filter definitions.
+// The filter definitions of the project take precedence over the global ones.
+// The original object is not modified.
+func mergeGlobalFilterDefinitions(
+	regolith map[string]interface{},
+) (map[string]interface{}, error) {
+	globalDefinitions, err := loadGlobalFilterDefinitions()
+	if err != nil {
+		return nil, burrito.WrapError(
+			err, "Failed to load the global filter definitions.")
+	}
+	if len(globalDefinitions) == 0 {
+		return regolith, nil
+	}
+	projectDefinitions, ok := regolith["filterDefinitions"].(map[string]interface{})
+	if _, exists := regolith["filterDefinitions"]; exists && !ok {
+		// The invalid definitions are reported by the parser
+		return regolith, nil
+	}
+	definitions := make(
+		map[string]interface{}, len(globalDefinitions)+len(projectDefinitions))
+	for name, definition := range globalDefinitions {
+		definitions[name] = definition
+	}
+	for name, definition := range projectDefinitions {
+		definitions[name] = definition
+	}
+	result := make(map[string]interface{}, len(regolith))
+	for key, value := range regolith {
+		result[key] = value
+	}
+	result["filterDefinitions"] = definitions
+	return result, nil
+}
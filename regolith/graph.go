@@ -0,0 +1,296 @@
+// Functions used by the "regolith graph" command, which prints a diagram of
+// the profiles, their filters and the dependencies between them.
+package regolith
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// The formats of the output of the "regolith graph" command.
+const (
+	dotGraphFormat     = "dot"
+	mermaidGraphFormat = "mermaid"
+)
+
+// The kinds of the nodes of the graph. They decide the shapes of the nodes.
+const (
+	profileGraphNode   = "profile"
+	filterGraphNode    = "filter"
+	subfilterGraphNode = "subfilter"
+)
+
+// graphNode is a profile or a filter on the graph.
+type graphNode struct {
+	id, label, kind string
+}
+
+// graphEdge connects two nodes of the graph. The dashed edges are the
+// relations that don't run anything ("extends" and the filter
+// dependencies).
+type graphEdge struct {
+	from, to, label string
+	dashed          bool
+}
+
+// configGraph is the graph of the profiles and the filters of a config. The
+// nodes and the edges are kept in the order in which they were added, so the
+// output is stable.
+type configGraph struct {
+	config          *Config
+	dotRegolithPath string
+
+	nodes   []graphNode
+	nodeIds map[string]struct{}
+	edges   []graphEdge
+}
+
+// addNode adds the node to the graph and returns true, or returns false if
+// the node with the same ID is already on the graph.
+func (g *configGraph) addNode(id, label, kind string) bool {
+	if _, ok := g.nodeIds[id]; ok {
+		return false
+	}
+	g.nodeIds[id] = struct{}{}
+	g.nodes = append(g.nodes, graphNode{id: id, label: label, kind: kind})
+	return true
+}
+
+// addProfile adds the profile, the filters it runs and the profiles it
+// references (with profile filters or with the "extends" property) to the
+// graph.
+func (g *configGraph) addProfile(name string) error {
+	id := "profile:" + name
+	if !g.addNode(id, name, profileGraphNode) {
+		return nil
+	}
+	profile, ok := g.config.Profiles[name]
+	if !ok {
+		return burrito.WrappedErrorf(
+			"Profile %q does not exist in the configuration.\n"+
+				"Available profiles:\n%s", name, g.config.ListProfiles())
+	}
+	if profile.Extends != "" {
+		if err := g.addProfile(profile.Extends); err != nil {
+			return burrito.PassError(err)
+		}
+		g.edges = append(g.edges, graphEdge{
+			from: id, to: "profile:" + profile.Extends, label: "extends",
+			dashed: true})
+	}
+	for i, filter := range profile.Filters {
+		var filterId string
+		if profileFilter, ok := filter.(*ProfileFilter); ok {
+			if err := g.addProfile(profileFilter.Profile); err != nil {
+				return burrito.WrapErrorf(
+					err, "Failed to add the nested profile to the graph.\n"+
+						"Profile: %s", name)
+			}
+			filterId = "profile:" + profileFilter.Profile
+		} else {
+			var err error
+			filterId, err = g.addFilter(filter, filterGraphNode)
+			if err != nil {
+				return burrito.PassError(err)
+			}
+		}
+		g.edges = append(g.edges, graphEdge{
+			from: id, to: filterId, label: fmt.Sprint(i + 1)})
+	}
+	return nil
+}
+
+// addFilter adds the filter to the graph and returns the ID of its node. The
+// remote filters are expanded into their subfilters and the remote filters
+// they depend on. The filters that are not installed are not expanded.
+func (g *configGraph) addFilter(filter FilterRunner, kind string) (string, error) {
+	id := "filter:" + filter.GetId()
+	runWith, _ := describeFilterRunner(filter)
+	label := fmt.Sprintf("%s (%s)", filter.GetId(), runWith)
+	remoteFilter, isRemote := filter.(*RemoteFilter)
+	installed := isRemote && remoteFilter.IsCached(g.dotRegolithPath)
+	if isRemote && !installed {
+		label += " [not installed]"
+	}
+	if !g.addNode(id, label, kind) || !installed {
+		return id, nil
+	}
+	subfilters, err := remoteFilter.subfilterCollection(g.dotRegolithPath)
+	if err != nil {
+		return "", burrito.WrapErrorf(
+			err, "%s\nFilter: %s", remoteFilterSubfilterCollectionError,
+			remoteFilter.Id)
+	}
+	for i, subfilter := range subfilters.Filters {
+		subfilterId, err := g.addFilter(subfilter, subfilterGraphNode)
+		if err != nil {
+			return "", burrito.PassError(err)
+		}
+		g.edges = append(g.edges, graphEdge{
+			from: id, to: subfilterId, label: fmt.Sprint(i + 1)})
+	}
+	dependencies, err := remoteFilter.Definition.filterDependencies(
+		g.dotRegolithPath)
+	if err != nil {
+		return "", burrito.WrapErrorf(
+			err, "Failed to resolve the filter dependencies.\nFilter: %s",
+			remoteFilter.Id)
+	}
+	for _, dependency := range dependencies {
+		dependencyFilter := &RemoteFilter{
+			Filter: Filter{Id: dependency.name},
+			Definition: RemoteFilterDefinition{
+				FilterDefinition: FilterDefinition{Id: dependency.name},
+				Url:              dependency.url,
+				Version:          dependency.version,
+			},
+		}
+		// The dependencies installed by Regolith are on the filter
+		// definitions list
+		definition, ok := g.config.FilterDefinitions[dependency.name]
+		if remoteDefinition, isRemote := definition.(*RemoteFilterDefinition); ok && isRemote {
+			dependencyFilter.Definition = *remoteDefinition
+		}
+		dependencyId, err := g.addFilter(dependencyFilter, filterGraphNode)
+		if err != nil {
+			return "", burrito.PassError(err)
+		}
+		g.edges = append(g.edges, graphEdge{
+			from: id, to: dependencyId, label: "depends on", dashed: true})
+	}
+	return id, nil
+}
+
+// dot returns the graph in the DOT language of Graphviz.
+func (g *configGraph) dot() string {
+	var builder strings.Builder
+	builder.WriteString("digraph regolith {\n\trankdir=LR;\n")
+	shapes := map[string]string{
+		profileGraphNode:   "box",
+		filterGraphNode:    "ellipse",
+		subfilterGraphNode: "oval, style=dashed",
+	}
+	for _, node := range g.nodes {
+		builder.WriteString(fmt.Sprintf(
+			"\t%q [label=%q, shape=%s];\n", node.id, node.label,
+			shapes[node.kind]))
+	}
+	for _, edge := range g.edges {
+		style := ""
+		if edge.dashed {
+			style = ", style=dashed"
+		}
+		builder.WriteString(fmt.Sprintf(
+			"\t%q -> %q [label=%q%s];\n", edge.from, edge.to, edge.label,
+			style))
+	}
+	builder.WriteString("}")
+	return builder.String()
+}
+
+// mermaid returns the graph as a Mermaid flowchart. Mermaid doesn't allow
+// the special characters in the IDs of the nodes, so the nodes are numbered.
+func (g *configGraph) mermaid() string {
+	var builder strings.Builder
+	builder.WriteString("flowchart LR\n")
+	ids := make(map[string]string, len(g.nodes))
+	escape := func(text string) string {
+		return strings.ReplaceAll(text, "\"", "#quot;")
+	}
+	for i, node := range g.nodes {
+		ids[node.id] = fmt.Sprintf("n%d", i)
+		format := "\t%s[\"%s\"]\n"
+		switch node.kind {
+		case filterGraphNode:
+			format = "\t%s([\"%s\"])\n"
+		case subfilterGraphNode:
+			format = "\t%s(\"%s\")\n"
+		}
+		builder.WriteString(fmt.Sprintf(
+			format, ids[node.id], escape(node.label)))
+	}
+	for _, edge := range g.edges {
+		arrow := "-->"
+		if edge.dashed {
+			arrow = "-.->"
+		}
+		builder.WriteString(fmt.Sprintf(
+			"\t%s %s|\"%s\"| %s\n", ids[edge.from], arrow, escape(edge.label),
+			ids[edge.to]))
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// Graph handles the "regolith graph" command. It prints a diagram of the
+// profiles, the filters they run, the profiles they reference and the
+// subfilters and the dependencies of the installed remote filters. Nothing
+// is run or installed.
+//
+// The "profileName" parameter limits the graph to the profile and the
+// profiles it references. The empty name means all of the profiles.
+//
+// The "format" parameter is the format of the output, "dot" (Graphviz) or
+// "mermaid".
+//
+// The "outputPath" parameter is the path to the file where the diagram is
+// saved. The empty path means printing the diagram to the standard output.
+//
+// The "configPath" parameter is the path to the config file. The empty path
+// means "config.json".
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func Graph(profileName, format, outputPath, configPath string, debug bool) error {
+	InitLogging(debug)
+	if format != dotGraphFormat && format != mermaidGraphFormat {
+		return burrito.WrappedErrorf(
+			"Unknown format of the graph %q. The supported formats are %q "+
+				"and %q.", format, dotGraphFormat, mermaidGraphFormat)
+	}
+	configMap, err1 := LoadConfigAsMap(configPath)
+	config, err2 := ConfigFromObject(configMap)
+	if err := firstErr(err1, err2); err != nil {
+		return burrito.WrapError(err, "Failed to load config.json.")
+	}
+	dotRegolithPath, err := GetDotRegolith(true, ".")
+	if err != nil {
+		return burrito.WrapError(
+			err, "Unable to get the path to regolith cache folder.")
+	}
+	graph := &configGraph{
+		config:          config,
+		dotRegolithPath: dotRegolithPath,
+		nodeIds:         make(map[string]struct{}),
+	}
+	profiles := []string{profileName}
+	if profileName == "" {
+		profiles = make([]string, 0, len(config.Profiles))
+		for name := range config.Profiles {
+			profiles = append(profiles, name)
+		}
+		sort.Strings(profiles)
+	}
+	for _, name := range profiles {
+		if err := graph.addProfile(name); err != nil {
+			return burrito.WrapError(err, "Failed to create the graph.")
+		}
+	}
+	diagram := graph.dot()
+	if format == mermaidGraphFormat {
+		diagram = graph.mermaid()
+	}
+	if outputPath == "" {
+		fmt.Println(diagram)
+		return nil
+	}
+	err = os.WriteFile(outputPath, []byte(diagram+"\n"), 0644)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, outputPath)
+	}
+	Logger.Infof("Graph saved to %q.", outputPath)
+	return nil
+}
@@ -0,0 +1,122 @@
+package regolith
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestConfigGraph checks the order of the nodes and the edges of the graph
+// of the profiles, whether the circular references of the profiles are
+// added only once and whether the installed remote filters are expanded into
+// their subfilters and dependencies.
+func TestConfigGraph(t *testing.T) {
+	InitLogging(false)
+	dotRegolithPath := t.TempDir()
+	installedPath := filepath.Join(dotRegolithPath, "cache/filters/installed")
+	if err := os.MkdirAll(installedPath, 0755); err != nil {
+		t.Fatal("Failed to create the installed filter:", err)
+	}
+	err := os.WriteFile(
+		filepath.Join(installedPath, "filter.json"),
+		[]byte(`{"filters": [{"runWith": "shell", "command": "echo"}],
+		"filterDependencies": ["github.com/owner/repo/dependency"]}`), 0644)
+	if err != nil {
+		t.Fatal("Failed to create the installed filter:", err)
+	}
+	remoteFilter := func(id string) *RemoteFilter {
+		return &RemoteFilter{
+			Filter: Filter{Id: id},
+			Definition: RemoteFilterDefinition{
+				FilterDefinition: FilterDefinition{Id: id},
+				Url:              "github.com/owner/repo",
+			},
+		}
+	}
+	config := &Config{RegolithProject: RegolithProject{
+		Profiles: map[string]Profile{
+			"default": {FilterCollection: FilterCollection{
+				Filters: []FilterRunner{
+					&PythonFilter{Filter: Filter{Id: "first"}},
+					&ProfileFilter{
+						Filter: Filter{Id: "nested"}, Profile: "nested"},
+				}}},
+			"nested": {
+				Extends: "base",
+				FilterCollection: FilterCollection{
+					Filters: []FilterRunner{
+						&ProfileFilter{
+							Filter: Filter{Id: "default"}, Profile: "default"},
+					}}},
+			"base": {FilterCollection: FilterCollection{
+				Filters: []FilterRunner{remoteFilter("missing")}}},
+			"remote": {FilterCollection: FilterCollection{
+				Filters: []FilterRunner{remoteFilter("installed")}}},
+		},
+	}}
+	tests := []struct {
+		profile  string
+		expected []string
+	}{
+		{
+			"default",
+			[]string{
+				`"profile:default" [label="default", shape=box];`,
+				`"filter:first" [label="first (python)", shape=ellipse];`,
+				`"profile:nested" [label="nested", shape=box];`,
+				`"profile:base" [label="base", shape=box];`,
+				`"filter:missing" [label="missing (remote) [not installed]", shape=ellipse];`,
+				`"profile:default" -> "filter:first" [label="1"];`,
+				`"profile:base" -> "filter:missing" [label="1"];`,
+				`"profile:nested" -> "profile:base" [label="extends", style=dashed];`,
+				`"profile:nested" -> "profile:default" [label="1"];`,
+				`"profile:default" -> "profile:nested" [label="2"];`,
+			},
+		},
+		{
+			"remote",
+			[]string{
+				`"profile:remote" [label="remote", shape=box];`,
+				`"filter:installed" [label="installed (remote)", shape=ellipse];`,
+				`"filter:installed:subfilter0" [label="installed:subfilter0 (shell)", shape=oval, style=dashed];`,
+				`"filter:dependency" [label="dependency (remote) [not installed]", shape=ellipse];`,
+				`"filter:installed" -> "filter:installed:subfilter0" [label="1"];`,
+				`"filter:installed" -> "filter:dependency" [label="depends on", style=dashed];`,
+				`"profile:remote" -> "filter:installed" [label="1"];`,
+			},
+		},
+	}
+	for _, test := range tests {
+		graph := &configGraph{
+			config:          config,
+			dotRegolithPath: dotRegolithPath,
+			nodeIds:         make(map[string]struct{}),
+		}
+		if err := graph.addProfile(test.profile); err != nil {
+			t.Errorf("%s: failed to create the graph: %s", test.profile, err)
+			continue
+		}
+		expected := "digraph regolith {\n\trankdir=LR;\n\t" +
+			strings.Join(test.expected, "\n\t") + "\n}"
+		if actual := graph.dot(); actual != expected {
+			t.Errorf(
+				"%s: unexpected graph.\nExpected:\n%s\nActual:\n%s",
+				test.profile, expected, actual)
+		}
+	}
+}
+
+// TestConfigGraphMissingProfile checks whether the graph of a profile that
+// doesn't exist returns an error.
+func TestConfigGraphMissingProfile(t *testing.T) {
+	InitLogging(false)
+	graph := &configGraph{
+		config:          &Config{},
+		dotRegolithPath: t.TempDir(),
+		nodeIds:         make(map[string]struct{}),
+	}
+	if err := graph.addProfile("missing"); err == nil {
+		t.Error("Adding a profile that doesn't exist didn't fail")
+	}
+}
@@ -0,0 +1,150 @@
+// Functions used for limiting the files passed to a filter with the "scope"
+// property.
+package regolith
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// inputScopePacks is a list of the names of the directories in the tmp
+// directory whose files are limited by the "scope" property of a filter. The
+// data folder is always passed to the filters completely, because they keep
+// their configuration there.
+var inputScopePacks = []string{"RP", "BP"}
+
+// parseScopePatterns parses the patterns of the "scope" property of a
+// filter. The patterns use the syntax of the .regolithignore files and are
+// relative to the roots of the packs.
+func parseScopePatterns(scope []string) ([]ignorePattern, error) {
+	result := []ignorePattern{}
+	for i, line := range scope {
+		pattern, ok := parseIgnorePattern(line)
+		if !ok {
+			return nil, burrito.WrappedErrorf(
+				"The pattern of the scope is empty.\nIndex: %d", i)
+		}
+		for _, segment := range pattern.segments {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, burrito.WrapErrorf(
+					err, "Invalid pattern of the scope.\nPattern: %s", line)
+			}
+		}
+		result = append(result, pattern)
+	}
+	return result, nil
+}
+
+// isInScope returns true if the file with the relPath path (relative to the
+// root of its pack, with forward slashes) matches the patterns. A pattern
+// that matches a directory matches all of the files inside of it. Like in
+// the .regolithignore files, the last matching pattern decides.
+func isInScope(patterns []ignorePattern, relPath string) bool {
+	parts := strings.Split(relPath, "/")
+	result := false
+	for _, pattern := range patterns {
+		for i := 1; i <= len(parts); i++ {
+			isDir := i < len(parts)
+			if pattern.dirOnly && !isDir {
+				continue
+			}
+			if matchIgnoreSegments(pattern.segments, parts[:i]) {
+				result = !pattern.negate
+				break
+			}
+		}
+	}
+	return result
+}
+
+// limitInputScope hides the files of the packs that don't match the "scope"
+// patterns of the filter, by moving them from the tmp directory to a backup
+// directory. It returns a function that moves the hidden files back. The
+// files created by the filter in place of the hidden files are replaced
+// with the original files. If the scope is empty, nothing is hidden.
+func limitInputScope(
+	scope []string, filterId string, dotRegolithPath string,
+) (func() error, error) {
+	if len(scope) == 0 {
+		return func() error { return nil }, nil
+	}
+	patterns, err := parseScopePatterns(scope)
+	if err != nil {
+		return nil, burrito.PassError(err)
+	}
+	tmpPath := getTmpPath(dotRegolithPath)
+	// Every call uses its own backup directory, because the filters of the
+	// nested profiles run in the same tmp directory
+	tmpRoot := getTmpRoot(dotRegolithPath)
+	backupPath, err := os.MkdirTemp(tmpRoot, ".inputScopeBackup-*")
+	if err != nil {
+		return nil, burrito.WrapErrorf(err, osMkdirError, tmpRoot)
+	}
+	// Restores the files that were moved before the failure
+	moved := []string{}
+	restore := func() error {
+		for _, relPath := range moved {
+			filePath := filepath.Join(tmpPath, relPath)
+			backupFilePath := filepath.Join(backupPath, relPath)
+			if _, err := os.Lstat(filePath); err == nil {
+				Logger.Warnf(
+					"Filter %q created a file which is outside of its "+
+						"scope. The file was replaced with the original "+
+						"file.\nPath: %s", filterId, filepath.ToSlash(relPath))
+				if err := os.RemoveAll(filePath); err != nil {
+					return burrito.WrapErrorf(err, osRemoveError, filePath)
+				}
+			}
+			if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+				return burrito.WrapErrorf(
+					err, osMkdirError, filepath.Dir(filePath))
+			}
+			if err := os.Rename(backupFilePath, filePath); err != nil {
+				return burrito.WrapErrorf(
+					err, osRenameError, backupFilePath, filePath)
+			}
+		}
+		if err := os.RemoveAll(backupPath); err != nil {
+			return burrito.WrapErrorf(err, osRemoveError, backupPath)
+		}
+		return nil
+	}
+	for _, pack := range inputScopePacks {
+		packPath := filepath.Join(tmpPath, pack)
+		err := walkArchiveFiles(packPath, func(filePath, relPath string, info fs.FileInfo) error {
+			if info.IsDir() || isInScope(patterns, relPath) {
+				return nil
+			}
+			tmpRelPath := filepath.Join(pack, filepath.FromSlash(relPath))
+			backupFilePath := filepath.Join(backupPath, tmpRelPath)
+			err := os.MkdirAll(filepath.Dir(backupFilePath), 0755)
+			if err != nil {
+				return burrito.WrapErrorf(
+					err, osMkdirError, filepath.Dir(backupFilePath))
+			}
+			err = os.Rename(filePath, backupFilePath)
+			if err != nil {
+				return burrito.WrapErrorf(
+					err, osRenameError, filePath, backupFilePath)
+			}
+			moved = append(moved, tmpRelPath)
+			return nil
+		})
+		if err != nil {
+			mainError := burrito.WrapErrorf(
+				err, "Failed to limit the scope of the filter.\n"+
+					"Filter: %s\nPack: %s", filterId, pack)
+			if handlerError := restore(); handlerError != nil {
+				return nil, burrito.PassErrorHandlerError(
+					mainError, handlerError, errorConnector)
+			}
+			return nil, mainError
+		}
+	}
+	return restore, nil
+}
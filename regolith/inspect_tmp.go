@@ -0,0 +1,85 @@
+// Functions used by the "regolith inspect-tmp" command, which prints the
+// files left in the tmp directory by "regolith run --keep-tmp".
+package regolith
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// InspectTmp handles the "regolith inspect-tmp" command. It prints the tree
+// of the files in the tmp directory of the project with their sizes. The
+// files are kept there by "regolith run --keep-tmp".
+//
+// The "depth" parameter limits the number of the printed levels of the
+// tree. 0 means no limit.
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func InspectTmp(depth int, debug bool) error {
+	InitLogging(debug)
+	dotRegolithPath, err := GetDotRegolith(true, ".")
+	if err != nil {
+		return burrito.WrapError(
+			err, "Unable to get the path to regolith cache folder.")
+	}
+	tmpPath, err := filepath.Abs(getTmpPath(dotRegolithPath))
+	if err != nil {
+		return burrito.WrapErrorf(err, filepathAbsError, tmpPath)
+	}
+	isEmpty, err := IsDirEmpty(tmpPath)
+	if err != nil || isEmpty {
+		return burrito.WrappedErrorf(
+			"There are no files in the tmp directory.\n"+
+				"Path: %s\n"+
+				"Run the profile with \"regolith run --keep-tmp\" first.",
+			tmpPath)
+	}
+	size, err := pathSize(tmpPath)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	fmt.Printf("%s (%s)\n", tmpPath, formatSize(size))
+	return printTmpTree(tmpPath, "", depth)
+}
+
+// printTmpTree prints the entries of the directory as the branches of a
+// tree. The prefix is printed before every line of the subtree. The depth
+// limits the number of the printed levels (0 means no limit).
+func printTmpTree(path, prefix string, depth int) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return burrito.WrapErrorf(err, osReadDirError, path)
+	}
+	for i, entry := range entries {
+		branch, indent := "├── ", "│   "
+		if i == len(entries)-1 {
+			branch, indent = "└── ", "    "
+		}
+		entryPath := filepath.Join(path, entry.Name())
+		size, err := pathSize(entryPath)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		fmt.Printf("%s%s%s (%s)\n", prefix, branch, name, formatSize(size))
+		if !entry.IsDir() || depth == 1 {
+			continue
+		}
+		nextDepth := depth
+		if depth > 0 {
+			nextDepth--
+		}
+		err = printTmpTree(entryPath, prefix+indent, nextDepth)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+	}
+	return nil
+}
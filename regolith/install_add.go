@@ -2,8 +2,11 @@
 package regolith
 
 import (
-	"os/exec"
+	"bufio"
+	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/Bedrock-OSS/go-burrito/burrito"
@@ -24,9 +27,12 @@ type parsedInstallFilterArg struct {
 
 // installFilters installs the filters from the list and their dependencies,
 // and copies their data to the data path. If the filter is already installed,
-// it returns an error unless the force flag is set.
+// it returns an error unless the force flag is set. The submodules of the
+// remote filters are not initialized if noSubmodules is set. The remote
+// filters listed in the "filterDependencies" of the installed filters are
+// installed as well and added to the filterDefinitions map.
 func installFilters(
-	filterDefinitions map[string]FilterInstaller, force bool,
+	filterDefinitions map[string]FilterInstaller, force, noSubmodules bool,
 	dataPath, dotRegolithPath string,
 ) error {
 	joinedPath := filepath.Join(dotRegolithPath, "cache/filters")
@@ -40,32 +46,92 @@ func installFilters(
 		return burrito.WrapErrorf(err, osMkdirError, "cache/venvs")
 	}
 
-	// Download all of the remote filters
-	for name, filterDefinition := range filterDefinitions {
-		Logger.Infof("Downloading %q filter...", name)
-		if remoteFilter, ok := filterDefinition.(*RemoteFilterDefinition); ok {
-			// Download the remote filter, and its dependencies
-			err := remoteFilter.Update(force, dotRegolithPath)
+	// Download all of the remote filters and the filters they depend on
+	names := make([]string, 0, len(filterDefinitions))
+	for name := range filterDefinitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	resolver := newFilterDependencyResolver(
+		filterDefinitions, force, noSubmodules, dataPath, dotRegolithPath)
+	for _, name := range names {
+		err := resolver.install(name)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+	}
+	if err := resolver.checkCycles(); err != nil {
+		return burrito.PassError(err)
+	}
+	resolver.logTree(names)
+	resolver.logSummary()
+	return nil
+}
+
+// resolveFilterPatterns returns the sorted names of the filter definitions
+// that match at least one of the glob patterns (for example "team_*"). The
+// patterns use the syntax of path.Match. Patterns that don't match any
+// filter are reported with a warning.
+func resolveFilterPatterns(
+	patterns []string, filterDefinitions map[string]FilterInstaller,
+) ([]string, error) {
+	names := make([]string, 0, len(filterDefinitions))
+	for name := range filterDefinitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	matched := make(map[string]struct{})
+	result := []string{}
+	for _, pattern := range patterns {
+		found := false
+		for _, name := range names {
+			ok, err := path.Match(pattern, name)
 			if err != nil {
-				return burrito.WrapErrorf(err, remoteFilterDownloadError, name)
+				return nil, burrito.WrapErrorf(
+					err, "Invalid filter name pattern.\nPattern: %s", pattern)
 			}
-			// Copy the data of the remote filter to the data path
-			remoteFilter.CopyFilterData(dataPath, dotRegolithPath)
-		} else {
-			// Non-remote filters must always update their dependencies.
-			// TODO - add option to track if the filter already installed
-			// its dependencies.
-			Logger.Infof("Installing %q filter dependencies...", name)
-			err = filterDefinition.InstallDependencies(nil, dotRegolithPath)
-			if err != nil {
-				return burrito.WrapErrorf(
-					err,
-					"Failed to install dependencies of the filter.\nFilter: %s.",
-					name)
+			if !ok {
+				continue
 			}
+			found = true
+			if _, ok := matched[name]; !ok {
+				matched[name] = struct{}{}
+				result = append(result, name)
+			}
+		}
+		if !found {
+			Logger.Warnf(
+				"Pattern %q doesn't match any filter definition.", pattern)
 		}
 	}
-	return nil
+	sort.Strings(result)
+	return result, nil
+}
+
+// LoadFilterRequirements loads the list of the filters to install from a
+// requirements file, used by the "regolith install --file" command. Every
+// line of the file has the same format as the arguments of the
+// "regolith install" command. Empty lines and lines starting with "#" are
+// ignored.
+func LoadFilterRequirements(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, burrito.WrapErrorf(err, osOpenError, path)
+	}
+	defer file.Close()
+	result := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		result = append(result, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, burrito.WrapErrorf(err, fileReadError, path)
+	}
+	return result, nil
 }
 
 // parseInstallFilterArgs parses a list of arguments of the
@@ -100,14 +166,21 @@ func parseInstallFilterArgs(
 			}
 			url, version = splitStr[0], splitStr[1]
 		} else {
-			url = arg
+			url, version = arg, ""
 		}
 		// Check if identifier is an URL. The last part of the URL is the name
-		// of the filter
-		if strings.Contains(url, "/") {
+		// of the filter, or the name of the archive of the filter
+		if isArchiveFilterUrl(url) {
+			name = archiveFilterName(url)
+		} else if strings.Contains(url, "/") {
 			splitStr := strings.Split(url, "/")
 			name = splitStr[len(splitStr)-1]
 			url = strings.Join(splitStr[:len(splitStr)-1], "/")
+			// Keep the "//" separator of the repository URL when the filter
+			// is in the root of the repository ("<repository>//<name>")
+			if strings.HasSuffix(url, "/") {
+				url += "/"
+			}
 		} else {
 			// Example inputs: "name_ninja==HEAD", "name_ninja"
 			name = url
@@ -119,6 +192,10 @@ func parseInstallFilterArgs(
 						"Filter name: %s", name)
 			}
 		}
+		err = checkFilterSourceAllowed(url, name)
+		if err != nil {
+			return nil, burrito.PassError(err)
+		}
 		key := [2]string{url, name}
 		if _, ok := parsedArgs[key]; ok {
 			return nil, burrito.WrapErrorf(
@@ -127,6 +204,7 @@ func parseInstallFilterArgs(
 		}
 		parsedArgs[key] = struct{}{}
 		result = append(result, &parsedInstallFilterArg{
+			raw:     arg,
 			url:     url,
 			name:    name,
 			version: version,
@@ -140,6 +218,20 @@ func parseInstallFilterArgs(
 // to download a filter, based on the url, name and version properties from
 // from the "regolith install" command arguments.
 func GetRemoteFilterDownloadRef(url, name, version string) (string, error) {
+	// The filters from the archives are pinned with the hashes of their
+	// content
+	if isArchiveFilterUrl(url) {
+		if isArchiveHash(version) {
+			return version, nil
+		}
+		if version != "" && version != "HEAD" && version != "latest" {
+			return "", burrito.WrappedErrorf(
+				"The version of a filter installed from an archive must be "+
+					"\"HEAD\", \"latest\" or the hash of the archive "+
+					"(\"%s...\").\nVersion: %s", archiveHashPrefix, version)
+		}
+		return archiveFilterHash(url)
+	}
 	// The custom type and a function is just to reduce the amount of code by
 	// changing the function signature. In order to pass it in the 'vg' list.
 	type vg []func(string, string) (string, error)
@@ -168,6 +260,100 @@ func GetRemoteFilterDownloadRef(url, name, version string) (string, error) {
 			"specified constraints.")
 }
 
+// resolvePinnedVersion returns the concrete version of the remote filter
+// with the "HEAD" or "latest" version, that can be written to the config
+// file instead of the keyword. The "HEAD" version is resolved to the SHA of
+// the HEAD of the repository and the "latest" version to the semantic
+// version from the newest tag of the filter.
+func resolvePinnedVersion(url, name, version string) (string, error) {
+	if isArchiveFilterUrl(url) && (version == "HEAD" || version == "latest") {
+		return archiveFilterHash(url)
+	}
+	switch version {
+	case "HEAD":
+		sha, err := GetHeadSha(url)
+		if err != nil {
+			return "", burrito.PassError(err)
+		}
+		return sha, nil
+	case "latest":
+		tag, err := GetLatestRemoteFilterTag(url, name)
+		if err != nil {
+			return "", burrito.PassError(err)
+		}
+		return trimFilterPrefix(tag, name), nil
+	}
+	return version, nil
+}
+
+// unpinnedFilters returns the sorted names of the remote filters with the
+// "HEAD" or "latest" versions, which are pinned by "regolith freeze".
+func unpinnedFilters(filterDefinitions map[string]FilterInstaller) []string {
+	result := []string{}
+	for name, filterDefinition := range filterDefinitions {
+		remoteFilter, ok := filterDefinition.(*RemoteFilterDefinition)
+		if !ok {
+			continue
+		}
+		if remoteFilter.Version == "HEAD" || remoteFilter.Version == "latest" {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// installedPinnedFilters returns the remote filters from the
+// filterDefinitions map with the versions from the pinned map (names of the
+// filters mapped to their new versions). Only the filters installed in the
+// cache with the pinned versions are returned, because the lock file is
+// based on the installed filters.
+func installedPinnedFilters(
+	filterDefinitions map[string]FilterInstaller, pinned map[string]string,
+	dotRegolithPath string,
+) map[string]FilterInstaller {
+	result := make(map[string]FilterInstaller, len(pinned))
+	for name, version := range pinned {
+		remoteFilter, ok := filterDefinitions[name].(*RemoteFilterDefinition)
+		if !ok {
+			continue
+		}
+		installedVersion, err := remoteFilter.InstalledVersion(dotRegolithPath)
+		if err != nil {
+			continue
+		}
+		sha, err := remoteFilter.installedSha(dotRegolithPath)
+		if err != nil || (installedVersion != version && sha != version) {
+			Logger.Debugf(
+				"Filter %q is not installed with the pinned version %q.",
+				name, version)
+			continue
+		}
+		pinnedFilter := *remoteFilter
+		pinnedFilter.Version = version
+		result[name] = &pinnedFilter
+	}
+	return result
+}
+
+// setFilterVersions sets the "version" properties of the filters from the
+// unparsed "filterDefinitions" object of the config file. The versions map
+// the names of the filters to their new versions.
+func setFilterVersions(
+	filterDefinitions map[string]interface{}, versions map[string]string,
+) error {
+	for name, version := range versions {
+		filterDefinition, ok := filterDefinitions[name].(map[string]interface{})
+		if !ok {
+			return burrito.WrappedErrorf(
+				jsonPathTypeError, "regolith->filterDefinitions->"+name,
+				"object")
+		}
+		filterDefinition["version"] = version
+	}
+	return nil
+}
+
 // GetLatestRemoteFilterTag returns the most up-to-date tag of the remote filter
 // specified by the filter name and URL.
 func GetLatestRemoteFilterTag(url, name string) (string, error) {
@@ -186,11 +372,11 @@ func GetLatestRemoteFilterTag(url, name string) (string, error) {
 // ListRemoteFilterTags returns the list tags of the remote filter specified by the
 // filter name and URL.
 func ListRemoteFilterTags(url, name string) ([]string, error) {
-	commandArgs := []string{"ls-remote", "--tags", "https://" + url}
-	output, err := exec.Command("git", commandArgs...).Output()
+	repositoryUrl, _ := splitFilterUrl(url)
+	output, err := gitOutputWithRetry(
+		"ls-remote", "--tags", gitRemoteUrl(repositoryUrl))
 	if err != nil {
-		command := "git " + strings.Join(commandArgs, " ")
-		return nil, burrito.WrapErrorf(err, execCommandError, command)
+		return nil, burrito.PassError(err)
 	}
 	// Go line by line though the output
 	var tags []string
@@ -215,19 +401,58 @@ func ListRemoteFilterTags(url, name string) ([]string, error) {
 // filter URL. This function does not check whether the filter actually exists
 // in the repository.
 func GetHeadSha(url string) (string, error) {
-	commandArgs := []string{
-		"ls-remote", "--symref", "https://" + url, "HEAD"}
-	output, err := exec.Command("git", commandArgs...).Output()
+	repositoryUrl, _ := splitFilterUrl(url)
+	output, err := gitOutputWithRetry(
+		"ls-remote", "--symref", gitRemoteUrl(repositoryUrl), "HEAD")
 	if err != nil {
-		commandText := "git " + strings.Join(commandArgs, " ")
-		return "", burrito.WrapErrorf(err, execCommandError, commandText)
+		return "", burrito.PassError(err)
 	}
 	// The result is on the second line.
 	lines := strings.Split(string(output), "\n")
+	if len(lines) < 2 || lines[1] == "" {
+		return "", burrito.WrappedErrorf(
+			"Unexpected output of \"git ls-remote\".\nURL: %s", url)
+	}
 	sha := strings.Split(lines[1], "\t")[0]
 	return sha, nil
 }
 
+// splitFilterUrl splits the URL of a remote filter into the URL of the
+// repository ("<host>/<owner>/<repository>") and the path to the folder in
+// the repository that contains the filter folders. The path is empty when
+// the filters are in the root of the repository. It lets the monorepos keep
+// their filters in nested folders, for example with the
+// "github.com/<owner>/<repository>/filters/textures" URL. The SSH URLs
+// ("git@<host>:<owner>/<repository>") are split the same way.
+//
+// The repositories with longer paths, like the repositories in the GitLab
+// subgroups, end with the explicit "//" separator, for example
+// "gitlab.com/<group>/<subgroup>/<repository>//filters". The folder after
+// the separator can be empty.
+func splitFilterUrl(url string) (repositoryUrl, folder string) {
+	schemeEnd := 0
+	if i := strings.Index(url, "://"); i != -1 {
+		schemeEnd = i + len("://")
+	}
+	if i := strings.Index(url[schemeEnd:], "//"); i != -1 {
+		i += schemeEnd
+		return url[:i], strings.Trim(url[i+len("//"):], "/")
+	}
+	if isSshUrl(url) {
+		prefix, path := splitSshUrl(url)
+		parts := strings.Split(path, "/")
+		if len(parts) <= 2 {
+			return url, ""
+		}
+		return prefix + strings.Join(parts[:2], "/"), strings.Join(parts[2:], "/")
+	}
+	parts := strings.Split(strings.Trim(url, "/"), "/")
+	if len(parts) <= 3 {
+		return url, ""
+	}
+	return strings.Join(parts[:3], "/"), strings.Join(parts[3:], "/")
+}
+
 // trimFilterPrefix removes the prefix of the filter name from versionTag if
 // versionTag follows the pattern <filterName>-<version>, otherwise it returns
 // the same string.
@@ -0,0 +1,354 @@
+package regolith
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestSplitFilterUrl checks whether the URLs of the filters are split into
+// the URL of the repository and the folder with the filters, including the
+// repositories with longer paths that use the "//" separator.
+func TestSplitFilterUrl(t *testing.T) {
+	tests := []struct {
+		url        string
+		repository string
+		folder     string
+	}{
+		{"github.com/owner/repo", "github.com/owner/repo", ""},
+		{"github.com/owner/repo/filters/textures",
+			"github.com/owner/repo", "filters/textures"},
+		{"gitlab.com/group/subgroup/repo//filters",
+			"gitlab.com/group/subgroup/repo", "filters"},
+		{"gitlab.com/group/subgroup/repo//",
+			"gitlab.com/group/subgroup/repo", ""},
+		{"gitlab.com/group/subgroup/repo//filters/",
+			"gitlab.com/group/subgroup/repo", "filters"},
+		{"git@github.com:owner/repo", "git@github.com:owner/repo", ""},
+		{"git@github.com:owner/repo/filters",
+			"git@github.com:owner/repo", "filters"},
+		{"git@gitlab.com:group/subgroup/repo//filters",
+			"git@gitlab.com:group/subgroup/repo", "filters"},
+		{"https://gitlab.com/group/subgroup/repo//filters",
+			"https://gitlab.com/group/subgroup/repo", "filters"},
+	}
+	for _, test := range tests {
+		repository, folder := splitFilterUrl(test.url)
+		if repository != test.repository || folder != test.folder {
+			t.Errorf(
+				"splitFilterUrl(%q) = (%q, %q), expected (%q, %q)",
+				test.url, repository, folder, test.repository, test.folder)
+		}
+	}
+}
+
+// TestParseInstallFilterArgsSeparator checks whether the "//" separator of
+// the repository URL is kept when the filter is in the root of a repository
+// with a longer path.
+func TestParseInstallFilterArgsSeparator(t *testing.T) {
+	InitLogging(false)
+	tests := []struct {
+		arg  string
+		url  string
+		name string
+	}{
+		{"gitlab.com/group/subgroup/repo//name_ninja",
+			"gitlab.com/group/subgroup/repo//", "name_ninja"},
+		{"gitlab.com/group/subgroup/repo//filters/name_ninja",
+			"gitlab.com/group/subgroup/repo//filters", "name_ninja"},
+		{"github.com/owner/repo/name_ninja",
+			"github.com/owner/repo", "name_ninja"},
+	}
+	for _, test := range tests {
+		parsed, err := parseInstallFilterArgs([]string{test.arg})
+		if err != nil {
+			t.Errorf("Failed to parse %q: %s", test.arg, err)
+			continue
+		}
+		if parsed[0].url != test.url || parsed[0].name != test.name {
+			t.Errorf(
+				"Parsed %q as (%q, %q), expected (%q, %q)", test.arg,
+				parsed[0].url, parsed[0].name, test.url, test.name)
+		}
+	}
+}
+
+// TestFilterGetterUrl checks whether the go-getter URLs of the repositories
+// with longer paths don't rely on the go-getter detectors.
+func TestFilterGetterUrl(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"github.com/owner/repo", "github.com/owner/repo//name?ref=HEAD"},
+		{"github.com/owner/repo/filters",
+			"github.com/owner/repo//filters/name?ref=HEAD"},
+		{"gitlab.com/group/subgroup/repo//",
+			"git::https://gitlab.com/group/subgroup/repo//name?ref=HEAD"},
+		{"gitlab.com/group/subgroup/repo//filters",
+			"git::https://gitlab.com/group/subgroup/repo//filters/name?ref=HEAD"},
+	}
+	for _, test := range tests {
+		actual := filterGetterUrl(test.url, "name", "HEAD")
+		if actual != test.expected {
+			t.Errorf(
+				"filterGetterUrl(%q) = %q, expected %q",
+				test.url, actual, test.expected)
+		}
+	}
+}
+
+// TestParseInstallFilterArgs checks whether the URLs, names and versions of
+// the filters are parsed from the arguments of the "regolith install"
+// command, including the SSH URLs.
+func TestParseInstallFilterArgs(t *testing.T) {
+	InitLogging(false)
+	tests := []struct {
+		arg     string
+		url     string
+		name    string
+		version string
+	}{
+		{"github.com/owner/repo/name_ninja",
+			"github.com/owner/repo", "name_ninja", ""},
+		{"github.com/owner/repo/name_ninja==1.2.3",
+			"github.com/owner/repo", "name_ninja", "1.2.3"},
+		{"github.com/owner/repo/filters/name_ninja==HEAD",
+			"github.com/owner/repo/filters", "name_ninja", "HEAD"},
+		{"git@github.com:owner/repo/name_ninja==latest",
+			"git@github.com:owner/repo", "name_ninja", "latest"},
+		{"ssh://git@example.com:2222/owner/repo/name_ninja",
+			"ssh://git@example.com:2222/owner/repo", "name_ninja", ""},
+	}
+	for _, test := range tests {
+		parsed, err := parseInstallFilterArgs([]string{test.arg})
+		if err != nil {
+			t.Errorf("Failed to parse %q: %s", test.arg, err)
+			continue
+		}
+		actual := parsed[0]
+		if actual.url != test.url || actual.name != test.name ||
+			actual.version != test.version {
+			t.Errorf(
+				"Parsed %q as (%q, %q, %q), expected (%q, %q, %q)", test.arg,
+				actual.url, actual.name, actual.version, test.url, test.name,
+				test.version)
+		}
+	}
+}
+
+// TestParseInstallFilterArgsErrors checks whether the invalid arguments of
+// the "regolith install" command are rejected.
+func TestParseInstallFilterArgsErrors(t *testing.T) {
+	InitLogging(false)
+	tests := [][]string{
+		{},
+		{"github.com/owner/repo/name_ninja==1.0.0==2.0.0"},
+		{"github.com/owner/repo/name_ninja",
+			"github.com/owner/repo/name_ninja==1.0.0"},
+	}
+	for _, args := range tests {
+		if _, err := parseInstallFilterArgs(args); err == nil {
+			t.Errorf("Parsing %q didn't fail", args)
+		}
+	}
+}
+
+// TestGetRemoteFilterDownloadRef checks whether the versions that don't
+// require checking the repository are converted to the Git references.
+func TestGetRemoteFilterDownloadRef(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{"1.2.3", "name_ninja-1.2.3"},
+		{"1.2.3-beta", "name_ninja-1.2.3-beta"},
+		{"a1b2c3d4", "a1b2c3d4"},
+		{"main", "main"},
+	}
+	for _, test := range tests {
+		actual, err := GetRemoteFilterDownloadRef(
+			"github.com/owner/repo", "name_ninja", test.version)
+		if err != nil {
+			t.Errorf("Failed to get the ref of %q: %s", test.version, err)
+		} else if actual != test.expected {
+			t.Errorf(
+				"The ref of %q is %q, expected %q",
+				test.version, actual, test.expected)
+		}
+	}
+}
+
+// TestTrimFilterPrefix checks whether the name of the filter is removed only
+// from the tags with the semantic versions of the filter.
+func TestTrimFilterPrefix(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{"name_ninja-1.2.3", "1.2.3"},
+		{"name_ninja-1.2.3-beta", "1.2.3-beta"},
+		{"name_ninja-main", "name_ninja-main"},
+		{"other-1.2.3", "other-1.2.3"},
+		{"1.2.3", "1.2.3"},
+	}
+	for _, test := range tests {
+		actual := trimFilterPrefix(test.tag, "name_ninja")
+		if actual != test.expected {
+			t.Errorf(
+				"trimFilterPrefix(%q) = %q, expected %q",
+				test.tag, actual, test.expected)
+		}
+	}
+}
+
+// TestUnpinnedFilters checks whether only the remote filters with the "HEAD"
+// and "latest" versions are pinned by "regolith freeze".
+func TestUnpinnedFilters(t *testing.T) {
+	remoteFilter := func(version string) *RemoteFilterDefinition {
+		return &RemoteFilterDefinition{
+			Url: "github.com/owner/repo", Version: version}
+	}
+	filterDefinitions := map[string]FilterInstaller{
+		"head":    remoteFilter("HEAD"),
+		"latest":  remoteFilter("latest"),
+		"pinned":  remoteFilter("1.2.3"),
+		"sha":     remoteFilter("a1b2c3d4"),
+		"python":  &PythonFilterDefinition{},
+		"another": remoteFilter("HEAD"),
+	}
+	expected := []string{"another", "head", "latest"}
+	if actual := unpinnedFilters(filterDefinitions); !reflect.DeepEqual(
+		actual, expected) {
+		t.Errorf(
+			"Unexpected filters to pin.\nExpected: %v\nActual: %v",
+			expected, actual)
+	}
+}
+
+// TestSetFilterVersions checks whether the versions are written to the
+// unparsed filter definitions without changing their other properties, and
+// whether the invalid definitions are reported.
+func TestSetFilterVersions(t *testing.T) {
+	InitLogging(false)
+	filterDefinitions := map[string]interface{}{
+		"head": map[string]interface{}{
+			"url": "github.com/owner/repo", "version": "HEAD"},
+		"pinned": map[string]interface{}{
+			"url": "github.com/owner/repo", "version": "1.0.0"},
+	}
+	err := setFilterVersions(filterDefinitions, map[string]string{
+		"head": "a1b2c3d4"})
+	if err != nil {
+		t.Fatal("Failed to set the versions:", err)
+	}
+	expected := map[string]interface{}{
+		"head": map[string]interface{}{
+			"url": "github.com/owner/repo", "version": "a1b2c3d4"},
+		"pinned": map[string]interface{}{
+			"url": "github.com/owner/repo", "version": "1.0.0"},
+	}
+	if !reflect.DeepEqual(filterDefinitions, expected) {
+		t.Errorf(
+			"Unexpected filter definitions.\nExpected: %v\nActual: %v",
+			expected, filterDefinitions)
+	}
+	err = setFilterVersions(filterDefinitions, map[string]string{
+		"missing": "1.0.0"})
+	if err == nil {
+		t.Error("Setting the version of a missing filter didn't fail")
+	}
+}
+
+// TestResolvePinnedVersion checks whether the versions other than "HEAD"
+// and "latest" are kept by "regolith freeze".
+func TestResolvePinnedVersion(t *testing.T) {
+	for _, version := range []string{"1.2.3", "a1b2c3d4", "main"} {
+		actual, err := resolvePinnedVersion(
+			"github.com/owner/repo", "name_ninja", version)
+		if err != nil {
+			t.Errorf("Failed to resolve %q: %s", version, err)
+		} else if actual != version {
+			t.Errorf("%q was resolved to %q", version, actual)
+		}
+	}
+}
+
+// TestInstalledPinnedFilters checks whether only the filters installed with
+// the pinned versions are added to the lock file by "regolith freeze".
+func TestInstalledPinnedFilters(t *testing.T) {
+	InitLogging(false)
+	dotRegolithPath := t.TempDir()
+	writeTestFilterJson(t, dotRegolithPath, "head", map[string]interface{}{
+		"version": testInstalledSha, installedShaKey: testInstalledSha})
+	writeTestFilterJson(t, dotRegolithPath, "latest", map[string]interface{}{
+		"version": "1.2.3", installedShaKey: testOtherSha})
+	writeTestFilterJson(t, dotRegolithPath, "outdated", map[string]interface{}{
+		"version": "1.0.0", installedShaKey: testOtherSha})
+	remoteFilter := func(id, version string) *RemoteFilterDefinition {
+		return &RemoteFilterDefinition{
+			FilterDefinition: FilterDefinition{Id: id},
+			Url:              "github.com/owner/repo",
+			Version:          version,
+		}
+	}
+	filterDefinitions := map[string]FilterInstaller{
+		"head":     remoteFilter("head", "HEAD"),
+		"latest":   remoteFilter("latest", "latest"),
+		"outdated": remoteFilter("outdated", "latest"),
+		"missing":  remoteFilter("missing", "HEAD"),
+	}
+	pinned := map[string]string{
+		"head":     testInstalledSha,
+		"latest":   "1.2.3",
+		"outdated": "1.2.3",
+		"missing":  testInstalledSha,
+	}
+	result := installedPinnedFilters(filterDefinitions, pinned, dotRegolithPath)
+	expected := map[string]string{"head": testInstalledSha, "latest": "1.2.3"}
+	actual := make(map[string]string, len(result))
+	for name, filter := range result {
+		actual[name] = filter.(*RemoteFilterDefinition).Version
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf(
+			"Unexpected pinned filters.\nExpected: %v\nActual: %v",
+			expected, actual)
+	}
+	// The filter definitions from the config must not be modified
+	headFilter := filterDefinitions["head"].(*RemoteFilterDefinition)
+	if version := headFilter.Version; version != "HEAD" {
+		t.Errorf("The version of the filter was modified: %q", version)
+	}
+}
+
+// TestFreezeInProject checks whether "regolith freeze" loads the config
+// file from the root of the project instead of the working directory.
+func TestFreezeInProject(t *testing.T) {
+	projectRoot := t.TempDir()
+	config := `{
+		"name": "freeze_test",
+		"author": "Bedrock-OSS",
+		"packs": {"behaviorPack": "./packs/BP", "resourcePack": "./packs/RP"},
+		"regolith": {
+			"dataPath": "./packs/data",
+			"filterDefinitions": {
+				"pinned": {"url": "github.com/owner/repo", "version": "1.2.3"}
+			},
+			"profiles": {"default": {"filters": [], "export": {"target": "local"}}}
+		}
+	}`
+	err := os.WriteFile(
+		filepath.Join(projectRoot, ConfigFilePath), []byte(config), 0644)
+	if err != nil {
+		t.Fatal("Failed to create the config file:", err)
+	}
+	if err := FreezeInProject(projectRoot, false, "", false); err != nil {
+		t.Error("Failed to freeze the filters of the project:", err)
+	}
+	if err := FreezeInProject(t.TempDir(), false, "", false); err == nil {
+		t.Error("Freezing the filters of a directory without a config " +
+			"file didn't fail")
+	}
+}
@@ -0,0 +1,115 @@
+// Functions used for modifying the JSON files without changing the order of
+// the keys of their objects.
+package regolith
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+	"muzzammil.xyz/jsonc"
+)
+
+// orderedJsonObject is a JSON object that remembers the order of its keys,
+// so it can be saved back with the keys in the original order.
+type orderedJsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// get returns the value of the key of the object.
+func (o *orderedJsonObject) get(key string) (interface{}, bool) {
+	value, ok := o.values[key]
+	return value, ok
+}
+
+// set replaces the value of the key of the object. New keys are added at
+// the end of the object.
+func (o *orderedJsonObject) set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *orderedJsonObject) MarshalJSON() ([]byte, error) {
+	var result bytes.Buffer
+	result.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			result.WriteByte(',')
+		}
+		keyJson, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		valueJson, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		result.Write(keyJson)
+		result.WriteByte(':')
+		result.Write(valueJson)
+	}
+	result.WriteByte('}')
+	return result.Bytes(), nil
+}
+
+// decodeOrderedJson decodes the JSON (or JSON with comments) data. The
+// objects are decoded to *orderedJsonObject and the numbers to json.Number,
+// so marshalling the result doesn't change the order of the keys or the
+// format of the numbers. The comments are removed.
+func decodeOrderedJson(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(jsonc.ToJSON(data)))
+	decoder.UseNumber()
+	result, err := decodeOrderedJsonValue(decoder)
+	if err != nil {
+		return nil, burrito.WrapError(err, "Failed to decode the JSON data.")
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, burrito.WrappedError(
+			"Failed to decode the JSON data: unexpected data after the " +
+				"end of the JSON value.")
+	}
+	return result, nil
+}
+
+// decodeOrderedJsonValue decodes the next value of the decoder for
+// decodeOrderedJson.
+func decodeOrderedJsonValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		result := &orderedJsonObject{values: map[string]interface{}{}}
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyToken.(string) // The decoder ensures it's a string
+			value, err := decodeOrderedJsonValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			result.set(key, value)
+		}
+		_, err = decoder.Token() // The closing bracket
+		return result, err
+	case json.Delim('['):
+		result := []interface{}{}
+		for decoder.More() {
+			value, err := decodeOrderedJsonValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+		}
+		_, err = decoder.Token() // The closing bracket
+		return result, err
+	}
+	return token, nil
+}
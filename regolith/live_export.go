@@ -0,0 +1,229 @@
+// Functions used by the experimental "live" export target, which exports the
+// packs to the development folders and reloads them in a running Minecraft
+// connected to Regolith with the "/connect" command.
+package regolith
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+	"golang.org/x/net/websocket"
+)
+
+// liveExportTarget is the name of the export target that reloads the packs
+// in a running Minecraft after exporting them.
+const liveExportTarget = "live"
+
+// The default values of the "port" and "timeout" properties of the "live"
+// export target. The timeout is in seconds.
+const (
+	defaultLivePort    = 19135
+	defaultLiveTimeout = 30
+)
+
+// liveServer is a websocket server that Minecraft connects to with the
+// "/connect" command. The server keeps running after the export, so in the
+// "regolith watch" mode Minecraft has to be connected only once.
+type liveServer struct {
+	port int
+
+	// mutex protects the connection and the responses
+	mutex sync.Mutex
+	// connected is closed when Minecraft connects, and replaced with a new
+	// channel when the connection is lost
+	connected chan struct{}
+	conn      *websocket.Conn
+	// responses maps the IDs of the sent command requests to the channels
+	// that receive the responses
+	responses map[string]chan liveMessage
+}
+
+// liveMessage is a message of the websocket protocol of Minecraft.
+type liveMessage struct {
+	Header liveMessageHeader      `json:"header"`
+	Body   map[string]interface{} `json:"body"`
+}
+
+// liveMessageHeader is the header of a liveMessage.
+type liveMessageHeader struct {
+	Version        int    `json:"version"`
+	RequestId      string `json:"requestId"`
+	MessagePurpose string `json:"messagePurpose"`
+	MessageType    string `json:"messageType,omitempty"`
+}
+
+var (
+	liveServersMutex sync.Mutex
+	// liveServers are the running servers of the "live" export targets by
+	// their ports
+	liveServers = map[int]*liveServer{}
+)
+
+// getLiveServer returns the running server that listens on the port, or
+// starts a new one.
+func getLiveServer(port int) (*liveServer, error) {
+	liveServersMutex.Lock()
+	defer liveServersMutex.Unlock()
+	if server, ok := liveServers[port]; ok {
+		return server, nil
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return nil, burrito.WrapErrorf(
+			err, "Failed to start the websocket server.\nPort: %d", port)
+	}
+	server := &liveServer{
+		port:      port,
+		connected: make(chan struct{}),
+		responses: map[string]chan liveMessage{},
+	}
+	go http.Serve(listener, websocket.Server{Handler: server.handle})
+	liveServers[port] = server
+	return server, nil
+}
+
+// handle handles the connection of Minecraft. It reads the messages until the
+// connection is closed, and passes the responses to the commands to the
+// channels waiting for them. Only one connection is used at a time, the new
+// connection replaces the old one.
+func (s *liveServer) handle(conn *websocket.Conn) {
+	s.mutex.Lock()
+	if s.conn != nil {
+		s.conn.Close()
+		s.connected = make(chan struct{})
+	}
+	s.conn = conn
+	close(s.connected)
+	s.mutex.Unlock()
+	Logger.Infof("Minecraft connected to Regolith on port %d.", s.port)
+	for {
+		var message liveMessage
+		if err := websocket.JSON.Receive(conn, &message); err != nil {
+			break
+		}
+		s.mutex.Lock()
+		response, ok := s.responses[message.Header.RequestId]
+		if ok {
+			delete(s.responses, message.Header.RequestId)
+			response <- message
+		}
+		s.mutex.Unlock()
+	}
+	s.mutex.Lock()
+	if s.conn == conn {
+		s.conn = nil
+		s.connected = make(chan struct{})
+	}
+	s.mutex.Unlock()
+}
+
+// waitForConnection returns the connection of Minecraft. If Minecraft is not
+// connected, it asks the user to connect it and waits until the timeout.
+func (s *liveServer) waitForConnection(timeout time.Duration) (*websocket.Conn, error) {
+	s.mutex.Lock()
+	conn, connected := s.conn, s.connected
+	s.mutex.Unlock()
+	if conn != nil {
+		return conn, nil
+	}
+	Logger.Infof(
+		"Waiting for Minecraft. Run \"/connect localhost:%d\" in the chat of "+
+			"Minecraft to reload the packs automatically.", s.port)
+	select {
+	case <-connected:
+	case <-time.After(timeout):
+		return nil, burrito.WrappedErrorf(
+			"Minecraft didn't connect to Regolith in %s.", timeout)
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.conn == nil {
+		return nil, burrito.WrappedError(
+			"Minecraft disconnected before the command was sent.")
+	}
+	return s.conn, nil
+}
+
+// runCommand sends the command to Minecraft and waits for the response until
+// the timeout. It returns an error if the command fails.
+func (s *liveServer) runCommand(
+	command string, timeout time.Duration,
+) error {
+	conn, err := s.waitForConnection(timeout)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	requestId, err := newUuid()
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	response := make(chan liveMessage, 1)
+	s.mutex.Lock()
+	s.responses[requestId] = response
+	s.mutex.Unlock()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.responses, requestId)
+		s.mutex.Unlock()
+	}()
+	err = websocket.JSON.Send(conn, liveMessage{
+		Header: liveMessageHeader{
+			Version:        1,
+			RequestId:      requestId,
+			MessagePurpose: "commandRequest",
+			MessageType:    "commandRequest",
+		},
+		Body: map[string]interface{}{
+			"version":     1,
+			"commandLine": command,
+			"origin":      map[string]interface{}{"type": "player"},
+		},
+	})
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, "Failed to send the command to Minecraft.\nCommand: %s",
+			command)
+	}
+	select {
+	case message := <-response:
+		// Minecraft uses the status code 0 for the successful commands
+		if statusCode, ok := message.Body["statusCode"].(float64); ok && statusCode != 0 {
+			statusMessage, _ := message.Body["statusMessage"].(string)
+			return burrito.WrappedErrorf(
+				"The command failed in Minecraft.\nCommand: %s\nMessage: %s",
+				command, statusMessage)
+		}
+	case <-time.After(timeout):
+		return burrito.WrappedErrorf(
+			"Minecraft didn't respond to the command in %s.\nCommand: %s",
+			timeout, command)
+	}
+	return nil
+}
+
+// reloadLiveExport runs the "/reload" command in Minecraft connected to the
+// server of the "live" export target. The packs are already exported to the
+// development folders, so a failure only means that the user has to reload
+// them manually. Because of that, the errors are logged as warnings.
+func reloadLiveExport(exportTarget ExportTarget) {
+	err := func() error {
+		server, err := getLiveServer(exportTarget.Port)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+		timeout := time.Duration(exportTarget.Timeout) * time.Second
+		return server.runCommand("reload", timeout)
+	}()
+	if err != nil {
+		Logger.Warnf(
+			"Failed to reload the packs in Minecraft. The packs were "+
+				"exported, but they must be reloaded manually: %s",
+			err.Error())
+		return
+	}
+	Logger.Info("Reloaded the packs in Minecraft.")
+}
@@ -0,0 +1,138 @@
+package regolith
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// TestLiveExportTargetOptions checks the default values and the validation
+// of the "port" and "timeout" properties of the "live" export target.
+func TestLiveExportTargetOptions(t *testing.T) {
+	target, err := ExportTargetFromObject(
+		map[string]interface{}{"target": liveExportTarget})
+	if err != nil {
+		t.Fatal("Failed to parse the export target:", err)
+	}
+	if target.Port != defaultLivePort || target.Timeout != defaultLiveTimeout {
+		t.Errorf(
+			"Unexpected default port %d and timeout %d",
+			target.Port, target.Timeout)
+	}
+	tests := []struct {
+		name    string
+		target  map[string]interface{}
+		isValid bool
+	}{
+		{"port and timeout", map[string]interface{}{
+			"target": "live", "port": 20000.0, "timeout": 5.0}, true},
+		{"port too high", map[string]interface{}{
+			"target": "live", "port": 70000.0}, false},
+		{"port zero", map[string]interface{}{
+			"target": "live", "port": 0.0}, false},
+		{"fractional port", map[string]interface{}{
+			"target": "live", "port": 20000.5}, false},
+		{"port string", map[string]interface{}{
+			"target": "live", "port": "20000"}, false},
+		{"negative timeout", map[string]interface{}{
+			"target": "live", "timeout": -1.0}, false},
+	}
+	for _, test := range tests {
+		_, err := ExportTargetFromObject(test.target)
+		if test.isValid && err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+		} else if !test.isValid && err == nil {
+			t.Errorf("%s: the export target wasn't rejected", test.name)
+		}
+	}
+}
+
+// TestLiveExportExperimental checks whether the "live" export target
+// requires the "--experimental" flag.
+func TestLiveExportExperimental(t *testing.T) {
+	experimental := Experimental
+	defer func() { Experimental = experimental }()
+	Experimental = false
+	_, _, err := GetExportPaths(
+		ExportTarget{Target: liveExportTarget}, "project")
+	if err == nil {
+		t.Error("The \"live\" export target was used without the " +
+			"experimental features")
+	}
+}
+
+// freeTestPort returns a port that isn't used by any other program.
+func freeTestPort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Skip("Unable to find a free port:", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// connectTestMinecraft connects to the server like Minecraft after the
+// "/connect" command, and answers the command requests with the status
+// codes from the list.
+func connectTestMinecraft(
+	t *testing.T, port int, statusCodes ...int,
+) <-chan string {
+	conn, err := websocket.Dial(
+		fmt.Sprintf("ws://localhost:%d/", port), "", "http://localhost/")
+	if err != nil {
+		t.Fatal("Failed to connect to the server:", err)
+	}
+	commands := make(chan string, len(statusCodes))
+	go func() {
+		defer conn.Close()
+		for _, statusCode := range statusCodes {
+			var request liveMessage
+			if err := websocket.JSON.Receive(conn, &request); err != nil {
+				return
+			}
+			commandLine, _ := request.Body["commandLine"].(string)
+			commands <- commandLine
+			request.Header.MessagePurpose = "commandResponse"
+			request.Body = map[string]interface{}{
+				"statusCode":    statusCode,
+				"statusMessage": "Status",
+			}
+			websocket.JSON.Send(conn, request)
+		}
+	}()
+	return commands
+}
+
+// TestLiveServerRunCommand checks whether the commands are sent to the
+// connected Minecraft, and whether the failed commands and the missing
+// connection are reported.
+func TestLiveServerRunCommand(t *testing.T) {
+	InitLogging(false)
+	server, err := getLiveServer(freeTestPort(t))
+	if err != nil {
+		t.Fatal("Failed to start the server:", err)
+	}
+	if other, err := getLiveServer(server.port); err != nil || other != server {
+		t.Error("The running server wasn't reused:", err)
+	}
+	timeout := 100 * time.Millisecond
+	if err := server.runCommand("reload", timeout); err == nil {
+		t.Error("The command was sent without the connection")
+	}
+	commands := connectTestMinecraft(t, server.port, 0, 1)
+	timeout = 5 * time.Second
+	if err := server.runCommand("reload", timeout); err != nil {
+		t.Error("The command failed:", err)
+	}
+	if err := server.runCommand("reload", timeout); err == nil {
+		t.Error("The failed command wasn't reported")
+	}
+	for i := 0; i < 2; i++ {
+		if command := <-commands; command != "reload" {
+			t.Errorf("Unexpected command: %q", command)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+// Functions related to the regolith-lock.json file, which stores the exact
+// versions of the installed remote filters to make the installations
+// reproducible.
+package regolith
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+	"golang.org/x/mod/semver"
+)
+
+// LockFilePath is the name of the lock file, saved next to the config file.
+const LockFilePath = "regolith-lock.json"
+
+// installedShaKey is the property of the filter.json file of an installed
+// remote filter with the SHA of the downloaded commit.
+const installedShaKey = "installedSha"
+
+// shaPattern matches full SHA-1 hashes of git commits.
+var shaPattern = regexp.MustCompile("^[0-9a-f]{40}$")
+
+// LockFile represents the content of the regolith-lock.json file.
+type LockFile struct {
+	Filters map[string]LockedFilter `json:"filters"`
+}
+
+// LockedFilter is an entry of the lock file that describes an installed
+// remote filter.
+type LockedFilter struct {
+	// Url is the URL of the repository of the filter.
+	Url string `json:"url"`
+
+	// Version is the resolved version of the filter. It's a semantic version
+	// or a SHA of the commit when the filter has no version tags.
+	Version string `json:"version"`
+
+	// Sha is the SHA of the commit that was used to install the filter.
+	Sha string `json:"sha"`
+
+	// Dependencies is the list of the names of the remote filters required
+	// by the filter (the "filterDependencies" of its filter.json file).
+	Dependencies []string `json:"dependencies"`
+}
+
+// NewLockFile creates an empty LockFile object.
+func NewLockFile() *LockFile {
+	return &LockFile{Filters: make(map[string]LockedFilter)}
+}
+
+// lockFilePath returns the path to the lock file of the project from the
+// projectRoot. The lock file is next to the config file from the configPath
+// (empty string means the default config file), so every config file has
+// its own lock file.
+func lockFilePath(projectRoot, configPath string) string {
+	configDir := filepath.Dir(resolveConfigPath(configPath))
+	return projectPath(projectRoot, filepath.Join(configDir, LockFilePath))
+}
+
+// LoadLockFile loads the lock file from the path. If the file doesn't exist,
+// it returns nil without an error.
+func LoadLockFile(path string) (*LockFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, burrito.WrapErrorf(err, fileReadError, path)
+	}
+	result := NewLockFile()
+	err = json.Unmarshal(data, result)
+	if err != nil {
+		return nil, burrito.WrapErrorf(err, jsonUnmarshalError, path)
+	}
+	if result.Filters == nil {
+		result.Filters = make(map[string]LockedFilter)
+	}
+	return result, nil
+}
+
+// Dump saves the lock file to the path.
+func (l *LockFile) Dump(path string) error {
+	result, _ := json.MarshalIndent(l, "", "\t") // no error
+	err := os.WriteFile(path, result, 0644)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, path)
+	}
+	return nil
+}
+
+// Update updates the entries of the lock file with the data of the
+// installed remote filters from the filterDefinitions map. Other types of
+// filters are ignored.
+func (l *LockFile) Update(
+	filterDefinitions map[string]FilterInstaller, dotRegolithPath string,
+) error {
+	for name, filterDefinition := range filterDefinitions {
+		remoteFilter, ok := filterDefinition.(*RemoteFilterDefinition)
+		// The filters from the local registry have no version to lock
+		if !ok || remoteFilter.isLocalRegistry() {
+			continue
+		}
+		// The filters downloaded before their SHAs were saved resolve them
+		// with the repository, so in the offline mode the entries of the
+		// unchanged filters are kept
+		if previous, ok := l.Filters[name]; ok && Offline &&
+			previous.Url == remoteFilter.Url {
+			version, err := remoteFilter.InstalledVersion(dotRegolithPath)
+			if err == nil &&
+				(version == previous.Version || version == previous.Sha) {
+				continue
+			}
+		}
+		lockedFilter, err := remoteFilter.lockedFilter(dotRegolithPath)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to resolve the lock data of the filter.\n"+
+					"Filter: %s", name)
+		}
+		// Filters installed from the SHA pinned in the lock file don't know
+		// their version tag. Keep the version from the previous entry.
+		if previous, ok := l.Filters[name]; ok &&
+			previous.Sha == lockedFilter.Sha {
+			lockedFilter.Version = previous.Version
+		}
+		l.Filters[name] = lockedFilter
+	}
+	return nil
+}
+
+// Prune removes the entries of the lock file that don't refer to any remote
+// filter from the filterDefinitions map.
+func (l *LockFile) Prune(filterDefinitions map[string]FilterInstaller) {
+	for name := range l.Filters {
+		filterDefinition, ok := filterDefinitions[name]
+		if !ok {
+			delete(l.Filters, name)
+			continue
+		}
+		if _, ok := filterDefinition.(*RemoteFilterDefinition); !ok {
+			delete(l.Filters, name)
+		}
+	}
+}
+
+// updateLockFile loads the lock file from the lockFilePath (or creates a new
+// one), updates it with the data of the filters from filterDefinitions and
+// saves it. If prune is true, the entries of the filters that are not in the
+// filterDefinitions map are removed.
+func updateLockFile(
+	filterDefinitions map[string]FilterInstaller, prune bool,
+	lockFilePath, dotRegolithPath string,
+) error {
+	lockFile, err := LoadLockFile(lockFilePath)
+	if err != nil {
+		return burrito.WrapError(err, "Failed to load the lock file.")
+	}
+	if lockFile == nil {
+		lockFile = NewLockFile()
+	}
+	if prune {
+		lockFile.Prune(filterDefinitions)
+	}
+	err = lockFile.Update(filterDefinitions, dotRegolithPath)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	err = lockFile.Dump(lockFilePath)
+	if err != nil {
+		return burrito.WrapError(err, "Failed to save the lock file.")
+	}
+	return nil
+}
+
+// lockedFilter creates a LockedFilter entry based on the installed version
+// of the filter. The SHA is read from the installed filter. Only the filters
+// downloaded before the SHAs were saved resolve it with the repository.
+func (f *RemoteFilterDefinition) lockedFilter(
+	dotRegolithPath string,
+) (LockedFilter, error) {
+	version, err := f.InstalledVersion(dotRegolithPath)
+	if err != nil {
+		return LockedFilter{}, burrito.PassError(err)
+	}
+	sha, err := f.installedSha(dotRegolithPath)
+	if err != nil {
+		return LockedFilter{}, burrito.PassError(err)
+	}
+	if sha == "" {
+		// Convert the version back to the git reference
+		ref := version
+		if semver.IsValid("v" + version) {
+			ref = f.remoteName() + "-" + version
+		}
+		sha, err = GetRemoteFilterSha(f.Url, ref)
+		if err != nil {
+			return LockedFilter{}, burrito.PassError(err)
+		}
+	}
+	dependencies, err := f.filterDependencyNames(dotRegolithPath)
+	if err != nil {
+		return LockedFilter{}, burrito.PassError(err)
+	}
+	return LockedFilter{
+		Url:          f.Url,
+		Version:      version,
+		Sha:          sha,
+		Dependencies: dependencies,
+	}, nil
+}
+
+// filterDependencyNames returns the sorted names of the remote filters
+// required by the installed filter.
+func (f *RemoteFilterDefinition) filterDependencyNames(
+	dotRegolithPath string,
+) ([]string, error) {
+	dependencies, err := f.filterDependencies(dotRegolithPath)
+	if err != nil {
+		return nil, burrito.PassError(err)
+	}
+	result := make([]string, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		result = append(result, dependency.name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// GetRemoteFilterSha returns the SHA of the commit referenced by the ref on
+// the repository specified by the url. If the ref is already a SHA, it's
+// returned without accessing the repository. The filters installed from the
+// archives use the hashes of the archives as their refs and SHAs.
+func GetRemoteFilterSha(url, ref string) (string, error) {
+	if shaPattern.MatchString(ref) || isArchiveFilterUrl(url) {
+		return ref, nil
+	}
+	repositoryUrl, _ := splitFilterUrl(url)
+	output, err := gitOutputWithRetry(
+		"ls-remote", gitRemoteUrl(repositoryUrl), ref, ref+"^{}")
+	if err != nil {
+		return "", burrito.PassError(err)
+	}
+	// Annotated tags have two entries. The one with the "^{}" suffix points
+	// at the commit, so it has a priority.
+	sha := ""
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) != 2 {
+			continue
+		}
+		if strings.HasSuffix(parts[1], "^{}") {
+			return parts[0], nil
+		}
+		if sha == "" {
+			sha = parts[0]
+		}
+	}
+	if sha == "" {
+		return "", burrito.WrappedErrorf(
+			"Unable to find the reference on the repository.\n"+
+				"Repository: %s\nReference: %s", url, ref)
+	}
+	return sha, nil
+}
+
+// pinFilterDefinitions returns a copy of the filterDefinitions map, where
+// the remote filters with "HEAD" or "latest" versions are replaced with
+// filters pinned to the SHAs from the lock file.
+func (l *LockFile) pinFilterDefinitions(
+	filterDefinitions map[string]FilterInstaller,
+) map[string]FilterInstaller {
+	result := make(map[string]FilterInstaller, len(filterDefinitions))
+	for name, filterDefinition := range filterDefinitions {
+		result[name] = filterDefinition
+		remoteFilter, ok := filterDefinition.(*RemoteFilterDefinition)
+		if !ok {
+			continue
+		}
+		if remoteFilter.Version != "HEAD" && remoteFilter.Version != "latest" {
+			continue
+		}
+		lockedFilter, ok := l.Filters[name]
+		if !ok || lockedFilter.Sha == "" || lockedFilter.Url != remoteFilter.Url {
+			continue
+		}
+		Logger.Debugf(
+			"Using the SHA from the lock file for %q filter: %s",
+			name, lockedFilter.Sha)
+		pinnedFilter := *remoteFilter
+		pinnedFilter.Version = lockedFilter.Sha
+		result[name] = &pinnedFilter
+	}
+	return result
+}
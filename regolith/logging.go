@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
@@ -50,46 +51,95 @@ func InitLogging(dev bool) {
 		ErrorOutputPaths:  []string{"color:stderr"},
 		DisableStacktrace: true,
 		DisableCaller:     true,
-		EncoderConfig: zapcore.EncoderConfig{
-			TimeKey:       "T",
-			LevelKey:      "L",
-			NameKey:       "N",
-			CallerKey:     "C",
-			FunctionKey:   zapcore.OmitKey,
-			MessageKey:    "M",
-			StacktraceKey: "S",
-			LineEnding:    zapcore.DefaultLineEnding,
-			// Color level and put it into brackets
-			EncodeLevel: func(level zapcore.Level, encoder zapcore.PrimitiveArrayEncoder) {
-				var result string
-				switch level {
-				case zap.InfoLevel:
-					result = fmt.Sprintf("[%s]", color.CyanString(level.CapitalString()))
-				case zap.DebugLevel:
-					result = fmt.Sprintf("[%s]", color.BlueString(level.CapitalString()))
-				case zap.WarnLevel:
-					result = fmt.Sprintf("[%s]", color.YellowString(level.CapitalString()))
-				case zap.ErrorLevel:
-					result = fmt.Sprintf("[%s]", color.RedString(level.CapitalString()))
-				case zap.FatalLevel:
-					result = fmt.Sprintf("[%s]", color.RedString(level.CapitalString()))
-				case zap.PanicLevel:
-				case zap.DPanicLevel:
-					result = fmt.Sprintf("[%s]", color.New(color.FgRed, color.BgWhite).Sprint(level.CapitalString()))
-				}
-				encoder.AppendString(result)
-			},
-			// Hide time
-			EncodeTime: func(time time.Time, encoder zapcore.PrimitiveArrayEncoder) {
-
-			},
-			EncodeDuration: zapcore.StringDurationEncoder,
-			// Hide caller
-			EncodeCaller: func(caller zapcore.EntryCaller, encoder zapcore.PrimitiveArrayEncoder) {
-
-			},
-		},
+		EncoderConfig:     newLoggerEncoderConfig(),
 	}.Build()
 	defer logger.Sync() // flushes buffer, if any
 	Logger = logger.Sugar()
 }
+
+// newLoggerEncoderConfig returns the encoder config of the Regolith logger.
+// The level is colored and put into brackets, the time and the caller are
+// hidden.
+func newLoggerEncoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
+		TimeKey:       "T",
+		LevelKey:      "L",
+		NameKey:       "N",
+		CallerKey:     "C",
+		FunctionKey:   zapcore.OmitKey,
+		MessageKey:    "M",
+		StacktraceKey: "S",
+		LineEnding:    zapcore.DefaultLineEnding,
+		// Color level and put it into brackets
+		EncodeLevel: func(level zapcore.Level, encoder zapcore.PrimitiveArrayEncoder) {
+			var result string
+			switch level {
+			case zap.InfoLevel:
+				result = fmt.Sprintf("[%s]", color.CyanString(level.CapitalString()))
+			case zap.DebugLevel:
+				result = fmt.Sprintf("[%s]", color.BlueString(level.CapitalString()))
+			case zap.WarnLevel:
+				result = fmt.Sprintf("[%s]", color.YellowString(level.CapitalString()))
+			case zap.ErrorLevel:
+				result = fmt.Sprintf("[%s]", color.RedString(level.CapitalString()))
+			case zap.FatalLevel:
+				result = fmt.Sprintf("[%s]", color.RedString(level.CapitalString()))
+			case zap.PanicLevel:
+			case zap.DPanicLevel:
+				result = fmt.Sprintf("[%s]", color.New(color.FgRed, color.BgWhite).Sprint(level.CapitalString()))
+			}
+			encoder.AppendString(result)
+		},
+		// Hide time
+		EncodeTime: func(time time.Time, encoder zapcore.PrimitiveArrayEncoder) {
+
+		},
+		EncodeDuration: zapcore.StringDurationEncoder,
+		// Hide caller
+		EncodeCaller: func(caller zapcore.EntryCaller, encoder zapcore.PrimitiveArrayEncoder) {
+
+		},
+	}
+}
+
+// logPrefix returns the prefix of the log messages about running the
+// filters of the context. The messages of the nested profiles are indented
+// by their nesting level and start with the colored name of the profile.
+// The messages of the top level profile have no prefix.
+func (c *RunContext) logPrefix() string {
+	if c.logDepth == 0 {
+		return ""
+	}
+	return fmt.Sprintf(
+		"%s[%s] ", strings.Repeat("  ", c.logDepth),
+		color.MagentaString(c.Profile))
+}
+
+// logger returns the logger of the messages about running the filters of
+// the context. In the summary-only mode, it's the logger of the run summary,
+// which buffers the messages. Otherwise, it's the global Logger. The context
+// can be nil.
+func (c *RunContext) logger() *zap.SugaredLogger {
+	if c != nil && c.runSummary != nil {
+		return c.runSummary.logger
+	}
+	return Logger
+}
+
+// logInfof logs the message with the info level and the prefix of the
+// nested profile of the context.
+func (c *RunContext) logInfof(template string, args ...interface{}) {
+	c.logger().Infof(c.logPrefix()+template, args...)
+}
+
+// logWarnf logs the message with the warning level and the prefix of the
+// nested profile of the context.
+func (c *RunContext) logWarnf(template string, args ...interface{}) {
+	c.logger().Warnf(c.logPrefix()+template, args...)
+}
+
+// logDebugf logs the message with the debug level and the prefix of the
+// nested profile of the context.
+func (c *RunContext) logDebugf(template string, args ...interface{}) {
+	c.logger().Debugf(c.logPrefix()+template, args...)
+}
@@ -0,0 +1,32 @@
+package regolith
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+// TestLogPrefix checks whether the log messages of the nested profiles are
+// indented by their nesting level and start with the name of the profile.
+func TestLogPrefix(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+	tests := []struct {
+		profile  string
+		depth    int
+		expected string
+	}{
+		{"default", 0, ""},
+		{"nested", 1, "  [nested] "},
+		{"deep", 3, "      [deep] "},
+	}
+	for _, test := range tests {
+		context := RunContext{Profile: test.profile, logDepth: test.depth}
+		if actual := context.logPrefix(); actual != test.expected {
+			t.Errorf(
+				"The prefix of %q at depth %d is %q, expected %q",
+				test.profile, test.depth, actual, test.expected)
+		}
+	}
+}
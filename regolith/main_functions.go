@@ -12,6 +12,38 @@ import (
 	"github.com/Bedrock-OSS/go-burrito/burrito"
 )
 
+// InstallOptions is a collection of the settings of the "regolith install"
+// and "regolith install-all" commands.
+type InstallOptions struct {
+	// ConfigPath is the path to the config file of the project, which is
+	// updated with the installed filters. The empty path means the default
+	// "config.json" file.
+	ConfigPath string
+
+	// Force makes Regolith install the filters even if they're already
+	// installed.
+	Force bool
+
+	// Upgrade makes "regolith install" update the filters that are already
+	// on the filter definitions list to the requested versions instead of
+	// returning an error. Unlike Force, it doesn't download the filters that
+	// are already up to date.
+	Upgrade bool
+
+	// NoConfigWrite makes "regolith install" only download the filters into
+	// the cache, without adding them to the config file and to the lock
+	// file.
+	NoConfigWrite bool
+
+	// NoSubmodules disables initializing the submodules of the Git
+	// repositories of the filters.
+	NoSubmodules bool
+
+	// Update makes "regolith install-all" ignore the SHAs pinned in the lock
+	// file for the filters with "HEAD" or "latest" versions.
+	Update bool
+}
+
 // Install handles the "regolith install" command. It installs specific filters
 // from the internet and adds them to the filtersDefinitions list in the
 // config.json file.
@@ -24,19 +56,32 @@ import (
 // updated to lastest SHA commit and "latest" updates the filter to the latest
 // version tag. If "filter-version" is not specified, the filter will be
 // installed with the latest version or HEAD if there is no valid version tags.
+// Instead of the filters, the list can contain names of the filter groups
+// from the config.json file. The filters of the groups are installed
+// atomically with the versions from the config file.
 //
-// The "force" parameter is a boolean that determines if the installation
-// should be forced even if the filter is already installed.
+// The "options" parameter contains the settings of the installation. The
+// Update option is ignored.
 //
 // The "debug" parameter is a boolean that determines if the debug messages
 // should be printed.
-func Install(filters []string, force, debug bool) error {
+func Install(filters []string, options InstallOptions, debug bool) error {
+	return install(".", filters, options, debug)
+}
+
+// install is the implementation of Install and InstallInProject. The paths
+// of the project are relative to the projectRoot.
+func install(
+	projectRoot string, filters []string, options InstallOptions, debug bool,
+) error {
 	InitLogging(debug)
 	Logger.Info("Installing filters...")
 	if !hasGit() {
 		Logger.Warn(gitNotInstalledWarning)
 	}
-	config, err := LoadConfigAsMap()
+	configPath := projectPath(
+		projectRoot, resolveConfigPath(options.ConfigPath))
+	config, err := LoadConfigAsMap(configPath)
 	if err != nil {
 		return burrito.WrapError(err, "Unable to load config file.")
 	}
@@ -45,6 +90,7 @@ func Install(filters []string, force, debug bool) error {
 	if err != nil {
 		return burrito.WrapError(err, "Failed to get data path from config file.")
 	}
+	dataPath = projectPath(projectRoot, dataPath)
 	filterDefinitions, err := filterDefinitionsFromConfigMap(config)
 	if err != nil {
 		return burrito.WrapError(
@@ -52,27 +98,78 @@ func Install(filters []string, force, debug bool) error {
 			"Failed to get the list of filter definitions from config file.")
 	}
 	// Get dotRegolithPath
-	dotRegolithPath, err := GetDotRegolith(false, ".")
+	dotRegolithPath, err := GetDotRegolith(false, projectRoot)
 	if err != nil {
 		return burrito.WrapError(
 			err, "Unable to get the path to regolith cache folder.")
 	}
 	// Lock the session
-	unlockSession, sessionLockErr := aquireSessionLock(dotRegolithPath)
+	unlockSession, sessionLockErr := aquireSessionLock(dotRegolithPath, 0)
 	if sessionLockErr != nil {
 		return burrito.WrapError(sessionLockErr, aquireSessionLockError)
 	}
 	defer func() { sessionLockErr = unlockSession() }()
+	// Install the filters of the filter groups with their versions from the
+	// config file
+	filterGroups, err := filterGroupsFromConfigMap(config)
+	if err != nil {
+		return burrito.WrapError(
+			err, "Failed to get the filter groups from config file.")
+	}
+	groupInstallers := make(map[string]FilterInstaller, 0)
+	filterArgs := []string{}
+	for _, filter := range filters {
+		group, ok := filterGroups[filter]
+		if !ok {
+			filterArgs = append(filterArgs, filter)
+			continue
+		}
+		for _, name := range group {
+			definition, ok := filterDefinitions[name].(map[string]interface{})
+			if !ok {
+				return burrito.WrappedErrorf(
+					jsonPathTypeError, "regolith->filterDefinitions->"+name,
+					"object")
+			}
+			groupInstallers[name], err = FilterInstallerFromObject(
+				name, definition)
+			if err != nil {
+				return burrito.WrapErrorf(
+					err, jsonPathParseError,
+					"regolith->filterDefinitions->"+name)
+			}
+			setFilterProjectRoot(groupInstallers[name], projectRoot)
+		}
+	}
+	if len(groupInstallers) != 0 {
+		if len(filterArgs) != 0 {
+			return burrito.WrappedError(
+				"Filter groups and other filters can't be installed with " +
+					"the same command.")
+		}
+		err = installFilterGroups(
+			groupInstallers, options.Force, options.NoConfigWrite,
+			options.NoSubmodules, dataPath,
+			lockFilePath(projectRoot, configPath), dotRegolithPath)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+		Logger.Info("Successfully installed the filter groups.")
+		return sessionLockErr // Return the error from the defer function
+	}
 	// Parse arguments into download tasks (requires downloading resolvers)
-	parsedArgs, err := parseInstallFilterArgs(filters)
+	parsedArgs, err := parseInstallFilterArgs(filterArgs)
 	if err != nil {
 		return burrito.WrapError(err, "Failed to parse arguments.")
 	}
 	// Check if the filters are already installed if force mode is disabled
-	if !force {
+	if !options.Force {
 		for _, parsedArg := range parsedArgs {
-			_, ok := filterDefinitions[parsedArg.name]
-			if ok {
+			definition, ok := filterDefinitions[parsedArg.name]
+			if !ok {
+				continue
+			}
+			if !options.Upgrade {
 				return burrito.WrappedErrorf(
 					"The filter is already on the filter definitions list.\n"+
 						"Filter: %s\n"+
@@ -80,6 +177,21 @@ func Install(filters []string, force, debug bool) error {
 						"please add \"--force\" flag to your "+
 						"\"regolith install\" command", parsedArg.name)
 			}
+			// Only the remote filters can be upgraded, replacing the local
+			// filters would lose their definitions
+			definitionMap, _ := definition.(map[string]interface{})
+			if _, ok := definitionMap["url"]; !ok {
+				return burrito.WrappedErrorf(
+					"The filter on the filter definitions list is not a "+
+						"remote filter, so it can't be upgraded.\n"+
+						"Filter: %s\n"+
+						"If you want to replace it, please add \"--force\" "+
+						"flag to your \"regolith install\" command",
+					parsedArg.name)
+			}
+			Logger.Infof(
+				"Filter %q is already installed, upgrading it to version %q.",
+				parsedArg.name, parsedArg.version)
 		}
 	}
 	// Convert to filter definitions for download
@@ -106,10 +218,17 @@ func Install(filters []string, force, debug bool) error {
 	}
 	// Download the filter definitions
 	err = installFilters(
-		filterInstallers, force, dataPath, dotRegolithPath)
+		filterInstallers, options.Force, options.NoSubmodules, dataPath,
+		dotRegolithPath)
 	if err != nil {
 		return burrito.WrapError(err, "Failed to install filters.")
 	}
+	if options.NoConfigWrite {
+		Logger.Info(
+			"Successfully installed the filters. The config file and the " +
+				"lock file were not modified.")
+		return sessionLockErr // Return the error from the defer function
+	}
 	// Add the filters to the config
 	for name, downloadedFilter := range filterInstallers {
 		// Add the filter to config file
@@ -117,7 +236,7 @@ func Install(filters []string, force, debug bool) error {
 	}
 	// Save the config file
 	jsonBytes, _ := json.MarshalIndent(config, "", "\t")
-	err = ioutil.WriteFile(ConfigFilePath, jsonBytes, 0644)
+	err = ioutil.WriteFile(configPath, jsonBytes, 0644)
 	if err != nil {
 		return burrito.WrapErrorf(
 			err,
@@ -126,6 +245,15 @@ func Install(filters []string, force, debug bool) error {
 				"Run \"regolith clean\" to fix invalid cache state.",
 			len(parsedArgs))
 	}
+	// Update the lock file
+	err = updateLockFile(
+		filterInstallers, false, lockFilePath(projectRoot, configPath),
+		dotRegolithPath)
+	if err != nil {
+		return burrito.WrapError(
+			err, "Successfully installed the filters but failed to update "+
+				"the lock file.")
+	}
 	Logger.Info("Successfully installed the filters.")
 	return sessionLockErr // Return the error from the defer function
 }
@@ -134,55 +262,1126 @@ func Install(filters []string, force, debug bool) error {
 // filters and their dependencies from the filtersDefinitions list in the
 // config.json file.
 //
-// The "force" parameter is a boolean that determines if the installation
-// should be forced even if the filter is already installed.
+// The "options" parameter contains the settings of the installation. The
+// Upgrade and NoConfigWrite options are ignored.
 //
 // The "debug" parameter is a boolean that determines if the debug messages
 // should be printed.
-func InstallAll(force, debug bool) error {
+func InstallAll(options InstallOptions, debug bool) error {
+	return installAll(".", options, debug)
+}
+
+// installAll is the implementation of InstallAll and InstallAllInProject.
+// The paths of the project are relative to the projectRoot.
+func installAll(projectRoot string, options InstallOptions, debug bool) error {
 	InitLogging(debug)
 	Logger.Info("Installing filters...")
 	if !hasGit() {
 		Logger.Warn(gitNotInstalledWarning)
 	}
-	configMap, err1 := LoadConfigAsMap()
+	configMap, err1 := LoadConfigAsMap(
+		projectPath(projectRoot, resolveConfigPath(options.ConfigPath)))
 	config, err2 := ConfigFromObject(configMap)
 	if err := firstErr(err1, err2); err != nil {
 		return burrito.WrapError(err, "Failed to load config.json.")
 	}
+	config.setProjectRoot(projectRoot)
 	// Get dotRegolithPath
-	dotRegolithPath, err := GetDotRegolith(false, ".")
+	dotRegolithPath, err := GetDotRegolith(false, projectRoot)
 	if err != nil {
 		return burrito.WrapError(
 			err, "Unable to get the path to regolith cache folder.")
 	}
 	// Lock the session
-	unlockSession, sessionLockErr := aquireSessionLock(dotRegolithPath)
+	unlockSession, sessionLockErr := aquireSessionLock(dotRegolithPath, 0)
 	if sessionLockErr != nil {
 		return burrito.WrapError(sessionLockErr, aquireSessionLockError)
 	}
 	defer func() { sessionLockErr = unlockSession() }()
+	// Use the versions pinned in the lock file unless updating
+	filterDefinitions := config.FilterDefinitions
+	if !options.Update {
+		lockFile, err := LoadLockFile(
+			lockFilePath(projectRoot, options.ConfigPath))
+		if err != nil {
+			return burrito.WrapError(err, "Failed to load the lock file.")
+		}
+		if lockFile != nil {
+			filterDefinitions = lockFile.pinFilterDefinitions(filterDefinitions)
+		}
+	}
 	// Install the filters
 	err = installFilters(
-		config.FilterDefinitions, force, config.DataPath, dotRegolithPath)
+		filterDefinitions, options.Force, options.NoSubmodules,
+		config.DataPath, dotRegolithPath)
 	if err != nil {
 		return burrito.WrapError(err, "Could not install filters.")
 	}
-	Logger.Info("Successfully installed the filters.")
-	return sessionLockErr // Return the error from the defer function
+	// Update the lock file
+	err = updateLockFile(
+		filterDefinitions, true, lockFilePath(projectRoot, options.ConfigPath),
+		dotRegolithPath)
+	if err != nil {
+		return burrito.WrapError(
+			err, "Successfully installed the filters but failed to update "+
+				"the lock file.")
+	}
+	Logger.Info("Successfully installed the filters.")
+	return sessionLockErr // Return the error from the defer function
+}
+
+// Update handles the "regolith update" command. It updates the selected
+// filters to the newest versions allowed by their filter definitions,
+// ignoring the SHAs pinned in the lock file, and updates the lock file.
+//
+// The "filters" parameter is a list of the names of the filters to update.
+// The names can be glob patterns (for example "team_*"), which are matched
+// against the names of the filter definitions from the config.json file, or
+// names of the filter groups. The filters of the groups are updated
+// atomically - if any of them fails, all of them are restored to their
+// previous versions.
+//
+// The "noSubmodules" parameter is a boolean that determines if the
+// submodules of the Git repositories of the filters should not be initialized.
+//
+// The "configPath" parameter is the path to the config file. The empty path
+// means "config.json".
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func Update(
+	filters []string, noSubmodules bool, configPath string, debug bool,
+) error {
+	return update(".", filters, noSubmodules, configPath, debug)
+}
+
+// update is the implementation of Update and UpdateInProject. The paths of
+// the project are relative to the projectRoot.
+func update(
+	projectRoot string, filters []string, noSubmodules bool,
+	configPath string, debug bool,
+) error {
+	InitLogging(debug)
+	Logger.Info("Updating filters...")
+	if !hasGit() {
+		Logger.Warn(gitNotInstalledWarning)
+	}
+	configMap, err1 := LoadConfigAsMap(
+		projectPath(projectRoot, resolveConfigPath(configPath)))
+	config, err2 := ConfigFromObject(configMap)
+	if err := firstErr(err1, err2); err != nil {
+		return burrito.WrapError(err, "Failed to load config.json.")
+	}
+	config.setProjectRoot(projectRoot)
+	filters, usesGroups := expandFilterGroups(filters, config.FilterGroups)
+	names, err := resolveFilterPatterns(filters, config.FilterDefinitions)
+	if err != nil {
+		return burrito.WrapError(err, "Failed to select the filters to update.")
+	}
+	if len(names) == 0 {
+		return burrito.WrappedError(
+			"No filters to update.\n" +
+				"Please specify at least one filter from the filter " +
+				"definitions list.")
+	}
+	filterInstallers := make(map[string]FilterInstaller, len(names))
+	for _, name := range names {
+		filterInstallers[name] = config.FilterDefinitions[name]
+	}
+	// Get dotRegolithPath
+	dotRegolithPath, err := GetDotRegolith(false, projectRoot)
+	if err != nil {
+		return burrito.WrapError(
+			err, "Unable to get the path to regolith cache folder.")
+	}
+	// Lock the session
+	unlockSession, sessionLockErr := aquireSessionLock(dotRegolithPath, 0)
+	if sessionLockErr != nil {
+		return burrito.WrapError(sessionLockErr, aquireSessionLockError)
+	}
+	defer func() { sessionLockErr = unlockSession() }()
+	// Install the filters. The filter groups are installed atomically.
+	if usesGroups {
+		err = installFiltersAtomically(
+			filterInstallers, false, noSubmodules, config.DataPath,
+			dotRegolithPath)
+	} else {
+		err = installFilters(
+			filterInstallers, false, noSubmodules, config.DataPath,
+			dotRegolithPath)
+	}
+	if err != nil {
+		return burrito.WrapError(err, "Could not update filters.")
+	}
+	// Update the lock file
+	err = updateLockFile(
+		filterInstallers, false, lockFilePath(projectRoot, configPath),
+		dotRegolithPath)
+	if err != nil {
+		return burrito.WrapError(
+			err, "Successfully updated the filters but failed to update "+
+				"the lock file.")
+	}
+	Logger.Info("Successfully updated the filters.")
+	return sessionLockErr // Return the error from the defer function
+}
+
+// Freeze handles the "regolith freeze" command. It replaces the "HEAD" and
+// "latest" versions of the remote filters in the config file with the
+// current SHAs of the repositories and the newest version tags of the
+// filters, so the project always uses the same versions of the filters.
+//
+// The "dryRun" parameter is a boolean that determines if the versions should
+// only be printed without modifying the config file.
+//
+// The "configPath" parameter is the path to the config file. The empty path
+// means "config.json".
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func Freeze(dryRun bool, configPath string, debug bool) error {
+	return freeze(".", dryRun, configPath, debug)
+}
+
+// freeze is the implementation of Freeze and FreezeInProject. The paths of
+// the project are relative to the projectRoot.
+func freeze(
+	projectRoot string, dryRun bool, configPath string, debug bool,
+) error {
+	InitLogging(debug)
+	Logger.Info("Pinning the versions of the filters...")
+	if !hasGit() {
+		Logger.Warn(gitNotInstalledWarning)
+	}
+	resolvedConfigPath := projectPath(
+		projectRoot, resolveConfigPath(configPath))
+	configMap, err1 := LoadConfigAsMap(resolvedConfigPath)
+	config, err2 := ConfigFromObject(configMap)
+	if err := firstErr(err1, err2); err != nil {
+		return burrito.WrapError(err, "Failed to load config.json.")
+	}
+	config.setProjectRoot(projectRoot)
+	filterDefinitions, err := filterDefinitionsFromConfigMap(configMap)
+	if err != nil {
+		return burrito.WrapError(
+			err,
+			"Failed to get the list of filter definitions from config file.")
+	}
+	pinned := make(map[string]string)
+	for _, name := range unpinnedFilters(config.FilterDefinitions) {
+		remoteFilter := config.FilterDefinitions[name].(*RemoteFilterDefinition)
+		version, err := resolvePinnedVersion(
+			remoteFilter.Url, remoteFilter.remoteName(), remoteFilter.Version)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to resolve the version of the filter.\n"+
+					"Filter: %s\nVersion: %s", name, remoteFilter.Version)
+		}
+		Logger.Infof("%s: %s -> %s", name, remoteFilter.Version, version)
+		pinned[name] = version
+	}
+	if len(pinned) == 0 {
+		Logger.Info("There are no \"HEAD\" or \"latest\" filters to pin.")
+		return nil
+	}
+	if dryRun {
+		Logger.Infof(
+			"Found %d filters to pin. The config file was not modified.",
+			len(pinned))
+		return nil
+	}
+	// Get dotRegolithPath
+	dotRegolithPath, err := GetDotRegolith(false, projectRoot)
+	if err != nil {
+		return burrito.WrapError(
+			err, "Unable to get the path to regolith cache folder.")
+	}
+	// Lock the session
+	unlockSession, sessionLockErr := aquireSessionLock(dotRegolithPath, 0)
+	if sessionLockErr != nil {
+		return burrito.WrapError(sessionLockErr, aquireSessionLockError)
+	}
+	defer func() { sessionLockErr = unlockSession() }()
+	// Write the versions to the unparsed config to keep the other properties
+	if err := setFilterVersions(filterDefinitions, pinned); err != nil {
+		return burrito.PassError(err)
+	}
+	// Save the config file
+	jsonBytes, _ := json.MarshalIndent(configMap, "", "\t")
+	err = ioutil.WriteFile(resolvedConfigPath, jsonBytes, 0644)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, resolvedConfigPath)
+	}
+	// Update the lock file. The filters that aren't installed with the pinned
+	// versions are added to it by "regolith install-all".
+	err = updateLockFile(
+		installedPinnedFilters(
+			config.FilterDefinitions, pinned, dotRegolithPath),
+		false, lockFilePath(projectRoot, configPath), dotRegolithPath)
+	if err != nil {
+		return burrito.WrapError(
+			err, "Successfully pinned the versions of the filters but "+
+				"failed to update the lock file.")
+	}
+	Logger.Infof("Pinned the versions of %d filters.", len(pinned))
+	return sessionLockErr // Return the error from the defer function
+}
+
+// Verify handles the "regolith verify" command. It checks whether the
+// filters installed in the cache match the filter definitions from the
+// config file and reports the missing, outdated and orphaned filters.
+// The function doesn't modify anything. It returns an error if any problems
+// were found.
+//
+// The "configPath" parameter is the path to the config file. The empty path
+// means "config.json".
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func Verify(configPath string, debug bool) error {
+	return verify(".", configPath, debug)
+}
+
+// verify is the implementation of Verify and VerifyInProject. The paths of
+// the project are relative to the projectRoot.
+func verify(projectRoot, configPath string, debug bool) error {
+	InitLogging(debug)
+	Logger.Info("Verifying the filter cache...")
+	problems, err := findFilterCacheProblems(projectRoot, true, configPath)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	if len(problems) == 0 {
+		Logger.Info("The filter cache matches the config file.")
+		return nil
+	}
+	for _, problem := range problems {
+		Logger.Warn(problem.String())
+	}
+	return burrito.WrappedErrorf(
+		"Found %d problems with the filter cache.\n"+
+			"You can fix the missing and outdated filters by running:\n"+
+			"regolith install-all", len(problems))
+}
+
+// DryInstallAll handles the "regolith install-all --dry-install" command. It
+// lists the filters that "regolith install-all" would install or reinstall
+// without modifying anything. It returns an error if any filter needs to be
+// installed, which makes it useful for failing fast in CI.
+//
+// The "update" parameter determines if the versions pinned in the lock file
+// should be ignored, the same way as in InstallAll.
+//
+// The "configPath" parameter is the path to the config file. The empty path
+// means "config.json".
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func DryInstallAll(update bool, configPath string, debug bool) error {
+	InitLogging(debug)
+	Logger.Info("Checking which filters need to be installed...")
+	problems, err := findFilterCacheProblems(".", !update, configPath)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	toInstall := []string{}
+	for _, problem := range problems {
+		if problem.Kind == filterCacheOrphaned {
+			continue // Orphaned filters don't need to be installed
+		}
+		Logger.Warn(problem.String())
+		toInstall = append(toInstall, problem.Filter)
+	}
+	if len(toInstall) == 0 {
+		Logger.Info("All of the filters are installed.")
+		return nil
+	}
+	return burrito.WrappedErrorf(
+		"%d filters need to be installed: %s\n"+
+			"You can install them by running:\n"+
+			"regolith install-all",
+		len(toInstall), strings.Join(toInstall, ", "))
+}
+
+// findFilterCacheProblems loads the config and the lock file of the project
+// from the projectRoot and returns the result of checkFilterCache. If
+// useLockFile is false, the lock file is ignored.
+func findFilterCacheProblems(
+	projectRoot string, useLockFile bool, configPath string,
+) ([]filterCacheProblem, error) {
+	configMap, err1 := LoadConfigAsMap(
+		projectPath(projectRoot, resolveConfigPath(configPath)))
+	config, err2 := ConfigFromObject(configMap)
+	if err := firstErr(err1, err2); err != nil {
+		return nil, burrito.WrapError(err, "Failed to load config.json.")
+	}
+	// Get dotRegolithPath
+	dotRegolithPath, err := GetDotRegolith(false, projectRoot)
+	if err != nil {
+		return nil, burrito.WrapError(
+			err, "Unable to get the path to regolith cache folder.")
+	}
+	var lockFile *LockFile
+	if useLockFile {
+		lockFile, err = LoadLockFile(lockFilePath(projectRoot, configPath))
+		if err != nil {
+			return nil, burrito.WrapError(err, "Failed to load the lock file.")
+		}
+	}
+	problems, err := checkFilterCache(
+		config.FilterDefinitions, lockFile, dotRegolithPath)
+	if err != nil {
+		return nil, burrito.WrapError(err, "Failed to check the filter cache.")
+	}
+	return problems, nil
+}
+
+// ListProfiles handles the "regolith list-profiles" command. It prints the
+// names of the profiles from the config file together with their
+// descriptions.
+//
+// The "configPath" parameter is the path to the config file. The empty path
+// means "config.json".
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func ListProfiles(configPath string, debug bool) error {
+	InitLogging(debug)
+	configMap, err1 := LoadConfigAsMap(configPath)
+	config, err2 := ConfigFromObject(configMap)
+	if err := firstErr(err1, err2); err != nil {
+		return burrito.WrapError(err, "Failed to load config.json.")
+	}
+	fmt.Println(config.ListProfiles())
+	return nil
+}
+
+// RenameFilter handles the "regolith filter rename" command. It changes the
+// name of a filter definition in the config file and updates the references
+// to the filter in all of the profiles. Renaming a remote filter moves its
+// downloaded files in the cache and its entry in the lock file, and saves
+// the old name as "remoteName", which is used to find the filter in its
+// repository. The virtual environments are shared by the venvSlot, so they
+// are kept as they are.
+//
+// The "configPath" parameter is the path to the config file. The empty path
+// means "config.json".
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func RenameFilter(oldName, newName, configPath string, debug bool) error {
+	InitLogging(debug)
+	configPath = resolveConfigPath(configPath)
+	configMap, err1 := LoadConfigAsMap(configPath)
+	config, err2 := ConfigFromObject(configMap)
+	if err := firstErr(err1, err2); err != nil {
+		return burrito.WrapError(err, "Failed to load config.json.")
+	}
+	filterDefinition, ok := config.FilterDefinitions[oldName]
+	if !ok {
+		return burrito.WrappedErrorf(
+			"The filter is not on the filter definitions list.\nFilter: %s",
+			oldName)
+	}
+	if _, ok := config.FilterDefinitions[newName]; ok {
+		return burrito.WrappedErrorf(
+			"The new name of the filter is already used by another filter "+
+				"definition.\nFilter: %s", newName)
+	}
+	if _, ok := config.FilterGroups[newName]; ok {
+		return burrito.WrappedErrorf(
+			"The new name of the filter is already used by a filter group."+
+				"\nFilter: %s", newName)
+	}
+	// Get parts of config file required for renaming
+	filterDefinitions, err := filterDefinitionsFromConfigMap(configMap)
+	if err != nil {
+		return burrito.WrapError(
+			err,
+			"Failed to get the list of filter definitions from config file.")
+	}
+	profiles, err := profilesFromConfigMap(configMap)
+	if err != nil {
+		return burrito.WrapError(
+			err, "Failed to get the list of profiles from config file.")
+	}
+	// Get dotRegolithPath
+	dotRegolithPath, err := GetDotRegolith(false, ".")
+	if err != nil {
+		return burrito.WrapError(
+			err, "Unable to get the path to regolith cache folder.")
+	}
+	// Lock the session
+	unlockSession, sessionLockErr := aquireSessionLock(dotRegolithPath, 0)
+	if sessionLockErr != nil {
+		return burrito.WrapError(sessionLockErr, aquireSessionLockError)
+	}
+	defer func() { sessionLockErr = unlockSession() }()
+	// Move the files of the remote filter
+	if remoteFilter, ok := filterDefinition.(*RemoteFilterDefinition); ok {
+		err = renameRemoteFilter(
+			remoteFilter, newName, configPath, dotRegolithPath)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+		if !remoteFilter.isLocalRegistry() {
+			definitionMap, ok := filterDefinitions[oldName].(map[string]interface{})
+			if !ok {
+				return burrito.WrappedErrorf(
+					jsonPathTypeError, "filterDefinitions->"+oldName, "object")
+			}
+			definitionMap["remoteName"] = remoteFilter.remoteName()
+		}
+	}
+	// Rename the filter
+	filterDefinitions[newName] = filterDefinitions[oldName]
+	delete(filterDefinitions, oldName)
+	references := renameFilterInProfiles(profiles, oldName, newName)
+	groupReferences := renameFilterInGroups(configMap, oldName, newName)
+	// Save the config file
+	jsonBytes, _ := json.MarshalIndent(configMap, "", "\t")
+	err = ioutil.WriteFile(configPath, jsonBytes, 0644)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, configPath)
+	}
+	Logger.Infof(
+		"Renamed filter %q to %q and updated %d references in the profiles "+
+			"and %d references in the filter groups.",
+		oldName, newName, references, groupReferences)
+	return sessionLockErr // Return the error from the defer function
+}
+
+// renameRemoteFilter moves the downloaded files of the remote filter in the
+// cache and its entry in the lock file of the config file from the
+// configPath to the newName.
+func renameRemoteFilter(
+	filter *RemoteFilterDefinition, newName, configPath,
+	dotRegolithPath string,
+) error {
+	oldPath := filter.GetDownloadPath(dotRegolithPath)
+	newPath := filepath.Join(filepath.Dir(oldPath), newName)
+	if _, err := os.Lstat(oldPath); err == nil {
+		if err := os.RemoveAll(newPath); err != nil {
+			return burrito.WrapErrorf(err, osRemoveError, newPath)
+		}
+		if err := os.Rename(oldPath, newPath); err != nil {
+			return burrito.WrapErrorf(err, osRenameError, oldPath, newPath)
+		}
+	} else if !os.IsNotExist(err) {
+		return burrito.WrapErrorf(err, osStatErrorAny, oldPath)
+	}
+	lockPath := lockFilePath(".", configPath)
+	lockFile, err := LoadLockFile(lockPath)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	if lockFile == nil {
+		return nil
+	}
+	lockedFilter, ok := lockFile.Filters[filter.Id]
+	if !ok {
+		return nil
+	}
+	delete(lockFile.Filters, filter.Id)
+	lockFile.Filters[newName] = lockedFilter
+	if err := lockFile.Dump(lockPath); err != nil {
+		return burrito.PassError(err)
+	}
+	return nil
+}
+
+// Migrate handles the "regolith migrate" command. It upgrades the config
+// file written for an older version of Regolith to the current format and
+// prints the list of the applied changes. The original file is saved with
+// the ".bak" extension. If the config is already up to date, nothing is
+// written.
+//
+// The "configPath" parameter is the path to the config file. The empty path
+// means "config.json".
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func Migrate(configPath string, debug bool) error {
+	return migrate(".", configPath, debug)
+}
+
+// migrate is the implementation of Migrate and MigrateInProject. The path
+// to the config file is relative to the projectRoot.
+func migrate(projectRoot, configPath string, debug bool) error {
+	InitLogging(debug)
+	configPath = projectPath(projectRoot, resolveConfigPath(configPath))
+	original, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileReadError, configPath)
+	}
+	configMap, err := LoadConfigAsMap(configPath)
+	if err != nil {
+		return burrito.WrapError(err, "Unable to load config file.")
+	}
+	changes, err := migrateConfig(configMap)
+	if err != nil {
+		return burrito.WrapError(err, "Failed to migrate the config file.")
+	}
+	if len(changes) == 0 {
+		Logger.Info("The config file is already up to date.")
+		return nil
+	}
+	// Make sure that the migrated config can be used
+	_, err = ConfigFromObject(configMap)
+	if err != nil {
+		return burrito.WrapError(
+			err, "The config file is invalid after the migration. The "+
+				"config file was not modified.")
+	}
+	backupPath := configPath + ".bak"
+	err = ioutil.WriteFile(backupPath, original, 0644)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, backupPath)
+	}
+	jsonBytes, _ := json.MarshalIndent(configMap, "", "\t")
+	err = ioutil.WriteFile(configPath, jsonBytes, 0644)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, configPath)
+	}
+	Logger.Infof(
+		"Migrated the config file. Applied changes:\n\t- %s\n"+
+			"The original file was saved to %q.",
+		strings.Join(changes, "\n\t- "), backupPath)
+	return nil
+}
+
+// AddProfile handles the "regolith add-profile" command. It adds a new
+// profile with an empty list of filters and the "development" export target
+// to the config file. The other content of the config file keeps the order
+// of its keys.
+//
+// The "force" parameter is a boolean that determines if an existing profile
+// with the same name should be replaced.
+//
+// The "configPath" parameter is the path to the config file. The empty path
+// means "config.json".
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func AddProfile(name string, force bool, configPath string, debug bool) error {
+	InitLogging(debug)
+	configPath = resolveConfigPath(configPath)
+	// Get dotRegolithPath
+	dotRegolithPath, err := GetDotRegolith(false, ".")
+	if err != nil {
+		return burrito.WrapError(
+			err, "Unable to get the path to regolith cache folder.")
+	}
+	// Lock the session
+	unlockSession, sessionLockErr := aquireSessionLock(dotRegolithPath, 0)
+	if sessionLockErr != nil {
+		return burrito.WrapError(sessionLockErr, aquireSessionLockError)
+	}
+	defer func() { sessionLockErr = unlockSession() }()
+	configMap, err := LoadConfigAsMap(configPath)
+	if err != nil {
+		return burrito.WrapError(err, "Unable to load config file.")
+	}
+	profiles, err := profilesFromConfigMap(configMap)
+	if err != nil {
+		return burrito.WrapError(
+			err, "Failed to get the list of profiles from config file.")
+	}
+	if _, ok := profiles[name]; ok && !force {
+		return burrito.WrappedErrorf(
+			"The profile already exists.\n"+
+				"Profile: %s\n"+
+				"If you want to replace the profile, please add \"--force\" "+
+				"flag to your \"regolith add-profile\" command", name)
+	}
+	// Use the same profile as the "default" profile created by "regolith init"
+	profile := Profile{
+		FilterCollection: FilterCollection{
+			Filters: []FilterRunner{},
+		},
+		ExportTarget: ExportTarget{
+			Target:   "development",
+			ReadOnly: false,
+		},
+	}
+	jsonBytes, _ := json.Marshal(profile)
+	profileMap := make(map[string]interface{})
+	json.Unmarshal(jsonBytes, &profileMap)
+	// Add the profile to the config file decoded with the order of the keys.
+	// The paths were already validated with the profilesFromConfigMap
+	original, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileReadError, configPath)
+	}
+	orderedConfig, err := decodeOrderedJson(original)
+	if err != nil {
+		return burrito.WrapErrorf(err, jsonUnmarshalError, configPath)
+	}
+	regolithObj, _ := orderedConfig.(*orderedJsonObject).get("regolith")
+	profilesObj, _ := regolithObj.(*orderedJsonObject).get("profiles")
+	profilesObj.(*orderedJsonObject).set(name, profileMap)
+	// Save the config file
+	jsonBytes, err = json.MarshalIndent(orderedConfig, "", "\t")
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, "Failed to serialize the config file.\nPath: %s", configPath)
+	}
+	err = ioutil.WriteFile(configPath, jsonBytes, 0644)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, configPath)
+	}
+	Logger.Infof("Added the %q profile.", name)
+	return sessionLockErr // Return the error from the defer function
+}
+
+// Changelog handles the "regolith changelog" command. It compares two export
+// manifests created with "regolith run --export-manifest" and prints the
+// added, removed and modified files grouped by packs and categories. If the
+// outputPath is not empty, the changes are also saved there as JSON.
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func Changelog(previousPath, currentPath, outputPath string, debug bool) error {
+	InitLogging(debug)
+	previous, err := LoadExportManifest(previousPath)
+	if err != nil {
+		return burrito.WrapError(err, "Failed to load the previous export manifest.")
+	}
+	current, err := LoadExportManifest(currentPath)
+	if err != nil {
+		return burrito.WrapError(err, "Failed to load the current export manifest.")
+	}
+	changes := diffExportManifests(previous, current)
+	fmt.Println(changes.String())
+	if outputPath != "" {
+		jsonBytes, _ := json.MarshalIndent(changes, "", "\t")
+		err = ioutil.WriteFile(outputPath, jsonBytes, 0644)
+		if err != nil {
+			return burrito.WrapErrorf(err, fileWriteError, outputPath)
+		}
+	}
+	return nil
+}
+
+// Which handles the "regolith which" command. It prints the path to the
+// installed filter, its pinned and installed versions and the SHA of the
+// commit used to install it. If jsonOutput is true, the information is
+// printed as JSON.
+//
+// The "configPath" parameter is the path to the config file. The empty path
+// means "config.json". The lock file is read from the directory of the
+// config file.
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func Which(filterName string, jsonOutput bool, configPath string, debug bool) error {
+	InitLogging(debug)
+	configMap, err1 := LoadConfigAsMap(configPath)
+	config, err2 := ConfigFromObject(configMap)
+	if err := firstErr(err1, err2); err != nil {
+		return burrito.WrapError(err, "Failed to load config.json.")
+	}
+	filterDefinition, ok := config.FilterDefinitions[filterName]
+	if !ok {
+		return burrito.WrappedErrorf(
+			"The filter is not on the filter definitions list.\nFilter: %s",
+			filterName)
+	}
+	dotRegolithPath, err := GetDotRegolith(jsonOutput, ".")
+	if err != nil {
+		return burrito.WrapError(
+			err, "Unable to get the path to regolith cache folder.")
+	}
+	lockFile, err := LoadLockFile(lockFilePath(".", configPath))
+	if err != nil {
+		return burrito.WrapError(err, "Failed to load the lock file.")
+	}
+	location, err := newFilterLocation(
+		filterName, filterDefinition, lockFile, dotRegolithPath)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	if jsonOutput {
+		fmt.Println(location.Json())
+	} else {
+		fmt.Println(location.String())
+	}
+	return nil
+}
+
+// FilterInfo handles the "regolith filter-info" command. It prints the
+// description, the available versions, the runtimes and the dependencies of
+// a remote filter without installing it. The filter argument uses the same
+// format as the arguments of "regolith install". If jsonOutput is true, the
+// information is printed as JSON.
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func FilterInfo(filter string, jsonOutput, debug bool) error {
+	InitLogging(debug)
+	parsedArgs, err := parseInstallFilterArgs([]string{filter})
+	if err != nil {
+		return burrito.WrapError(err, "Failed to parse the filter argument.")
+	}
+	parsedArg := parsedArgs[0]
+	info, err := fetchRemoteFilterInfo(
+		parsedArg.url, parsedArg.name, parsedArg.version)
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, "Failed to get the information about the filter.\n"+
+				"Filter: %s", filter)
+	}
+	if jsonOutput {
+		fmt.Println(info.Json())
+	} else {
+		fmt.Println(info.String())
+	}
+	return nil
+}
+
+// runOrWatch handles both 'regolith run' and 'regolith watch' commands based
+// on the 'watch' parameter. It runs/watches the profile named after
+// 'profileName' parameter. The 'options' argument changes the way the filters
+// are executed. The 'debug' argument determines if the debug messages should
+// be printed or not. The paths of the project and the relative paths from
+// the options are relative to the 'projectRoot'.
+func runOrWatch(
+	projectRoot, profileName string, options RunOptions, debug, watch bool,
+) error {
+	InitLogging(debug)
+	// The profile from the file is named after the file unless the name is
+	// specified
+	if profileName == "" && options.ProfileFile != "" {
+		profileName = options.ProfileFile
+	}
+	if profileName == "" {
+		profileName = "default"
+	}
+	options.ExportManifest = projectPath(projectRoot, options.ExportManifest)
+	options.ProfileReport = projectPath(projectRoot, options.ProfileReport)
+	// Load the Config and the profile
+	configJson, err := LoadConfigAsMap(
+		projectPath(projectRoot, resolveConfigPath(options.ConfigPath)))
+	if err != nil {
+		return burrito.WrapError(err, "Could not load \"config.json\".")
+	}
+	if options.ProfileFile != "" {
+		err = addProfileFromFile(
+			configJson, projectPath(projectRoot, options.ProfileFile),
+			profileName)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to load the profile from the file.\nPath: %s",
+				options.ProfileFile)
+		}
+	}
+	err = substituteSettingsVariables(configJson, profileName, options.Vars)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	config, err := ConfigFromObject(configJson)
+	if err != nil {
+		return burrito.WrapError(err, "Could not load \"config.json\".")
+	}
+	config.setProjectRoot(projectRoot)
+	projectDir, err := config.projectDir()
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	profile, ok := config.Profiles[profileName]
+	if !ok {
+		return burrito.WrappedErrorf(
+			"Profile %q does not exist in the configuration.\n"+
+				"Available profiles:\n%s", profileName, config.ListProfiles())
+	}
+	// Replace the export targets before running the filters, so the long
+	// builds are not wasted on an unwritable destination
+	if options.ExportTarget != "" {
+		profile, err = overrideExportTarget(
+			profile, options.ExportTarget, projectRoot)
+		if err != nil {
+			return burrito.WrapError(err, "Failed to override the export target.")
+		}
+		config.Profiles[profileName] = profile
+	}
+	maxMemory, err := resolveMaxMemory(options.MaxMemory)
+	if err != nil {
+		return burrito.WrapError(err, "Failed to parse the memory limit.")
+	}
+	// Check the git repository before the build
+	if options.RequireCleanGit {
+		err = checkCleanGitTree(projectDir)
+		if err != nil {
+			return burrito.WrapError(err, "Failed to verify the git repository.")
+		}
+	}
+	// Get dotRegolithPath
+	dotRegolithPath, err := GetDotRegolith(false, projectRoot)
+	if err != nil {
+		return burrito.WrapError(
+			err, "Unable to get the path to regolith cache folder.")
+	}
+	err = CreateDirectoryIfNotExists(dotRegolithPath)
+	if err != nil {
+		return burrito.WrapErrorf(err, osMkdirError, dotRegolithPath)
+	}
+	// Lock the session
+	unlockSession, sessionLockErr := aquireSessionLock(
+		dotRegolithPath, options.LockTimeout)
+	if sessionLockErr != nil {
+		return burrito.WrapError(sessionLockErr, aquireSessionLockError)
+	}
+	defer func() { sessionLockErr = unlockSession() }()
+	// Remove the files of the previous runs for a clean build
+	if options.Clean {
+		err = cleanBuildState(dotRegolithPath)
+		if err != nil {
+			return burrito.WrapError(
+				err, "Failed to remove the files of the previous runs.")
+		}
+	}
+	// Prepare the clean state for the watch session
+	if watch {
+		err = prepareWatchSession(projectRoot, options, dotRegolithPath)
+		if err != nil {
+			return burrito.WrapError(err, "Failed to prepare the watch session.")
+		}
+	}
+	// Check the filters of the profile
+	err = CheckProfileImpl(profile, profileName, *config, nil, dotRegolithPath)
+	if err != nil {
+		return err
+	}
+	context := RunContext{
+		AbsoluteLocation: projectDir,
+		Config:           config,
+		Parent:           nil,
+		Profile:          profileName,
+		DotRegolithPath:  dotRegolithPath,
+		Options:          options,
+	}
+	if options.ContinueOnError {
+		context.filterFailures = &filterFailures{}
+	}
+	context.filterWarnings = newFilterWarnings()
+	context.tmpDataLinks = &tmpDataLinks{}
+	if watch && !options.AlwaysExportAll {
+		context.exportedPacks = newExportedPacks()
+	}
+	context.maxMemory = maxMemory
+	// List the files changed since the git reference for the incremental
+	// run
+	if options.Since != "" {
+		changedFiles, err := listChangedFiles(
+			projectDir, options.Since, config)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to list the files changed since %q.",
+				options.Since)
+		}
+		if changedFilesEnv(changedFiles) == "" {
+			Logger.Warnf(
+				"Too many files changed since %q to pass their list to "+
+					"the filters. The filters will process all of the files.",
+				options.Since)
+		} else {
+			Logger.Infof(
+				"%d source files changed since %q.", len(changedFiles),
+				options.Since)
+			context.changedFiles = changedFiles
+		}
+	}
+	if options.Benchmark > 0 {
+		err = BenchmarkProfile(context)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to benchmark profile %q", profileName)
+		}
+		return sessionLockErr // Return the error from the defer function
+	}
+	if options.DryRun {
+		err = DryRunProfile(context)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to dry run profile %q", profileName)
+		}
+		return sessionLockErr // Return the error from the defer function
+	}
+	if watch { // Loop until program termination (CTRL+C)
+		context.StartWatchingSourceFiles()
+		awaitInitialRun(&context)
+		// The files held by other processes (antivirus, editors) can break
+		// the file operations of a build, the next attempt usually succeeds
+		context.retryFileLocks = true
+		for {
+			err = runProfileWithHooks(context)
+			if err != nil {
+				Logger.Errorf(
+					"Failed to run profile %q: %s",
+					profileName, burrito.PassError(err).Error())
+			} else {
+				Logger.Infof("Successfully ran the %q profile.", profileName)
+			}
+			Logger.Info("Press Ctrl+C to stop watching.")
+			context.AwaitInterruption()
+			Logger.Warn("Restarting...")
+		}
+		// return nil // Unreachable code
+	}
+	var report *profileReport
+	if options.ProfileReport != "" {
+		report = attachProfileReport(&context)
+	}
+	var summary *runSummary
+	if options.SummaryOnly {
+		summary = attachRunSummary(&context)
+	}
+	// The first Ctrl+C lets the current filter finish
+	context.cancellation = startRunCancellation()
+	err = runProfileWithHooks(context)
+	context.cancellation.stop()
+	if summary != nil {
+		summary.finish(err)
+	}
+	if report != nil {
+		// The report is written even if the profile failed
+		reportErr := report.write(options.ProfileReport)
+		if reportErr != nil {
+			reportErr = burrito.WrapError(
+				reportErr, "Failed to write the profile report.")
+			if err != nil {
+				return burrito.PassErrorHandlerError(
+					burrito.WrapErrorf(
+						err, "Failed to run profile %q", profileName),
+					reportErr, errorConnector)
+			}
+			return reportErr
+		}
+		Logger.Infof("Profile report saved to %q.", options.ProfileReport)
+	}
+	if err == ErrRunCancelled {
+		return ErrRunCancelled // Not wrapped, so the callers can detect it
+	} else if err != nil {
+		return burrito.WrapErrorf(err, "Failed to run profile %q", profileName)
+	}
+	Logger.Infof("Successfully ran the %q profile.", profileName)
+	return sessionLockErr // Return the error from the defer function
+}
+
+// cleanBuildState removes the files left in the dotRegolithPath by the
+// previous runs of the profiles: the tmp directory, the cached outputs of
+// the filters, the backups of the packs and the files hidden by the scopes
+// of the filters, the backup of the input of the retried filters and the
+// copy of the exported data left by an interrupted export. The installed
+// filters and their virtual environments are kept.
+func cleanBuildState(dotRegolithPath string) error {
+	tmpRoot := getTmpRoot(dotRegolithPath)
+	paths := []string{
+		getTmpPath(dotRegolithPath),
+		filepath.Join(dotRegolithPath, filterCacheDir),
+		filepath.Join(tmpRoot, filterRetryBackupName),
+		filepath.Join(dotRegolithPath, ".dataExportCopy"),
+	}
+	// The backups have random suffixes
+	for _, pattern := range []string{
+		".scopeBackup-*", ".packsBackup-*", ".inputScopeBackup-*",
+	} {
+		backups, _ := filepath.Glob(filepath.Join(tmpRoot, pattern))
+		paths = append(paths, backups...)
+	}
+	for _, path := range paths {
+		Logger.Infof("Cleaning %q...", path)
+		if err := os.RemoveAll(path); err != nil {
+			return burrito.WrapErrorf(err, osRemoveError, path)
+		}
+	}
+	return nil
+}
+
+// prepareWatchSession runs the actions that "regolith watch" performs only
+// once, before the first run of the profile. With the InitialClean option,
+// it removes the files left by the previous runs, the same as the "--clean"
+// flag of "regolith run" (see cleanBuildState). With the
+// InitialVerify option, it returns an error if the installed filters don't
+// match the config file.
+func prepareWatchSession(
+	projectRoot string, options RunOptions, dotRegolithPath string,
+) error {
+	if options.InitialClean {
+		Logger.Info("Cleaning the files of the previous runs before starting " +
+			"the watch session...")
+		if err := cleanBuildState(dotRegolithPath); err != nil {
+			return burrito.WrapError(
+				err, "Failed to remove the files of the previous runs.")
+		}
+	}
+	if options.InitialVerify {
+		Logger.Info("Verifying the filter cache...")
+		problems, err := findFilterCacheProblems(
+			projectRoot, true, options.ConfigPath)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+		if len(problems) != 0 {
+			for _, problem := range problems {
+				Logger.Warn(problem.String())
+			}
+			return burrito.WrappedErrorf(
+				"Found %d problems with the filter cache.\n"+
+					"You can fix the missing and outdated filters by "+
+					"running:\nregolith install-all", len(problems))
+		}
+	}
+	return nil
 }
 
-// runOrWatch handles both 'regolith run' and 'regolith watch' commands based
-// on the 'watch' parameter. It runs/watches the profile named after
-// 'profileName' parameter. The 'debug' argument determines if the debug
-// messages should be printed or not.
-func runOrWatch(profileName string, debug, watch bool) error {
+// awaitInitialRun blocks until the first change of the watched files if the
+// SkipInitialRun option ("--run-on-start=false") is set. Otherwise, it
+// returns immediately and the profile runs when the watch session starts.
+func awaitInitialRun(context *RunContext) {
+	if !context.Options.SkipInitialRun {
+		return
+	}
+	Logger.Info("Waiting for changes. Press Ctrl+C to stop watching.")
+	context.AwaitInterruption()
+	Logger.Warn("Restarting...")
+}
+
+// Run handles the "regolith run" command. It runs selected profile and exports
+// created resource pack and behvaiour pack to the target destination.
+func Run(profileName string, options RunOptions, debug bool) error {
+	return runOrWatch(".", profileName, options, debug, false)
+}
+
+// Watch handles the "regolith watch" command. It watches the project
+// directories and it runs selected profile and exports created resource pack
+// and behvaiour pack to the target destination when the project changes.
+func Watch(profileName string, options RunOptions, debug bool) error {
+	return runOrWatch(".", profileName, options, debug, true)
+}
+
+// Export handles the "regolith export" command. It exports the packs left in
+// the tmp directory by the last run of Regolith, without running the filters
+// again. The 'target' argument overrides the export target of the profile.
+// If it's empty, the export targets of the profile are used. The
+// 'configPath' argument is the path to the config file, the empty path means
+// "config.json".
+func Export(profileName, target, configPath string, debug bool) error {
+	return export(".", profileName, target, configPath, debug)
+}
+
+// export is the implementation of Export and ExportInProject. The paths of
+// the project are relative to the projectRoot.
+func export(
+	projectRoot, profileName, target, configPath string, debug bool,
+) error {
 	InitLogging(debug)
 	if profileName == "" {
 		profileName = "default"
 	}
 	// Load the Config and the profile
-	configJson, err := LoadConfigAsMap()
+	configJson, err := LoadConfigAsMap(
+		projectPath(projectRoot, resolveConfigPath(configPath)))
 	if err != nil {
 		return burrito.WrapError(err, "Could not load \"config.json\".")
 	}
@@ -190,85 +1389,79 @@ func runOrWatch(profileName string, debug, watch bool) error {
 	if err != nil {
 		return burrito.WrapError(err, "Could not load \"config.json\".")
 	}
+	config.setProjectRoot(projectRoot)
 	profile, ok := config.Profiles[profileName]
 	if !ok {
 		return burrito.WrappedErrorf(
-			"Profile %q does not exist in the configuration.", profileName)
+			"Profile %q does not exist in the configuration.\n"+
+				"Available profiles:\n%s", profileName, config.ListProfiles())
+	}
+	if target != "" {
+		profile.ExportTarget.Target = target
+		profile.ExportTargets = nil
 	}
+	// The data was already exported by the last run
+	profile.Filters = nil
 	// Get dotRegolithPath
-	dotRegolithPath, err := GetDotRegolith(false, ".")
+	dotRegolithPath, err := GetDotRegolith(false, projectRoot)
 	if err != nil {
 		return burrito.WrapError(
 			err, "Unable to get the path to regolith cache folder.")
 	}
-	err = CreateDirectoryIfNotExists(dotRegolithPath)
-	if err != nil {
-		return burrito.WrapErrorf(err, osMkdirError, dotRegolithPath)
-	}
 	// Lock the session
-	unlockSession, sessionLockErr := aquireSessionLock(dotRegolithPath)
+	unlockSession, sessionLockErr := aquireSessionLock(dotRegolithPath, 0)
 	if sessionLockErr != nil {
 		return burrito.WrapError(sessionLockErr, aquireSessionLockError)
 	}
 	defer func() { sessionLockErr = unlockSession() }()
-	// Check the filters of the profile
-	err = CheckProfileImpl(profile, profileName, *config, nil, dotRegolithPath)
-	if err != nil {
-		return err
-	}
-	path, _ := filepath.Abs(".")
-	context := RunContext{
-		AbsoluteLocation: path,
-		Config:           config,
-		Parent:           nil,
-		Profile:          profileName,
-		DotRegolithPath:  dotRegolithPath,
-	}
-	if watch { // Loop until program termination (CTRL+C)
-		context.StartWatchingSourceFiles()
-		for {
-			err = RunProfile(context)
-			if err != nil {
-				Logger.Errorf(
-					"Failed to run profile %q: %s",
-					profileName, burrito.PassError(err).Error())
-			} else {
-				Logger.Infof("Successfully ran the %q profile.", profileName)
-			}
-			Logger.Info("Press Ctrl+C to stop watching.")
-			context.AwaitInterruption()
-			Logger.Warn("Restarting...")
+	// Check if the last build is still in the tmp directory
+	for _, pack := range []string{"RP", "BP"} {
+		packPath := filepath.Join(getTmpPath(dotRegolithPath), pack)
+		isEmpty, err := IsDirEmpty(packPath)
+		if err != nil || isEmpty {
+			return burrito.WrappedErrorf(
+				"There is no build to export in the tmp directory.\n"+
+					"Path: %s\n"+
+					"Run the profile with \"regolith run --keep-tmp\" first. "+
+					"Without the \"--keep-tmp\" flag, the profiles with a "+
+					"single export target move the packs out of the tmp "+
+					"directory.", packPath)
 		}
-		// return nil // Unreachable code
 	}
-	err = RunProfile(context)
+	Logger.Infof("Exporting the last build of the %q profile.", profileName)
+	err = exportProject(
+		profile, profileName, config.Name, config.DataPath, dotRegolithPath,
+		projectRoot, true, false, nil)
 	if err != nil {
-		return burrito.WrapErrorf(err, "Failed to run profile %q", profileName)
+		return burrito.WrapError(err, exportProjectError)
 	}
-	Logger.Infof("Successfully ran the %q profile.", profileName)
+	Logger.Infof("Successfully exported the %q profile.", profileName)
 	return sessionLockErr // Return the error from the defer function
 }
 
-// Run handles the "regolith run" command. It runs selected profile and exports
-// created resource pack and behvaiour pack to the target destination.
-func Run(profileName string, debug bool) error {
-	return runOrWatch(profileName, debug, false)
-}
-
-// Watch handles the "regolith watch" command. It watches the project
-// directories and it runs selected profile and exports created resource pack
-// and behvaiour pack to the target destination when the project changes.
-func Watch(profileName string, debug bool) error {
-	return runOrWatch(profileName, debug, true)
-}
-
 // ApplyFilter handles the "regolith apply-filter" command.
 // ApplyFilter mode modifies RP and BP file in place (using source). The config and
 // properties of the filter are passed via commandline.
-func ApplyFilter(filterName string, filterArgs []string, debug bool) error {
+//
+// The "configPath" parameter is the path to the config file. The empty path
+// means "config.json".
+func ApplyFilter(
+	filterName string, filterArgs []string, configPath string, debug bool,
+) error {
+	return applyFilter(".", filterName, filterArgs, configPath, debug)
+}
+
+// applyFilter is the implementation of ApplyFilter and
+// ApplyFilterInProject. The paths of the project are relative to the
+// projectRoot.
+func applyFilter(
+	projectRoot, filterName string, filterArgs []string, configPath string,
+	debug bool,
+) error {
 	InitLogging(debug)
 	// Load the Config and the profile
-	configJson, err := LoadConfigAsMap()
+	configJson, err := LoadConfigAsMap(
+		projectPath(projectRoot, resolveConfigPath(configPath)))
 	if err != nil {
 		return burrito.WrapError(err, "Could not load \"config.json\".")
 	}
@@ -276,7 +1469,12 @@ func ApplyFilter(filterName string, filterArgs []string, debug bool) error {
 	if err != nil {
 		return burrito.WrapError(err, "Could not load \"config.json\".")
 	}
-	filterDefinition, ok := config.FilterDefinitions[filterName]
+	config.setProjectRoot(projectRoot)
+	projectDir, err := config.projectDir()
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	_, ok := config.FilterDefinitions[filterName]
 	if !ok {
 		return burrito.WrappedErrorf(
 			"Unable to find the filter on the \"filterDefinitions\" list "+
@@ -284,7 +1482,7 @@ func ApplyFilter(filterName string, filterArgs []string, debug bool) error {
 				"Filter name: %s", filterName)
 	}
 	// Get dotRegolithPath
-	dotRegolithPath, err := GetDotRegolith(false, ".")
+	dotRegolithPath, err := GetDotRegolith(false, projectRoot)
 	if err != nil {
 		return burrito.WrapError(
 			err, "Unable to get the path to regolith cache folder.")
@@ -294,7 +1492,7 @@ func ApplyFilter(filterName string, filterArgs []string, debug bool) error {
 		return burrito.WrapErrorf(err, osMkdirError, dotRegolithPath)
 	}
 	// Lock the session
-	unlockSession, sessionLockErr := aquireSessionLock(dotRegolithPath)
+	unlockSession, sessionLockErr := aquireSessionLock(dotRegolithPath, 0)
 	if sessionLockErr != nil {
 		return burrito.WrapError(sessionLockErr, aquireSessionLockError)
 	}
@@ -309,27 +1507,28 @@ func ApplyFilter(filterName string, filterArgs []string, debug bool) error {
 		"filter":    filterName,
 		"arguments": filterArgs,
 	}
-	filterRunner, err := filterDefinition.CreateFilterRunner(runConfiguration)
+	filterRunner, err := FilterRunnerFromObjectAndDefinitions(
+		runConfiguration, config.FilterDefinitions)
 	if err != nil {
-		return burrito.WrapErrorf(err, createFilterRunnerError, filterName)
+		return burrito.PassError(err)
 	}
 	// Create run context
-	path, _ := filepath.Abs(".")
 	runContext := RunContext{
 		Config:              config,
 		Parent:              nil,
 		Profile:             "[dynamic profile]",
 		DotRegolithPath:     dotRegolithPath,
 		interruptionChannel: nil,
-		AbsoluteLocation:    path,
+		AbsoluteLocation:    projectDir,
 	}
 	// Check the filter
 	err = filterRunner.Check(runContext)
 	if err != nil {
 		return burrito.WrapErrorf(err, filterRunnerCheckError, filterName)
 	}
-	// Setup tmp directory
-	err = SetupTmpFiles(*config, dotRegolithPath)
+	// Setup tmp directory. The filter runs outside of a profile, so the data
+	// files are always copied
+	err = SetupTmpFiles(*config, dotRegolithPath, false)
 	if err != nil {
 		return burrito.WrapErrorf(err, setupTmpFilesError, dotRegolithPath)
 	}
@@ -350,19 +1549,147 @@ func ApplyFilter(filterName string, filterArgs []string, debug bool) error {
 	return sessionLockErr
 }
 
+// RunFilterTests handles the "regolith test" command. It runs the filter on
+// the inputs of the test fixtures from the testsPath directory and compares
+// the results with the expected outputs of the fixtures.
+//
+// The "fixtures" parameter is the list of the names of the fixtures to run.
+// The empty list runs all of the fixtures.
+//
+// The "configPath" parameter is the path to the config file. The empty path
+// means "config.json".
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func RunFilterTests(
+	filterName string, fixtures []string, testsPath, configPath string,
+	debug bool,
+) error {
+	return runFilterTests(
+		".", filterName, fixtures, testsPath, configPath, debug)
+}
+
+// runFilterTests is the implementation of RunFilterTests and
+// RunFilterTestsInProject. The paths of the project and the testsPath are
+// relative to the projectRoot.
+func runFilterTests(
+	projectRoot, filterName string, fixtures []string,
+	testsPath, configPath string, debug bool,
+) error {
+	InitLogging(debug)
+	// Load the Config
+	configJson, err := LoadConfigAsMap(
+		projectPath(projectRoot, resolveConfigPath(configPath)))
+	if err != nil {
+		return burrito.WrapError(err, "Could not load \"config.json\".")
+	}
+	config, err := ConfigFromObject(configJson)
+	if err != nil {
+		return burrito.WrapError(err, "Could not load \"config.json\".")
+	}
+	config.setProjectRoot(projectRoot)
+	projectDir, err := config.projectDir()
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	testsPath = projectPath(projectRoot, testsPath)
+	_, ok := config.FilterDefinitions[filterName]
+	if !ok {
+		return burrito.WrappedErrorf(
+			"Unable to find the filter on the \"filterDefinitions\" list "+
+				"of the \"config.json\" file.\n"+
+				"Filter name: %s", filterName)
+	}
+	fixtures, err = listFilterTestFixtures(testsPath, fixtures)
+	if err != nil {
+		return burrito.WrapError(err, "Failed to list the test fixtures.")
+	}
+	if len(fixtures) == 0 {
+		return burrito.WrappedErrorf(
+			"No test fixtures found.\nTests path: %s", testsPath)
+	}
+	// Get dotRegolithPath
+	dotRegolithPath, err := GetDotRegolith(false, projectRoot)
+	if err != nil {
+		return burrito.WrapError(
+			err, "Unable to get the path to regolith cache folder.")
+	}
+	err = CreateDirectoryIfNotExists(dotRegolithPath)
+	if err != nil {
+		return burrito.WrapErrorf(err, osMkdirError, dotRegolithPath)
+	}
+	// Lock the session
+	unlockSession, sessionLockErr := aquireSessionLock(dotRegolithPath, 0)
+	if sessionLockErr != nil {
+		return burrito.WrapError(sessionLockErr, aquireSessionLockError)
+	}
+	defer func() { sessionLockErr = unlockSession() }()
+	// Create the filter
+	filterRunner, err := FilterRunnerFromObjectAndDefinitions(
+		map[string]interface{}{"filter": filterName}, config.FilterDefinitions)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	runContext := RunContext{
+		Config:              config,
+		Parent:              nil,
+		Profile:             "[dynamic profile]",
+		DotRegolithPath:     dotRegolithPath,
+		interruptionChannel: nil,
+		AbsoluteLocation:    projectDir,
+	}
+	err = filterRunner.Check(runContext)
+	if err != nil {
+		return burrito.WrapErrorf(err, filterRunnerCheckError, filterName)
+	}
+	// Run the tests
+	failed := 0
+	for _, fixture := range fixtures {
+		Logger.Infof("Running the %q test fixture.", fixture)
+		differences, err := runFilterTest(
+			filterRunner, *config, runContext,
+			filepath.Join(testsPath, fixture))
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to run the test fixture.\nFixture: %s", fixture)
+		}
+		if len(differences) == 0 {
+			Logger.Infof("PASS: %s", fixture)
+			continue
+		}
+		failed++
+		Logger.Errorf(
+			"FAIL: %s\n%s", fixture, strings.Join(differences, "\n"))
+	}
+	if failed > 0 {
+		return burrito.WrappedErrorf(
+			"%d of %d filter tests failed.", failed, len(fixtures))
+	}
+	Logger.Infof("All %d filter tests passed.", len(fixtures))
+	return sessionLockErr
+}
+
 // Init handles the "regolith init" command. It initializes a new Regolith
 // project in the current directory.
 //
 // The "debug" parameter is a boolean that determines if the debug messages
 // should be printed.
-func Init(debug bool) error {
+//
+// The "template" parameter is the URL of a git repository to copy the
+// project from. If it's empty, the default project is created.
+func Init(debug bool, template string) error {
+	return initProject(".", debug, template)
+}
+
+// initProject is the implementation of Init and InitInProject. It creates
+// the project in the projectRoot directory.
+func initProject(projectRoot string, debug bool, template string) error {
 	InitLogging(debug)
 	Logger.Info("Initializing Regolith project...")
 
-	wd, err := os.Getwd()
+	wd, err := filepath.Abs(projectRoot)
 	if err != nil {
-		return burrito.WrapError(
-			err, osGetwdError)
+		return burrito.WrapErrorf(err, filepathAbsError, projectRoot)
 	}
 	if isEmpty, err := IsDirEmpty(wd); err != nil {
 		return burrito.WrapErrorf(
@@ -373,7 +1700,17 @@ func Init(debug bool) error {
 				"directory.\n\"regolith init\" can be used only in empty "+
 				"directories.", wd)
 	}
-	ioutil.WriteFile(".gitignore", []byte(GitIgnore), 0644)
+	if template != "" {
+		err = initFromTemplate(template, wd)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to initialize the project from the template.\n"+
+					"Template: %s", template)
+		}
+		Logger.Info("Regolith project initialized.")
+		return nil
+	}
+	ioutil.WriteFile(filepath.Join(wd, ".gitignore"), []byte(GitIgnore), 0644)
 	// Create new default configuration
 	userConfig, err := getCombinedUserConfig()
 	if err != nil {
@@ -406,12 +1743,13 @@ func Init(debug bool) error {
 	// Add the schema property, this is a little hacky
 	rawJsonData := make(map[string]interface{}, 0)
 	json.Unmarshal(jsonBytes, &rawJsonData)
-	rawJsonData["$schema"] = "https://raw.githubusercontent.com/Bedrock-OSS/regolith-schemas/main/config/v1.1.json"
+	rawJsonData["$schema"] = ConfigSchemaUrl
 	jsonBytes, _ = json.MarshalIndent(rawJsonData, "", "\t")
 
-	err = ioutil.WriteFile(ConfigFilePath, jsonBytes, 0644)
+	configPath := filepath.Join(wd, ConfigFilePath)
+	err = ioutil.WriteFile(configPath, jsonBytes, 0644)
 	if err != nil {
-		return burrito.WrapErrorf(err, "Failed to write data to %q", ConfigFilePath)
+		return burrito.WrapErrorf(err, "Failed to write data to %q", configPath)
 	}
 	var ConfigurationFolders = []string{
 		"packs",
@@ -421,7 +1759,7 @@ func Init(debug bool) error {
 		filepath.Join(".regolith", "cache/venvs"),
 	}
 	for _, folder := range ConfigurationFolders {
-		err = os.MkdirAll(folder, 0755)
+		err = os.MkdirAll(filepath.Join(wd, folder), 0755)
 		if err != nil {
 			Logger.Error("Could not create folder: %s", folder, err)
 		}
@@ -431,6 +1769,47 @@ func Init(debug bool) error {
 	return nil
 }
 
+// initFromTemplate clones the git repository from the template URL into a
+// temporary directory, removes its ".git" folder and checks whether its
+// "config.json" file is valid. The files are moved to the empty project
+// directory only after the validation, so a failure leaves it empty.
+func initFromTemplate(template, projectDir string) error {
+	if !hasGit() {
+		return burrito.WrappedError(gitNotInstalledWarning)
+	}
+	cloneDir, err := os.MkdirTemp("", "regolith-template-")
+	if err != nil {
+		return burrito.WrapError(
+			err, "Failed to create a temporary directory for the template.")
+	}
+	defer os.RemoveAll(cloneDir)
+	Logger.Infof("Cloning the template from %s...", template)
+	err = RunSubProcess(
+		nil, "git", []string{"clone", "--depth", "1", template, "."},
+		cloneDir, cloneDir, "git")
+	if err != nil {
+		return burrito.WrapError(err, "Failed to clone the template repository.")
+	}
+	gitPath := filepath.Join(cloneDir, ".git")
+	err = os.RemoveAll(gitPath)
+	if err != nil {
+		return burrito.WrapErrorf(err, osRemoveError, gitPath)
+	}
+	configMap, err1 := LoadConfigAsMap(filepath.Join(cloneDir, ConfigFilePath))
+	_, err2 := ConfigFromObject(configMap)
+	if err := firstErr(err1, err2); err != nil {
+		return burrito.WrapError(
+			err, "The template doesn't contain a valid \"config.json\" file.")
+	}
+	err = MoveOrCopy(cloneDir, projectDir, false, false)
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, "Failed to move the template files to the project.\n"+
+				"Path: %s", projectDir)
+	}
+	return nil
+}
+
 // Cleans the cache folder of regolith (.regolith in normal mode or a path in
 // AppData). The path to clean is determined by the dotRegolithPath parameter.
 // leaveEmptyPath determines if regolith should leave an empty folder at
@@ -443,28 +1822,82 @@ func clean(dotRegolithPath string) error {
 	return nil
 }
 
+// currentProjectCachePaths returns the paths removed by
+// CleanCurrentProject - the ".regolith" directory of the project from the
+// projectRoot and the cache of the project in the application data folder.
+// If the tmp directory is moved with the "--tmp-dir" flag or the
+// REGOLITH_TMP environment variable, the directories of the project in the
+// selected location are added after them.
+func currentProjectCachePaths(projectRoot string) ([]string, error) {
+	dotRegolithPath, err := getAppDataDotRegolith(true, projectRoot)
+	if err != nil {
+		return nil, burrito.WrapError(
+			err, "Unable to get the path to regolith cache folder.")
+	}
+	paths := []string{projectPath(projectRoot, ".regolith"), dotRegolithPath}
+	if getTmpDirOverride() != "" {
+		paths = append(paths, getTmpRoot(paths[0]), getTmpRoot(paths[1]))
+	}
+	return paths, nil
+}
+
+// userCachePath returns the path removed by CleanUserCache - the directory
+// with all of the Regolith cache files in the user app data.
+func userCachePath() (string, error) {
+	userCache, err := os.UserCacheDir()
+	if err != nil {
+		return "", burrito.WrappedError(osUserCacheDirError)
+	}
+	return filepath.Join(userCache, appDataCachePath), nil
+}
+
+// filterCachePaths returns the paths removed by CleanFilterCache - the
+// filter cache directory of the project from the projectRoot in the cache
+// location selected by the user config.
+func filterCachePaths(projectRoot string) ([]string, error) {
+	dotRegolithPath, err := GetDotRegolith(true, projectRoot)
+	if err != nil {
+		return nil, burrito.WrapError(
+			err, "Unable to get the path to regolith cache folder.")
+	}
+	return []string{filepath.Join(dotRegolithPath, filterCacheDir)}, nil
+}
+
 func CleanCurrentProject() error {
-	Logger.Infof("Cleaning cache...")
+	return cleanCurrentProject(".")
+}
 
+// cleanCurrentProject is the implementation of CleanCurrentProject for the
+// project from the projectRoot.
+func cleanCurrentProject(projectRoot string) error {
+	Logger.Infof("Cleaning cache...")
+	paths, err := currentProjectCachePaths(projectRoot)
+	if err != nil {
+		return burrito.PassError(err)
+	}
 	// Clean .regolith
 	Logger.Infof("Cleaning \".regolith\"...")
-	err := clean(".regolith")
+	err = clean(paths[0])
 	if err != nil {
 		return burrito.WrapErrorf(
 			err, "Failed to clean the cache from \".regolith\".")
 	}
 	// Clean cache from AppData
 	Logger.Infof("Cleaning the cache in application data folder...")
-	dotRegolithPath, err := getAppDataDotRegolith(true, ".")
-	if err != nil {
-		return burrito.WrapError(
-			err, "Unable to get the path to regolith cache folder.")
-	}
-	Logger.Infof("Regolith cache folder is: %s", dotRegolithPath)
-	err = clean(dotRegolithPath)
+	Logger.Infof("Regolith cache folder is: %s", paths[1])
+	err = clean(paths[1])
 	if err != nil {
 		return burrito.WrapErrorf(
-			err, "Failed to clean the cache from %q.", dotRegolithPath)
+			err, "Failed to clean the cache from %q.", paths[1])
+	}
+	// Clean the tmp directories moved out of the cache
+	for _, path := range paths[2:] {
+		Logger.Infof("Cleaning the tmp directory of the project: %s", path)
+		err = clean(path)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to clean the tmp directory from %q.", path)
+		}
 	}
 	Logger.Infof("Cache cleaned.")
 	return nil
@@ -473,11 +1906,10 @@ func CleanCurrentProject() error {
 func CleanUserCache() error {
 	Logger.Infof("Cleaning all Regolith cache files from user app data...")
 	// App data enabled - use user cache dir
-	userCache, err := os.UserCacheDir()
+	regolithCacheFiles, err := userCachePath()
 	if err != nil {
-		return burrito.WrappedError(osUserCacheDirError)
+		return burrito.PassError(err)
 	}
-	regolithCacheFiles := filepath.Join(userCache, appDataCachePath)
 	Logger.Infof("Regolith cache files are located in: %s", regolithCacheFiles)
 	err = os.RemoveAll(regolithCacheFiles)
 	if err != nil {
@@ -488,17 +1920,115 @@ func CleanUserCache() error {
 	return nil
 }
 
+// CleanFilterCache removes the cached outputs of the cacheable filters of
+// the current project from the cache location selected by the user config.
+func CleanFilterCache() error {
+	return cleanFilterCache(".")
+}
+
+// cleanFilterCache is the implementation of CleanFilterCache for the project
+// from the projectRoot.
+func cleanFilterCache(projectRoot string) error {
+	Logger.Infof("Cleaning the filter cache...")
+	paths, err := filterCachePaths(projectRoot)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	for _, filterCachePath := range paths {
+		err = os.RemoveAll(filterCachePath)
+		if err != nil {
+			return burrito.WrapErrorf(err, osRemoveError, filterCachePath)
+		}
+	}
+	Logger.Infof("Filter cache cleaned.")
+	return nil
+}
+
+// Unlock handles the "regolith unlock" command. It removes the session lock
+// of the project left by a Regolith process that didn't exit properly.
+//
+// The "force" parameter makes the function remove the lock even if the
+// process that holds it is still running.
+//
+// The "configPath" parameter is the path to the config file. The empty path
+// means "config.json". All of the config files of the project share the
+// same session lock, so the path is only checked to exist. The config file
+// isn't loaded, so the lock can be removed even if the file is invalid.
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func Unlock(force bool, configPath string, debug bool) error {
+	return unlock(".", force, configPath, debug)
+}
+
+// unlock is the implementation of Unlock and UnlockInProject for the
+// project from the projectRoot.
+func unlock(projectRoot string, force bool, configPath string, debug bool) error {
+	InitLogging(debug)
+	if configPath != "" {
+		configPath = projectPath(projectRoot, configPath)
+		if _, err := os.Stat(configPath); err != nil {
+			return burrito.WrapErrorf(err, osStatErrorAny, configPath)
+		}
+	}
+	dotRegolithPath, err := GetDotRegolith(false, projectRoot)
+	if err != nil {
+		return burrito.WrapError(
+			err, "Unable to get the path to regolith cache folder.")
+	}
+	removed, err := removeSessionLock(dotRegolithPath, force)
+	if err != nil {
+		return burrito.WrapError(err, "Failed to unlock the session.")
+	}
+	if !removed {
+		Logger.Info("The session is not locked.")
+		return nil
+	}
+	Logger.Info("Session unlocked.")
+	return nil
+}
+
 // Clean handles the "regolith clean" command. It cleans the cache from the
 // dotRegolithPath directory.
 //
 // The "debug" parameter is a boolean that determines if the debug messages
-// should be printed.
-func Clean(debug, userCache bool) error {
+// should be printed. The "filterCache" parameter limits the cleaning to the
+// cached outputs of the cacheable filters. The "dryRun" parameter makes the
+// function print the paths that would be removed with their sizes, without
+// removing anything.
+func Clean(debug, userCache, filterCache, dryRun bool) error {
+	return cleanProject(".", debug, userCache, filterCache, dryRun)
+}
+
+// cleanProject is the implementation of Clean and CleanInProject for the
+// project from the projectRoot.
+func cleanProject(
+	projectRoot string, debug, userCache, filterCache, dryRun bool,
+) error {
 	InitLogging(debug)
+	if dryRun {
+		var paths []string
+		var err error
+		if userCache {
+			var path string
+			path, err = userCachePath()
+			paths = []string{path}
+		} else if filterCache {
+			paths, err = filterCachePaths(projectRoot)
+		} else {
+			paths, err = currentProjectCachePaths(projectRoot)
+		}
+		if err != nil {
+			return burrito.PassError(err)
+		}
+		return previewClean(paths)
+	}
 	if userCache {
 		return CleanUserCache()
+	} else if filterCache {
+		return cleanFilterCache(projectRoot)
 	} else {
-		return CleanCurrentProject()
+		return cleanCurrentProject(projectRoot)
 	}
 }
 
@@ -606,6 +2136,36 @@ func manageUserConfigEdit(debug bool, index int, key, value string) error {
 				resolversSet[resolver] = struct{}{}
 			}
 		}
+	case "allowed_filter_sources":
+		if index == -1 {
+			userConfig.AllowedFilterSources = append(
+				userConfig.AllowedFilterSources, value)
+		} else {
+			if len(userConfig.AllowedFilterSources) <= index {
+				return burrito.WrappedError("Index out of range.")
+			}
+			userConfig.AllowedFilterSources[index] = value
+		}
+	case "download_attempts":
+		if index != -1 {
+			return burrito.WrappedError("Cannot use --index with non-array property.")
+		}
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < 1 {
+			return burrito.WrappedErrorf("Invalid value for positive integer property.\n"+
+				"\tValue: %s", value)
+		}
+		userConfig.DownloadAttempts = &intValue
+	case "filter_cache_size":
+		if index != -1 {
+			return burrito.WrappedError("Cannot use --index with non-array property.")
+		}
+		intValue, err := strconv.Atoi(value)
+		if err != nil || intValue < 1 {
+			return burrito.WrappedErrorf("Invalid value for positive integer property.\n"+
+				"\tValue: %s", value)
+		}
+		userConfig.FilterCacheSize = &intValue
 	default:
 		return burrito.WrappedErrorf(invalidUserConfigPropertyError, key)
 	}
@@ -648,6 +2208,27 @@ func manageUserConfigDelete(debug bool, index int, key string) error {
 				userConfig.Resolvers[:index],
 				userConfig.Resolvers[index+1:]...)
 		}
+	case "allowed_filter_sources":
+		if index == -1 {
+			userConfig.AllowedFilterSources = nil
+		} else {
+			if len(userConfig.AllowedFilterSources) <= index {
+				return burrito.WrappedError("Index out of range.")
+			}
+			userConfig.AllowedFilterSources = append(
+				userConfig.AllowedFilterSources[:index],
+				userConfig.AllowedFilterSources[index+1:]...)
+		}
+	case "download_attempts":
+		if index != -1 {
+			return burrito.WrappedError("Cannot use --index with non-array property.")
+		}
+		userConfig.DownloadAttempts = nil
+	case "filter_cache_size":
+		if index != -1 {
+			return burrito.WrappedError("Cannot use --index with non-array property.")
+		}
+		userConfig.FilterCacheSize = nil
 	default:
 		return burrito.WrappedErrorf(invalidUserConfigPropertyError, key)
 	}
@@ -0,0 +1,64 @@
+// Functions used by the "regolith run --max-memory" flag, which limits the
+// memory available to the filters.
+package regolith
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// memorySizeUnits are the suffixes of the sizes accepted by the
+// "--max-memory" flag with their multipliers. The longer suffixes go first.
+var memorySizeUnits = []struct {
+	suffix     string
+	multiplier uint64
+}{
+	{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30},
+	{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30},
+	{"B", 1},
+}
+
+// parseMemorySize parses the size of the memory in bytes, with an optional
+// binary unit, for example "512M", "2GiB" or "1048576".
+func parseMemorySize(size string) (uint64, error) {
+	value := strings.ToUpper(strings.TrimSpace(size))
+	multiplier := uint64(1)
+	for _, unit := range memorySizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	number, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || number == 0 {
+		return 0, burrito.WrappedErrorf(
+			"Invalid memory size %q. The size must be a positive integer "+
+				"with an optional unit (K, M or G), for example \"512M\".",
+			size)
+	}
+	return number * multiplier, nil
+}
+
+// resolveMaxMemory parses the value of the "--max-memory" flag. It returns 0
+// (no limit) if the flag is not set or if the system doesn't support
+// limiting the memory of the filters.
+func resolveMaxMemory(maxMemory string) (uint64, error) {
+	if maxMemory == "" {
+		return 0, nil
+	}
+	limit, err := parseMemorySize(maxMemory)
+	if err != nil {
+		return 0, burrito.PassError(err)
+	}
+	if !memoryLimitSupported {
+		Logger.Warn(
+			"Limiting the memory of the filters is only supported on " +
+				"Linux. The \"--max-memory\" flag is ignored.")
+		return 0, nil
+	}
+	return limit, nil
+}
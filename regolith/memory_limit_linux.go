@@ -0,0 +1,19 @@
+//go:build linux
+// +build linux
+
+package regolith
+
+import "golang.org/x/sys/unix"
+
+// memoryLimitSupported is true on the systems that support limiting the
+// memory of the filters with the "--max-memory" flag.
+const memoryLimitSupported = true
+
+// limitProcessMemory limits the size of the data segment (the heap and the
+// other private memory) of the running process to the limit in bytes. The
+// processes started by the process inherit the limit. The allocations above
+// the limit fail, which usually stops the process.
+func limitProcessMemory(pid int, limit uint64) error {
+	return unix.Prlimit(
+		pid, unix.RLIMIT_DATA, &unix.Rlimit{Cur: limit, Max: limit}, nil)
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package regolith
+
+// memoryLimitSupported is true on the systems that support limiting the
+// memory of the filters with the "--max-memory" flag.
+const memoryLimitSupported = false
+
+// limitProcessMemory is a placeholder for a function which is supported only
+// on Linux. The "--max-memory" flag is ignored on other systems.
+func limitProcessMemory(pid int, limit uint64) error {
+	return nil
+}
@@ -0,0 +1,179 @@
+// Functions used for limiting the packs that a filter can see and modify with
+// the "packs" and "outputScope" properties.
+package regolith
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// outputScopePacks is a list of the names of the directories in the tmp
+// directory that can be used in the "outputScope" property of a filter.
+var outputScopePacks = []string{"RP", "BP", "data"}
+
+// isOutputScopePack returns true if the pack is a valid value of the
+// "outputScope" property.
+func isOutputScopePack(pack string) bool {
+	for _, p := range outputScopePacks {
+		if p == pack {
+			return true
+		}
+	}
+	return false
+}
+
+// limitOutputScope hides the packs that are not in the output scope of the
+// filter, by moving them from the tmp directory to a backup directory and
+// replacing them with empty directories. It returns a function that discards
+// the changes made to the empty directories and moves the hidden packs back.
+// If the scope is empty, nothing is hidden.
+func limitOutputScope(
+	scope []string, filterId string, dotRegolithPath string,
+) (func() error, error) {
+	hidden := []string{}
+	for _, pack := range outputScopePacks {
+		inScope := len(scope) == 0
+		for _, p := range scope {
+			if p == pack {
+				inScope = true
+				break
+			}
+		}
+		if !inScope {
+			hidden = append(hidden, pack)
+		}
+	}
+	return hideTmpPacks(
+		hidden, true, ".scopeBackup", "output scope", filterId,
+		dotRegolithPath)
+}
+
+// hideTmpPacks moves the packs from the tmp directory to a new backup
+// directory next to the tmp directory. The name of the backup directory
+// starts with the backupName and has a random suffix, so the filters of the
+// nested profiles, which run in the same tmp directory, never remove the
+// packs hidden by the filters that run them. If keepEmpty is true, the hidden
+// packs are replaced with empty directories. It returns a function that
+// discards the changes made by the filter to the hidden packs and moves them
+// back. The reason is the name of the property of the filter that hides the
+// packs, used in the warnings about the discarded changes.
+func hideTmpPacks(
+	hidden []string, keepEmpty bool, backupName, reason, filterId string,
+	dotRegolithPath string,
+) (func() error, error) {
+	if len(hidden) == 0 {
+		return func() error { return nil }, nil
+	}
+	tmpPath := getTmpPath(dotRegolithPath)
+	tmpRoot := getTmpRoot(dotRegolithPath)
+	backupPath, err := os.MkdirTemp(tmpRoot, backupName+"-*")
+	if err != nil {
+		return nil, burrito.WrapErrorf(err, osMkdirError, tmpRoot)
+	}
+	// Restores the packs that were moved before the failure
+	moved := []string{}
+	restore := func() error {
+		for _, pack := range moved {
+			packPath := filepath.Join(tmpPath, pack)
+			modified := false
+			if keepEmpty {
+				isEmpty, err := IsDirEmpty(packPath)
+				modified = err == nil && !isEmpty
+			} else {
+				_, err := os.Lstat(packPath)
+				modified = err == nil
+			}
+			if modified {
+				Logger.Warnf(
+					"Filter %q modified the %q directory which is outside of "+
+						"its %s. The changes were discarded.",
+					filterId, pack, reason)
+			}
+			if err := os.RemoveAll(packPath); err != nil {
+				return burrito.WrapErrorf(err, osRemoveError, packPath)
+			}
+			backupPackPath := filepath.Join(backupPath, pack)
+			if err := os.Rename(backupPackPath, packPath); err != nil {
+				return burrito.WrapErrorf(
+					err, osRenameError, backupPackPath, packPath)
+			}
+		}
+		if err := os.RemoveAll(backupPath); err != nil {
+			return burrito.WrapErrorf(err, osRemoveError, backupPath)
+		}
+		return nil
+	}
+	for _, pack := range hidden {
+		packPath := filepath.Join(tmpPath, pack)
+		backupPackPath := filepath.Join(backupPath, pack)
+		err := os.Rename(packPath, backupPackPath)
+		if err == nil {
+			moved = append(moved, pack)
+			if keepEmpty {
+				err = os.MkdirAll(packPath, 0755)
+			}
+		} else if os.IsNotExist(err) {
+			err = nil // Nothing to hide
+		}
+		if err != nil {
+			mainError := burrito.WrapErrorf(
+				err, "Failed to limit the %s of the filter.\n"+
+					"Filter: %s\nPack: %s", reason, filterId, pack)
+			if handlerError := restore(); handlerError != nil {
+				return nil, burrito.PassErrorHandlerError(
+					mainError, handlerError, errorConnector)
+			}
+			return nil, mainError
+		}
+	}
+	return restore, nil
+}
+
+// limitFilterPacks hides the packs that are not on the "packs" list of the
+// filter, by moving them from the tmp directory to a backup directory. Unlike
+// limitOutputScope, it doesn't leave empty directories in place of the
+// hidden packs, so the filter doesn't see them at all. It returns a function
+// that moves the hidden packs back. If the list is empty, nothing is hidden.
+func limitFilterPacks(
+	packs []string, filterId string, dotRegolithPath string,
+) (func() error, error) {
+	if len(packs) == 0 {
+		return func() error { return nil }, nil
+	}
+	hidden := []string{}
+	for _, pack := range outputScopePacks {
+		listed := false
+		for _, p := range packs {
+			listed = listed || p == pack
+		}
+		if !listed {
+			hidden = append(hidden, pack)
+		}
+	}
+	return hideTmpPacks(
+		hidden, false, ".packsBackup", "packs", filterId, dotRegolithPath)
+}
+
+// chainRestoreFunctions returns a function that runs the restore functions
+// of the limits of the filter in the given order. The later functions run
+// even if the earlier ones fail, and their errors are combined.
+func chainRestoreFunctions(restores ...func() error) func() error {
+	return func() error {
+		var result error
+		for _, restore := range restores {
+			err := restore()
+			if err == nil {
+				continue
+			}
+			if result == nil {
+				result = burrito.PassError(err)
+			} else {
+				result = burrito.PassErrorHandlerError(
+					result, err, errorConnector)
+			}
+		}
+		return result
+	}
+}
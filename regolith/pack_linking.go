@@ -0,0 +1,106 @@
+package regolith
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+	"muzzammil.xyz/jsonc"
+)
+
+// loadTmpManifest loads the manifest of the pack ("BP" or "RP") from the tmp
+// directory. It returns nil if the pack has no manifest.
+func loadTmpManifest(
+	dotRegolithPath, pack string,
+) (map[string]interface{}, error) {
+	path := filepath.Join(getTmpPath(dotRegolithPath), pack, "manifest.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, burrito.WrapErrorf(err, fileReadError, path)
+	}
+	var manifest map[string]interface{}
+	err = jsonc.Unmarshal(data, &manifest)
+	if err != nil {
+		return nil, burrito.WrapErrorf(err, jsonUnmarshalError, path)
+	}
+	return manifest, nil
+}
+
+// addManifestDependency adds the dependency on the pack with the header of
+// the other manifest to the manifest, unless it's already there. It returns
+// true if the manifest was modified.
+func addManifestDependency(
+	manifest map[string]interface{}, header map[string]interface{},
+) (bool, error) {
+	dependencies, ok := manifest["dependencies"].([]interface{})
+	if !ok && manifest["dependencies"] != nil {
+		return false, burrito.WrappedErrorf(
+			jsonPathTypeError, "dependencies", "array")
+	}
+	for _, dependency := range dependencies {
+		dependency, ok := dependency.(map[string]interface{})
+		if ok && dependency["uuid"] == header["uuid"] {
+			return false, nil
+		}
+	}
+	manifest["dependencies"] = append(dependencies, map[string]interface{}{
+		"uuid":    header["uuid"],
+		"version": header["version"],
+	})
+	return true, nil
+}
+
+// LinkPackManifests adds the UUID and the version of the header of the
+// resource pack to the dependencies of the behavior pack and vice versa.
+// It modifies the manifests in the tmp directory, the source files are
+// never modified. The dependencies that already exist are kept. If one of
+// the packs has no manifest, nothing is linked.
+func LinkPackManifests(dotRegolithPath string) error {
+	manifests := make(map[string]map[string]interface{}, 2)
+	headers := make(map[string]map[string]interface{}, 2)
+	for _, pack := range []string{"BP", "RP"} {
+		manifest, err := loadTmpManifest(dotRegolithPath, pack)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+		if manifest == nil {
+			Logger.Debugf(
+				"Skipping linking the packs, the %s has no manifest.", pack)
+			return nil
+		}
+		header, ok := manifest["header"].(map[string]interface{})
+		if !ok {
+			return burrito.WrappedErrorf(
+				jsonPathMissingError, pack+"/manifest.json->header")
+		}
+		if _, ok := header["uuid"].(string); !ok {
+			return burrito.WrappedErrorf(
+				jsonPathMissingError, pack+"/manifest.json->header->uuid")
+		}
+		manifests[pack] = manifest
+		headers[pack] = header
+	}
+	for pack, other := range map[string]string{"BP": "RP", "RP": "BP"} {
+		modified, err := addManifestDependency(manifests[pack], headers[other])
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to add the dependency to the manifest.\n"+
+					"Pack: %s", pack)
+		}
+		if !modified {
+			continue
+		}
+		path := filepath.Join(getTmpPath(dotRegolithPath), pack, "manifest.json")
+		data, _ := json.MarshalIndent(manifests[pack], "", "\t") // no error
+		err = os.WriteFile(path, data, 0644)
+		if err != nil {
+			return burrito.WrapErrorf(err, fileWriteError, path)
+		}
+		Logger.Debugf("Added the %s to the dependencies of the %s.", other, pack)
+	}
+	return nil
+}
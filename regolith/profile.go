@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/Bedrock-OSS/go-burrito/burrito"
@@ -14,27 +15,42 @@ import (
 )
 
 // SetupTmpFiles set up the workspace for the filters.
-func SetupTmpFiles(config Config, dotRegolithPath string) error {
+func SetupTmpFiles(config Config, dotRegolithPath string, linkData bool) error {
+	_, err := setupTmpFilesImpl(config, dotRegolithPath, linkData)
+	return err
+}
+
+// setupTmpFilesImpl is the implementation of SetupTmpFiles. It returns true
+// if any of the files of the data folder was hardlinked into the tmp
+// directory.
+func setupTmpFilesImpl(
+	config Config, dotRegolithPath string, linkData bool,
+) (bool, error) {
 	start := time.Now()
 	// Setup Directories
-	tmpPath := filepath.Join(dotRegolithPath, "tmp")
+	err := checkTmpRoot(dotRegolithPath)
+	if err != nil {
+		return false, burrito.WrapError(err, "Invalid location of the tmp directory.")
+	}
+	tmpPath := getTmpPath(dotRegolithPath)
 	Logger.Debugf("Cleaning \"%s\"", tmpPath)
-	err := os.RemoveAll(tmpPath)
+	err = os.RemoveAll(tmpPath)
 	if err != nil {
-		return burrito.WrapErrorf(err, osRemoveError, tmpPath)
+		return false, burrito.WrapErrorf(err, osRemoveError, tmpPath)
 	}
 
 	err = os.MkdirAll(tmpPath, 0755)
 	if err != nil {
-		return burrito.WrapErrorf(err, osMkdirError, tmpPath)
+		return false, burrito.WrapErrorf(err, osMkdirError, tmpPath)
 	}
 
 	// Copy the contents of the 'regolith' folder to '[dotRegolithPath]/tmp'
 	Logger.Debugf("Copying project files to \"%s\"", tmpPath)
 	// Avoid repetetive code of preparing ResourceFolder, BehaviorFolder
 	// and DataPath with a closure
+	dataLinked := false
 	setup_tmp_directory := func(
-		path, shortName, descriptiveName string,
+		path, shortName, descriptiveName string, link bool,
 	) error {
 		p := filepath.Join(tmpPath, shortName)
 		if path != "" {
@@ -49,10 +65,24 @@ func SetupTmpFiles(config Config, dotRegolithPath string) error {
 					}
 				}
 			} else if stats.IsDir() {
+				// The files from the .regolithignore file are not copied
+				ignore, err := loadRegolithIgnore(path)
+				if err != nil {
+					return burrito.PassError(err)
+				}
+				if link {
+					hardlinked, err := linkDir(path, p, ignore)
+					if err != nil {
+						return burrito.PassError(err)
+					}
+					dataLinked = hardlinked
+					return nil
+				}
 				err = copy.Copy(
 					path,
 					p,
-					copy.Options{PreserveTimes: false, Sync: false})
+					copy.Options{
+						PreserveTimes: false, Sync: false, Skip: ignore.skip})
 				if err != nil {
 					return burrito.WrapErrorf(err, osCopyError, path, p)
 				}
@@ -68,24 +98,28 @@ func SetupTmpFiles(config Config, dotRegolithPath string) error {
 		return nil
 	}
 
-	err = setup_tmp_directory(config.ResourceFolder, "RP", "resource folder")
+	err = setup_tmp_directory(
+		config.ResourceFolder, "RP", "resource folder", false)
 	if err != nil {
-		return burrito.WrapErrorf(
+		return false, burrito.WrapErrorf(
 			err, "Failed to setup RP folder in the temporary directory.")
 	}
-	err = setup_tmp_directory(config.BehaviorFolder, "BP", "behavior folder")
+	err = setup_tmp_directory(
+		config.BehaviorFolder, "BP", "behavior folder", false)
 	if err != nil {
-		return burrito.WrapErrorf(
+		return false, burrito.WrapErrorf(
 			err, "Failed to setup BP folder in the temporary directory.")
 	}
-	err = setup_tmp_directory(config.DataPath, "data", "data folder")
+	// The data folder is often large and rarely modified by the filters, so
+	// its files are linked instead of copied if possible
+	err = setup_tmp_directory(config.DataPath, "data", "data folder", linkData)
 	if err != nil {
-		return burrito.WrapErrorf(
+		return false, burrito.WrapErrorf(
 			err, "Failed to setup data folder in the temporary directory.")
 	}
 
 	Logger.Debug("Setup done in ", time.Since(start))
-	return nil
+	return dataLinked, nil
 }
 
 func CheckProfileImpl(
@@ -104,6 +138,30 @@ func CheckProfileImpl(
 			return burrito.WrapErrorf(err, filterRunnerCheckError, f.GetId())
 		}
 	}
+	// Validate the settings of the remote filters that declare a schema.
+	// All of the problems are reported at once.
+	problems := []string{}
+	for _, f := range profile.Filters {
+		remoteFilter, ok := f.(*RemoteFilter)
+		if !ok {
+			continue
+		}
+		violations, err := remoteFilter.validateSettings(dotRegolithPath)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to validate the settings of the filter.\n"+
+					"Filter: %s", f.GetId())
+		}
+		for _, violation := range violations {
+			problems = append(problems, fmt.Sprintf(
+				"- Filter: %s\n  %s", f.GetId(), violation.String()))
+		}
+	}
+	if len(problems) != 0 {
+		return burrito.WrappedErrorf(
+			"The settings of the filters don't match their schemas.\n%s",
+			strings.Join(problems, "\n"))
+	}
 	return nil
 }
 
@@ -111,32 +169,91 @@ func CheckProfileImpl(
 // context. If context is in the watch mode, it can repeat the process multiple
 // times in case of interruptions (changes in the source files).
 func RunProfile(context RunContext) error {
+	// The log files of the filters are recreated by every run
+	if context.Options.FilterLogs {
+		err := clearProfileLogs(context.DotRegolithPath, context.Profile)
+		if err != nil {
+			return burrito.WrapError(
+				err, "Failed to remove the log files of the previous run.")
+		}
+	}
 start:
 	// Prepare tmp files
 	profile, err := context.GetProfile()
 	if err != nil {
 		return burrito.WrapErrorf(err, runContextGetProfileError)
 	}
-	err = SetupTmpFiles(*context.Config, context.DotRegolithPath)
+	// Setting up the tmp files starts with removing the tmp directory, so
+	// it can be retried
+	var dataLinked bool
+	err = retryFileOperation(
+		context.retryFileLocks, "prepare the tmp directory", func() error {
+			var err error
+			dataLinked, err = setupTmpFilesImpl(
+				*context.Config, context.DotRegolithPath,
+				!context.Options.NoHardlink)
+			return err
+		})
 	if err != nil {
 		return burrito.WrapErrorf(err, setupTmpFilesError, context.DotRegolithPath)
 	}
+	context.tmpDataLinks.setLinked(dataLinked)
 	if context.IsInterrupted() {
 		goto start
 	}
 	// Run the profile
+	context.filterFailures.reset()
+	context.filterWarnings.reset()
 	interrupted, err := RunProfileImpl(context)
+	if context.cancellation.isCancelled() {
+		if err != nil && err != ErrRunCancelled {
+			Logger.Debugf("The cancelled run failed: %s", err.Error())
+		}
+		if err := cleanUpCancelledRun(context.DotRegolithPath); err != nil {
+			return burrito.PassErrorHandlerError(
+				ErrRunCancelled, err, errorConnector)
+		}
+		return ErrRunCancelled
+	}
+	if !interrupted {
+		context.filterWarnings.logSummary()
+	}
 	if err != nil {
 		return burrito.PassError(err)
 	}
 	if interrupted {
 		goto start
 	}
+	// With "--fail-fast=false" the project is exported only if some of the
+	// filters succeeded and exporting the partial results is allowed
+	failuresErr := context.filterFailures.err()
+	if failuresErr != nil {
+		if !context.Options.ExportOnError || context.filterFailures.succeeded == 0 {
+			return failuresErr
+		}
+		Logger.Warn("Some of the filters failed, exporting the results of the other filters.")
+	}
+	// Save the list of the exported files
+	if context.Options.ExportManifest != "" {
+		manifest, err := createExportManifest(context.DotRegolithPath)
+		if err != nil {
+			return burrito.WrapError(err, "Failed to create the export manifest.")
+		}
+		err = manifest.Dump(context.Options.ExportManifest)
+		if err != nil {
+			return burrito.WrapError(err, "Failed to save the export manifest.")
+		}
+	}
 	// Export files
 	Logger.Info("Moving files to target directory.")
 	start := time.Now()
-	err = ExportProject(
-		profile, context.Config.Name, context.Config.DataPath, context.DotRegolithPath)
+	err = exportProject(
+		profile, context.Profile, context.Config.Name, context.Config.DataPath,
+		context.DotRegolithPath, context.Config.projectRoot,
+		context.Options.KeepTmp, context.retryFileLocks, context.exportedPacks)
+	if context.exportListener != nil {
+		context.exportListener(time.Since(start), err)
+	}
 	if err != nil {
 		return burrito.WrapError(err, exportProjectError)
 	}
@@ -144,7 +261,12 @@ start:
 		goto start
 	}
 	Logger.Debug("Done in ", time.Since(start))
-	return nil
+	if context.Options.KeepTmp {
+		Logger.Infof(
+			"The files of the run were kept in the tmp directory.\nPath: %s",
+			getTmpPath(context.DotRegolithPath))
+	}
+	return failuresErr
 }
 
 // RunProfileImpl runs the profile from the given context and returns true
@@ -157,25 +279,230 @@ func RunProfileImpl(context RunContext) (bool, error) {
 	// Run the filters!
 	for filter := range profile.Filters {
 		filter := profile.Filters[filter]
+		// After Ctrl+C, the current filter finishes but the next one
+		// doesn't start
+		if context.cancellation.isCancelled() {
+			return false, ErrRunCancelled
+		}
 		// Disabled filters are skipped
 		disabled, err := filter.IsDisabled(context)
 		if err != nil {
 			return false, burrito.WrapErrorf(err, "Failed to check if filter is disabled")
 		}
 		if disabled {
-			Logger.Infof("Filter \"%s\" is disabled, skipping.", filter.GetId())
+			context.logInfof("Filter \"%s\" is disabled, skipping.", filter.GetId())
+			continue
+		}
+		// Filters with unmet "when" condition are skipped the same way
+		conditionMet, err := filter.IsConditionMet(context)
+		if err != nil {
+			return false, burrito.WrapErrorf(
+				err, "Failed to check the condition of the filter.\n"+
+					"Filter: %s", filter.GetId())
+		}
+		if !conditionMet {
+			context.logInfof(
+				"Filter \"%s\" skipped by condition, its \"when\" "+
+					"expression is false.", filter.GetId())
 			continue
 		}
 		// Skip printing if the filter ID is empty (most likely a nested profile)
 		if filter.GetId() != "" {
-			Logger.Infof("Running filter %s", filter.GetId())
+			context.logInfof("Running filter %s", filter.GetId())
+		}
+		// Reuse the output of the cacheable filters if the input didn't change
+		cacheHash := ""
+		definition, ok := context.Config.FilterDefinitions[filter.GetId()]
+		if ok && definition.IsCacheable() {
+			cacheHash, err = hashFilterInput(
+				filter, definition, context.DotRegolithPath)
+			if err != nil {
+				return false, burrito.WrapErrorf(
+					err, "Failed to hash the input of the filter.\nFilter: %s",
+					filter.GetId())
+			}
+			restored, err := restoreFilterCache(cacheHash, context.DotRegolithPath)
+			if err != nil {
+				return false, burrito.WrapErrorf(
+					err, "Failed to restore the cached output of the filter.\n"+
+						"Filter: %s", filter.GetId())
+			}
+			if restored {
+				context.logInfof(
+					"Filter %s restored from cache, skipping.", filter.GetId())
+				context.recordFilterResult(filter.GetId(), nil)
+				continue
+			}
+		}
+		// Hide the packs that are not on the "packs" list of the filter
+		restorePacks, err := limitFilterPacks(
+			filter.GetPacks(), filter.GetId(), context.DotRegolithPath)
+		if err != nil {
+			return false, burrito.PassError(err)
+		}
+		// Hide the packs that are outside of the output scope of the filter
+		restoreOutputScope, err := limitOutputScope(
+			filter.GetOutputScope(), filter.GetId(), context.DotRegolithPath)
+		if err != nil {
+			if handlerError := restorePacks(); handlerError != nil {
+				return false, burrito.PassErrorHandlerError(
+					burrito.PassError(err), handlerError, errorConnector)
+			}
+			return false, burrito.PassError(err)
+		}
+		// Hide the files that don't match the scope of the filter
+		restoreInputScope, err := limitInputScope(
+			filter.GetScope(), filter.GetId(), context.DotRegolithPath)
+		if err != nil {
+			handlerError := chainRestoreFunctions(
+				restoreOutputScope, restorePacks)()
+			if handlerError != nil {
+				return false, burrito.PassErrorHandlerError(
+					burrito.PassError(err), handlerError, errorConnector)
+			}
+			return false, burrito.PassError(err)
+		}
+		restoreScope := chainRestoreFunctions(
+			restoreInputScope, restoreOutputScope, restorePacks)
+		// Modifying the hardlinked data files would modify the project
+		if canWriteData(filter) {
+			err = breakDataLinks(
+				context.Config.DataPath, context.DotRegolithPath,
+				context.tmpDataLinks)
+			if err != nil {
+				mainError := burrito.WrapErrorf(
+					err, "Failed to copy the hardlinked files of the data "+
+						"folder.\nFilter: %s", filter.GetId())
+				if handlerError := restoreScope(); handlerError != nil {
+					return false, burrito.PassErrorHandlerError(
+						mainError, handlerError, errorConnector)
+				}
+				return false, mainError
+			}
+		}
+		// Nested profiles don't have IDs, their filters report their
+		// changes separately
+		var snapshot tmpSnapshot
+		if context.Options.ReportChanges && filter.GetId() != "" {
+			snapshot, err = snapshotTmpFiles(context.DotRegolithPath)
+			if err != nil {
+				mainError := burrito.WrapErrorf(
+					err, "Failed to list the files before running the "+
+						"filter.\nFilter: %s", filter.GetId())
+				if handlerError := restoreScope(); handlerError != nil {
+					return false, burrito.PassErrorHandlerError(
+						mainError, handlerError, errorConnector)
+				}
+				return false, mainError
+			}
+		}
+		// Save the output of the filter to its log file. Nested profiles
+		// don't have IDs, their filters have separate log files.
+		filterContext := context
+		if context.Options.FilterLogs && filter.GetId() != "" {
+			filterContext.filterLog, err = openFilterLog(
+				context.DotRegolithPath, context.Profile, filter.GetId())
+			if err != nil {
+				mainError := burrito.WrapErrorf(
+					err, "Failed to create the log file of the filter.\n"+
+						"Filter: %s", filter.GetId())
+				if handlerError := restoreScope(); handlerError != nil {
+					return false, burrito.PassErrorHandlerError(
+						mainError, handlerError, errorConnector)
+				}
+				return false, mainError
+			}
+		}
+		// The filters with the "retries" property run again after failures
+		retries, retryDelay := 0, time.Duration(0)
+		if ok {
+			retries, retryDelay = definition.RetryPolicy()
 		}
 		// Run the filter in watch mode
 		start := time.Now()
-		interrupted, err := filter.Run(context)
-		Logger.Debugf("Executed in %s", time.Since(start))
+		interrupted, err := runFilterWithRetries(
+			filter, filterContext, retries, retryDelay)
+		duration := time.Since(start)
+		context.logDebugf("Executed in %s", duration)
+		if filterContext.filterLog != nil {
+			if err := filterContext.filterLog.Close(); err != nil {
+				context.logWarnf(
+					"Failed to save the log file of the filter %q:\n%s",
+					filter.GetId(), err.Error())
+			}
+		}
+		// Nested profiles don't have IDs, their filters report their
+		// warnings separately
+		if filter.GetId() != "" {
+			context.collectFilterWarnings(filter.GetId())
+		}
+		if snapshot != nil && err == nil {
+			after, err := snapshotTmpFiles(context.DotRegolithPath)
+			if err != nil {
+				mainError := burrito.WrapErrorf(
+					err, "Failed to list the files after running the "+
+						"filter.\nFilter: %s", filter.GetId())
+				if handlerError := restoreScope(); handlerError != nil {
+					return false, burrito.PassErrorHandlerError(
+						mainError, handlerError, errorConnector)
+				}
+				return false, mainError
+			}
+			context.logInfof(
+				"Filter %s changes: %s", filter.GetId(),
+				diffTmpSnapshots(snapshot, after))
+		}
+		// Nested profiles don't have IDs, their filters are reported
+		// separately
+		if context.filterRunListener != nil && filter.GetId() != "" {
+			context.filterRunListener(filter.GetId(), duration, err)
+		}
 		if err != nil {
-			return false, burrito.WrapErrorf(err, filterRunnerRunError, filter.GetId())
+			mainError := burrito.WrapErrorf(err, filterRunnerRunError, filter.GetId())
+			if filterContext.filterLog != nil {
+				mainError = burrito.WrapErrorf(
+					err, filterRunnerRunError+"\nLog file: %s",
+					filter.GetId(), filterContext.filterLog.path)
+			}
+			if handlerError := restoreScope(); handlerError != nil {
+				return false, burrito.PassErrorHandlerError(
+					mainError, handlerError, errorConnector)
+			}
+			// Nested profiles don't have IDs, their filters record their
+			// failures separately
+			if filter.GetId() != "" && context.recordFilterResult(filter.GetId(), mainError) {
+				continue
+			}
+			return false, mainError
+		}
+		if err := restoreScope(); err != nil {
+			return false, burrito.WrapErrorf(
+				err, "Failed to restore the files outside of the scope of "+
+					"the filter.\nFilter: %s", filter.GetId())
+		}
+		// Check the output of the filter before the next filter uses it
+		if validation := filter.GetValidation(); validation != nil && !interrupted {
+			err = validation.Validate(&context, context.DotRegolithPath)
+			if err != nil {
+				mainError := burrito.WrapErrorf(
+					err, "The output of the filter is invalid.\nFilter: %s",
+					filter.GetId())
+				if context.recordFilterResult(filter.GetId(), mainError) {
+					continue
+				}
+				return false, mainError
+			}
+		}
+		if filter.GetId() != "" && !interrupted {
+			context.recordFilterResult(filter.GetId(), nil)
+		}
+		if cacheHash != "" && !interrupted {
+			err = storeFilterCache(cacheHash, context.DotRegolithPath)
+			if err != nil {
+				return false, burrito.WrapErrorf(
+					err, "Failed to cache the output of the filter.\n"+
+						"Filter: %s", filter.GetId())
+			}
 		}
 		if interrupted {
 			return true, nil
@@ -263,46 +590,191 @@ type FilterCollection struct {
 type Profile struct {
 	FilterCollection
 	ExportTarget ExportTarget `json:"export,omitempty"`
+	Description  string       `json:"description,omitempty"`
+	Extends      string       `json:"extends,omitempty"`
+
+	// ExportTargets is a list of the additional export targets of the
+	// profile. The packs are exported to all of them in parallel.
+	ExportTargets []ExportTarget `json:"exports,omitempty"`
+
+	// PreRun and PostRun are the shell commands that run in the root of the
+	// project before and after building the profile. The PostRun commands
+	// run even if the build fails.
+	PreRun  []string `json:"preRun,omitempty"`
+	PostRun []string `json:"postRun,omitempty"`
+}
+
+// allExportTargets returns the main export target of the profile followed by
+// its additional export targets.
+func (p *Profile) allExportTargets() []ExportTarget {
+	result := make([]ExportTarget, 0, len(p.ExportTargets)+1)
+	result = append(result, p.ExportTarget)
+	return append(result, p.ExportTargets...)
 }
 
 func ProfileFromObject(
 	obj map[string]interface{}, filterDefinitions map[string]FilterInstaller,
 ) (Profile, error) {
 	result := Profile{}
-	// Filters
+	// Extends (optional)
+	if extends, ok := obj["extends"]; ok {
+		extends, ok := extends.(string)
+		if !ok {
+			return result, burrito.WrappedErrorf(
+				jsonPathTypeError, "extends", "string")
+		}
+		result.Extends = extends
+	}
+	// Filters (optional if the profile extends another profile)
 	if _, ok := obj["filters"]; !ok {
-		return result, burrito.WrappedErrorf(jsonPathMissingError, "filters")
+		if result.Extends == "" {
+			return result, burrito.WrappedErrorf(jsonPathMissingError, "filters")
+		}
+	} else {
+		filters, ok := obj["filters"].([]interface{})
+		if !ok {
+			return result, burrito.WrappedErrorf(jsonPathTypeError, "filters", "array")
+		}
+		for i, filter := range filters {
+			filter, ok := filter.(map[string]interface{})
+			if !ok {
+				return result, burrito.WrappedErrorf(
+					jsonPathTypeError, fmt.Sprintf("filters->%d", i), "object")
+			}
+			filterRunner, err := FilterRunnerFromObjectAndDefinitions(
+				filter, filterDefinitions)
+			if err != nil {
+				return result, burrito.WrapErrorf(
+					err, jsonPathParseError, fmt.Sprintf("filters->%d", i))
+			}
+			result.Filters = append(result.Filters, filterRunner)
+		}
 	}
-	filters, ok := obj["filters"].([]interface{})
-	if !ok {
-		return result, burrito.WrappedErrorf(jsonPathTypeError, "filters", "array")
+	// ExportTarget (optional if the profile extends another profile)
+	if _, ok := obj["export"]; !ok {
+		if result.Extends == "" {
+			return result, burrito.WrappedErrorf(jsonPathMissingError, "export")
+		}
+	} else {
+		export, ok := obj["export"].(map[string]interface{})
+		if !ok {
+			return result, burrito.WrappedErrorf(jsonPathTypeError, "export", "object")
+		}
+		exportTarget, err := ExportTargetFromObject(export)
+		if err != nil {
+			return result, burrito.WrapErrorf(err, jsonPathParseError, "export")
+		}
+		result.ExportTarget = exportTarget
 	}
-	for i, filter := range filters {
-		filter, ok := filter.(map[string]interface{})
+	// ExportTargets (optional)
+	if exports, ok := obj["exports"]; ok {
+		exports, ok := exports.([]interface{})
 		if !ok {
 			return result, burrito.WrappedErrorf(
-				jsonPathTypeError, fmt.Sprintf("filters->%d", i), "object")
+				jsonPathTypeError, "exports", "array")
 		}
-		filterRunner, err := FilterRunnerFromObjectAndDefinitions(
-			filter, filterDefinitions)
-		if err != nil {
-			return result, burrito.WrapErrorf(
-				err, jsonPathParseError, fmt.Sprintf("filters->%d", i))
+		for i, export := range exports {
+			export, ok := export.(map[string]interface{})
+			if !ok {
+				return result, burrito.WrappedErrorf(
+					jsonPathTypeError, fmt.Sprintf("exports->%d", i), "object")
+			}
+			exportTarget, err := ExportTargetFromObject(export)
+			if err != nil {
+				return result, burrito.WrapErrorf(
+					err, jsonPathParseError, fmt.Sprintf("exports->%d", i))
+			}
+			result.ExportTargets = append(result.ExportTargets, exportTarget)
 		}
-		result.Filters = append(result.Filters, filterRunner)
 	}
-	// ExportTarget
-	if _, ok := obj["export"]; !ok {
-		return result, burrito.WrappedErrorf(jsonPathMissingError, "export")
+	// Description (optional)
+	if description, ok := obj["description"]; ok {
+		description, ok := description.(string)
+		if !ok {
+			return result, burrito.WrappedErrorf(
+				jsonPathTypeError, "description", "string")
+		}
+		result.Description = description
 	}
-	export, ok := obj["export"].(map[string]interface{})
-	if !ok {
-		return result, burrito.WrappedErrorf(jsonPathTypeError, "export", "object")
+	// PreRun (optional)
+	preRun, err := hooksFromObject(obj, "preRun")
+	if err != nil {
+		return result, burrito.PassError(err)
 	}
-	exportTarget, err := ExportTargetFromObject(export)
+	result.PreRun = preRun
+	// PostRun (optional)
+	postRun, err := hooksFromObject(obj, "postRun")
 	if err != nil {
-		return result, burrito.WrapErrorf(err, jsonPathParseError, "export")
+		return result, burrito.PassError(err)
 	}
-	result.ExportTarget = exportTarget
+	result.PostRun = postRun
 	return result, nil
 }
+
+// resolveProfileInheritance merges the profiles that use the "extends"
+// property with the profiles they extend. The filters of the merged profile
+// are the filters of the parent followed by the filters of the child. The
+// export targets of the child are used if the child specifies its main export
+// target, otherwise the export targets of the parent are used. The "preRun"
+// and "postRun" commands of the parent are used if the child doesn't specify
+// them. Returns an error if a profile extends a
+// profile that doesn't exist or if the inheritance is circular.
+func resolveProfileInheritance(profiles map[string]Profile) error {
+	resolved := make(map[string]bool, len(profiles))
+	var resolve func(name string, chain []string) error
+	resolve = func(name string, chain []string) error {
+		if resolved[name] {
+			return nil
+		}
+		for _, visited := range chain {
+			if visited == name {
+				return burrito.WrappedErrorf(
+					"Found circular inheritance in the profiles.\n"+
+						"Inheritance chain: %s",
+					strings.Join(append(chain, name), " -> "))
+			}
+		}
+		profile := profiles[name]
+		if profile.Extends == "" {
+			resolved[name] = true
+			return nil
+		}
+		if _, ok := profiles[profile.Extends]; !ok {
+			return burrito.WrappedErrorf(
+				"The profile extends a profile that doesn't exist.\n"+
+					"Profile: %s\nExtended profile: %s",
+				name, profile.Extends)
+		}
+		err := resolve(profile.Extends, append(chain, name))
+		if err != nil {
+			return burrito.PassError(err)
+		}
+		parent := profiles[profile.Extends]
+		filters := make(
+			[]FilterRunner, 0, len(parent.Filters)+len(profile.Filters))
+		filters = append(filters, parent.Filters...)
+		profile.Filters = append(filters, profile.Filters...)
+		if profile.ExportTarget.Target == "" {
+			profile.ExportTarget = parent.ExportTarget
+			profile.ExportTargets = parent.ExportTargets
+		}
+		// The hooks are inherited unless the child specifies its own
+		// (possibly empty) list
+		if profile.PreRun == nil {
+			profile.PreRun = parent.PreRun
+		}
+		if profile.PostRun == nil {
+			profile.PostRun = parent.PostRun
+		}
+		profiles[name] = profile
+		resolved[name] = true
+		return nil
+	}
+	for name := range profiles {
+		err := resolve(name, nil)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+	}
+	return nil
+}
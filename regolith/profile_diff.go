@@ -0,0 +1,348 @@
+// Functions used for comparing two profiles with the "regolith profile-diff"
+// command.
+package regolith
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// canonicalFilter is a filter of a profile normalized for comparing it with
+// the filters of another profile.
+type canonicalFilter struct {
+	// Name identifies the filter in the profile. It's the id of the filter
+	// or "profile:<name>" for the nested profiles. If the profile runs the
+	// same filter multiple times, the next occurrences get the "#2", "#3"...
+	// suffixes.
+	Name string
+
+	// Properties are the properties of the filter, like "settings" or
+	// "arguments", normalized with a JSON round trip.
+	Properties map[string]interface{}
+}
+
+// canonicalProfile is a profile normalized for comparing it with another
+// profile.
+type canonicalProfile struct {
+	Filters []canonicalFilter
+
+	// ExportTargets maps the JSON paths of the export targets ("export",
+	// "exports->0", ...) to their normalized properties.
+	ExportTargets map[string]map[string]interface{}
+}
+
+// propertyChange is a property that has different values in the compared
+// profiles. The value is nil if the property is missing.
+type propertyChange struct {
+	Property string      `json:"property"`
+	A        interface{} `json:"a"`
+	B        interface{} `json:"b"`
+}
+
+// filterChange lists the changed properties of a filter used by both of the
+// compared profiles.
+type filterChange struct {
+	Filter  string           `json:"filter"`
+	Changes []propertyChange `json:"changes"`
+}
+
+// exportTargetChange lists the changed properties of an export target.
+type exportTargetChange struct {
+	Target  string           `json:"target"`
+	Changes []propertyChange `json:"changes"`
+}
+
+// profileDiff lists the differences between two profiles. The added filters
+// exist only in the second profile and the removed filters only in the
+// first one. The moved filters exist in both of the profiles, but in a
+// different order.
+type profileDiff struct {
+	Profiles      [2]string            `json:"profiles"`
+	Added         []string             `json:"addedFilters"`
+	Removed       []string             `json:"removedFilters"`
+	Moved         []string             `json:"movedFilters"`
+	Filters       []filterChange       `json:"changedFilters"`
+	ExportTargets []exportTargetChange `json:"changedExportTargets"`
+}
+
+// jsonRoundTrip converts the value to the types used by the JSON decoder
+// (maps, slices, float64...), so values created in different ways can be
+// compared with reflect.DeepEqual.
+func jsonRoundTrip(value interface{}) map[string]interface{} {
+	data, _ := json.Marshal(value) // no error
+	result := make(map[string]interface{})
+	json.Unmarshal(data, &result)
+	return result
+}
+
+// canonicalizeProfile normalizes the profile for comparing it with another
+// profile.
+func canonicalizeProfile(profile Profile) canonicalProfile {
+	result := canonicalProfile{
+		ExportTargets: make(map[string]map[string]interface{})}
+	occurrences := make(map[string]int)
+	for _, filter := range profile.Filters {
+		_, basicFilter := describeFilterRunner(filter)
+		name := filter.GetId()
+		if profileFilter, ok := filter.(*ProfileFilter); ok {
+			name = "profile:" + profileFilter.Profile
+		}
+		occurrences[name]++
+		if occurrences[name] > 1 {
+			name = fmt.Sprintf("%s#%d", name, occurrences[name])
+		}
+		var properties map[string]interface{}
+		if basicFilter != nil {
+			properties = jsonRoundTrip(basicFilter)
+		} else {
+			properties = make(map[string]interface{})
+		}
+		delete(properties, "filter")
+		result.Filters = append(result.Filters, canonicalFilter{
+			Name: name, Properties: properties})
+	}
+	result.ExportTargets["export"] = jsonRoundTrip(profile.ExportTarget)
+	for i, target := range profile.ExportTargets {
+		result.ExportTargets[fmt.Sprintf("exports->%d", i)] =
+			jsonRoundTrip(target)
+	}
+	return result
+}
+
+// diffProperties compares the properties of two objects. The properties are
+// sorted by name.
+func diffProperties(a, b map[string]interface{}) []propertyChange {
+	names := make(map[string]struct{}, len(a)+len(b))
+	for name := range a {
+		names[name] = struct{}{}
+	}
+	for name := range b {
+		names[name] = struct{}{}
+	}
+	result := []propertyChange{}
+	for name := range names {
+		if !reflect.DeepEqual(a[name], b[name]) {
+			result = append(
+				result, propertyChange{Property: name, A: a[name], B: b[name]})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Property < result[j].Property
+	})
+	return result
+}
+
+// longestCommonSubsequence returns the names from the longest common
+// subsequence of the two lists of names.
+func longestCommonSubsequence(a, b []string) map[string]struct{} {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	result := make(map[string]struct{})
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		if a[i] == b[j] {
+			result[a[i]] = struct{}{}
+			i++
+			j++
+		} else if lengths[i+1][j] >= lengths[i][j+1] {
+			i++
+		} else {
+			j++
+		}
+	}
+	return result
+}
+
+// diffProfiles compares two profiles. The names are used only for the
+// output.
+func diffProfiles(nameA string, a Profile, nameB string, b Profile) profileDiff {
+	result := profileDiff{
+		Profiles: [2]string{nameA, nameB},
+		Added:    []string{}, Removed: []string{}, Moved: []string{},
+		Filters: []filterChange{}, ExportTargets: []exportTargetChange{},
+	}
+	canonicalA, canonicalB := canonicalizeProfile(a), canonicalizeProfile(b)
+	// Filters
+	filtersA := make(map[string]canonicalFilter, len(canonicalA.Filters))
+	for _, filter := range canonicalA.Filters {
+		filtersA[filter.Name] = filter
+	}
+	filtersB := make(map[string]canonicalFilter, len(canonicalB.Filters))
+	for _, filter := range canonicalB.Filters {
+		filtersB[filter.Name] = filter
+	}
+	// The filters that are not a part of the longest common subsequence of
+	// the shared filters changed their positions
+	var commonA, commonB []string
+	for _, filter := range canonicalA.Filters {
+		if _, ok := filtersB[filter.Name]; ok {
+			commonA = append(commonA, filter.Name)
+		} else {
+			result.Removed = append(result.Removed, filter.Name)
+		}
+	}
+	for _, filter := range canonicalB.Filters {
+		if _, ok := filtersA[filter.Name]; ok {
+			commonB = append(commonB, filter.Name)
+		} else {
+			result.Added = append(result.Added, filter.Name)
+		}
+	}
+	unmoved := longestCommonSubsequence(commonA, commonB)
+	for _, name := range commonB {
+		if _, ok := unmoved[name]; !ok {
+			result.Moved = append(result.Moved, name)
+		}
+		changes := diffProperties(
+			filtersA[name].Properties, filtersB[name].Properties)
+		if len(changes) != 0 {
+			result.Filters = append(
+				result.Filters, filterChange{Filter: name, Changes: changes})
+		}
+	}
+	// Export targets
+	targets := make([]string, 0, len(canonicalA.ExportTargets))
+	for target := range canonicalA.ExportTargets {
+		targets = append(targets, target)
+	}
+	for target := range canonicalB.ExportTargets {
+		if _, ok := canonicalA.ExportTargets[target]; !ok {
+			targets = append(targets, target)
+		}
+	}
+	sort.Strings(targets)
+	for _, target := range targets {
+		changes := diffProperties(
+			canonicalA.ExportTargets[target], canonicalB.ExportTargets[target])
+		if len(changes) != 0 {
+			result.ExportTargets = append(
+				result.ExportTargets,
+				exportTargetChange{Target: target, Changes: changes})
+		}
+	}
+	return result
+}
+
+// isEmpty returns true if the profiles have no differences.
+func (d profileDiff) isEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Moved) == 0 &&
+		len(d.Filters) == 0 && len(d.ExportTargets) == 0
+}
+
+// formatDiffValue returns the value of the changed property as JSON, or
+// "(none)" if the property is missing.
+func formatDiffValue(value interface{}) string {
+	if value == nil {
+		return "(none)"
+	}
+	data, _ := json.Marshal(value) // no error
+	return string(data)
+}
+
+// String returns a human-readable summary of the differences. The added
+// filters are marked with "+", the removed filters with "-" and the moved
+// and changed filters with "~".
+func (d profileDiff) String() string {
+	if d.isEmpty() {
+		return fmt.Sprintf(
+			"The %q and %q profiles don't have any differences.",
+			d.Profiles[0], d.Profiles[1])
+	}
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf(
+		"Differences between the %q and %q profiles:\n",
+		d.Profiles[0], d.Profiles[1]))
+	writeChanges := func(changes []propertyChange) {
+		for _, change := range changes {
+			builder.WriteString(fmt.Sprintf(
+				"      %s: %s -> %s\n", change.Property,
+				formatDiffValue(change.A), formatDiffValue(change.B)))
+		}
+	}
+	if len(d.Added)+len(d.Removed)+len(d.Moved)+len(d.Filters) != 0 {
+		builder.WriteString("  Filters:\n")
+		for _, name := range d.Added {
+			builder.WriteString(fmt.Sprintf("    + %s\n", name))
+		}
+		for _, name := range d.Removed {
+			builder.WriteString(fmt.Sprintf("    - %s\n", name))
+		}
+		for _, name := range d.Moved {
+			builder.WriteString(fmt.Sprintf("    ~ %s (moved)\n", name))
+		}
+		for _, filter := range d.Filters {
+			builder.WriteString(fmt.Sprintf("    ~ %s\n", filter.Filter))
+			writeChanges(filter.Changes)
+		}
+	}
+	if len(d.ExportTargets) != 0 {
+		builder.WriteString("  Export targets:\n")
+		for _, target := range d.ExportTargets {
+			builder.WriteString(fmt.Sprintf("    ~ %s\n", target.Target))
+			writeChanges(target.Changes)
+		}
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// Json returns the differences as indented JSON.
+func (d profileDiff) Json() string {
+	data, _ := json.MarshalIndent(d, "", "\t") // no error
+	return string(data)
+}
+
+// ProfileDiff handles the "regolith profile-diff" command. It compares the
+// filters and the export targets of two profiles from the config.json file
+// and prints the differences. The profiles are compared after resolving
+// their "extends" properties. If jsonOutput is true, the differences are
+// printed as JSON.
+//
+// The "configPath" parameter is the path to the config file. The empty path
+// means "config.json".
+//
+// The "debug" parameter is a boolean that determines if the debug messages
+// should be printed.
+func ProfileDiff(
+	profileA, profileB string, jsonOutput bool, configPath string, debug bool,
+) error {
+	InitLogging(debug)
+	configMap, err1 := LoadConfigAsMap(configPath)
+	config, err2 := ConfigFromObject(configMap)
+	if err := firstErr(err1, err2); err != nil {
+		return burrito.WrapError(err, "Failed to load config.json.")
+	}
+	profiles := make([]Profile, 2)
+	for i, name := range []string{profileA, profileB} {
+		profile, ok := config.Profiles[name]
+		if !ok {
+			return burrito.WrappedErrorf(
+				"Profile %q does not exist in the configuration.\n"+
+					"Available profiles:\n%s", name, config.ListProfiles())
+		}
+		profiles[i] = profile
+	}
+	diff := diffProfiles(profileA, profiles[0], profileB, profiles[1])
+	if jsonOutput {
+		fmt.Println(diff.Json())
+	} else {
+		fmt.Println(diff.String())
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Functions used by the "preRun" and "postRun" properties of the profiles,
+// which run the commands before and after building the profile.
+package regolith
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// hooksFromObject parses the list of the commands of the "preRun" or the
+// "postRun" property of a profile.
+func hooksFromObject(obj map[string]interface{}, property string) ([]string, error) {
+	hooks, ok := obj[property]
+	if !ok {
+		return nil, nil
+	}
+	hooksArray, ok := hooks.([]interface{})
+	if !ok {
+		return nil, burrito.WrappedErrorf(jsonPathTypeError, property, "array")
+	}
+	result := []string{}
+	for i, hook := range hooksArray {
+		hook, ok := hook.(string)
+		if !ok {
+			return nil, burrito.WrappedErrorf(
+				jsonPathTypeError, fmt.Sprintf("%s->%d", property, i), "string")
+		}
+		result = append(result, hook)
+	}
+	return result, nil
+}
+
+// runProfileHooks runs the commands with the system shell in the root of the
+// project (projectDir), one after another. It stops at the first command that fails. The
+// extraEnv is added to the environment variables of the commands.
+func runProfileHooks(
+	commands []string, property, projectDir string, extraEnv []string,
+) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	shell, arg, err := findShell()
+	if err != nil {
+		return burrito.WrapError(err, "Unable to find a valid shell.")
+	}
+	for _, command := range commands {
+		Logger.Infof("Running the %s command: %s", property, command)
+		err = runSubProcessWithEnv(
+			nil, shell, []string{arg, command}, projectDir, projectDir,
+			property, extraEnv)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "The %s command failed.\nCommand: %s", property, command)
+		}
+	}
+	return nil
+}
+
+// runProfileWithHooks runs the profile with RunProfile between the "preRun"
+// and the "postRun" commands of the profile. If the "preRun" commands fail,
+// the profile is not run. The "postRun" commands always run, like a finally
+// block. They receive the result of the build in the REGOLITH_RUN_SUCCESS
+// environment variable ("true" or "false").
+func runProfileWithHooks(context RunContext) error {
+	profile, err := context.GetProfile()
+	if err != nil {
+		return burrito.WrapErrorf(err, runContextGetProfileError)
+	}
+	projectDir, err := context.Config.projectDir()
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	profileEnv := fmt.Sprintf("REGOLITH_PROFILE=%s", context.Profile)
+	err = runProfileHooks(
+		profile.PreRun, "preRun", projectDir, []string{profileEnv})
+	if err != nil {
+		err = burrito.WrapError(err, "Failed to run the preRun commands.")
+	} else {
+		err = RunProfile(context)
+	}
+	hookErr := runProfileHooks(
+		profile.PostRun, "postRun", projectDir, []string{
+			profileEnv,
+			"REGOLITH_RUN_SUCCESS=" + strconv.FormatBool(err == nil),
+		})
+	if hookErr == nil {
+		return err
+	}
+	hookErr = burrito.WrapError(hookErr, "Failed to run the postRun commands.")
+	if err == ErrRunCancelled {
+		// The cancellation error is not wrapped, so the callers can detect it
+		Logger.Error(hookErr)
+		return err
+	}
+	if err != nil {
+		return burrito.PassErrorHandlerError(err, hookErr, errorConnector)
+	}
+	return hookErr
+}
@@ -0,0 +1,93 @@
+// Functions used by the "regolith run --profile-report" command, which saves
+// the execution times of the filters in a JSON file.
+package regolith
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// Values of the "exitStatus" property of the entries of the profile report
+const (
+	profileReportSuccess = "success"
+	profileReportFailure = "failure"
+)
+
+// profileReport is the content of the file created with the
+// "--profile-report" flag.
+type profileReport struct {
+	Profile string               `json:"profile"`
+	Filters []profileReportEntry `json:"filters"`
+
+	// ExportDurationMs is nil if the profile failed before the export.
+	ExportDurationMs *int64 `json:"exportDurationMs"`
+	ExportStatus     string `json:"exportStatus,omitempty"`
+	TotalDurationMs  int64  `json:"totalDurationMs"`
+
+	// start is the time of creating the report, used for calculating the
+	// TotalDurationMs.
+	start time.Time
+}
+
+// profileReportEntry is an entry of the profile report that describes a
+// single execution of a filter.
+type profileReportEntry struct {
+	FilterId   string `json:"filterId"`
+	DurationMs int64  `json:"durationMs"`
+	ExitStatus string `json:"exitStatus"`
+}
+
+// profileReportStatus returns the exit status of the report entry based on
+// the error returned by the filter or the export.
+func profileReportStatus(err error) string {
+	if err != nil {
+		return profileReportFailure
+	}
+	return profileReportSuccess
+}
+
+// attachProfileReport sets the listeners of the context which fill the
+// returned profile report while the profile is running. It should be called
+// right before running the profile.
+func attachProfileReport(context *RunContext) *profileReport {
+	report := &profileReport{
+		Profile: context.Profile,
+		Filters: []profileReportEntry{},
+		start:   time.Now(),
+	}
+	context.filterRunListener = func(
+		filterId string, duration time.Duration, err error,
+	) {
+		report.Filters = append(report.Filters, profileReportEntry{
+			FilterId:   filterId,
+			DurationMs: duration.Milliseconds(),
+			ExitStatus: profileReportStatus(err),
+		})
+	}
+	context.exportListener = func(duration time.Duration, err error) {
+		durationMs := duration.Milliseconds()
+		report.ExportDurationMs = &durationMs
+		report.ExportStatus = profileReportStatus(err)
+	}
+	return report
+}
+
+// write saves the report as a JSON file at the given path. The total
+// duration is the time elapsed since attaching the report to the context.
+func (r *profileReport) write(path string) error {
+	r.TotalDurationMs = time.Since(r.start).Milliseconds()
+	data, _ := json.MarshalIndent(r, "", "\t") // no error
+	err := CreateDirectoryIfNotExists(filepath.Dir(path))
+	if err != nil {
+		return burrito.WrapErrorf(err, osMkdirError, filepath.Dir(path))
+	}
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, path)
+	}
+	return nil
+}
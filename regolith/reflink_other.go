@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package regolith
+
+import (
+	"errors"
+	"os"
+)
+
+// reflinkFile creates a copy-on-write clone of the src file at the dst path.
+// Reflinks are only supported on Linux, on other systems it always fails.
+func reflinkFile(src, dst string, perm os.FileMode) error {
+	return errors.New("reflinks are not supported on this system")
+}
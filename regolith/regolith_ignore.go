@@ -0,0 +1,217 @@
+// Functions for handling the .regolithignore files, which exclude some of the
+// files of the source folders from the tmp directory.
+package regolith
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+	"github.com/otiai10/copy"
+)
+
+// regolithIgnoreFileName is the name of the file with the patterns of the
+// files that aren't copied from a source folder to the tmp directory.
+const regolithIgnoreFileName = ".regolithignore"
+
+// ignorePattern is a single pattern from a .regolithignore file.
+type ignorePattern struct {
+	// segments are the parts of the pattern split on "/". The patterns
+	// that aren't anchored to the root of the source folder start with "**".
+	segments []string
+
+	// negate is true for the patterns starting with "!", which include the
+	// files excluded by the previous patterns again.
+	negate bool
+
+	// dirOnly is true for the patterns ending with "/", which match only the
+	// directories.
+	dirOnly bool
+}
+
+// regolithIgnore is the list of the patterns from the .regolithignore file
+// of a source folder. A nil regolithIgnore doesn't ignore anything.
+type regolithIgnore struct {
+	// root is the path to the source folder with the .regolithignore file.
+	// The patterns are relative to it.
+	root string
+
+	patterns []ignorePattern
+}
+
+// loadRegolithIgnore loads the .regolithignore file from the root of the
+// source folder. It returns nil if the file doesn't exist. The file uses the
+// syntax of the .gitignore files.
+func loadRegolithIgnore(root string) (*regolithIgnore, error) {
+	ignorePath := filepath.Join(root, regolithIgnoreFileName)
+	file, err := os.Open(ignorePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, burrito.WrapErrorf(err, fileReadError, ignorePath)
+	}
+	defer file.Close()
+	result := &regolithIgnore{root: root}
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		pattern, ok := parseIgnorePattern(scanner.Text())
+		if !ok {
+			continue
+		}
+		for _, segment := range pattern.segments {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, burrito.WrapErrorf(
+					err, "Invalid pattern in the %s file.\n"+
+						"Path: %s\nLine: %d",
+					regolithIgnoreFileName, ignorePath, lineNumber)
+			}
+		}
+		result.patterns = append(result.patterns, pattern)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, burrito.WrapErrorf(err, fileReadError, ignorePath)
+	}
+	return result, nil
+}
+
+// parseIgnorePattern parses a line of a .regolithignore file. It returns
+// false for the empty lines and the comments.
+func parseIgnorePattern(line string) (ignorePattern, bool) {
+	result := ignorePattern{}
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return result, false
+	}
+	if strings.HasPrefix(line, "!") {
+		result.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		result.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	// The patterns with a slash are relative to the root of the source
+	// folder, the other patterns match the files in all subfolders
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return result, false
+	}
+	result.segments = strings.Split(line, "/")
+	if !anchored {
+		result.segments = append([]string{"**"}, result.segments...)
+	}
+	return result, true
+}
+
+// matchIgnoreSegments returns true if the parts of the path match the
+// segments of the pattern. The "**" segment matches any number of parts.
+func matchIgnoreSegments(segments, parts []string) bool {
+	if len(segments) == 0 {
+		return len(parts) == 0
+	}
+	if segments[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchIgnoreSegments(segments[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	matched, _ := path.Match(segments[0], parts[0]) // validated on load
+	return matched && matchIgnoreSegments(segments[1:], parts[1:])
+}
+
+// isIgnored returns true if the file with the relPath path (relative to the
+// root of the source folder, with forward slashes) is ignored. Like in the
+// .gitignore files, the last matching pattern decides. The .regolithignore
+// file itself is always ignored.
+func (r *regolithIgnore) isIgnored(relPath string, isDir bool) bool {
+	if r == nil || relPath == "." {
+		return false
+	}
+	if relPath == regolithIgnoreFileName {
+		return true
+	}
+	parts := strings.Split(relPath, "/")
+	result := false
+	for _, pattern := range r.patterns {
+		if pattern.dirOnly && !isDir {
+			continue
+		}
+		if matchIgnoreSegments(pattern.segments, parts) {
+			result = !pattern.negate
+		}
+	}
+	return result
+}
+
+// isIgnoredPath works like isIgnored, but takes a path inside of the root
+// of the source folder.
+func (r *regolithIgnore) isIgnoredPath(p string, isDir bool) (bool, error) {
+	if r == nil {
+		return false, nil
+	}
+	relPath, err := filepath.Rel(r.root, p)
+	if err != nil {
+		return false, burrito.WrapErrorf(err, filepathRelError, r.root, p)
+	}
+	return r.isIgnored(filepath.ToSlash(relPath), isDir), nil
+}
+
+// skip is the Skip function of copy.Options, which skips the ignored files.
+func (r *regolithIgnore) skip(src string) (bool, error) {
+	if r == nil {
+		return false, nil
+	}
+	info, err := os.Lstat(src)
+	if err != nil {
+		return false, burrito.WrapErrorf(err, osStatErrorAny, src)
+	}
+	return r.isIgnoredPath(src, info.IsDir())
+}
+
+// copyIgnoredFiles copies the ignored files from the sourceDir (the source
+// folder or its subfolder) to the tmpDir, unless the tmpDir already has a
+// file with the same path. It's used before replacing the source files with
+// the files from the tmp directory, so the ignored files are not lost.
+func copyIgnoredFiles(ignore *regolithIgnore, sourceDir, tmpDir string) error {
+	if ignore == nil {
+		return nil
+	}
+	return filepath.WalkDir(sourceDir, func(p string, d fs.DirEntry, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return burrito.WrapErrorf(err, osWalkError, sourceDir)
+		}
+		ignored, err := ignore.isIgnoredPath(p, d.IsDir())
+		if err != nil || !ignored {
+			return err
+		}
+		relPath, err := filepath.Rel(sourceDir, p)
+		if err != nil {
+			return burrito.WrapErrorf(err, filepathRelError, sourceDir, p)
+		}
+		target := filepath.Join(tmpDir, relPath)
+		if _, err := os.Lstat(target); os.IsNotExist(err) {
+			err = copy.Copy(p, target, copy.Options{PreserveTimes: false, Sync: false})
+			if err != nil {
+				return burrito.WrapErrorf(err, osCopyError, p, target)
+			}
+		}
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
@@ -107,7 +107,9 @@ func DownloadResolverMaps() error {
 						"Short URL: "+shortUrl)
 			}
 			Logger.Debugf("Downloading resolver using URL: %s", url)
-			err = getter.GetFile(savePath, url)
+			err = retryNetworkOperation("download resolver", func() error {
+				return getter.GetFile(savePath, url)
+			})
 			if err != nil {
 				return burrito.WrapErrorf(err, "Failed to download the file.\nURL: %s", url)
 			}
@@ -171,10 +173,13 @@ func getResolversMap() (*map[string]ResolverMapItem, error) {
 	if resolverMap != nil {
 		return resolverMap, nil
 	}
-	err := DownloadResolverMaps()
-	if err != nil {
-		Logger.Warnf(
-			"Failed to download resolver map: %s", err.Error())
+	// The offline mode uses the resolver maps downloaded previously
+	if !Offline {
+		err := DownloadResolverMaps()
+		if err != nil {
+			Logger.Warnf(
+				"Failed to download resolver map: %s", err.Error())
+		}
 	}
 	result := make(map[string]ResolverMapItem)
 	// Load all resolver files into a map, where the ke is the URL of the resovler
@@ -0,0 +1,151 @@
+// Functions used by the "regolith run --summary-only" command, which hides
+// the logs of the filters that succeeded and prints a short summary of the
+// run instead.
+package regolith
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// summaryLogBuffer is a thread-safe buffer for the logs written while
+// running the profile in the summary-only mode. The filters log their output
+// from separate goroutines.
+type summaryLogBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *summaryLogBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *summaryLogBuffer) Sync() error {
+	return nil
+}
+
+// take returns the content of the buffer and clears it.
+func (b *summaryLogBuffer) take() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	result := b.buffer.String()
+	b.buffer.Reset()
+	return result
+}
+
+// runSummaryEntry describes a single execution of a filter or the export.
+type runSummaryEntry struct {
+	name     string
+	duration time.Duration
+	err      error
+}
+
+// runSummary collects the data printed at the end of the run in the
+// summary-only mode.
+type runSummary struct {
+	profile string
+	entries []runSummaryEntry
+	start   time.Time
+
+	// buffer stores the logs of the current step of the run. It's cleared
+	// after every successful filter and export.
+	buffer *summaryLogBuffer
+
+	// logger writes to the buffer. It's used for the logs of the filters of
+	// the contexts with the summary (see RunContext.logger).
+	logger *zap.SugaredLogger
+}
+
+// attachRunSummary adds the summary to the context, so the logs of the
+// filters are written to its buffer, and sets the listeners of the context
+// which fill the returned summary while the profile is running. The
+// previous listeners of the context are still called. It should be called
+// right before running the profile and followed by the finish method of the
+// summary.
+func attachRunSummary(context *RunContext) *runSummary {
+	summary := &runSummary{
+		profile: context.Profile,
+		entries: []runSummaryEntry{},
+		start:   time.Now(),
+		buffer:  &summaryLogBuffer{},
+	}
+	summary.logger = zap.New(zapcore.NewCore(
+		zapcore.NewConsoleEncoder(newLoggerEncoderConfig()),
+		summary.buffer, LoggerLevel)).Sugar()
+	context.runSummary = summary
+
+	filterRunListener := context.filterRunListener
+	context.filterRunListener = func(
+		filterId string, duration time.Duration, err error,
+	) {
+		if filterRunListener != nil {
+			filterRunListener(filterId, duration, err)
+		}
+		summary.add(filterId, duration, err)
+	}
+	exportListener := context.exportListener
+	context.exportListener = func(duration time.Duration, err error) {
+		if exportListener != nil {
+			exportListener(duration, err)
+		}
+		summary.add("[export]", duration, err)
+	}
+	return summary
+}
+
+// add adds an entry to the summary. If the step succeeded, its logs are
+// discarded.
+func (s *runSummary) add(name string, duration time.Duration, err error) {
+	s.entries = append(s.entries, runSummaryEntry{
+		name: name, duration: duration, err: err})
+	if err == nil {
+		s.buffer.take()
+	}
+}
+
+// finish prints the summary of the run. If the run failed (err is not nil),
+// the logs of the failed step are printed before the summary.
+func (s *runSummary) finish(err error) {
+	logs := s.buffer.take()
+	if err != nil && logs != "" {
+		fmt.Fprint(color.Output, logs)
+	}
+	result := color.GreenString("success")
+	if err != nil {
+		result = color.RedString("failure")
+	}
+	fmt.Printf("\nSummary of the %q profile:\n", s.profile)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tDURATION\tSTATUS")
+	for _, entry := range s.entries {
+		fmt.Fprintf(
+			w, "%s\t%s\t%s\n", entry.name,
+			entry.duration.Round(time.Millisecond),
+			profileReportStatus(entry.err))
+	}
+	fmt.Fprintf(
+		w, "[total]\t%s\t\n", time.Since(s.start).Round(time.Millisecond))
+	w.Flush()
+	fmt.Printf("Filters run: %d, result: %s\n", s.filterCount(), result)
+}
+
+// filterCount returns the number of the filter executions in the summary.
+func (s *runSummary) filterCount() int {
+	count := 0
+	for _, entry := range s.entries {
+		if entry.name != "[export]" {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,124 @@
+package regolith
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// TestRunSummary checks whether the logs of the filters are buffered in the
+// summary-only mode without replacing the global logger, whether the logs of
+// the successful filters are discarded, and whether the summary lists the
+// filters and the result of the run.
+func TestRunSummary(t *testing.T) {
+	InitLogging(false)
+	noColor, output := color.NoColor, color.Output
+	color.NoColor = true
+	defer func() { color.NoColor, color.Output = noColor, output }()
+	failure := errors.New("failure")
+	tests := []struct {
+		name       string
+		err        error
+		expected   []string
+		unexpected []string
+	}{
+		{
+			"success",
+			nil,
+			[]string{
+				`Summary of the "default" profile:`,
+				"first 10ms success",
+				"second 20ms success",
+				"[export] 30ms success",
+				"Filters run: 2, result: success",
+			},
+			[]string{"Log of the first filter", "Log of the second filter"},
+		},
+		{
+			"failure",
+			failure,
+			[]string{
+				"Log of the second filter",
+				"first 10ms success",
+				"second 20ms failure",
+				"Filters run: 2, result: failure",
+			},
+			[]string{"Log of the first filter", "[export]"},
+		},
+	}
+	for _, test := range tests {
+		logger := Logger
+		context := RunContext{Profile: "default"}
+		summary := attachRunSummary(&context)
+		if Logger != logger {
+			t.Fatalf("%s: the global logger was replaced", test.name)
+		}
+		result := captureStdout(t, func() error {
+			color.Output = colorOutputWriter{}
+			context.logInfof("Log of the first filter")
+			context.filterRunListener("first", 10*time.Millisecond, nil)
+			context.logInfof("Log of the second filter")
+			context.filterRunListener("second", 20*time.Millisecond, test.err)
+			if test.err == nil {
+				context.exportListener(30*time.Millisecond, nil)
+			}
+			summary.finish(test.err)
+			return nil
+		})
+		// The columns of the table are aligned with spaces
+		lines := strings.Split(result, "\n")
+		for i, line := range lines {
+			lines[i] = strings.Join(strings.Fields(line), " ")
+		}
+		result = strings.Join(lines, "\n")
+		for _, expected := range test.expected {
+			if !strings.Contains(result, expected) {
+				t.Errorf(
+					"%s: the summary doesn't contain %q.\nSummary:\n%s",
+					test.name, expected, result)
+			}
+		}
+		for _, unexpected := range test.unexpected {
+			if strings.Contains(result, unexpected) {
+				t.Errorf(
+					"%s: the summary contains %q.\nSummary:\n%s",
+					test.name, unexpected, result)
+			}
+		}
+	}
+}
+
+// colorOutputWriter writes to the current standard output, so the output
+// written to color.Output can be captured with captureStdout.
+type colorOutputWriter struct{}
+
+func (colorOutputWriter) Write(p []byte) (int, error) {
+	return os.Stdout.Write(p)
+}
+
+// captureStdout runs the function and returns what it printed to the
+// standard output.
+func captureStdout(t *testing.T, f func() error) string {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal("Unable to create a pipe:", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	err = f()
+	os.Stdout = stdout
+	writer.Close()
+	if err != nil {
+		t.Fatal("The function failed:", err)
+	}
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal("Unable to read the output:", err)
+	}
+	return string(output)
+}
@@ -0,0 +1,309 @@
+// Functions used for validating the settings of the remote filters against
+// the JSON schemas declared in the "settingsSchema" property of their
+// filter.json files. Only a subset of the JSON Schema keywords is supported,
+// the other keywords are ignored with a warning.
+package regolith
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// supportedSchemaKeywords is the set of the JSON Schema keywords used by
+// validateJsonSchema and the annotation keywords that don't affect the
+// validation.
+var supportedSchemaKeywords = map[string]bool{
+	"type": true, "enum": true, "const": true, "required": true,
+	"properties": true, "additionalProperties": true, "items": true,
+	"minItems": true, "maxItems": true, "minimum": true, "maximum": true,
+	"minLength": true, "maxLength": true, "pattern": true,
+	// Annotations
+	"$schema": true, "$id": true, "$comment": true, "title": true,
+	"description": true, "default": true, "examples": true,
+	"deprecated": true, "readOnly": true, "writeOnly": true,
+}
+
+// schemaViolation is a single problem found while validating a value
+// against a JSON schema.
+type schemaViolation struct {
+	// jsonPath is the path to the invalid value in the "a->b->0" format
+	jsonPath string
+
+	// message describes the problem
+	message string
+}
+
+func (v schemaViolation) String() string {
+	return fmt.Sprintf("%s: %s", v.jsonPath, v.message)
+}
+
+// validateSettings validates the settings of the remote filter against the
+// "settingsSchema" from its filter.json file. It returns the list of all of
+// the problems. Filters without the schema are always valid.
+func (f *RemoteFilter) validateSettings(
+	dotRegolithPath string,
+) ([]schemaViolation, error) {
+	filterJson, err := f.Definition.LoadFilterJson(dotRegolithPath)
+	if err != nil {
+		return nil, burrito.WrapErrorf(
+			err, "Could not load filter.json for %q filter.", f.Id)
+	}
+	schemaObj, ok := filterJson["settingsSchema"]
+	if !ok {
+		return nil, nil
+	}
+	schema, ok := schemaObj.(map[string]interface{})
+	if !ok {
+		return nil, burrito.WrappedErrorf(
+			jsonPathTypeError, "settingsSchema", "object")
+	}
+	unsupported := []string{}
+	findUnsupportedSchemaKeywords(schema, "settingsSchema", &unsupported)
+	if len(unsupported) != 0 {
+		Logger.Warnf(
+			"The settings schema of the filter uses keywords that Regolith "+
+				"doesn't support. They are ignored, so the settings that "+
+				"break them are not reported.\nFilter: %s\nKeywords:\n\t- %s",
+			f.Id, strings.Join(unsupported, "\n\t- "))
+	}
+	var settings interface{} = map[string]interface{}{}
+	if f.Settings != nil {
+		settings = f.Settings
+	}
+	violations := []schemaViolation{}
+	validateJsonSchema(settings, schema, "settings", &violations)
+	return violations, nil
+}
+
+// validateJsonSchema validates the value against the JSON schema and
+// appends the problems to the violations list. The supported keywords are:
+// "type", "enum", "const", "required", "properties", "additionalProperties",
+// "items", "minItems", "maxItems", "minimum", "maximum", "minLength",
+// "maxLength" and "pattern".
+func validateJsonSchema(
+	value interface{}, schema map[string]interface{}, jsonPath string,
+	violations *[]schemaViolation,
+) {
+	report := func(format string, args ...interface{}) {
+		*violations = append(*violations, schemaViolation{
+			jsonPath: jsonPath, message: fmt.Sprintf(format, args...)})
+	}
+	// Type
+	if typeObj, ok := schema["type"]; ok {
+		var types []string
+		switch typeObj := typeObj.(type) {
+		case string:
+			types = []string{typeObj}
+		case []interface{}:
+			for _, t := range typeObj {
+				if t, ok := t.(string); ok {
+					types = append(types, t)
+				}
+			}
+		}
+		matches := false
+		for _, t := range types {
+			if jsonSchemaTypeMatches(value, t) {
+				matches = true
+				break
+			}
+		}
+		if len(types) > 0 && !matches {
+			report("expected %s, got %s",
+				strings.Join(types, " or "), jsonSchemaTypeName(value))
+			// The other keywords would only report the same problem again
+			return
+		}
+	}
+	// Enum and const
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, item := range enum {
+			if reflect.DeepEqual(item, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			allowed, _ := json.Marshal(enum)
+			report("value must be one of %s", allowed)
+		}
+	}
+	if constValue, ok := schema["const"]; ok &&
+		!reflect.DeepEqual(constValue, value) {
+		expected, _ := json.Marshal(constValue)
+		report("value must be equal to %s", expected)
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		validateJsonSchemaObject(value, schema, jsonPath, violations)
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range value {
+				validateJsonSchema(
+					item, items, fmt.Sprintf("%s->%d", jsonPath, i),
+					violations)
+			}
+		}
+		if minItems, ok := schema["minItems"].(float64); ok &&
+			float64(len(value)) < minItems {
+			report("array must have at least %v items", minItems)
+		}
+		if maxItems, ok := schema["maxItems"].(float64); ok &&
+			float64(len(value)) > maxItems {
+			report("array must have at most %v items", maxItems)
+		}
+	case float64:
+		if minimum, ok := schema["minimum"].(float64); ok && value < minimum {
+			report("value must be at least %v", minimum)
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && value > maximum {
+			report("value must be at most %v", maximum)
+		}
+	case string:
+		length := float64(len([]rune(value)))
+		if minLength, ok := schema["minLength"].(float64); ok &&
+			length < minLength {
+			report("string must have at least %v characters", minLength)
+		}
+		if maxLength, ok := schema["maxLength"].(float64); ok &&
+			length > maxLength {
+			report("string must have at most %v characters", maxLength)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				report("the schema has an invalid pattern %q", pattern)
+			} else if !re.MatchString(value) {
+				report("string must match the pattern %q", pattern)
+			}
+		}
+	}
+}
+
+// findUnsupportedSchemaKeywords appends the paths of the keywords of the
+// JSON schema that are not in supportedSchemaKeywords to the unsupported
+// list. The subschemas of the "properties", "additionalProperties" and
+// "items" keywords are checked recursively.
+func findUnsupportedSchemaKeywords(
+	schema map[string]interface{}, jsonPath string, unsupported *[]string,
+) {
+	keywords := make([]string, 0, len(schema))
+	for keyword := range schema {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+	for _, keyword := range keywords {
+		if !supportedSchemaKeywords[keyword] {
+			*unsupported = append(*unsupported, jsonPath+"->"+keyword)
+		}
+	}
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		names := make([]string, 0, len(properties))
+		for name := range properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := properties[name].(map[string]interface{}); ok {
+				findUnsupportedSchemaKeywords(
+					property, jsonPath+"->properties->"+name, unsupported)
+			}
+		}
+	}
+	for _, keyword := range []string{"additionalProperties", "items"} {
+		if subschema, ok := schema[keyword].(map[string]interface{}); ok {
+			findUnsupportedSchemaKeywords(
+				subschema, jsonPath+"->"+keyword, unsupported)
+		}
+	}
+}
+
+// validateJsonSchemaObject validates the object-specific keywords of the
+// JSON schema: "required", "properties" and "additionalProperties".
+func validateJsonSchemaObject(
+	value map[string]interface{}, schema map[string]interface{},
+	jsonPath string, violations *[]schemaViolation,
+) {
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			name, ok := name.(string)
+			if !ok {
+				continue
+			}
+			if _, ok := value[name]; !ok {
+				*violations = append(*violations, schemaViolation{
+					jsonPath: jsonPath + "->" + name,
+					message:  "required property is missing"})
+			}
+		}
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	// Sort the names for the deterministic order of the problems
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		propertyPath := jsonPath + "->" + name
+		if propertySchema, ok := properties[name]; ok {
+			if propertySchema, ok := propertySchema.(map[string]interface{}); ok {
+				validateJsonSchema(
+					value[name], propertySchema, propertyPath, violations)
+			}
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				*violations = append(*violations, schemaViolation{
+					jsonPath: propertyPath,
+					message:  "property is not allowed"})
+			}
+		case map[string]interface{}:
+			validateJsonSchema(value[name], additional, propertyPath, violations)
+		}
+	}
+}
+
+// jsonSchemaTypeMatches returns true if the value decoded from JSON matches
+// the JSON Schema type.
+func jsonSchemaTypeMatches(value interface{}, schemaType string) bool {
+	switch schemaType {
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	}
+	return jsonSchemaTypeName(value) == schemaType
+}
+
+// jsonSchemaTypeName returns the name of the JSON Schema type of the value
+// decoded from JSON.
+func jsonSchemaTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,56 @@
+package regolith
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestFindUnsupportedSchemaKeywords checks whether the keywords of the
+// settings schemas that are not supported by the validation are found in
+// the nested subschemas, and whether the annotations are not reported.
+func TestFindUnsupportedSchemaKeywords(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		expected []string
+	}{
+		{
+			"supported keywords",
+			`{"type": "object", "title": "Settings", "required": ["a"],
+			"properties": {"a": {"type": "string", "pattern": "^a",
+			"description": "A", "default": "a"}}}`,
+			[]string{},
+		},
+		{
+			"top-level keywords",
+			`{"oneOf": [], "allOf": [], "$ref": "#/$defs/a", "$defs": {}}`,
+			[]string{
+				"settingsSchema->$defs", "settingsSchema->$ref",
+				"settingsSchema->allOf", "settingsSchema->oneOf"},
+		},
+		{
+			"nested keywords",
+			`{"properties": {"a": {"anyOf": []}, "b": {"type": "string"}},
+			"additionalProperties": {"format": "uri"},
+			"items": {"uniqueItems": true}}`,
+			[]string{
+				"settingsSchema->properties->a->anyOf",
+				"settingsSchema->additionalProperties->format",
+				"settingsSchema->items->uniqueItems"},
+		},
+	}
+	for _, test := range tests {
+		var schema map[string]interface{}
+		if err := json.Unmarshal([]byte(test.schema), &schema); err != nil {
+			t.Fatalf("%s: invalid schema: %s", test.name, err)
+		}
+		actual := []string{}
+		findUnsupportedSchemaKeywords(schema, "settingsSchema", &actual)
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf(
+				"%s: unexpected unsupported keywords.\nExpected: %v\n"+
+					"Actual: %v", test.name, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Functions for replacing the ${var:name} and ${json:name} placeholders in
+// the settings of the filters with the values from the "--var" flags of
+// "regolith run" and "regolith watch".
+package regolith
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// settingsVariablePattern matches the ${var:name} and ${var:name=default}
+// placeholders, and the ${json:name} and ${json:name=default} placeholders
+// of the values parsed as JSON.
+var settingsVariablePattern = regexp.MustCompile(
+	`\$\{(var|json):([A-Za-z0-9_.-]+)(?:=([^}]*))?\}`)
+
+// parseRunVariables parses the list of variables in the "key=value" format.
+func parseRunVariables(vars []string) (map[string]string, error) {
+	result := make(map[string]string, len(vars))
+	for _, variable := range vars {
+		key, value, ok := strings.Cut(variable, "=")
+		if !ok || key == "" {
+			return nil, burrito.WrappedErrorf(
+				"Invalid variable. The variables must use the \"key=value\" "+
+					"format.\nVariable: %s", variable)
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+// substituteSettingsVariables replaces the placeholders in the "settings"
+// of the filters of the selected profile, the profiles that it runs with the
+// profile filters and the filter definitions used by them (before parsing
+// the config) with the values of the variables. The other profiles and
+// filter definitions are not changed, so their placeholders don't need the
+// variables. The placeholders with a default value (${var:name=default}) use
+// the default when the variable is not set, the other placeholders of the
+// variables that are not set are errors. The values are always strings,
+// unless the setting consists only of a ${json:name} placeholder. In that
+// case, the value is parsed as JSON, so the variables can change the types
+// of the settings.
+func substituteSettingsVariables(
+	configJson map[string]interface{}, profileName string, vars []string,
+) error {
+	values, err := parseRunVariables(vars)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	used := make(map[string]struct{})
+	regolithObj, _ := configJson["regolith"].(map[string]interface{})
+	profiles, _ := regolithObj["profiles"].(map[string]interface{})
+	visitedProfiles := make(map[string]struct{})
+	usedFilters := make(map[string]struct{})
+	pendingProfiles := []string{profileName}
+	for len(pendingProfiles) != 0 {
+		profileName := pendingProfiles[0]
+		pendingProfiles = pendingProfiles[1:]
+		if _, ok := visitedProfiles[profileName]; ok {
+			continue
+		}
+		visitedProfiles[profileName] = struct{}{}
+		profile, _ := profiles[profileName].(map[string]interface{})
+		filters, _ := profile["filters"].([]interface{})
+		for i, filter := range filters {
+			filter, ok := filter.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if nested, ok := filter["profile"].(string); ok {
+				pendingProfiles = append(pendingProfiles, nested)
+			}
+			if id, ok := filter["filter"].(string); ok {
+				usedFilters[id] = struct{}{}
+			}
+			settings, ok := filter["settings"]
+			if !ok {
+				continue
+			}
+			settings, err := substituteVariables(settings, values, used)
+			if err != nil {
+				return burrito.WrapErrorf(
+					err, "Failed to substitute the variables in the settings "+
+						"of the %s filter.\nProfile: %s", nth(i), profileName)
+			}
+			filter["settings"] = settings
+		}
+	}
+	filterDefinitions, _ := regolithObj["filterDefinitions"].(map[string]interface{})
+	for name, definition := range filterDefinitions {
+		if _, ok := usedFilters[name]; !ok {
+			continue
+		}
+		definition, ok := definition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		settings, ok := definition["settings"]
+		if !ok {
+			continue
+		}
+		settings, err := substituteVariables(settings, values, used)
+		if err != nil {
+			return burrito.WrapErrorf(
+				err, "Failed to substitute the variables in the settings "+
+					"of the filter definition.\nFilter: %s", name)
+		}
+		definition["settings"] = settings
+	}
+	var unused []string
+	for key := range values {
+		if _, ok := used[key]; !ok {
+			unused = append(unused, key)
+		}
+	}
+	if len(unused) != 0 {
+		sort.Strings(unused)
+		Logger.Warnf(
+			"The variables are not used in the settings of the profile: %s",
+			strings.Join(unused, ", "))
+	}
+	return nil
+}
+
+// substituteVariables returns a copy of the JSON value with the placeholders
+// of its strings replaced with the values of the variables. The names of
+// the substituted variables are added to the used set.
+func substituteVariables(
+	value interface{}, values map[string]string, used map[string]struct{},
+) (interface{}, error) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			item, err := substituteVariables(item, values, used)
+			if err != nil {
+				return nil, burrito.PassError(err)
+			}
+			result[key] = item
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(value))
+		for i, item := range value {
+			item, err := substituteVariables(item, values, used)
+			if err != nil {
+				return nil, burrito.PassError(err)
+			}
+			result[i] = item
+		}
+		return result, nil
+	case string:
+		return substituteStringVariables(value, values, used)
+	}
+	return value, nil
+}
+
+// substituteStringVariables replaces the placeholders in the string.
+func substituteStringVariables(
+	value string, values map[string]string, used map[string]struct{},
+) (interface{}, error) {
+	var err error
+	lookup := func(match []string) string {
+		name := match[2]
+		if variable, ok := values[name]; ok {
+			used[name] = struct{}{}
+			return variable
+		}
+		// The default value is the whole match after "=", so the empty
+		// default (${var:name=}) is different from no default
+		if strings.Contains(match[0], "=") {
+			return match[3]
+		}
+		if err == nil {
+			err = burrito.WrappedErrorf(
+				"The variable is not set. Use the \"--var %s=<value>\" flag "+
+					"or add a default value to the placeholder.\n"+
+					"Variable: %s", name, name)
+		}
+		return ""
+	}
+	// The placeholder is the whole string
+	if match := settingsVariablePattern.FindStringSubmatch(value); match != nil &&
+		match[0] == value {
+		result := lookup(match)
+		if err != nil {
+			return nil, err
+		}
+		if match[1] != "json" {
+			return result, nil
+		}
+		var jsonValue interface{}
+		if err := json.Unmarshal([]byte(result), &jsonValue); err != nil {
+			return nil, burrito.WrapErrorf(
+				err, "The value of the variable is not valid JSON.\n"+
+					"Variable: %s\nValue: %s", match[2], result)
+		}
+		return jsonValue, nil
+	}
+	result := settingsVariablePattern.ReplaceAllStringFunc(
+		value, func(placeholder string) string {
+			return lookup(settingsVariablePattern.FindStringSubmatch(placeholder))
+		})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
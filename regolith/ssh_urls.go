@@ -0,0 +1,67 @@
+// Functions used for installing the remote filters from the Git
+// repositories accessed over SSH, for example the private repositories that
+// require a deploy key.
+package regolith
+
+import (
+	"regexp"
+	"strings"
+)
+
+// scpLikeUrlPattern matches the beginning of the SSH URLs written in the
+// scp-like syntax used by Git, for example "git@github.com:org/repo".
+var scpLikeUrlPattern = regexp.MustCompile(`^[\w.-]+@[\w.-]+:`)
+
+// isSshUrl returns true if the URL of a filter or a repository uses SSH,
+// either in the scp-like syntax ("git@github.com:org/repo") or with the
+// "ssh://" scheme ("ssh://git@github.com/org/repo").
+func isSshUrl(url string) bool {
+	return strings.HasPrefix(url, "ssh://") || scpLikeUrlPattern.MatchString(url)
+}
+
+// splitSshUrl splits the SSH URL into the prefix with the user and the host
+// (including the separator after the host) and the path on the host. For
+// example "git@github.com:org/repo" is split into "git@github.com:" and
+// "org/repo".
+func splitSshUrl(url string) (prefix, path string) {
+	if strings.HasPrefix(url, "ssh://") {
+		rest := strings.TrimPrefix(url, "ssh://")
+		i := strings.Index(rest, "/")
+		if i == -1 {
+			return url, ""
+		}
+		return "ssh://" + rest[:i+1], strings.Trim(rest[i+1:], "/")
+	}
+	prefix = scpLikeUrlPattern.FindString(url)
+	return prefix, strings.Trim(url[len(prefix):], "/")
+}
+
+// sshUrlHost returns the host (with the optional port) from the prefix
+// returned by splitSshUrl.
+func sshUrlHost(prefix string) string {
+	host := strings.TrimPrefix(prefix, "ssh://")
+	if i := strings.Index(host, "@"); i != -1 {
+		host = host[i+1:]
+	}
+	return strings.TrimRight(host, ":/")
+}
+
+// sshGetterUrl returns the SSH URL of the repository with the "ssh://"
+// scheme, which is understood by go-getter.
+func sshGetterUrl(repositoryUrl string) string {
+	if strings.HasPrefix(repositoryUrl, "ssh://") {
+		return repositoryUrl
+	}
+	prefix, path := splitSshUrl(repositoryUrl)
+	return "ssh://" + strings.TrimSuffix(prefix, ":") + "/" + path
+}
+
+// gitRemoteUrl returns the URL of the repository passed to the Git
+// commands. The SSH URLs are used as they are, so Git uses the SSH agent
+// and the known_hosts file of the user. The other URLs use HTTPS.
+func gitRemoteUrl(repositoryUrl string) string {
+	if isSshUrl(repositoryUrl) {
+		return repositoryUrl
+	}
+	return "https://" + repositoryUrl
+}
@@ -0,0 +1,80 @@
+package regolith
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestSshUrls checks whether the SSH URLs of the repositories are detected
+// and converted to the URLs used by go-getter and Git, and to the form used
+// by the "allowed_filter_sources" user config property.
+func TestSshUrls(t *testing.T) {
+	tests := []struct {
+		url        string
+		isSsh      bool
+		getterUrl  string
+		remoteUrl  string
+		normalized string
+	}{
+		{"github.com/owner/repo", false, "", "https://github.com/owner/repo",
+			"github.com/owner/repo"},
+		{"git@github.com:owner/repo", true, "ssh://git@github.com/owner/repo",
+			"git@github.com:owner/repo", "github.com/owner/repo"},
+		{"deploy.key@git.example.com:Owner/Repo/",
+			true, "ssh://deploy.key@git.example.com/Owner/Repo",
+			"deploy.key@git.example.com:Owner/Repo/",
+			"git.example.com/owner/repo"},
+		{"ssh://git@example.com:2222/owner/repo", true,
+			"ssh://git@example.com:2222/owner/repo",
+			"ssh://git@example.com:2222/owner/repo",
+			"example.com:2222/owner/repo"},
+	}
+	for _, test := range tests {
+		if actual := isSshUrl(test.url); actual != test.isSsh {
+			t.Errorf(
+				"isSshUrl(%q) = %v, expected %v", test.url, actual, test.isSsh)
+		}
+		if test.isSsh {
+			if actual := sshGetterUrl(test.url); actual != test.getterUrl {
+				t.Errorf(
+					"sshGetterUrl(%q) = %q, expected %q",
+					test.url, actual, test.getterUrl)
+			}
+		}
+		if actual := gitRemoteUrl(test.url); actual != test.remoteUrl {
+			t.Errorf(
+				"gitRemoteUrl(%q) = %q, expected %q",
+				test.url, actual, test.remoteUrl)
+		}
+		if actual := normalizeFilterSource(test.url); actual != test.normalized {
+			t.Errorf(
+				"normalizeFilterSource(%q) = %q, expected %q",
+				test.url, actual, test.normalized)
+		}
+	}
+}
+
+// TestIsSshAuthError checks whether the authentication errors of SSH are
+// detected and aren't treated as the temporary network errors.
+func TestIsSshAuthError(t *testing.T) {
+	tests := []struct {
+		message   string
+		authError bool
+	}{
+		{"git@github.com: Permission denied (publickey).", true},
+		{"Host key verification failed.", true},
+		{"Could not resolve host: github.com", false},
+		{"Repository not found.", false},
+	}
+	for _, test := range tests {
+		err := errors.New(test.message)
+		if actual := isSshAuthError(err); actual != test.authError {
+			t.Errorf(
+				"isSshAuthError(%q) = %v, expected %v",
+				test.message, actual, test.authError)
+		}
+		if test.authError && isNetworkError(err) {
+			t.Errorf("%q is treated as a network error", test.message)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package regolith
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// TmpDir is the directory where Regolith creates the tmp directories of the
+// projects, instead of the .regolith directory. It's set by the global
+// "--tmp-dir" flag. Pointing it at a ramdisk speeds up the builds of large
+// projects.
+var TmpDir string
+
+// tmpDirEnvVar is the name of the environment variable that sets the
+// directory for the tmp directories if the "--tmp-dir" flag is not used.
+const tmpDirEnvVar = "REGOLITH_TMP"
+
+// getTmpDirOverride returns the absolute path to the directory selected with
+// the "--tmp-dir" flag or the REGOLITH_TMP environment variable, or an empty
+// string if the tmp directory is in the dotRegolithPath.
+func getTmpDirOverride() string {
+	override := TmpDir
+	if override == "" {
+		override = os.Getenv(tmpDirEnvVar)
+	}
+	if override == "" {
+		return ""
+	}
+	absOverride, err := filepath.Abs(override)
+	if err != nil {
+		return override
+	}
+	return absOverride
+}
+
+// getTmpRoot returns the path to the directory that contains the tmp
+// directory and the backups of the files moved out of it by the filters, so
+// the files can be moved without copying them. It's the dotRegolithPath,
+// unless the tmp directory is moved to another location. In that case, every
+// project gets its own subdirectory named after the MD5 hash of the path to
+// its dotRegolithPath, so the projects never remove each other's files.
+func getTmpRoot(dotRegolithPath string) string {
+	override := getTmpDirOverride()
+	if override == "" {
+		return dotRegolithPath
+	}
+	absDotRegolithPath, err := filepath.Abs(dotRegolithPath)
+	if err != nil {
+		absDotRegolithPath = dotRegolithPath
+	}
+	hash := md5.Sum([]byte(absDotRegolithPath))
+	return filepath.Join(override, hex.EncodeToString(hash[:]))
+}
+
+// getTmpPath returns the path to the tmp directory where the filters run.
+func getTmpPath(dotRegolithPath string) string {
+	return filepath.Join(getTmpRoot(dotRegolithPath), "tmp")
+}
+
+// checkTmpRoot checks whether the directory selected with the "--tmp-dir"
+// flag or the REGOLITH_TMP environment variable can be used for the tmp
+// directory. The directory is created if it doesn't exist, and it must be
+// writable. If the files can't be hard linked between the directory and the
+// dotRegolithPath, the directory is on another volume. It still works, but
+// the files are copied instead of being moved.
+func checkTmpRoot(dotRegolithPath string) error {
+	if getTmpDirOverride() == "" {
+		return nil
+	}
+	tmpRoot := getTmpRoot(dotRegolithPath)
+	if err := os.MkdirAll(tmpRoot, 0755); err != nil {
+		return burrito.WrapErrorf(err, osMkdirError, tmpRoot)
+	}
+	probe, err := os.CreateTemp(tmpRoot, ".write-test")
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, "The tmp directory is not writable.\nPath: %s", tmpRoot)
+	}
+	probePath := probe.Name()
+	probe.Close()
+	defer os.Remove(probePath)
+	linkPath := filepath.Join(dotRegolithPath, filepath.Base(probePath))
+	if err := os.Link(probePath, linkPath); err != nil {
+		Logger.Debugf(
+			"The tmp directory is on a different volume than the cache of "+
+				"the project. The files will be copied instead of being "+
+				"moved.\nPath: %s", tmpRoot)
+	} else {
+		os.Remove(linkPath)
+	}
+	Logger.Debugf("Using the tmp directory in %q.", tmpRoot)
+	return nil
+}
@@ -0,0 +1,196 @@
+package regolith
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+	"github.com/otiai10/copy"
+)
+
+// tmpDataLinks remembers whether the data folder in the tmp directory of a
+// run may contain hardlinks to the files of the data folder of the project.
+// The nil value means that it's unknown, so the links are always checked.
+type tmpDataLinks struct {
+	linked bool
+}
+
+// mayBeLinked returns true if the data folder in the tmp directory may
+// contain hardlinks. It's safe to call on nil.
+func (l *tmpDataLinks) mayBeLinked() bool {
+	return l == nil || l.linked
+}
+
+// setLinked records whether the data folder in the tmp directory contains
+// hardlinks. It's safe to call on nil, which records nothing.
+func (l *tmpDataLinks) setLinked(linked bool) {
+	if l != nil {
+		l.linked = linked
+	}
+}
+
+// linkDir recreates the src directory at the dst path without copying the
+// content of the files where possible. The files are cloned with reflinks
+// if the filesystem supports them, and hardlinked otherwise. The files that
+// can't be linked (for example because the directories are on different
+// devices) are copied. The files ignored by the .regolithignore file of the
+// src directory are skipped. It returns true if any of the files was
+// hardlinked.
+func linkDir(src, dst string, ignore *regolithIgnore) (bool, error) {
+	hardlinked := false
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return burrito.WrapErrorf(err, osWalkError, src)
+		}
+		if ignored, err := ignore.isIgnoredPath(path, d.IsDir()); err != nil {
+			return burrito.PassError(err)
+		} else if ignored && d.IsDir() {
+			return filepath.SkipDir
+		} else if ignored {
+			return nil
+		}
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return burrito.WrapErrorf(err, filepathRelError, src, path)
+		}
+		target := filepath.Join(dst, relPath)
+		if d.IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return burrito.WrapErrorf(err, osMkdirError, target)
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return burrito.WrapErrorf(err, osStatErrorAny, path)
+		}
+		if info.Mode().IsRegular() {
+			if reflinkFile(path, target, info.Mode().Perm()) == nil {
+				return nil
+			}
+			if os.Link(path, target) == nil {
+				hardlinked = true
+				return nil
+			}
+		}
+		// Symbolic links and the files that can't be linked
+		err = copy.Copy(path, target, copy.Options{PreserveTimes: false, Sync: false})
+		if err != nil {
+			return burrito.WrapErrorf(err, osCopyError, path, target)
+		}
+		return nil
+	})
+	return hardlinked, err
+}
+
+// breakDataLinks replaces the files of the data folder in the tmp directory
+// that are hardlinked to the files of the data folder of the project with
+// their copies. It must be called before running a filter that can modify
+// the data folder, because modifying a hardlinked file would modify the
+// source file as well. The reflinks are not affected, because they are
+// copy-on-write. The links remember whether there is anything to replace.
+func breakDataLinks(
+	dataPath, dotRegolithPath string, links *tmpDataLinks,
+) error {
+	if !links.mayBeLinked() {
+		return nil
+	}
+	tmpDataPath := filepath.Join(getTmpPath(dotRegolithPath), "data")
+	broken := 0
+	err := filepath.WalkDir(tmpDataPath, func(path string, d fs.DirEntry, err error) error {
+		if os.IsNotExist(err) {
+			return nil // The data folder may be removed by a filter
+		} else if err != nil {
+			return burrito.WrapErrorf(err, osWalkError, tmpDataPath)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(tmpDataPath, path)
+		if err != nil {
+			return burrito.WrapErrorf(err, filepathRelError, tmpDataPath, path)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return burrito.WrapErrorf(err, osStatErrorAny, path)
+		}
+		sourcePath := filepath.Join(dataPath, relPath)
+		sourceInfo, err := os.Stat(sourcePath)
+		if err != nil || !os.SameFile(info, sourceInfo) {
+			return nil
+		}
+		err = replaceWithCopy(path, info.Mode().Perm())
+		if err != nil {
+			return burrito.PassError(err)
+		}
+		broken++
+		return nil
+	})
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	if broken != 0 {
+		Logger.Debugf("Replaced %d hardlinked data files with copies", broken)
+	}
+	links.setLinked(false)
+	return nil
+}
+
+// replaceWithCopy replaces the file with a copy of itself. The copy is
+// written next to the file and renamed, so the file is never left
+// incomplete.
+func replaceWithCopy(path string, perm os.FileMode) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return burrito.WrapErrorf(err, osOpenError, path)
+	}
+	defer source.Close()
+	copyPath := path + ".regolith-copy"
+	target, err := os.OpenFile(
+		copyPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return burrito.WrapErrorf(err, osCreateError, copyPath)
+	}
+	_, err = io.Copy(target, source)
+	closeErr := target.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(copyPath)
+		return burrito.WrapErrorf(err, osCopyError, path, copyPath)
+	}
+	source.Close()
+	if err := os.Rename(copyPath, path); err != nil {
+		os.Remove(copyPath)
+		return burrito.WrapErrorf(err, osRenameError, copyPath, path)
+	}
+	return nil
+}
+
+// canWriteData returns true if the filter can modify the files of the data
+// folder in the tmp directory. The data folder is hidden from the filters
+// that don't have it in their "packs" or "outputScope" lists, so they can
+// only write to an empty directory. The filters that don't modify the data
+// folder can opt out of copying its hardlinked files this way.
+func canWriteData(filter FilterRunner) bool {
+	return packsContainData(filter.GetPacks()) &&
+		packsContainData(filter.GetOutputScope())
+}
+
+// packsContainData returns true if the list of the packs from the "packs"
+// or the "outputScope" property of a filter contains the data folder. An
+// empty list contains all of the packs.
+func packsContainData(packs []string) bool {
+	if len(packs) == 0 {
+		return true
+	}
+	for _, pack := range packs {
+		if pack == "data" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package regolith
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCanWriteData checks whether the hardlinks of the data folder are
+// copied only before the filters that can see and modify the data folder.
+func TestCanWriteData(t *testing.T) {
+	tests := []struct {
+		name        string
+		packs       []string
+		outputScope []string
+		expected    bool
+	}{
+		{"no limits", nil, nil, true},
+		{"data in output scope", nil, []string{"BP", "data"}, true},
+		{"data out of output scope", nil, []string{"RP", "BP"}, false},
+		{"data in packs", []string{"data"}, nil, true},
+		{"data out of packs", []string{"RP"}, nil, false},
+		{"data out of packs in scope", []string{"BP"}, []string{"data"}, false},
+	}
+	for _, test := range tests {
+		filter := &ProfileFilter{Filter: Filter{
+			Packs: test.packs, OutputScope: test.outputScope}}
+		if actual := canWriteData(filter); actual != test.expected {
+			t.Errorf(
+				"%s: canWriteData returned %v, expected %v",
+				test.name, actual, test.expected)
+		}
+	}
+}
+
+// TestBreakDataLinks checks whether the hardlinked files of the data folder
+// in the tmp directory are replaced with copies only when the links of the
+// run may contain them.
+func TestBreakDataLinks(t *testing.T) {
+	InitLogging(false)
+	for _, links := range []*tmpDataLinks{nil, {linked: true}, {linked: false}} {
+		dataPath := t.TempDir()
+		dotRegolithPath := t.TempDir()
+		source := filepath.Join(dataPath, "data.txt")
+		if err := os.WriteFile(source, []byte("original"), 0644); err != nil {
+			t.Fatal("Failed to create the data file:", err)
+		}
+		tmpDataPath := filepath.Join(getTmpPath(dotRegolithPath), "data")
+		if err := os.MkdirAll(tmpDataPath, 0755); err != nil {
+			t.Fatal("Failed to create the tmp directory:", err)
+		}
+		target := filepath.Join(tmpDataPath, "data.txt")
+		if err := os.Link(source, target); err != nil {
+			t.Skip("Hardlinks are not supported:", err)
+		}
+		expectLinked := !links.mayBeLinked()
+		err := breakDataLinks(dataPath, dotRegolithPath, links)
+		if err != nil {
+			t.Fatal("Failed to break the links:", err)
+		}
+		sourceInfo, err1 := os.Stat(source)
+		targetInfo, err2 := os.Stat(target)
+		if err := firstErr(err1, err2); err != nil {
+			t.Fatal("Failed to stat the data files:", err)
+		}
+		if linked := os.SameFile(sourceInfo, targetInfo); linked != expectLinked {
+			t.Errorf(
+				"The data file is linked: %v, expected: %v (links: %+v)",
+				linked, expectLinked, links)
+		}
+		if links.mayBeLinked() && links != nil {
+			t.Error("The links weren't marked as broken")
+		}
+	}
+}
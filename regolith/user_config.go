@@ -37,6 +37,21 @@ type UserConfig struct {
 	// Resolvers is a list of URLs to resolvers that Regolith will use to find
 	// filters for the "regolith install" command.
 	Resolvers []string `json:"resolvers,omitempty"`
+
+	// AllowedFilterSources is a list of URL prefixes of the sources from
+	// which Regolith is allowed to download filters. An empty list allows
+	// all sources.
+	AllowedFilterSources []string `json:"allowed_filter_sources,omitempty"`
+
+	// DownloadAttempts is the number of attempts of the network operations
+	// (like downloading filters) that fail because of network errors. It's a
+	// pointer to an integer to allow for the default value to be nil.
+	DownloadAttempts *int `json:"download_attempts,omitempty"`
+
+	// FilterCacheSize is the maximal size of the cached outputs of the
+	// cacheable filters of a project in megabytes. It's a pointer to an
+	// integer to allow for the default value to be nil.
+	FilterCacheSize *int `json:"filter_cache_size,omitempty"`
 }
 
 func NewUserConfig() *UserConfig {
@@ -44,6 +59,9 @@ func NewUserConfig() *UserConfig {
 		UseProjectAppDataStorage: nil,
 		Username:                 nil,
 		Resolvers:                []string{},
+		AllowedFilterSources:     []string{},
+		DownloadAttempts:         nil,
+		FilterCacheSize:          nil,
 	}
 }
 
@@ -53,6 +71,12 @@ func (u *UserConfig) String() string {
 	result += "\n" + extra
 	extra, _ = u.stringPropertyValue("resolvers")
 	result += "\n" + extra
+	extra, _ = u.stringPropertyValue("allowed_filter_sources")
+	result += "\n" + extra
+	extra, _ = u.stringPropertyValue("download_attempts")
+	result += "\n" + extra
+	extra, _ = u.stringPropertyValue("filter_cache_size")
+	result += "\n" + extra
 	return result
 }
 
@@ -81,6 +105,27 @@ func (u *UserConfig) stringPropertyValue(name string) (string, error) {
 			result += fmt.Sprintf("\t- [%v] %s\n", i, resolver)
 		}
 		return result, nil
+	case "allowed_filter_sources":
+		if len(u.AllowedFilterSources) == 0 {
+			return "allowed_filter_sources: []", nil
+		}
+		result := "allowed_filter_sources: \n"
+		for i, source := range u.AllowedFilterSources {
+			result += fmt.Sprintf("\t- [%v] %s\n", i, source)
+		}
+		return result, nil
+	case "download_attempts":
+		value := "null"
+		if u.DownloadAttempts != nil {
+			value = fmt.Sprintf("%v", *u.DownloadAttempts)
+		}
+		return fmt.Sprintf("download_attempts: %v", value), nil
+	case "filter_cache_size":
+		value := "null"
+		if u.FilterCacheSize != nil {
+			value = fmt.Sprintf("%v", *u.FilterCacheSize)
+		}
+		return fmt.Sprintf("filter_cache_size: %v", value), nil
 	}
 	return "", burrito.WrapErrorf(nil, invalidUserConfigPropertyError, name)
 }
@@ -100,6 +145,17 @@ func (u *UserConfig) fillDefaults() {
 		u.Resolvers = []string{}
 	}
 	u.Resolvers = append(u.Resolvers, resolverUrl)
+	if u.AllowedFilterSources == nil {
+		u.AllowedFilterSources = []string{}
+	}
+	if u.DownloadAttempts == nil {
+		u.DownloadAttempts = new(int)
+		*u.DownloadAttempts = defaultDownloadAttempts
+	}
+	if u.FilterCacheSize == nil {
+		u.FilterCacheSize = new(int)
+		*u.FilterCacheSize = defaultFilterCacheSize
+	}
 }
 
 // fillWithFileData fills the user config with the data loaded from a file. If
@@ -11,6 +11,8 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/Bedrock-OSS/go-burrito/burrito"
 	"github.com/nightlyone/lockfile"
@@ -95,44 +97,169 @@ func CreateDirectoryIfNotExists(directory string) error {
 
 // GetAbsoluteWorkingDirectory returns an absolute path to [dotRegolithPath]/tmp
 func GetAbsoluteWorkingDirectory(dotRegolithPath string) string {
-	absoluteWorkingDir, _ := filepath.Abs(filepath.Join(dotRegolithPath, "tmp"))
+	absoluteWorkingDir, _ := filepath.Abs(getTmpPath(dotRegolithPath))
 	return absoluteWorkingDir
 }
 
-// CreateEnvironmentVariables creates an array of environment variables including custom ones
-func CreateEnvironmentVariables(filterDir string) ([]string, error) {
-	projectDir, err := os.Getwd()
+// isolatedEnvAllowlist is a list of the environment variables that are
+// passed to the filters when the environment is isolated. They're required
+// by most of the runtimes to work properly.
+var isolatedEnvAllowlist = []string{
+	"PATH", "PATHEXT", "HOME", "USERPROFILE", "TMP", "TEMP", "TMPDIR",
+	"SYSTEMROOT", "SYSTEMDRIVE", "WINDIR", "COMSPEC", "APPDATA",
+	"LOCALAPPDATA", "LANG",
+}
+
+// CreateEnvironmentVariables creates an array of environment variables including custom ones.
+// The context is nil for the subprocesses that don't run filters (for example
+// when installing the dependencies of the filters).
+func CreateEnvironmentVariables(filterDir string, context *RunContext) ([]string, error) {
+	env, _, err := createEnvironment(filterDir, context)
+	return env, err
+}
+
+// createEnvironment works like CreateEnvironmentVariables but additionally
+// returns the values loaded from the ".env" file, which must be redacted
+// from the logs.
+func createEnvironment(filterDir string, context *RunContext) ([]string, []string, error) {
+	var config *Config
+	if context != nil {
+		config = context.Config
+	}
+	projectDir, err := config.projectDir()
 	if err != nil {
-		return nil, burrito.WrapErrorf(err, osGetwdError)
+		return nil, nil, burrito.PassError(err)
+	}
+	env := os.Environ()
+	if context != nil && context.Options.IsolateEnv {
+		env = isolateEnvironment(env, context.Options.AllowedEnv)
 	}
-	return append(os.Environ(), fmt.Sprintf("FILTER_DIR=%s", filterDir), fmt.Sprintf("ROOT_DIR=%s", projectDir), fmt.Sprintf("DEBUG=%t", burrito.Debug)), nil
+	dotEnv, err := loadContextDotEnv(context, projectDir)
+	if err != nil {
+		return nil, nil, burrito.PassError(err)
+	}
+	env = mergeDotEnv(env, dotEnv)
+	if context != nil {
+		metadata := context.GetProjectMetadata()
+		env = append(
+			env,
+			fmt.Sprintf("REGOLITH_PROJECT_NAME=%s", metadata.Name),
+			fmt.Sprintf("REGOLITH_PROJECT_AUTHOR=%s", metadata.Author))
+		if context.changedFiles != nil {
+			env = append(env, changedFilesEnv(context.changedFiles))
+		}
+	}
+	return append(env, fmt.Sprintf("FILTER_DIR=%s", filterDir), fmt.Sprintf("ROOT_DIR=%s", projectDir), fmt.Sprintf("DEBUG=%t", burrito.Debug)), dotEnvSecrets(dotEnv), nil
+}
+
+// isolateEnvironment returns the variables from the env list (in the
+// "KEY=value" format) whose names are on the isolatedEnvAllowlist or on the
+// allowed list. The names are case-insensitive.
+func isolateEnvironment(env []string, allowed []string) []string {
+	result := []string{}
+	for _, variable := range env {
+		name := strings.SplitN(variable, "=", 2)[0]
+		for _, allowedName := range append(isolatedEnvAllowlist, allowed...) {
+			if strings.EqualFold(name, allowedName) {
+				result = append(result, variable)
+				break
+			}
+		}
+	}
+	return result
 }
 
 // RunSubProcess runs a sub-process with specified arguments and working
-// directory
-func RunSubProcess(command string, args []string, filterDir string, workingDir string, outputLabel string) error {
-	Logger.Debugf("Exec: %s %s", command, strings.Join(args, " "))
+// directory. The context is used for creating the environment variables and
+// should be nil if the sub-process doesn't run a filter.
+func RunSubProcess(context *RunContext, command string, args []string, filterDir string, workingDir string, outputLabel string) error {
+	return runSubProcessWithEnv(context, command, args, filterDir, workingDir, outputLabel, nil)
+}
+
+// runSubProcessWithEnv works like RunSubProcess but additionally sets the
+// environment variables from the extraEnv list (in the "KEY=value" format).
+func runSubProcessWithEnv(context *RunContext, command string, args []string, filterDir string, workingDir string, outputLabel string, extraEnv []string) error {
+	logger := context.logger()
+	logger.Debugf("Exec: %s %s", command, strings.Join(args, " "))
 	cmd := exec.Command(command, args...)
 	cmd.Dir = workingDir
 	out, _ := cmd.StdoutPipe()
 	err, _ := cmd.StderrPipe()
-	go LogStd(out, Logger.Infof, outputLabel)
-	go LogStd(err, Logger.Errorf, outputLabel)
-	env, err1 := CreateEnvironmentVariables(filterDir)
+	env, secrets, err1 := createEnvironment(filterDir, context)
 	if err1 != nil {
 		return burrito.WrapErrorf(
 			err1,
 			"Failed to create FILTER_DIR and ROOT_DIR environment variables.")
 	}
-	cmd.Env = env
+	cmd.Env = append(env, extraEnv...)
 
-	return cmd.Run()
+	var cancellation *runCancellation
+	var log *filterLog
+	var maxMemory uint64
+	if context != nil {
+		cancellation = context.cancellation
+		log = context.filterLog
+		maxMemory = context.maxMemory
+	}
+	finish, err1 := cancellation.startProcess(cmd)
+	if err1 != nil {
+		return err1
+	}
+	defer finish()
+	if maxMemory > 0 {
+		if err1 := limitProcessMemory(cmd.Process.Pid, maxMemory); err1 != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+			return burrito.WrapErrorf(
+				err1, "Failed to limit the memory of the process.\n"+
+					"Memory limit: %s", formatSize(int64(maxMemory)))
+		}
+	}
+	// The output must be read completely before calling Wait, which closes
+	// the pipes
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		logStdRedacted(out, logger.Infof, outputLabel, secrets, func(line string) {
+			log.writeLine(line, false)
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		logStdRedacted(err, logger.Errorf, outputLabel, secrets, func(line string) {
+			log.writeLine(line, true)
+		})
+	}()
+	wg.Wait()
+	if err := cmd.Wait(); err != nil {
+		if maxMemory > 0 {
+			return burrito.WrapErrorf(
+				err, "The process failed while its memory was limited. If it "+
+					"ran out of memory, increase the limit with the "+
+					"\"--max-memory\" flag.\nMemory limit: %s",
+				formatSize(int64(maxMemory)))
+		}
+		return err
+	}
+	return nil
 }
 
 func LogStd(in io.ReadCloser, logFunc func(template string, args ...interface{}), outputLabel string) {
+	logStdRedacted(in, logFunc, outputLabel, nil, nil)
+}
+
+// logStdRedacted works like LogStd but replaces the secrets in the output
+// with asterisks. The redacted lines are also passed to the tee function,
+// which can be nil.
+func logStdRedacted(in io.ReadCloser, logFunc func(template string, args ...interface{}), outputLabel string, secrets []string, tee func(line string)) {
 	scanner := bufio.NewScanner(in)
 	for scanner.Scan() {
-		logFunc("[%s] %s", outputLabel, scanner.Text())
+		line := redactSecrets(scanner.Text(), secrets)
+		logFunc("[%s] %s", outputLabel, line)
+		if tee != nil {
+			tee(line)
+		}
 	}
 }
 
@@ -166,8 +293,9 @@ func getAppDataDotRegolith(silent bool, projectRoot string) (string, error) {
 
 // GetDotRegolith returns the path to the directory where Regolith stores
 // its cached data (like filters, Python venvs, etc.). If user confg setting
-// for using app data by profiles is is set to false it returns relative
-// directory: ".regolith" otherwise it returns path inside the AppData directory.
+// for using app data by profiles is is set to false it returns the
+// ".regolith" directory of the projectRoot (relative, if the projectRoot is
+// "."), otherwise it returns path inside the AppData directory.
 // Based on the hash value of the project's root directory. If the path isn't
 // .regolith it also logs a message which tells where the data is stored
 // unless the silent flag is set to true. The projectRoot path can be relative
@@ -180,33 +308,111 @@ func GetDotRegolith(silent bool, projectRoot string) (string, error) {
 		return "", burrito.WrapError(err, getUserConfigError)
 	}
 	if !*userConfig.UseProjectAppDataStorage {
-		return ".regolith", nil
+		return projectPath(projectRoot, ".regolith"), nil
 	}
 	return getAppDataDotRegolith(silent, projectRoot)
 }
 
+// getSessionLockPath returns the absolute path to the session lock file in
+// the dotRegolithPath directory.
+func getSessionLockPath(dotRegolithPath string) (string, error) {
+	sessionLockPath, err := filepath.Abs(filepath.Join(dotRegolithPath, "session_lock"))
+	if err != nil {
+		return "", burrito.WrapError(err, "Could not get the absolute path to the session_lock file.")
+	}
+	return sessionLockPath, nil
+}
+
+// removeSessionLock removes the session lock file from the dotRegolithPath
+// directory. The lock held by a running process is removed only if the
+// force flag is set. It returns false if there was no lock file to remove.
+func removeSessionLock(dotRegolithPath string, force bool) (bool, error) {
+	sessionLockPath, err := getSessionLockPath(dotRegolithPath)
+	if err != nil {
+		return false, burrito.PassError(err)
+	}
+	if _, err := os.Stat(sessionLockPath); os.IsNotExist(err) {
+		return false, nil
+	}
+	sessionLock, err := lockfile.New(sessionLockPath)
+	if err != nil {
+		return false, burrito.WrapError(err, "Could not open the session_lock file.")
+	}
+	owner, err := sessionLock.GetOwner()
+	switch {
+	case err == nil && force:
+		Logger.Warnf(
+			"The session is locked by a running process (PID %d). Removing "+
+				"the lock anyway.", owner.Pid)
+	case err == nil:
+		return false, burrito.WrappedErrorf(
+			"The session is locked by a running process (PID %d).\n"+
+				"Stop the other instance of Regolith or use the \"--force\" "+
+				"flag to remove the lock anyway.", owner.Pid)
+	case err == lockfile.ErrDeadOwner || err == lockfile.ErrInvalidPid:
+		Logger.Debugf("The session lock is stale: %s", err.Error())
+	case force:
+		Logger.Warnf(
+			"Unable to check the owner of the session lock: %s", err.Error())
+	default:
+		return false, burrito.WrapError(
+			err, "Unable to check the owner of the session lock.\n"+
+				"Use the \"--force\" flag to remove the lock anyway.")
+	}
+	err = os.Remove(sessionLockPath)
+	if err != nil {
+		return false, burrito.WrapErrorf(err, osRemoveError, sessionLockPath)
+	}
+	return true, nil
+}
+
 // AquireSessionLock creates a lock file in specified directory and
 // returns a function that releases the lock.
 // The path should point to the .regolith directory.
-func aquireSessionLock(dotRegolithPath string) (func() error, error) {
+// If the lock is held by another process, the function retries with
+// exponential backoff until the timeout passes. The timeout 0 makes the
+// function fail immediately. Locks of processes that no longer exist are
+// reclaimed automatically.
+func aquireSessionLock(dotRegolithPath string, timeout time.Duration) (func() error, error) {
 	// Create dotRegolithPath if it doesn't exist
 	err := CreateDirectoryIfNotExists(dotRegolithPath)
 	if err != nil {
 		return nil, burrito.WrapErrorf(err, osMkdirError, dotRegolithPath)
 	}
 	// Get the session lock
-	sessionLockPath, err := filepath.Abs(filepath.Join(dotRegolithPath, "session_lock"))
+	sessionLockPath, err := getSessionLockPath(dotRegolithPath)
 	if err != nil {
-		return nil, burrito.WrapError(err, "Could not get the absolute path to the session_lock file.")
+		return nil, burrito.PassError(err)
 	}
 	sessionLock, err := lockfile.New(sessionLockPath)
 	if err != nil {
 		return nil, burrito.WrapError(err, "Could not create session_lock file.")
 	}
-	err = sessionLock.TryLock()
-	if err != nil {
-		return nil, burrito.WrapError(
-			err, "Could not lock the session_lock file. Is another instance of regolith running?")
+	deadline := time.Now().Add(timeout)
+	backoff := 100 * time.Millisecond
+	for {
+		// TryLock reclaims the locks with the PIDs of dead processes
+		err = sessionLock.TryLock()
+		if err == nil {
+			break
+		}
+		// The last wait is shortened to end at the deadline, so the lock is
+		// tried once more right before giving up
+		remaining := time.Until(deadline)
+		temporaryErr, ok := err.(lockfile.TemporaryError)
+		if !ok || !temporaryErr.Temporary() || remaining <= 0 {
+			return nil, burrito.WrapError(
+				err, "Could not lock the session_lock file. Is another instance of regolith running?")
+		}
+		if backoff > remaining {
+			backoff = remaining
+		}
+		Logger.Debugf("The session is locked, retrying in %s.", backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > 2*time.Second {
+			backoff = 2 * time.Second
+		}
 	}
 	unlockFunc := func() error {
 		return sessionLock.Unlock()
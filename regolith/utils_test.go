@@ -0,0 +1,47 @@
+package regolith
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestAquireSessionLockDeadline checks whether the session lock is tried
+// once more at the deadline, when the remaining time is shorter than the
+// next backoff. The lock is held by another process that exits after the
+// last regular retry but before the deadline.
+func TestAquireSessionLockDeadline(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The test uses the \"sleep\" command")
+	}
+	InitLogging(false)
+	dotRegolithPath := t.TempDir()
+	holder := exec.Command("sleep", "10")
+	if err := holder.Start(); err != nil {
+		t.Skip("Unable to start the process holding the lock:", err)
+	}
+	err := os.WriteFile(
+		filepath.Join(dotRegolithPath, "session_lock"),
+		[]byte(fmt.Sprintf("%d\n", holder.Process.Pid)), 0644)
+	if err != nil {
+		t.Fatal("Failed to create the session lock:", err)
+	}
+	// The retries happen after 100 ms and 300 ms, the next backoff would end
+	// after the deadline
+	go func() {
+		time.Sleep(320 * time.Millisecond)
+		holder.Process.Kill()
+		holder.Wait()
+	}()
+	unlock, err := aquireSessionLock(dotRegolithPath, 450*time.Millisecond)
+	if err != nil {
+		t.Fatal("The lock wasn't tried again at the deadline:", err)
+	}
+	if err := unlock(); err != nil {
+		t.Error("Failed to unlock the session:", err)
+	}
+}
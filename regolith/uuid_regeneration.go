@@ -0,0 +1,171 @@
+package regolith
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+	"muzzammil.xyz/jsonc"
+)
+
+// uuidMappingFileName is the name of the file in the .regolith directory that
+// maps the UUIDs from the source manifests to their regenerated versions.
+// The mapping makes the regenerated UUIDs stable between the runs.
+const uuidMappingFileName = "uuid_mapping.json"
+
+// regenerateUuidsEnabled returns whether the UUIDs of the exported packs
+// should be regenerated. The packs in the tmp directory are shared by all of
+// the export targets, so all of them must use the same setting.
+func regenerateUuidsEnabled(targets []ExportTarget) (bool, error) {
+	return sharedExportOption(
+		targets, "regenerateUuids",
+		func(target ExportTarget) bool { return target.RegenerateUuids })
+}
+
+// sharedExportOption returns the value of a boolean property of the export
+// targets that modifies the packs in the tmp directory. The packs are shared
+// by all of the export targets, so the property must have the same value in
+// all of them.
+func sharedExportOption(
+	targets []ExportTarget, property string,
+	value func(target ExportTarget) bool,
+) (bool, error) {
+	if len(targets) == 0 {
+		return false, nil
+	}
+	result := value(targets[0])
+	for _, target := range targets[1:] {
+		if value(target) != result {
+			return false, burrito.WrappedErrorf(
+				"The %q property must have the same value in all of the "+
+					"export targets of the profile.", property)
+		}
+	}
+	return result, nil
+}
+
+// loadUuidMapping loads the UUID mapping from the .regolith directory. If the
+// file doesn't exist, it returns an empty mapping.
+func loadUuidMapping(path string) (map[string]string, error) {
+	result := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, nil
+		}
+		return nil, burrito.WrapErrorf(err, fileReadError, path)
+	}
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		return nil, burrito.WrapErrorf(err, jsonUnmarshalError, path)
+	}
+	return result, nil
+}
+
+// newUuid generates a random (version 4) UUID.
+func newUuid() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", burrito.WrapError(err, "Failed to generate UUID.")
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf(
+		"%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// manifestUuids returns the UUIDs from the header and the modules of the
+// manifest.
+func manifestUuids(manifest map[string]interface{}) []string {
+	var result []string
+	if header, ok := manifest["header"].(map[string]interface{}); ok {
+		if uuid, ok := header["uuid"].(string); ok {
+			result = append(result, uuid)
+		}
+	}
+	modules, _ := manifest["modules"].([]interface{})
+	for _, module := range modules {
+		module, ok := module.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if uuid, ok := module["uuid"].(string); ok {
+			result = append(result, uuid)
+		}
+	}
+	return result
+}
+
+// RegenerateManifestUuids replaces the header and module UUIDs of the
+// manifests of the packs in the tmp directory. The dependencies that point
+// to the replaced UUIDs are updated as well, so the resource pack and the
+// behavior pack stay linked. The new UUIDs are saved in the mapping file in
+// the .regolith directory and reused in the following runs.
+func RegenerateManifestUuids(dotRegolithPath string) error {
+	mappingPath := filepath.Join(dotRegolithPath, uuidMappingFileName)
+	mapping, err := loadUuidMapping(mappingPath)
+	if err != nil {
+		return burrito.WrapError(err, "Failed to load the UUID mapping.")
+	}
+	// The manifests could have been processed already (for example by a
+	// previous "regolith export"), the regenerated UUIDs stay unchanged.
+	regenerated := make(map[string]struct{}, len(mapping))
+	for _, uuid := range mapping {
+		regenerated[uuid] = struct{}{}
+	}
+	// Read the manifests and extend the mapping with their UUIDs
+	manifests := make(map[string]string)
+	for _, pack := range []string{"BP", "RP"} {
+		path := filepath.Join(getTmpPath(dotRegolithPath), pack, "manifest.json")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return burrito.WrapErrorf(err, fileReadError, path)
+		}
+		var manifest map[string]interface{}
+		err = jsonc.Unmarshal(data, &manifest)
+		if err != nil {
+			return burrito.WrapErrorf(err, jsonUnmarshalError, path)
+		}
+		for _, uuid := range manifestUuids(manifest) {
+			if _, ok := regenerated[uuid]; ok {
+				continue
+			}
+			if _, ok := mapping[uuid]; ok {
+				continue
+			}
+			mapping[uuid], err = newUuid()
+			if err != nil {
+				return burrito.PassError(err)
+			}
+		}
+		manifests[path] = string(data)
+	}
+	// Replace the UUIDs. The text of the manifests is edited directly to
+	// keep their formatting.
+	replacements := make([]string, 0, len(mapping)*2)
+	for sourceUuid, regeneratedUuid := range mapping {
+		replacements = append(
+			replacements, "\""+sourceUuid+"\"", "\""+regeneratedUuid+"\"")
+	}
+	replacer := strings.NewReplacer(replacements...)
+	for path, data := range manifests {
+		err = os.WriteFile(path, []byte(replacer.Replace(data)), 0644)
+		if err != nil {
+			return burrito.WrapErrorf(err, fileWriteError, path)
+		}
+	}
+	data, _ := json.MarshalIndent(mapping, "", "\t") // no error
+	err = os.WriteFile(mappingPath, data, 0644)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, mappingPath)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Functions used for checking whether the filters installed in the cache
+// match the filter definitions from the config file.
+package regolith
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// Kinds of problems found by checkFilterCache
+const (
+	// The filter is defined in the config but it's not in the cache
+	filterCacheMissing = "missing"
+	// The filter is in the cache but with a different version than required
+	filterCacheOutdated = "outdated"
+	// The cache has a filter that is not defined in the config
+	filterCacheOrphaned = "orphaned"
+)
+
+// filterCacheProblem describes a single inconsistency between the filter
+// definitions from the config file and the filters installed in the cache.
+type filterCacheProblem struct {
+	// Filter is the name of the filter
+	Filter string
+	// Kind is one of filterCacheMissing, filterCacheOutdated or
+	// filterCacheOrphaned
+	Kind string
+	// Details is a human-readable description of the problem
+	Details string
+}
+
+func (p filterCacheProblem) String() string {
+	return fmt.Sprintf("%s (%s): %s", p.Filter, p.Kind, p.Details)
+}
+
+// checkFilterCache compares the remote filters from the filterDefinitions
+// map with the filters installed in the cache and returns the list of the
+// problems sorted by the names of the filters. The lockFile is optional, if
+// it's not nil, the "HEAD" and "latest" filters are compared against the
+// versions pinned in it. The function doesn't modify anything.
+func checkFilterCache(
+	filterDefinitions map[string]FilterInstaller, lockFile *LockFile,
+	dotRegolithPath string,
+) ([]filterCacheProblem, error) {
+	result := []filterCacheProblem{}
+	for name, filterDefinition := range filterDefinitions {
+		remoteFilter, ok := filterDefinition.(*RemoteFilterDefinition)
+		if !ok {
+			continue // Only the remote filters are stored in the cache
+		}
+		downloadPath := remoteFilter.GetDownloadPath(dotRegolithPath)
+		if _, err := os.Stat(downloadPath); err != nil {
+			if !os.IsNotExist(err) {
+				return nil, burrito.WrapErrorf(err, osStatErrorAny, downloadPath)
+			}
+			result = append(result, filterCacheProblem{
+				Filter:  name,
+				Kind:    filterCacheMissing,
+				Details: fmt.Sprintf("%q doesn't exist", downloadPath),
+			})
+			continue
+		}
+		if remoteFilter.isLocalRegistry() {
+			continue // Linked filters are always up to date
+		}
+		installedVersion, err := remoteFilter.InstalledVersion(dotRegolithPath)
+		if err != nil {
+			result = append(result, filterCacheProblem{
+				Filter:  name,
+				Kind:    filterCacheOutdated,
+				Details: "unable to read the installed version",
+			})
+			continue
+		}
+		installedVersion = trimFilterPrefix(
+			installedVersion, remoteFilter.remoteName())
+		if !remoteFilter.matchesVersion(installedVersion, lockFile) {
+			result = append(result, filterCacheProblem{
+				Filter: name,
+				Kind:   filterCacheOutdated,
+				Details: fmt.Sprintf(
+					"installed version %q, required version %q",
+					installedVersion, remoteFilter.Version),
+			})
+		}
+	}
+	// Find the filters in cache that aren't defined in the config
+	filtersPath := filepath.Join(dotRegolithPath, "cache/filters")
+	entries, err := os.ReadDir(filtersPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, burrito.WrapErrorf(err, osStatErrorAny, filtersPath)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		filterDefinition, ok := filterDefinitions[entry.Name()]
+		if ok {
+			if _, ok := filterDefinition.(*RemoteFilterDefinition); ok {
+				continue
+			}
+		}
+		result = append(result, filterCacheProblem{
+			Filter: entry.Name(),
+			Kind:   filterCacheOrphaned,
+			Details: fmt.Sprintf(
+				"%q is not a remote filter from the config",
+				filepath.Join(filtersPath, entry.Name())),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Filter < result[j].Filter
+	})
+	return result, nil
+}
+
+// matchesVersion returns true if the installedVersion satisfies the version
+// of the filter definition. The "HEAD" and "latest" versions are compared
+// with the versions from the lock file if possible, otherwise they match any
+// installed version.
+func (f *RemoteFilterDefinition) matchesVersion(
+	installedVersion string, lockFile *LockFile,
+) bool {
+	if f.Version != "HEAD" && f.Version != "latest" {
+		return f.Version == installedVersion
+	}
+	if lockFile == nil {
+		return true
+	}
+	lockedFilter, ok := lockFile.Filters[f.Id]
+	if !ok || lockedFilter.Url != f.Url {
+		return true
+	}
+	return installedVersion == lockedFilter.Version ||
+		installedVersion == lockedFilter.Sha
+}
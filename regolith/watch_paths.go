@@ -0,0 +1,209 @@
+// Functions used for selecting the directories watched by "regolith watch"
+// with the "watchPaths" and "ignorePaths" settings.
+package regolith
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// watchedDir is a directory watched for changes by "regolith watch".
+type watchedDir struct {
+	// path is the path to the directory
+	path string
+
+	// recursive is true if the changes in the subdirectories of the
+	// directory are also reported
+	recursive bool
+
+	// source is the interruption message sent when the directory changes
+	// ("rp", "bp" or "data")
+	source string
+}
+
+// maxLoggedChangedPaths is the maximal number of the changed files listed in
+// the log when the watched files change.
+const maxLoggedChangedPaths = 10
+
+// watchInterruption is the message sent to the interruption channel when the
+// watched files change.
+type watchInterruption struct {
+	// source is the source of the change ("rp", "bp" or "data")
+	source string
+
+	// paths are the paths to the changed files. The list can be empty if the
+	// watcher lost track of the changes.
+	paths []string
+}
+
+// log prints the list of the changed files that caused the interruption.
+// Every file is listed once, and only the first maxLoggedChangedPaths of
+// them are printed.
+func (i watchInterruption) log() {
+	paths := []string{}
+	seen := make(map[string]bool, len(i.paths))
+	for _, p := range i.paths {
+		if !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		Logger.Infof("Change detected in: %s", i.source)
+		return
+	}
+	more := ""
+	if len(paths) > maxLoggedChangedPaths {
+		more = fmt.Sprintf(" (and %d more)", len(paths)-maxLoggedChangedPaths)
+		paths = paths[:maxLoggedChangedPaths]
+	}
+	Logger.Infof("Change detected in: %s%s", strings.Join(paths, ", "), more)
+}
+
+// stringListFromObject returns the value of the property of the object as a
+// list of strings. An empty list is returned if the property doesn't exist.
+func stringListFromObject(
+	obj map[string]interface{}, property string,
+) ([]string, error) {
+	value, ok := obj[property]
+	if !ok {
+		return []string{}, nil
+	}
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, burrito.WrappedErrorf(
+			jsonPropertyTypeError, property, "array")
+	}
+	result := make([]string, 0, len(list))
+	for i, item := range list {
+		item, ok := item.(string)
+		if !ok {
+			return nil, burrito.WrappedErrorf(
+				jsonPropertyTypeError, fmt.Sprintf("%s->%d", property, i),
+				"string")
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+// resolveWatchedDirs returns the list of the directories that should be
+// watched for changes. By default, the RP, BP and data folders are watched.
+// The watchPaths list can limit the watcher to some of their
+// subdirectories. The directories matching the glob patterns from the
+// ignorePaths list are not watched at all, so the directories that contain
+// them are watched without their subdirectories, and their other
+// subdirectories are watched separately.
+func resolveWatchedDirs(
+	config *Config, watchPaths, ignorePaths []string,
+) ([]watchedDir, error) {
+	for _, pattern := range ignorePaths {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, burrito.WrapErrorf(
+				err, "Invalid ignored path pattern.\nPattern: %s", pattern)
+		}
+	}
+	sources := []watchedDir{
+		{path: config.ResourceFolder, recursive: true, source: "rp"},
+		{path: config.BehaviorFolder, recursive: true, source: "bp"},
+		{path: config.DataPath, recursive: true, source: "data"},
+	}
+	roots := sources
+	if len(watchPaths) != 0 {
+		roots = make([]watchedDir, 0, len(watchPaths))
+		for _, watchPath := range watchPaths {
+			found := false
+			for _, source := range sources {
+				if isSameOrNestedPath(watchPath, source.path) {
+					roots = append(roots, watchedDir{
+						path: watchPath, recursive: true,
+						source: source.source})
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, burrito.WrappedErrorf(
+					"The watched path must be inside of the resource pack, "+
+						"the behavior pack or the data folder.\nPath: %s",
+					watchPath)
+			}
+			if _, err := os.Stat(watchPath); err != nil {
+				return nil, burrito.WrapErrorf(err, osStatErrorAny, watchPath)
+			}
+		}
+	}
+	result := []watchedDir{}
+	for _, root := range roots {
+		dirs, _, err := splitIgnoredDirs(root.path, root.source, ignorePaths)
+		if err != nil {
+			return nil, burrito.PassError(err)
+		}
+		result = append(result, dirs...)
+	}
+	return result, nil
+}
+
+// splitIgnoredDirs returns the directories that should be watched to
+// report the changes in the dir directory and its subdirectories, without
+// watching the ignored directories. The second returned value is true if
+// the dir directory is ignored or contains ignored directories.
+func splitIgnoredDirs(
+	dir, source string, ignorePaths []string,
+) ([]watchedDir, bool, error) {
+	if isIgnoredWatchPath(dir, ignorePaths) {
+		return nil, true, nil
+	}
+	if len(ignorePaths) == 0 {
+		return []watchedDir{{path: dir, recursive: true, source: source}},
+			false, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, false, burrito.WrapErrorf(err, osReadDirError, dir)
+	}
+	subdirs := []watchedDir{}
+	hasIgnored := false
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dirs, ignored, err := splitIgnoredDirs(
+			filepath.Join(dir, entry.Name()), source, ignorePaths)
+		if err != nil {
+			return nil, false, burrito.PassError(err)
+		}
+		subdirs = append(subdirs, dirs...)
+		hasIgnored = hasIgnored || ignored
+	}
+	if !hasIgnored {
+		return []watchedDir{{path: dir, recursive: true, source: source}},
+			false, nil
+	}
+	result := []watchedDir{{path: dir, recursive: false, source: source}}
+	return append(result, subdirs...), true, nil
+}
+
+// isIgnoredWatchPath returns true if the path matches any of the glob
+// patterns. The patterns are matched against the path with forward
+// slashes. Patterns without slashes are also matched against the name of
+// the directory.
+func isIgnoredWatchPath(dir string, ignorePaths []string) bool {
+	slashPath := filepath.ToSlash(filepath.Clean(dir))
+	for _, pattern := range ignorePaths {
+		if ok, _ := path.Match(pattern, slashPath); ok {
+			return true
+		}
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(slashPath)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+package regolith
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestIsIgnoredWatchPath checks whether the glob patterns are matched
+// against the whole path and the patterns without slashes against the name
+// of the directory.
+func TestIsIgnoredWatchPath(t *testing.T) {
+	tests := []struct {
+		dir      string
+		patterns []string
+		expected bool
+	}{
+		{"packs/RP/textures", nil, false},
+		{"packs/RP/textures", []string{"textures"}, true},
+		{"./packs/RP/textures/", []string{"textures"}, true},
+		{"packs/RP/textures", []string{"tex*"}, true},
+		{"packs/RP/textures", []string{"packs/RP/*"}, true},
+		{"packs/BP/textures", []string{"packs/RP/*"}, false},
+		{"packs/RP/textures/blocks", []string{"packs/RP/*"}, false},
+		{"packs/RP/sounds", []string{"textures", "models"}, false},
+	}
+	for _, test := range tests {
+		actual := isIgnoredWatchPath(filepath.FromSlash(test.dir), test.patterns)
+		if actual != test.expected {
+			t.Errorf(
+				"isIgnoredWatchPath(%q, %v) returned %v, expected %v",
+				test.dir, test.patterns, actual, test.expected)
+		}
+	}
+}
+
+// TestStringListFromObject checks whether the "watchPaths" and
+// "ignorePaths" properties of the config are parsed as lists of strings.
+func TestStringListFromObject(t *testing.T) {
+	obj := map[string]interface{}{
+		"list":    []interface{}{"a", "b"},
+		"string":  "a",
+		"numbers": []interface{}{"a", 1.0},
+	}
+	list, err := stringListFromObject(obj, "list")
+	if err != nil || !reflect.DeepEqual(list, []string{"a", "b"}) {
+		t.Errorf("Unexpected list: %v (error: %v)", list, err)
+	}
+	list, err = stringListFromObject(obj, "missing")
+	if err != nil || list == nil || len(list) != 0 {
+		t.Errorf("Unexpected list of missing property: %v (error: %v)", list, err)
+	}
+	for _, property := range []string{"string", "numbers"} {
+		if _, err := stringListFromObject(obj, property); err == nil {
+			t.Errorf("Property %q was parsed as a list of strings", property)
+		}
+	}
+}
+
+// TestResolveWatchedDirs checks which directories are watched with the
+// "watchPaths" and "ignorePaths" settings.
+func TestResolveWatchedDirs(t *testing.T) {
+	InitLogging(false)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	os.Chdir(t.TempDir())
+	for _, dir := range []string{
+		"packs/RP/textures/blocks", "packs/RP/models",
+		"packs/BP/entities", "packs/data"} {
+		if err := os.MkdirAll(filepath.FromSlash(dir), 0755); err != nil {
+			t.Fatal("Failed to create the project directories:", err)
+		}
+	}
+	config := &Config{
+		Packs: Packs{
+			ResourceFolder: filepath.FromSlash("packs/RP"),
+			BehaviorFolder: filepath.FromSlash("packs/BP"),
+		},
+		RegolithProject: RegolithProject{DataPath: filepath.FromSlash("packs/data")},
+	}
+	dir := func(path string, recursive bool, source string) watchedDir {
+		return watchedDir{
+			path: filepath.FromSlash(path), recursive: recursive,
+			source: source}
+	}
+	tests := []struct {
+		name        string
+		watchPaths  []string
+		ignorePaths []string
+		expected    []watchedDir
+	}{
+		{
+			name: "default",
+			expected: []watchedDir{
+				dir("packs/RP", true, "rp"),
+				dir("packs/BP", true, "bp"),
+				dir("packs/data", true, "data"),
+			},
+		},
+		{
+			name:       "watch paths",
+			watchPaths: []string{"packs/RP/textures", "packs/data"},
+			expected: []watchedDir{
+				dir("packs/RP/textures", true, "rp"),
+				dir("packs/data", true, "data"),
+			},
+		},
+		{
+			name:        "ignored directory",
+			ignorePaths: []string{"blocks"},
+			expected: []watchedDir{
+				dir("packs/RP", false, "rp"),
+				dir("packs/RP/models", true, "rp"),
+				dir("packs/RP/textures", false, "rp"),
+				dir("packs/BP", true, "bp"),
+				dir("packs/data", true, "data"),
+			},
+		},
+		{
+			name:        "ignored watch path",
+			watchPaths:  []string{"packs/BP"},
+			ignorePaths: []string{"packs/BP"},
+			expected:    []watchedDir{},
+		},
+	}
+	for _, test := range tests {
+		actual, err := resolveWatchedDirs(
+			config, test.watchPaths, test.ignorePaths)
+		if err != nil {
+			t.Errorf("%s: failed to resolve the directories: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf(
+				"%s: unexpected directories.\nExpected: %+v\nActual: %+v",
+				test.name, test.expected, actual)
+		}
+	}
+	for _, test := range []struct {
+		name        string
+		watchPaths  []string
+		ignorePaths []string
+	}{
+		{"outside of the packs", []string{"packs"}, nil},
+		{"missing watch path", []string{"packs/RP/sounds"}, nil},
+		{"invalid pattern", nil, []string{"["}},
+	} {
+		_, err := resolveWatchedDirs(config, test.watchPaths, test.ignorePaths)
+		if err == nil {
+			t.Errorf("%s: the directories were resolved", test.name)
+		}
+	}
+}
+
+// TestWatchInterruptionLog checks whether the changed files that trigger a
+// rebuild are logged once, and whether the long lists are shortened.
+func TestWatchInterruptionLog(t *testing.T) {
+	InitLogging(false)
+	logger := Logger
+	defer func() { Logger = logger }()
+	manyPaths := []string{}
+	for i := 0; i < maxLoggedChangedPaths+2; i++ {
+		manyPaths = append(manyPaths, fmt.Sprintf("BP/file%d.json", i))
+	}
+	tests := []struct {
+		name     string
+		paths    []string
+		expected string
+	}{
+		{"unknown files", nil, "Change detected in: bp"},
+		{
+			"duplicated files",
+			[]string{"BP/a.json", "BP/b.json", "BP/a.json"},
+			"Change detected in: BP/a.json, BP/b.json",
+		},
+		{
+			"many files",
+			manyPaths,
+			"Change detected in: BP/file0.json, BP/file1.json, " +
+				"BP/file2.json, BP/file3.json, BP/file4.json, BP/file5.json, " +
+				"BP/file6.json, BP/file7.json, BP/file8.json, BP/file9.json " +
+				"(and 2 more)",
+		},
+	}
+	for _, test := range tests {
+		core, logs := observer.New(zap.InfoLevel)
+		Logger = zap.New(core).Sugar()
+		watchInterruption{source: "bp", paths: test.paths}.log()
+		entries := logs.All()
+		if len(entries) != 1 || entries[0].Message != test.expected {
+			t.Errorf(
+				"%s: unexpected log.\nExpected: %q\nActual: %v",
+				test.name, test.expected, entries)
+		}
+	}
+}
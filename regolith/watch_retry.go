@@ -0,0 +1,79 @@
+package regolith
+
+import (
+	"errors"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// watchRetryAttempts is the number of attempts of a file operation in the
+// watch mode that fails because another process holds one of its files.
+const watchRetryAttempts = 3
+
+// watchRetryDelay is the delay between the attempts of a file operation in
+// the watch mode.
+var watchRetryDelay = 500 * time.Millisecond
+
+// The Windows error codes of the file operations on the files opened by
+// other processes.
+const (
+	errorSharingViolation syscall.Errno = 32 // ERROR_SHARING_VIOLATION
+	errorLockViolation    syscall.Errno = 33 // ERROR_LOCK_VIOLATION
+)
+
+// fileLockErrorMessages are the fragments of the messages (in lower case)
+// of the Windows errors from errorSharingViolation and errorLockViolation.
+// They're used for the errors that were already converted to text.
+var fileLockErrorMessages = []string{
+	"because it is being used by another process",
+	"because another process has locked a portion of the file",
+}
+
+// isFileLockError returns true if the error was caused by a file that was
+// held by another process, for example an antivirus or an editor. Only
+// Windows prevents modifying the files opened by other processes, so on the
+// other systems it always returns false.
+func isFileLockError(err error) bool {
+	return runtime.GOOS == "windows" && isWindowsFileLockError(err)
+}
+
+// isWindowsFileLockError returns true if the error is the Windows sharing
+// violation or lock violation error.
+func isWindowsFileLockError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		return errno == errorSharingViolation || errno == errorLockViolation
+	}
+	message := strings.ToLower(err.Error())
+	for _, pattern := range fileLockErrorMessages {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryFileOperation runs the file operation. If retry is true, the
+// operation is retried a few times after a short delay when it fails
+// because of a file held by another process. The operation must be safe to
+// run again after a failure. The other errors are returned immediately.
+// The description is used in the warnings about the failed attempts.
+func retryFileOperation(retry bool, description string, operation func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := operation()
+		if err == nil || !retry || !isFileLockError(err) ||
+			attempt >= watchRetryAttempts {
+			return err
+		}
+		Logger.Warnf(
+			"Failed to %s because a file is used by another process "+
+				"(attempt %d of %d). Retrying in %s...",
+			description, attempt, watchRetryAttempts, watchRetryDelay)
+		time.Sleep(watchRetryDelay)
+	}
+}
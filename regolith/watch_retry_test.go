@@ -0,0 +1,47 @@
+package regolith
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+)
+
+// TestIsWindowsFileLockError checks whether only the Windows sharing
+// violation and lock violation errors are detected as the errors of the
+// files held by other processes.
+func TestIsWindowsFileLockError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"no error", nil, false},
+		{"sharing violation",
+			&os.PathError{Op: "remove", Path: "a", Err: errorSharingViolation},
+			true},
+		{"lock violation",
+			&os.PathError{Op: "open", Path: "a", Err: errorLockViolation},
+			true},
+		{"other error code",
+			&os.PathError{Op: "remove", Path: "a", Err: syscall.Errno(5)},
+			false},
+		{"sharing violation converted to text", errors.New(
+			"Failed to remove the file.\nremove a: The process cannot " +
+				"access the file because it is being used by another process."),
+			true},
+		{"lock violation converted to text", errors.New(
+			"open a: The process cannot access the file because another " +
+				"process has locked a portion of the file."),
+			true},
+		{"permission denied", errors.New("remove a: permission denied"), false},
+		{"access denied", errors.New("remove a: Access is denied."), false},
+	}
+	for _, test := range tests {
+		if actual := isWindowsFileLockError(test.err); actual != test.expected {
+			t.Errorf(
+				"%s: isWindowsFileLockError returned %v, expected %v",
+				test.name, actual, test.expected)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package regolith
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestPrepareWatchSessionInitialClean checks whether the InitialClean option
+// of "regolith watch" removes the tmp directory, the cached outputs of the
+// filters and the backups of the scopes left by the previous runs, and
+// whether they're kept without it.
+func TestPrepareWatchSessionInitialClean(t *testing.T) {
+	InitLogging(false)
+	for _, initialClean := range []bool{false, true} {
+		dotRegolithPath := t.TempDir()
+		paths := []string{
+			filepath.Join(dotRegolithPath, "tmp", "BP", "stale.txt"),
+			filepath.Join(dotRegolithPath, filterCacheDir, "filter", "out"),
+			filepath.Join(dotRegolithPath, ".scopeBackup-1", "data", "x"),
+			filepath.Join(dotRegolithPath, filterRetryBackupName, "RP", "x"),
+		}
+		for _, path := range paths {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal("Failed to create the directory:", err)
+			}
+			if err := os.WriteFile(path, []byte("stale"), 0644); err != nil {
+				t.Fatal("Failed to create the file:", err)
+			}
+		}
+		err := prepareWatchSession(
+			".", RunOptions{InitialClean: initialClean}, dotRegolithPath)
+		if err != nil {
+			t.Fatal("Failed to prepare the watch session:", err)
+		}
+		for _, path := range paths {
+			_, err := os.Stat(path)
+			if initialClean && !os.IsNotExist(err) {
+				t.Errorf("The file wasn't removed by the initial clean: %s", path)
+			} else if !initialClean && err != nil {
+				t.Errorf("The file was removed without the initial clean: %s", path)
+			}
+		}
+	}
+}
+
+// TestAwaitInitialRun checks whether the watch session waits for the first
+// change of the files only with the SkipInitialRun option.
+func TestAwaitInitialRun(t *testing.T) {
+	InitLogging(false)
+	context := RunContext{Options: RunOptions{SkipInitialRun: false}}
+	// Returns without the interruption channel
+	awaitInitialRun(&context)
+
+	context = RunContext{
+		Options:             RunOptions{SkipInitialRun: true},
+		interruptionChannel: make(chan watchInterruption),
+	}
+	done := make(chan struct{})
+	go func() {
+		awaitInitialRun(&context)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("The initial run didn't wait for the changes")
+	case <-time.After(100 * time.Millisecond):
+	}
+	context.interruptionChannel <- watchInterruption{
+		source: "bp", paths: []string{"BP/entity.json"}}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The initial run didn't start after the change")
+	}
+}
@@ -0,0 +1,120 @@
+package regolith
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+)
+
+// The values of the "type" property of the output of "regolith which".
+const (
+	remoteFilterLocation        = "remote"
+	localRegistryFilterLocation = "local-registry"
+	localFilterLocation         = "local"
+)
+
+// filterLocation describes where a filter from the filter definitions list
+// is installed. It's the output of the "regolith which" command.
+type filterLocation struct {
+	// Filter is the name of the filter.
+	Filter string `json:"filter"`
+
+	// Type is "remote", "local-registry" or "local". The local filters are
+	// not installed, they run directly from the files of the project.
+	Type string `json:"type"`
+
+	// Url is the URL of the repository of a remote filter.
+	Url string `json:"url,omitempty"`
+
+	// Path is the absolute path to the installed remote filter.
+	Path string `json:"path,omitempty"`
+
+	// Version is the version of the filter pinned in the config file.
+	Version string `json:"version,omitempty"`
+
+	// Installed is true if the filter is installed in the filter cache.
+	Installed bool `json:"installed"`
+
+	// InstalledVersion is the version from the "filter.json" file of the
+	// installed filter.
+	InstalledVersion string `json:"installedVersion,omitempty"`
+
+	// Sha is the SHA of the commit used to install the filter, from the
+	// lock file.
+	Sha string `json:"sha,omitempty"`
+}
+
+// newFilterLocation finds the installation of the filter from the filter
+// definition.
+func newFilterLocation(
+	name string, filterDefinition FilterInstaller, lockFile *LockFile,
+	dotRegolithPath string,
+) (filterLocation, error) {
+	result := filterLocation{Filter: name, Type: localFilterLocation}
+	remoteFilter, ok := filterDefinition.(*RemoteFilterDefinition)
+	if !ok {
+		return result, nil
+	}
+	result.Type = remoteFilterLocation
+	if remoteFilter.isLocalRegistry() {
+		result.Type = localRegistryFilterLocation
+	}
+	result.Url = remoteFilter.Url
+	result.Version = remoteFilter.Version
+	downloadPath := remoteFilter.GetDownloadPath(dotRegolithPath)
+	path, err := filepath.Abs(downloadPath)
+	if err != nil {
+		return result, burrito.WrapErrorf(err, filepathAbsError, downloadPath)
+	}
+	result.Path = path
+	installedVersion, err := remoteFilter.InstalledVersion(dotRegolithPath)
+	if err == nil {
+		result.Installed = true
+		result.InstalledVersion = installedVersion
+	}
+	if lockFile != nil {
+		if lockedFilter, ok := lockFile.Filters[name]; ok {
+			result.Sha = lockedFilter.Sha
+		}
+	}
+	return result, nil
+}
+
+// String returns a human readable description of the filter location.
+func (l filterLocation) String() string {
+	lines := []string{
+		fmt.Sprintf("Filter: %s", l.Filter),
+		fmt.Sprintf("Type: %s", l.Type),
+	}
+	if l.Type == localFilterLocation {
+		return strings.Join(lines, "\n")
+	}
+	optional := func(value string) string {
+		if value == "" {
+			return "unknown"
+		}
+		return value
+	}
+	if l.Url != "" {
+		lines = append(lines, fmt.Sprintf("URL: %s", l.Url))
+	}
+	lines = append(lines,
+		fmt.Sprintf("Path: %s", l.Path),
+		fmt.Sprintf("Pinned version: %s", optional(l.Version)))
+	if !l.Installed {
+		return strings.Join(append(lines, "Installed: no"), "\n")
+	}
+	lines = append(lines,
+		fmt.Sprintf("Installed version: %s", optional(l.InstalledVersion)),
+		fmt.Sprintf("SHA: %s", optional(l.Sha)))
+	return strings.Join(lines, "\n")
+}
+
+// Json returns the filter location as indented JSON.
+func (l filterLocation) Json() string {
+	data, _ := json.MarshalIndent(l, "", "\t") // no error
+	return string(data)
+}
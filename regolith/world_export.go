@@ -0,0 +1,184 @@
+// Functions used by the "mcworld" and "mctemplate" export targets, which
+// bundle the packs into a copy of a world and save it as an archive.
+package regolith
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/Bedrock-OSS/go-burrito/burrito"
+	"github.com/otiai10/copy"
+	"muzzammil.xyz/jsonc"
+)
+
+// The names of the export targets that export the packs inside of a world.
+const (
+	mcworldExportTarget    = "mcworld"
+	mctemplateExportTarget = "mctemplate"
+)
+
+// isWorldArchiveExportTarget returns true if the export target bundles the
+// packs into a world archive.
+func isWorldArchiveExportTarget(target string) bool {
+	return target == mcworldExportTarget || target == mctemplateExportTarget
+}
+
+// worldArchivePath returns the path to the archive created by the
+// "mcworld" or "mctemplate" export target.
+func worldArchivePath(exportTarget ExportTarget, name string) (string, error) {
+	if exportTarget.WorldPath == "" {
+		return "", burrito.WrappedErrorf(
+			"The %q export target requires the \"worldPath\" property.",
+			exportTarget.Target)
+	}
+	if exportTarget.Path != "" {
+		return exportTarget.Path, nil
+	}
+	return filepath.Join("build", name+"."+exportTarget.Target), nil
+}
+
+// worldPack describes a pack added to the world by the world archive export
+// targets.
+type worldPack struct {
+	// source is the path to the pack in the tmp directory
+	source string
+	// packsDir is the name of the directory of the world with the packs
+	packsDir string
+	// referencesFile is the name of the file of the world that lists the
+	// packs applied to the world
+	referencesFile string
+	// suffix is the suffix of the name of the pack directory
+	suffix string
+}
+
+// exportWorldArchive copies the world from the "worldPath" of the export
+// target to a temporary directory, adds the packs from the tmp directory to
+// it, updates the references to the packs in the world and writes the
+// result into the archive at the target path. The world from the
+// "worldPath" is never modified.
+func exportWorldArchive(
+	exportTarget ExportTarget, name, target, dotRegolithPath string,
+) error {
+	if exportTarget.Target == mctemplateExportTarget {
+		manifestPath := filepath.Join(exportTarget.WorldPath, "manifest.json")
+		if _, err := os.Stat(manifestPath); err != nil {
+			return burrito.WrapErrorf(
+				err, "The world template must have a \"manifest.json\" file.\n"+
+					"Path: %s", manifestPath)
+		}
+	}
+	stagingPath, err := os.MkdirTemp(dotRegolithPath, "world-export-")
+	if err != nil {
+		return burrito.WrapErrorf(err, osMkdirError, dotRegolithPath)
+	}
+	defer os.RemoveAll(stagingPath)
+	err = copy.Copy(
+		exportTarget.WorldPath, stagingPath,
+		copy.Options{PreserveTimes: false, Sync: false})
+	if err != nil {
+		return burrito.WrapErrorf(
+			err, osCopyError, exportTarget.WorldPath, stagingPath)
+	}
+	packs := []worldPack{
+		{
+			source:         filepath.Join(getTmpPath(dotRegolithPath), "BP"),
+			packsDir:       "behavior_packs",
+			referencesFile: "world_behavior_packs.json",
+			suffix:         "_bp",
+		},
+		{
+			source:         filepath.Join(getTmpPath(dotRegolithPath), "RP"),
+			packsDir:       "resource_packs",
+			referencesFile: "world_resource_packs.json",
+			suffix:         "_rp",
+		},
+	}
+	for _, pack := range packs {
+		err = addPackToWorld(pack, name, stagingPath)
+		if err != nil {
+			return burrito.PassError(err)
+		}
+	}
+	err = writeZipArchive(stagingPath, target, exportTarget.CompressionLevel)
+	if err != nil {
+		return burrito.PassError(err)
+	}
+	return nil
+}
+
+// addPackToWorld copies the pack into the world and adds the reference to
+// the pack to the list of the packs applied to the world. The references
+// to the other packs are kept. The packs without a manifest are skipped.
+func addPackToWorld(pack worldPack, name, worldPath string) error {
+	manifestPath := filepath.Join(pack.source, "manifest.json")
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			Logger.Warnf(
+				"The pack has no manifest, it won't be added to the world."+
+					"\nPath: %s", pack.source)
+			return nil
+		}
+		return burrito.WrapErrorf(err, fileReadError, manifestPath)
+	}
+	var manifest map[string]interface{}
+	err = jsonc.Unmarshal(data, &manifest)
+	if err != nil {
+		return burrito.WrapErrorf(err, jsonUnmarshalError, manifestPath)
+	}
+	header, _ := manifest["header"].(map[string]interface{})
+	uuid, ok := header["uuid"].(string)
+	if !ok {
+		return burrito.WrapErrorf(
+			burrito.WrappedErrorf(jsonPathMissingError, "header->uuid"),
+			jsonPathParseError, manifestPath)
+	}
+	version, ok := header["version"]
+	if !ok {
+		return burrito.WrapErrorf(
+			burrito.WrappedErrorf(jsonPathMissingError, "header->version"),
+			jsonPathParseError, manifestPath)
+	}
+	// Copy the pack
+	packPath := filepath.Join(worldPath, pack.packsDir, name+pack.suffix)
+	err = os.RemoveAll(packPath)
+	if err != nil {
+		return burrito.WrapErrorf(err, osRemoveError, packPath)
+	}
+	err = copy.Copy(
+		pack.source, packPath, copy.Options{PreserveTimes: false, Sync: false})
+	if err != nil {
+		return burrito.WrapErrorf(err, osCopyError, pack.source, packPath)
+	}
+	// Update the references
+	referencesPath := filepath.Join(worldPath, pack.referencesFile)
+	references := []interface{}{}
+	data, err = os.ReadFile(referencesPath)
+	if err == nil {
+		err = jsonc.Unmarshal(data, &references)
+		if err != nil {
+			return burrito.WrapErrorf(err, jsonUnmarshalError, referencesPath)
+		}
+	} else if !os.IsNotExist(err) {
+		return burrito.WrapErrorf(err, fileReadError, referencesPath)
+	}
+	updatedReferences := make([]interface{}, 0, len(references)+1)
+	for _, reference := range references {
+		if obj, ok := reference.(map[string]interface{}); ok &&
+			obj["pack_id"] == uuid {
+			continue
+		}
+		updatedReferences = append(updatedReferences, reference)
+	}
+	updatedReferences = append(updatedReferences, map[string]interface{}{
+		"pack_id": uuid,
+		"version": version,
+	})
+	data, _ = json.MarshalIndent(updatedReferences, "", "\t") // no error
+	err = os.WriteFile(referencesPath, data, 0644)
+	if err != nil {
+		return burrito.WrapErrorf(err, fileWriteError, referencesPath)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// new profile to the config, keeps the "$schema" property and the order of
+// the keys, and refuses to replace an existing profile without the force
+// flag.
+func TestAddProfile(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(minimalProjectPath)
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.AddProfile("build", false, "", true); err != nil {
+		t.Fatal("'regolith add-profile' failed:", err.Error())
+	}
+	if err := regolith.AddProfile("dev", false, "", true); err == nil {
+		t.Fatal("Adding an existing profile without the force flag didn't fail")
+	}
+	if err := regolith.AddProfile("dev", true, "", true); err != nil {
+		t.Fatal("'regolith add-profile --force' failed:", err.Error())
+	}
+	configMap, err := regolith.LoadConfigAsMap(regolith.ConfigFilePath)
+	if err != nil {
+		t.Fatal("Failed to load the config file:", err)
+	}
+	if _, ok := configMap["$schema"]; !ok {
+		t.Fatal("The \"$schema\" property was removed from the config")
+	}
+	// The keys of the config file keep their order
+	configData, err := ioutil.ReadFile(regolith.ConfigFilePath)
+	if err != nil {
+		t.Fatal("Failed to read the config file:", err)
+	}
+	keys := []string{
+		"$schema", "name", "author", "packs", "regolith", "profiles", "dev",
+		"build", "dataPath"}
+	lastIndex := -1
+	for _, key := range keys {
+		index := strings.Index(string(configData), "\""+key+"\"")
+		if index <= lastIndex {
+			t.Fatalf(
+				"The order of the keys of the config file changed. Expected "+
+					"order: %v\nConfig file:\n%s", keys, configData)
+		}
+		lastIndex = index
+	}
+	config, err := regolith.ConfigFromObject(configMap)
+	if err != nil {
+		t.Fatal("The config file is invalid after adding the profile:", err)
+	}
+	for _, name := range []string{"build", "dev"} {
+		profile, ok := config.Profiles[name]
+		if !ok {
+			t.Fatalf("The %q profile is missing", name)
+		}
+		if len(profile.Filters) != 0 ||
+			profile.ExportTarget.Target != "development" {
+			t.Fatalf("Unexpected content of the %q profile: %+v", name, profile)
+		}
+	}
+	// The "--config" flag selects the config file to modify
+	if err := copy.Copy(regolith.ConfigFilePath, "build.json"); err != nil {
+		t.Fatal("Failed to copy the config file:", err)
+	}
+	if err := regolith.AddProfile("release", false, "build.json", true); err != nil {
+		t.Fatal("'regolith add-profile --config' failed:", err.Error())
+	}
+	for path, expected := range map[string]bool{
+		regolith.ConfigFilePath: false, "build.json": true,
+	} {
+		configMap, err := regolith.LoadConfigAsMap(path)
+		if err != nil {
+			t.Fatal("Failed to load the config file:", err)
+		}
+		config, err := regolith.ConfigFromObject(configMap)
+		if err != nil {
+			t.Fatal("The config file is invalid after adding the profile:", err)
+		}
+		if _, ok := config.Profiles["release"]; ok != expected {
+			t.Fatalf(
+				"The %q file has the \"release\" profile: %v, expected: %v",
+				path, ok, expected)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestRunInProject runs a profile of two projects at the same time with
+// RunInProject from outside of the project directories and checks whether
+// the projects are exported to their own build directories and whether the
+// working directory stays the same.
+func TestRunInProject(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directories of both projects
+	project, err := filepath.Abs(filepath.Join(archiveExportPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	projectRoots := []string{
+		filepath.Join(tmpDir, "first"), filepath.Join(tmpDir, "second")}
+	for _, projectRoot := range projectRoots {
+		err = copy.Copy(
+			project,
+			projectRoot,
+			copy.Options{PreserveTimes: false, Sync: false},
+		)
+		if err != nil {
+			t.Fatalf(
+				"Failed to copy test files from %q into the working directory %q",
+				project, projectRoot,
+			)
+		}
+	}
+	// THE TEST
+	errs := make([]error, len(projectRoots))
+	var wg sync.WaitGroup
+	for i, projectRoot := range projectRoots {
+		wg.Add(1)
+		go func(i int, projectRoot string) {
+			defer wg.Done()
+			errs[i] = regolith.RunInProject(
+				projectRoot, "tar", regolith.RunOptions{}, true)
+		}(i, projectRoot)
+	}
+	wg.Wait()
+	for i, projectRoot := range projectRoots {
+		if errs[i] != nil {
+			t.Fatalf("'regolith run' failed in %q: %s", projectRoot, errs[i])
+		}
+		for _, pack := range []string{"bp", "rp"} {
+			path := filepath.Join(
+				projectRoot, "build", "regolith_test_project_"+pack+".tar.gz")
+			if _, err := os.Stat(path); err != nil {
+				t.Fatalf("The profile wasn't exported to %q: %s", path, err)
+			}
+		}
+	}
+	currentWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	if currentWd != wd {
+		t.Fatalf(
+			"The working directory was changed.\nExpected: %s\nActual: %s",
+			wd, currentWd)
+	}
+	// Errors are returned instead of stopping the program
+	err = regolith.RunInProject(
+		filepath.Join(tmpDir, "missing"), "tar", regolith.RunOptions{}, true)
+	if err == nil {
+		t.Fatal("RunInProject didn't fail for a missing project root")
+	}
+}
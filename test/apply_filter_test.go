@@ -51,7 +51,7 @@ func TestApplyFilter(t *testing.T) {
 	}
 	// THE TEST
 	os.Chdir(tmpDir)
-	if err := regolith.ApplyFilter("test_filter", []string{"Regolith"}, true); err != nil {
+	if err := regolith.ApplyFilter("test_filter", []string{"Regolith"}, "", true); err != nil {
 		t.Fatal("'regolith apply-filter' failed:", err.Error())
 	}
 	// Load expected result
@@ -0,0 +1,409 @@
+package test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestTarExport runs a profile with the "tar" export target and checks
+// whether the exported archives contain the files of the packs.
+func TestTarExport(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(archiveExportPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.Run("tar", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	for _, pack := range []string{"bp", "rp"} {
+		path := filepath.Join("build", "regolith_test_project_"+pack+".tar.gz")
+		names, err := listTarGzFiles(path)
+		if err != nil {
+			t.Fatalf("Failed to read the exported archive %q: %s", path, err)
+		}
+		if _, ok := names["manifest.json"]; !ok {
+			t.Fatalf("The exported archive %q has no manifest.json", path)
+		}
+	}
+}
+
+// TestZipExport runs a profile with the "zip" export target and checks
+// whether the exported archives contain the files of the packs.
+func TestZipExport(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(archiveExportPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.Run("zip", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	for _, pack := range []string{"BP", "RP"} {
+		path := filepath.Join("dist", pack+".zip")
+		zipReader, err := zip.OpenReader(path)
+		if err != nil {
+			t.Fatalf("Failed to read the exported archive %q: %s", path, err)
+		}
+		defer zipReader.Close()
+		found := false
+		for _, file := range zipReader.File {
+			if file.Name == "manifest.json" {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("The exported archive %q has no manifest.json", path)
+		}
+	}
+}
+
+// TestMcworldExport runs a profile with the "mcworld" export target and
+// checks whether the exported world contains the files of the world, the
+// packs and the updated references to the packs.
+func TestMcworldExport(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(archiveExportPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.Run("mcworld", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	path := filepath.Join("dist", "world.mcworld")
+	zipReader, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("Failed to read the exported world %q: %s", path, err)
+	}
+	defer zipReader.Close()
+	files := make(map[string]*zip.File)
+	for _, file := range zipReader.File {
+		files[file.Name] = file
+	}
+	for _, name := range []string{
+		"levelname.txt",
+		"db/CURRENT",
+		"behavior_packs/regolith_test_project_bp/manifest.json",
+		"resource_packs/regolith_test_project_rp/manifest.json",
+		"world_resource_packs.json",
+	} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("The exported world has no %q file", name)
+		}
+	}
+	// The reference to the other pack is kept and the reference to the BP
+	// is updated
+	file, ok := files["world_behavior_packs.json"]
+	if !ok {
+		t.Fatal("The exported world has no world_behavior_packs.json file")
+	}
+	reader, err := file.Open()
+	if err != nil {
+		t.Fatal("Failed to open world_behavior_packs.json:", err)
+	}
+	defer reader.Close()
+	var references []struct {
+		PackId  string `json:"pack_id"`
+		Version []int  `json:"version"`
+	}
+	if err := json.NewDecoder(reader).Decode(&references); err != nil {
+		t.Fatal("Failed to parse world_behavior_packs.json:", err)
+	}
+	if len(references) != 2 ||
+		references[0].PackId != "0b6f3d8a-5b8c-4c8e-9f4e-2a8f1c3e7d11" ||
+		references[1].PackId != "96b53fd2-b7a1-4d26-b74f-1b9394c8d0bc" ||
+		!reflect.DeepEqual(references[1].Version, []int{1, 0, 0}) {
+		t.Fatalf("Unexpected pack references: %v", references)
+	}
+	// The source world is not modified
+	source, err := ioutil.ReadFile(filepath.Join(
+		project, "world", "world_behavior_packs.json"))
+	if err != nil {
+		t.Fatal("Failed to read the source world_behavior_packs.json:", err)
+	}
+	exported, err := ioutil.ReadFile(
+		filepath.Join("world", "world_behavior_packs.json"))
+	if err != nil {
+		t.Fatal("Failed to read the world_behavior_packs.json:", err)
+	}
+	if string(source) != string(exported) {
+		t.Fatal("The export modified the source world")
+	}
+	if _, err := os.Stat(filepath.Join("world", "behavior_packs")); err == nil {
+		t.Fatal("The export added the packs to the source world")
+	}
+}
+
+// TestMultiTargetExport runs a profile with multiple export targets and
+// checks whether the packs are exported to all of them.
+func TestMultiTargetExport(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(archiveExportPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.Run("multi", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	expectedFiles := []string{
+		"build/BP/manifest.json",
+		"build/RP/manifest.json",
+		"dist/exact/BP/manifest.json",
+		"dist/exact/RP/manifest.json",
+		"build/regolith_test_project_bp.tar.gz",
+		"build/regolith_test_project_rp.tar.gz",
+		"dist/BP.zip",
+		"dist/RP.zip",
+	}
+	for _, path := range expectedFiles {
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("The export target didn't create %q: %s", path, err)
+		}
+	}
+}
+
+// TestExecExport runs a profile with the "exec" export target, which should
+// run the command with the paths to the packs, and a profile whose export
+// command fails, which should make the run fail.
+func TestExecExport(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(archiveExportPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.Run("exec", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	output, err := ioutil.ReadFile("exec_export.txt")
+	if err != nil {
+		t.Fatal("The export command didn't create its output file:", err)
+	}
+	for _, pack := range []string{"BP", "RP"} {
+		if !strings.Contains(string(output), filepath.Join("tmp", pack)) {
+			t.Fatalf(
+				"The path to the %s wasn't passed to the export command: %q",
+				pack, string(output))
+		}
+	}
+	if err := regolith.Run("exec_fail", regolith.RunOptions{}, true); err == nil {
+		t.Fatal("'regolith run' didn't fail when the export command failed")
+	}
+}
+
+// listTarGzFiles returns the names of the files from the gzip-compressed tar
+// archive.
+func listTarGzFiles(path string) (map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+	tarReader := tar.NewReader(gzipReader)
+	result := make(map[string]struct{})
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[header.Name] = struct{}{}
+	}
+}
+
+// TestArchiveCompressionOptions checks whether the compression options of
+// the export targets are validated when the configuration is loaded.
+func TestArchiveCompressionOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  map[string]interface{}
+		isValid bool
+	}{
+		{"gzip tar", map[string]interface{}{
+			"target": "tar", "compression": "gzip", "compressionLevel": 9.0}, true},
+		{"uncompressed tar", map[string]interface{}{
+			"target": "tar", "compression": "none"}, true},
+		{"zip level", map[string]interface{}{
+			"target": "zip", "compressionLevel": 0.0}, true},
+		{"mcworld level", map[string]interface{}{
+			"target": "mcworld", "compressionLevel": 5.0}, true},
+		{"brotli tar", map[string]interface{}{
+			"target": "tar", "compression": "brotli"}, false},
+		{"gzip zip", map[string]interface{}{
+			"target": "zip", "compression": "gzip"}, false},
+		{"level too high", map[string]interface{}{
+			"target": "tar", "compressionLevel": 10.0}, false},
+		{"negative level", map[string]interface{}{
+			"target": "zip", "compressionLevel": -1.0}, false},
+		{"fractional level", map[string]interface{}{
+			"target": "zip", "compressionLevel": 1.5}, false},
+		{"level of directory target", map[string]interface{}{
+			"target": "local", "compressionLevel": 5.0}, false},
+	}
+	for _, test := range tests {
+		_, err := regolith.ExportTargetFromObject(test.target)
+		if test.isValid && err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+		} else if !test.isValid && err == nil {
+			t.Errorf("%s: the export target wasn't rejected", test.name)
+		}
+	}
+}
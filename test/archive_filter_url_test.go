@@ -0,0 +1,184 @@
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestArchiveFilterUrl installs a remote filter from the URL of a zip
+// archive served by a local server, runs it, and checks that the version of
+// the filter is pinned with the hash of the archive. Renaming the filter
+// must move its files and its lock data, and installing the filter with a
+// different hash must fail.
+func TestArchiveFilterUrl(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(archiveFilterUrlPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// Pack the filter into a zip archive, in a directory like the archives
+	// of the repositories created by GitHub
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for _, name := range []string{"filter.json", "main.py"} {
+		data, err := ioutil.ReadFile(
+			filepath.Join(archiveFilterUrlPath, "archived_filter", name))
+		if err != nil {
+			t.Fatal("Unable to read the files of the filter:", err)
+		}
+		file, err := writer.Create("archived_filter-main/" + name)
+		if err == nil {
+			_, err = file.Write(data)
+		}
+		if err != nil {
+			t.Fatal("Unable to create the archive of the filter:", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal("Unable to create the archive of the filter:", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Write(archive.Bytes())
+		}))
+	defer server.Close()
+	// THE TEST
+	os.Chdir(tmpDir)
+	setArchiveFilterVersion := func(url, version string) {
+		config, err := ioutil.ReadFile("config.json")
+		if err != nil {
+			t.Fatal("Unable to read the config file:", err)
+		}
+		config = []byte(strings.ReplaceAll(
+			string(config), "ARCHIVE_URL", url))
+		config = []byte(strings.ReplaceAll(
+			string(config), "\"HEAD\"", "\""+version+"\""))
+		if err := ioutil.WriteFile("config.json", config, 0644); err != nil {
+			t.Fatal("Unable to write the config file:", err)
+		}
+	}
+	setArchiveFilterVersion(server.URL+"/archived_filter.zip", "HEAD")
+	if err := regolith.InstallAll(regolith.InstallOptions{}, true); err != nil {
+		t.Fatal("'regolith install-all' failed:", err.Error())
+	}
+	if err := regolith.Run("default", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	output, err := ioutil.ReadFile(filepath.Join("build", "BP", "out.txt"))
+	if err != nil {
+		t.Fatal("Unable to read the output of the filter:", err)
+	}
+	if string(output) != "Hello from the archive" {
+		t.Fatalf("Unexpected output of the filter: %q", string(output))
+	}
+	// The lock file pins the filter with the hash of the archive
+	lockFileData, err := ioutil.ReadFile(regolith.LockFilePath)
+	if err != nil {
+		t.Fatal("Unable to read the lock file:", err)
+	}
+	var lockFile regolith.LockFile
+	if err := json.Unmarshal(lockFileData, &lockFile); err != nil {
+		t.Fatal("Unable to parse the lock file:", err)
+	}
+	sha := lockFile.Filters["archived_filter"].Sha
+	if !strings.HasPrefix(sha, "sha256:") {
+		t.Fatalf("The filter is not pinned with the hash of the archive: %q", sha)
+	}
+	// The lock file of a different config file is saved next to it
+	if err := os.MkdirAll("configs", 0755); err != nil {
+		t.Fatal("Unable to create the directory of the config file:", err)
+	}
+	if err := copy.Copy("config.json", filepath.Join("configs", "build.json")); err != nil {
+		t.Fatal("Unable to copy the config file:", err)
+	}
+	err = regolith.InstallAll(regolith.InstallOptions{
+		ConfigPath: filepath.Join("configs", "build.json")}, true)
+	if err != nil {
+		t.Fatal("'regolith install-all' failed:", err.Error())
+	}
+	configLockFile := filepath.Join("configs", regolith.LockFilePath)
+	if _, err := os.Stat(configLockFile); err != nil {
+		t.Fatalf("The lock file wasn't saved next to the config file: %s", err)
+	}
+	// Renaming the filter moves its files and its entry in the lock file
+	err = regolith.RenameFilter("archived_filter", "renamed_filter", "", true)
+	if err != nil {
+		t.Fatal("'regolith filter rename' failed:", err.Error())
+	}
+	renamedPath := filepath.Join(".regolith", "cache", "filters", "renamed_filter")
+	if _, err := os.Stat(renamedPath); err != nil {
+		t.Fatal("The files of the filter weren't moved:", err)
+	}
+	lockFileData, err = ioutil.ReadFile(regolith.LockFilePath)
+	if err != nil {
+		t.Fatal("Unable to read the lock file:", err)
+	}
+	lockFile = regolith.LockFile{}
+	if err := json.Unmarshal(lockFileData, &lockFile); err != nil {
+		t.Fatal("Unable to parse the lock file:", err)
+	}
+	if lockFile.Filters["renamed_filter"].Sha != sha {
+		t.Fatalf("The lock file entry wasn't renamed: %s", lockFileData)
+	}
+	configMap, err := regolith.LoadConfigAsMap("")
+	if err != nil {
+		t.Fatal("Unable to load the config file:", err)
+	}
+	definitions := configMap["regolith"].(map[string]interface{})["filterDefinitions"]
+	definition := definitions.(map[string]interface{})["renamed_filter"]
+	remoteName := definition.(map[string]interface{})["remoteName"]
+	if remoteName != "archived_filter" {
+		t.Fatalf("Unexpected remoteName of the renamed filter: %v", remoteName)
+	}
+	if err := regolith.Run("default", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed after renaming the filter:", err.Error())
+	}
+	// Installing the filter with a different hash must fail
+	setArchiveFilterVersion("", "sha256:0000")
+	err = regolith.InstallAll(regolith.InstallOptions{Force: true}, true)
+	if err == nil {
+		t.Fatal("'regolith install-all' didn't fail with a wrong hash.")
+	}
+	if !strings.Contains(err.Error(), sha) {
+		t.Fatalf(
+			"The error message doesn't contain the hash of the archive.\n"+
+				"Hash: %s\nError: %s", sha, err.Error())
+	}
+}
@@ -0,0 +1,100 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestBenchmark runs "regolith run --benchmark" on a profile that uses the
+// same filter twice, with and without the "--no-export" flag, and checks
+// the rows of the printed table.
+func TestBenchmark(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The test project uses a shell filter")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(benchmarkPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	for _, noExport := range []bool{false, true} {
+		os.RemoveAll("build")
+		// The table is printed to the standard output. The pipe is read
+		// while the profile runs because the logs are printed there too.
+		reader, writer, err := os.Pipe()
+		if err != nil {
+			t.Fatal("Unable to create a pipe:", err)
+		}
+		output := make(chan string)
+		go func() {
+			data, _ := ioutil.ReadAll(reader)
+			output <- string(data)
+		}()
+		stdout := os.Stdout
+		os.Stdout = writer
+		err = regolith.Run(
+			"default",
+			regolith.RunOptions{Benchmark: 2, NoExport: noExport},
+			true)
+		os.Stdout = stdout
+		writer.Close()
+		table := <-output
+		if err != nil {
+			t.Fatal("'regolith run --benchmark' failed:", err.Error())
+		}
+		expected := []string{"writer ", "writer (2)", "[total]"}
+		if noExport {
+			if strings.Contains(table, "[export]") {
+				t.Errorf("The export was measured with \"--no-export\":\n%s", table)
+			}
+			if _, err := os.Stat("build"); !os.IsNotExist(err) {
+				t.Error("The project was exported with \"--no-export\"")
+			}
+		} else {
+			expected = append(expected, "[export]")
+		}
+		if !strings.Contains(table, "NAME") {
+			t.Fatalf("The benchmark table wasn't printed:\n%s", table)
+		}
+		for _, name := range expected {
+			if !strings.Contains(table, name) {
+				t.Errorf("The benchmark table doesn't contain %q:\n%s", name, table)
+			}
+		}
+	}
+}
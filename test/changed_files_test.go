@@ -0,0 +1,103 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestRunSince runs a profile in a git repository with a modified file of
+// the BP and a new file of the RP, with and without the "--since" flag. The
+// filter should receive the list of these files only with the flag.
+func TestRunSince(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(changedFilesPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	for _, args := range [][]string{
+		{"init"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com",
+			"commit", "-m", "Project"},
+	} {
+		cmd := exec.Command("git", args...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Unable to create the git repository: %s\n%s", err, out)
+		}
+	}
+	err = ioutil.WriteFile(
+		filepath.Join("packs", "BP", "manifest.json"), []byte("{}"), 0644)
+	if err != nil {
+		t.Fatal("Unable to modify the project:", err)
+	}
+	err = ioutil.WriteFile(
+		filepath.Join("packs", "RP", "new.json"), []byte("{}"), 0644)
+	if err != nil {
+		t.Fatal("Unable to modify the project:", err)
+	}
+	cases := []struct {
+		options  regolith.RunOptions
+		expected string
+	}{
+		{regolith.RunOptions{}, "<missing>"},
+		{
+			regolith.RunOptions{Since: "HEAD"},
+			`["BP/manifest.json","RP/new.json"]`,
+		},
+	}
+	for _, c := range cases {
+		if err := regolith.Run("default", c.options, true); err != nil {
+			t.Fatal("'regolith run' failed:", err.Error())
+		}
+		result, err := ioutil.ReadFile(
+			filepath.Join("build", "BP", "changed_files.txt"))
+		if err != nil {
+			t.Fatal("Unable to read the output of the filter:", err)
+		}
+		if string(result) != c.expected {
+			t.Fatalf(
+				"Unexpected list of the changed files.\n"+
+					"Options: %+v\nExpected: %q\nActual: %q",
+				c.options, c.expected, string(result))
+		}
+	}
+	err = regolith.Run(
+		"default", regolith.RunOptions{Since: "missing-ref"}, true)
+	if err == nil {
+		t.Fatal("'regolith run' didn't fail with a missing git reference")
+	}
+}
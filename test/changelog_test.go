@@ -0,0 +1,114 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestChangelog checks whether the 'regolith changelog' command finds the
+// added, removed and modified files in the export manifests and groups them
+// by packs and categories.
+func TestChangelog(t *testing.T) {
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	defer os.RemoveAll(tmpDir)
+	// THE TEST
+	outputPath := filepath.Join(tmpDir, "changelog.json")
+	err = regolith.Changelog(
+		filepath.Join(changelogPath, "previous_manifest.json"),
+		filepath.Join(changelogPath, "current_manifest.json"),
+		outputPath, true)
+	if err != nil {
+		t.Fatal("'regolith changelog' failed:", err.Error())
+	}
+	var expected, actual interface{}
+	for path, target := range map[string]*interface{}{
+		filepath.Join(changelogPath, "expected_changelog.json"): &expected,
+		outputPath: &actual,
+	} {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %q: %s", path, err)
+		}
+		if err := json.Unmarshal(data, target); err != nil {
+			t.Fatalf("Failed to parse %q: %s", path, err)
+		}
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf(
+			"Unexpected changelog.\nExpected: %v\nActual: %v", expected, actual)
+	}
+}
+
+// TestExportManifest checks whether the ExportManifest option of
+// 'regolith run' lists the exported files.
+func TestExportManifest(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(outputScopePath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	options := regolith.RunOptions{ExportManifest: "manifest.json"}
+	if err := regolith.Run("default", options, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	manifest, err := regolith.LoadExportManifest("manifest.json")
+	if err != nil {
+		t.Fatal("Failed to load the export manifest:", err)
+	}
+	expected := map[string][]string{
+		"BP": {"manifest.json"},
+		"RP": {"manifest.json", "out.txt"},
+	}
+	for pack, files := range expected {
+		if len(manifest.Packs[pack]) != len(files) {
+			t.Fatalf(
+				"Unexpected number of files of %s in the manifest: %v",
+				pack, manifest.Packs[pack])
+		}
+		for _, file := range files {
+			if _, ok := manifest.Packs[pack][file]; !ok {
+				t.Fatalf("File %s/%s is missing from the manifest", pack, file)
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestRequireCleanGit runs a profile with the "--require-clean-git" flag
+// outside of a git repository, in a repository without changes and in a
+// repository with an uncommitted change. Only the last run should fail.
+func TestRequireCleanGit(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(archiveExportPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	options := regolith.RunOptions{RequireCleanGit: true}
+	if err := regolith.Run("tar", options, true); err != nil {
+		t.Fatal("'regolith run' failed outside of a git repository:", err.Error())
+	}
+	for _, args := range [][]string{
+		{"init"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com",
+			"commit", "-m", "Project"},
+	} {
+		cmd := exec.Command("git", args...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Unable to create the git repository: %s\n%s", err, out)
+		}
+	}
+	if err := regolith.Run("tar", options, true); err != nil {
+		t.Fatal("'regolith run' failed in a clean git repository:", err.Error())
+	}
+	err = ioutil.WriteFile(
+		filepath.Join("packs", "BP", "manifest.json"), []byte("{}"), 0644)
+	if err != nil {
+		t.Fatal("Unable to modify the project:", err)
+	}
+	err = regolith.Run("tar", options, true)
+	if err == nil {
+		t.Fatal("'regolith run' didn't fail in a git repository with changes")
+	}
+	if !strings.Contains(err.Error(), "packs/BP/manifest.json") {
+		t.Fatal("The error doesn't list the changed file:", err.Error())
+	}
+}
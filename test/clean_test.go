@@ -0,0 +1,68 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestCleanDryRun runs a profile and checks whether "regolith clean" with
+// the dry run option leaves the cache of the project untouched, while the
+// normal "regolith clean" removes it.
+func TestCleanDryRun(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(archiveExportPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.Run("tar", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	if _, err := os.Stat(".regolith"); err != nil {
+		t.Fatal("The run didn't create the .regolith directory:", err)
+	}
+	if err := regolith.Clean(true, false, false, true); err != nil {
+		t.Fatal("'regolith clean --dry-run' failed:", err.Error())
+	}
+	if _, err := os.Stat(".regolith"); err != nil {
+		t.Fatal("'regolith clean --dry-run' removed the cache:", err)
+	}
+	if err := regolith.Clean(true, false, false, false); err != nil {
+		t.Fatal("'regolith clean' failed:", err.Error())
+	}
+	if _, err := os.Stat(".regolith"); !os.IsNotExist(err) {
+		t.Fatal("'regolith clean' didn't remove the cache")
+	}
+}
@@ -43,6 +43,12 @@ const (
 	versionedRemoteFilterProjectAfterRun = "testdata/versioned_remote_filter_project_after_run"
 	exeFilterPath                        = "testdata/exe_filter"
 
+	// shellScriptFilterPath is a directory that contains files for testing
+	// the shell filters with the "script" property. The project has a
+	// profile that runs a script which writes its argument to a file and a
+	// profile with a script that fails.
+	shellScriptFilterPath = "testdata/shell_script_filter"
+
 	// profileFilterPath is a directory that contains files for testing
 	// ProfileFilter. It contains a project and an expected result. The
 	// projects has both valid and invalid profiles.
@@ -69,6 +75,250 @@ const (
 	conditionalFilterPath = "testdata/conditional_filter"
 
 	dataModifyRemoteFilter = "testdata/data_modify_remote_filter"
+
+	// profileDescriptionPath contains a project with two profiles that have
+	// descriptions and one profile without a description.
+	profileDescriptionPath = "testdata/profile_description"
+
+	// profileExtendsPath contains files for testing profiles that use the
+	// 'extends' property. The 'project' has a 'child' profile that extends
+	// the 'base' profile and the 'expected_build_result' is the result of
+	// running the 'child' profile. The 'circular_project' has two profiles
+	// that extend each other.
+	profileExtendsPath = "testdata/profile_extends"
+
+	// archiveExportPath contains a project with profiles that export the
+	// packs into archive files. The "world" folder of the project is used
+	// by the "mcworld" export target. Its world_behavior_packs.json file
+	// references the BP of the project with an old version and another pack.
+	archiveExportPath = "testdata/archive_export"
+
+	// isolateEnvPath contains a project with a filter that writes the value of
+	// the REGOLITH_TEST_SECRET environment variable to the BP/env.txt file.
+	// It's used for testing the 'regolith run --isolate-env' command.
+	isolateEnvPath = "testdata/isolate_env"
+
+	// profileOverridesPath contains a project with profiles that run a nested
+	// profile with different arguments and settings. Its filter writes the
+	// arguments and settings it receives to a JSON file in the BP.
+	profileOverridesPath = "testdata/profile_overrides"
+
+	// dotEnvPath contains a project with a filter that writes the value of
+	// an environment variable to a file and a ".env" file that defines that
+	// variable. The "secrets.env" file defines the variable with a different
+	// value. It's used for testing the 'regolith run --dotenv' and
+	// 'regolith run --env-file' commands.
+	dotEnvPath = "testdata/dotenv"
+
+	// updatePatternsPath contains a project with local filters whose names
+	// share a prefix. It's used for testing the glob patterns of the
+	// 'regolith update' command.
+	updatePatternsPath = "testdata/update_patterns"
+
+	// changelogPath contains two export manifests and the expected result of
+	// comparing them with the 'regolith changelog' command.
+	changelogPath = "testdata/changelog"
+
+	// profileReportPath contains a project with a profile that succeeds and
+	// a profile with a failing filter. It's used for testing the
+	// 'regolith run --profile-report' command.
+	profileReportPath = "testdata/profile_report"
+
+	// filterRenamePath contains a project with a filter definition used in
+	// multiple profiles. It's used for testing the 'regolith filter rename'
+	// command.
+	filterRenamePath = "testdata/filter_rename"
+
+	// filterCachePath contains a project with a cacheable filter that writes
+	// the current time to a file. It's used for testing if the output of the
+	// filter is restored from the cache when its input doesn't change.
+	filterCachePath = "testdata/filter_cache"
+
+	// outputScopePath contains a project with a filter that writes to both
+	// RP and BP, but its output scope is limited to RP. The
+	// 'expected_build_result' contains only the changes made to RP.
+	outputScopePath = "testdata/output_scope"
+
+	// dataLinksPath contains a project with a filter that modifies a file of
+	// the data folder in place. It's used for testing whether the files
+	// linked to the tmp directory are copied before running the filter.
+	dataLinksPath = "testdata/data_links"
+
+	// filterValidationPath contains a project with a filter that writes a
+	// valid JSON file to the RP and an invalid JSON file to the BP. The
+	// 'valid' profile validates the output of the filter successfully and
+	// the other profiles fail the validation in different ways.
+	filterValidationPath = "testdata/filter_validation"
+
+	// regolithIgnorePath contains a project with .regolithignore files in
+	// the RP and BP, which exclude some of their files from the tmp
+	// directory. The 'expected_build_result' contains only the files that
+	// are not ignored.
+	regolithIgnorePath = "testdata/regolith_ignore"
+
+	// settingsVariablesPath contains a project with a shell filter that
+	// writes its settings to BP/settings.json. The settings use the
+	// ${var:name} placeholders replaced with the variables passed to
+	// 'regolith run'.
+	settingsVariablesPath = "testdata/settings_variables"
+
+	// settingsMergePath contains a project with a shell filter that writes
+	// its settings to BP/settings.json. The filter definition has default
+	// settings. The "overrides" profile overrides some of them and the
+	// "defaults" profile uses the defaults.
+	settingsMergePath = "testdata/settings_merge"
+
+	// filterGroupsPath contains a project with a filter group of two remote
+	// filters that can't be downloaded. The first filter of the group is
+	// already installed in the cache. It's used for testing whether the
+	// filters of a group are restored when updating the group fails.
+	filterGroupsPath = "testdata/filter_groups"
+
+	// localRegistryPath contains a project with a filter linked from the
+	// local registry (the "registry/linked_filter" directory of the
+	// project). The filter copies its message.txt file into the BP.
+	localRegistryPath = "testdata/local_registry"
+
+	// settingsSchemaPath contains a project with a remote filter installed in
+	// the cache, which declares a schema of its settings. The 'valid' profile
+	// uses valid settings and the 'invalid' profile uses settings with three
+	// problems.
+	settingsSchemaPath = "testdata/settings_schema"
+
+	// verifyPath contains two projects with fake filter caches for testing
+	// the 'regolith verify' command. The 'valid_project' has a cache that
+	// matches its config. The 'invalid_project' has an outdated, a missing
+	// and an orphaned filter.
+	verifyPath = "testdata/verify"
+
+	// configMigrationPath contains a project with a config file in the old
+	// format (data paths and the "unsafe" property in the profiles, a profile
+	// without an export target and an unversioned remote filter) and the
+	// expected_config.json file with the result of 'regolith migrate'.
+	configMigrationPath = "testdata/config_migration"
+
+	// filterDataPath contains a project with a remote filter installed in
+	// the cache. The filter uses the "exportData" property and has a "data"
+	// folder with two files. The data folder of the project already has one
+	// of them with different content. The filter copies its data folder into
+	// the BP.
+	filterDataPath = "testdata/filter_data"
+
+	// filterDependenciesPath contains a project with two remote filters
+	// installed in the cache. The filters depend on each other using the
+	// "filterDependencies" property of their filter.json files.
+	filterDependenciesPath = "testdata/filter_dependencies"
+
+	// filterTestsPath contains a project with a shell filter that copies a
+	// file of the BP and the "tests" folder with two test fixtures of the
+	// filter. The "pass" fixture expects the copied file and the "fail"
+	// fixture expects a copy with different content.
+	filterTestsPath = "testdata/filter_tests"
+
+	// changedFilesPath contains a project with a filter that writes the value
+	// of the REGOLITH_CHANGED_FILES environment variable to the
+	// BP/changed_files.txt file. It's used for testing the
+	// 'regolith run --since' command.
+	changedFilesPath = "testdata/changed_files"
+
+	// readOnlyExportPath contains a project with a profile that exports the
+	// packs to the "build" folder with the "readOnly" option.
+	readOnlyExportPath = "testdata/read_only_export"
+
+	// profileFilePath contains a project with a filter that isn't used by
+	// the profiles of its config file and the "adhoc-profile.json" file with
+	// a profile that runs the filter. It's used for testing the
+	// 'regolith run --profile-file' command.
+	profileFilePath = "testdata/profile_file"
+
+	// inputScopePath contains a project with a filter that lists the files
+	// of the RP that it can see and tries to modify a file outside of its
+	// "scope".
+	inputScopePath = "testdata/input_scope"
+
+	// filterPacksPath contains a project with a filter that lists the packs
+	// that it can see and tries to create a file in a pack that isn't on
+	// its "packs" list.
+	filterPacksPath = "testdata/filter_packs"
+
+	// filterLogsPath contains a project with a filter that prints a line to
+	// the stdout and a line to the stderr. The filter of the "failing"
+	// profile fails after printing them.
+	filterLogsPath = "testdata/filter_logs"
+
+	// linkPacksPath contains a project with a profile that exports the packs
+	// with the "linkPacks" option. The BP already depends on the RP, the RP
+	// has no dependencies.
+	linkPacksPath = "testdata/link_packs"
+
+	// profileHooksPath contains a project with a profile that has "preRun"
+	// and "postRun" commands, which append their names and the result of the
+	// build to the hooks.txt file. The "failing" profile extends it with a
+	// filter that fails.
+	profileHooksPath = "testdata/profile_hooks"
+
+	// archiveFilterUrlPath contains a project with a remote filter installed
+	// from the URL of a zip archive and the "archived_filter" directory with
+	// the files of the filter. The test replaces the "ARCHIVE_URL"
+	// placeholder in the config with the URL of a local server that serves
+	// the archive.
+	archiveFilterUrlPath = "testdata/archive_filter_url"
+
+	// failFastPath contains a project with a profile that runs three filters.
+	// The first and the last filter fail after creating their files, the
+	// filter in the middle succeeds. The 'cached' profile runs a cacheable
+	// filter followed by a failing filter.
+	failFastPath = "testdata/fail_fast"
+
+	// exportPathTemplatesPath contains a project with the "exact" export
+	// target which uses the {{.Name}}, {{.Profile}} and {{.Date}}
+	// placeholders in its paths.
+	exportPathTemplatesPath = "testdata/export_path_templates"
+
+	// globalFiltersPath contains a project that runs a filter defined only
+	// in the global filter definitions and a filter defined both in the
+	// project and globally, and the filters.json file with the global
+	// filter definitions.
+	globalFiltersPath = "testdata/global_filters"
+
+	// maxMemoryPath contains a project with a filter that allocates 256 MiB
+	// of memory.
+	maxMemoryPath = "testdata/max_memory"
+
+	// exportOptimizationPath contains a project that exports the packs with
+	// the "optimize" option and a filter that marks one of the JSON files as
+	// already optimized.
+	exportOptimizationPath = "testdata/export_optimization"
+
+	// profileDiffPath contains a project with the "a" and "b" profiles,
+	// which have different filters and export targets. The "a" profile
+	// inherits some of its filters from the "base" profile.
+	profileDiffPath = "testdata/profile_diff"
+
+	// filterWarningsPath contains a project with a filter that reports
+	// warnings in the warnings.json file, and fails if the file of the
+	// previous filter wasn't removed.
+	filterWarningsPath = "testdata/filter_warnings"
+
+	// filterRetriesPath contains a project with a filter that fails the
+	// first two times it runs. The "default" profile retries it enough
+	// times to succeed, and the "not_enough_retries" profile doesn't.
+	filterRetriesPath = "testdata/filter_retries"
+
+	// exportLayoutPath contains a project with the "layout" property of the
+	// "local" export target. The "conflict" profile exports both of the
+	// packs to the same directory.
+	exportLayoutPath = "testdata/export_layout"
+
+	// runCancellationPath contains a project with a filter that sends Ctrl+C
+	// to Regolith and finishes after a second, followed by another filter
+	// that shouldn't run. Both filters write files to the root of the
+	// project.
+	runCancellationPath = "testdata/run_cancellation"
+
+	// benchmarkPath contains a project with a profile that runs the same
+	// shell filter twice, used for testing "regolith run --benchmark".
+	benchmarkPath = "testdata/benchmark"
 )
 
 // firstErr returns the first error in a list of errors. If the list is empty
@@ -86,7 +336,8 @@ func firstErr(errors ...error) error {
 // relative to 'root' directory used as keys, and with md5 hashes paths as
 // values. The directory paths use empty strings instead of MD5. The function
 // ignores files called .ignoreme (they simulate empty directories
-// in git repository).
+// in git repository) and the regolith-lock.json files (they contain SHAs of
+// the remote repositories).
 func listPaths(path string, root string) (map[string]string, error) {
 	result := map[string]string{}
 	err := filepath.WalkDir(path,
@@ -94,7 +345,8 @@ func listPaths(path string, root string) (map[string]string, error) {
 			if err != nil {
 				return err
 			}
-			if data.Name() == ".ignoreme" || data.Name() == "lockfile.txt" { // Ignored file
+			if data.Name() == ".ignoreme" || data.Name() == "lockfile.txt" ||
+				data.Name() == "regolith-lock.json" { // Ignored file
 				return nil
 			}
 			relPath, err := filepath.Rel(root, path)
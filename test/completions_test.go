@@ -0,0 +1,60 @@
+package test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+)
+
+// TestCompletions checks the names of the profiles and the filters listed
+// by the shell completion for the projects with a local filter and with a
+// remote filter.
+func TestCompletions(t *testing.T) {
+	localConfig := filepath.Join(isolateEnvPath, "project", "config.json")
+	remoteConfig := filepath.Join(settingsSchemaPath, "project", "config.json")
+	cases := []struct {
+		name     string
+		actual   []string
+		expected []string
+	}{
+		{
+			"profiles",
+			regolith.CompleteProfileNames(remoteConfig, ""),
+			[]string{"invalid", "valid"},
+		},
+		{
+			"profiles with a prefix",
+			regolith.CompleteProfileNames(remoteConfig, "v"),
+			[]string{"valid"},
+		},
+		{
+			"filters",
+			regolith.CompleteFilterNames(localConfig, "", false),
+			[]string{"print_env"},
+		},
+		{
+			"remote filters of a project with a local filter",
+			regolith.CompleteFilterNames(localConfig, "", true),
+			[]string{},
+		},
+		{
+			"remote filters",
+			regolith.CompleteFilterNames(remoteConfig, "", true),
+			[]string{"schema_filter"},
+		},
+		{
+			"missing config file",
+			regolith.CompleteProfileNames("missing.json", ""),
+			[]string{},
+		},
+	}
+	for _, c := range cases {
+		if !reflect.DeepEqual(c.actual, c.expected) {
+			t.Errorf(
+				"Unexpected completion of %s.\nExpected: %v\nActual: %v",
+				c.name, c.expected, c.actual)
+		}
+	}
+}
@@ -11,7 +11,9 @@ import (
 )
 
 // TestConditionalFilter runs a test that checks whether the 'when' property
-// of a filter properly locks/enables the execution of the filter.
+// of a filter properly locks/enables the execution of the filter. The second
+// part of the test uses the conditions based on the profile name and the
+// environment variables.
 func TestConditionalFilter(t *testing.T) {
 	wd, err := os.Getwd()
 	if err != nil {
@@ -52,7 +54,7 @@ func TestConditionalFilter(t *testing.T) {
 	}
 	// THE TEST
 	os.Chdir(tmpDir)
-	if err := regolith.Run("default", true); err != nil {
+	if err := regolith.Run("default", regolith.RunOptions{}, true); err != nil {
 		t.Fatal("'regolith run' failed:", err.Error())
 	}
 	// Load expected result
@@ -68,4 +70,20 @@ func TestConditionalFilter(t *testing.T) {
 	}
 	// Compare the results
 	comparePathMaps(expectedPaths, actualPaths, t)
+
+	// Every filter of the "env" profile overwrites the out.txt file, so it
+	// contains the output of the last filter that wasn't skipped
+	t.Setenv("REGOLITH_TEST_CONDITION", "true")
+	os.Unsetenv("REGOLITH_TEST_UNSET")
+	if err := regolith.Run("env", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	output, err := ioutil.ReadFile(filepath.Join(tmpDirBuild, "BP", "out.txt"))
+	if err != nil {
+		t.Fatal("Unable to read the output of the filters:", err)
+	}
+	if string(output) != "env" {
+		t.Fatalf("Unexpected output of the filters: %q, expected: %q",
+			string(output), "env")
+	}
 }
@@ -0,0 +1,185 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestConfigSet runs a test that checks whether "regolith config set"
+// modifies the properties of the config file selected with the dotted paths
+// and whether it refuses to save an invalid config file.
+func TestConfigSet(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(minimalProjectPath)
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	// The path relative to the "regolith" object, the value is a string
+	err = regolith.ConfigSet("profiles.dev.export.target", "local", "", true)
+	if err != nil {
+		t.Fatal("'regolith config set' failed:", err.Error())
+	}
+	// The full path, the value is a boolean
+	err = regolith.ConfigSet(
+		"regolith.profiles.dev.export.readOnly", "true", "", true)
+	if err != nil {
+		t.Fatal("'regolith config set' failed:", err.Error())
+	}
+	// The invalid value is not saved
+	err = regolith.ConfigSet("profiles.dev.filters", "5", "", true)
+	if err == nil {
+		t.Fatal("'regolith config set' didn't fail with an invalid value.")
+	}
+	data, err := ioutil.ReadFile("config.json")
+	if err != nil {
+		t.Fatal("Unable to read the config file:", err)
+	}
+	var config struct {
+		Schema   string `json:"$schema"`
+		Regolith struct {
+			Profiles map[string]struct {
+				Filters []interface{} `json:"filters"`
+				Export  struct {
+					Target   string `json:"target"`
+					ReadOnly bool   `json:"readOnly"`
+				} `json:"export"`
+			} `json:"profiles"`
+		} `json:"regolith"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal("Unable to parse the config file:", err)
+	}
+	if config.Schema == "" {
+		t.Fatal("The \"$schema\" property was removed.")
+	}
+	profile := config.Regolith.Profiles["dev"]
+	if profile.Export.Target != "local" || !profile.Export.ReadOnly {
+		t.Fatalf(
+			"Unexpected export target.\nTarget: %s\nRead only: %v",
+			profile.Export.Target, profile.Export.ReadOnly)
+	}
+	if profile.Filters == nil {
+		t.Fatal("The invalid value of the filters was saved.")
+	}
+}
+
+// TestConfigGetSetConfigPath runs a test that checks whether "regolith config
+// get" and "regolith config set" use the config file selected with the
+// "--config" flag instead of "config.json".
+func TestConfigGetSetConfigPath(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(minimalProjectPath)
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	original, err := ioutil.ReadFile("config.json")
+	if err != nil {
+		t.Fatal("Unable to read the config file:", err)
+	}
+	err = ioutil.WriteFile("build-config.json", original, 0644)
+	if err != nil {
+		t.Fatal("Unable to create the second config file:", err)
+	}
+	err = regolith.ConfigSet(
+		"profiles.dev.export.target", "local", "build-config.json", true)
+	if err != nil {
+		t.Fatal("'regolith config set' failed:", err.Error())
+	}
+	data, err := ioutil.ReadFile("config.json")
+	if err != nil {
+		t.Fatal("Unable to read the config file:", err)
+	}
+	if string(data) != string(original) {
+		t.Fatal("'regolith config set' modified \"config.json\".")
+	}
+	data, err = ioutil.ReadFile("build-config.json")
+	if err != nil {
+		t.Fatal("Unable to read the second config file:", err)
+	}
+	var config struct {
+		Regolith struct {
+			Profiles map[string]struct {
+				Export struct {
+					Target string `json:"target"`
+				} `json:"export"`
+			} `json:"profiles"`
+		} `json:"regolith"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal("Unable to parse the config file:", err)
+	}
+	if target := config.Regolith.Profiles["dev"].Export.Target; target != "local" {
+		t.Fatalf("Unexpected export target: %s", target)
+	}
+	err = regolith.ConfigGet(
+		"profiles.dev.export.target", "build-config.json", true)
+	if err != nil {
+		t.Fatal("'regolith config get' failed:", err.Error())
+	}
+	err = regolith.ConfigGet("profiles.dev.export.target", "missing.json", true)
+	if err == nil {
+		t.Fatal("'regolith config get' didn't fail with a missing config file.")
+	}
+}
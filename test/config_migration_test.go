@@ -0,0 +1,96 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestMigrate checks whether 'regolith migrate' upgrades an old config file
+// to the current format, backs up the original file and doesn't change
+// anything when it's run again.
+func TestMigrate(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	expectedPath, err := filepath.Abs(
+		filepath.Join(configMigrationPath, "expected_config.json"))
+	if err != nil {
+		t.Fatal("Unable to get absolute path to the expected config:", err)
+	}
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(configMigrationPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	original, err := ioutil.ReadFile(filepath.Join(project, "config.json"))
+	if err != nil {
+		t.Fatal("Failed to read the original config file:", err)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.Migrate("", true); err != nil {
+		t.Fatal("'regolith migrate' failed:", err.Error())
+	}
+	expected, err := regolith.LoadConfigAsMap(expectedPath)
+	if err != nil {
+		t.Fatal("Failed to load the expected config file:", err)
+	}
+	migrated, err := regolith.LoadConfigAsMap(regolith.ConfigFilePath)
+	if err != nil {
+		t.Fatal("Failed to load the migrated config file:", err)
+	}
+	if !reflect.DeepEqual(expected, migrated) {
+		t.Fatalf("Unexpected migrated config:\n%v", migrated)
+	}
+	backup, err := ioutil.ReadFile(regolith.ConfigFilePath + ".bak")
+	if err != nil {
+		t.Fatal("Failed to read the backup of the config file:", err)
+	}
+	if string(backup) != string(original) {
+		t.Fatal("The backup is different from the original config file")
+	}
+	// Running the command again doesn't change anything
+	migratedBytes, err := ioutil.ReadFile(regolith.ConfigFilePath)
+	if err != nil {
+		t.Fatal("Failed to read the migrated config file:", err)
+	}
+	if err := regolith.Migrate("", true); err != nil {
+		t.Fatal("Second 'regolith migrate' failed:", err.Error())
+	}
+	afterBytes, err := ioutil.ReadFile(regolith.ConfigFilePath)
+	if err != nil {
+		t.Fatal("Failed to read the migrated config file:", err)
+	}
+	if string(afterBytes) != string(migratedBytes) {
+		t.Fatal("Running 'regolith migrate' twice modified the config file")
+	}
+}
@@ -0,0 +1,66 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestConfigPath checks whether a project whose config file has a
+// non-default name can be run with the ConfigPath option.
+func TestConfigPath(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(archiveExportPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	err = os.Rename("config.json", "build-config.json")
+	if err != nil {
+		t.Fatal("Unable to rename the config file:", err)
+	}
+	if err := regolith.Run("tar", regolith.RunOptions{}, true); err == nil {
+		t.Fatal("'regolith run' didn't fail without the config.json file")
+	}
+	options := regolith.RunOptions{ConfigPath: "build-config.json"}
+	if err := regolith.Run("tar", options, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	for _, pack := range []string{"bp", "rp"} {
+		path := filepath.Join("build", "regolith_test_project_"+pack+".tar.gz")
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("The profile wasn't exported to %q: %s", path, err)
+		}
+	}
+}
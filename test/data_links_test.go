@@ -0,0 +1,77 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestDataLinks runs a test that checks whether the changes made by a filter
+// to the linked files of the data folder don't modify the data folder of the
+// project, with and without the "--no-hardlink" flag.
+func TestDataLinks(t *testing.T) {
+	for _, noHardlink := range []bool{false, true} {
+		runDataLinksTest(t, noHardlink)
+	}
+}
+
+func runDataLinksTest(t *testing.T, noHardlink bool) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(dataLinksPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	err = regolith.Run(
+		"default", regolith.RunOptions{NoHardlink: noHardlink}, true)
+	if err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	// The filter sees the original file and modifies its copy
+	output, err := ioutil.ReadFile(filepath.Join("build", "RP", "data.txt"))
+	if err != nil {
+		t.Fatal("Unable to read the output of the filter:", err)
+	}
+	if string(output) != "original modified" {
+		t.Fatalf("Unexpected output of the filter: %q", string(output))
+	}
+	// The data folder of the project is not modified
+	data, err := ioutil.ReadFile(filepath.Join("packs", "data", "data.txt"))
+	if err != nil {
+		t.Fatal("Unable to read the data file of the project:", err)
+	}
+	if string(data) != "original" {
+		t.Fatalf("The data file of the project was modified: %q", string(data))
+	}
+}
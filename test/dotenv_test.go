@@ -0,0 +1,86 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestDotEnv checks whether the variables from the ".env" file are passed to
+// the filters only when the DotEnv option is enabled and whether the real
+// environment variables take precedence over them.
+func TestDotEnv(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(dotEnvPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	os.Unsetenv("REGOLITH_TEST_SECRET")
+	cases := []struct {
+		env      string
+		options  regolith.RunOptions
+		expected string
+	}{
+		{"", regolith.RunOptions{}, "<missing>"},
+		{"", regolith.RunOptions{DotEnv: true}, "from dotenv"},
+		{"", regolith.RunOptions{EnvFile: "secrets.env"}, "from env file"},
+		{"secret", regolith.RunOptions{DotEnv: true}, "secret"},
+	}
+	for _, c := range cases {
+		if c.env != "" {
+			os.Setenv("REGOLITH_TEST_SECRET", c.env)
+			defer os.Unsetenv("REGOLITH_TEST_SECRET")
+		}
+		if err := regolith.Run("default", c.options, true); err != nil {
+			t.Fatal("'regolith run' failed:", err.Error())
+		}
+		result, err := ioutil.ReadFile(filepath.Join("build", "BP", "env.txt"))
+		if err != nil {
+			t.Fatal("Unable to read the output of the filter:", err)
+		}
+		if string(result) != c.expected {
+			t.Fatalf(
+				"Unexpected value of the environment variable.\n"+
+					"Options: %+v\nExpected: %q\nActual: %q",
+				c.options, c.expected, string(result))
+		}
+	}
+	// The file from the "--env-file" flag must exist
+	err = regolith.Run(
+		"default", regolith.RunOptions{EnvFile: "missing.env"}, true)
+	if err == nil {
+		t.Fatal("'regolith run' didn't fail with a missing env file")
+	}
+}
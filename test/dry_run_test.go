@@ -0,0 +1,61 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestDryRun runs a profile with nested profiles in the dry run mode and
+// checks whether nothing was exported. It also makes sure that the dry run
+// still detects the circular profiles.
+func TestDryRun(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(profileFilterPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	options := regolith.RunOptions{DryRun: true}
+	if err := regolith.Run("invalid_circular_profile_1", options, true); err == nil {
+		t.Fatal("'regolith run --dry-run' didn't return an error after " +
+			"running a circular profile filter")
+	}
+	if err := regolith.Run("correct_nested_profile", options, true); err != nil {
+		t.Fatal("'regolith run --dry-run' failed:", err.Error())
+	}
+	if _, err := os.Stat("build"); !os.IsNotExist(err) {
+		t.Fatal("'regolith run --dry-run' exported the project")
+	}
+}
@@ -0,0 +1,133 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestExportCommand checks whether "regolith export" fails without a build
+// in the tmp directory, and whether it exports the build of a profile with
+// an archive export target to the "local" target, twice in a row.
+func TestExportCommand(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(archiveExportPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.Export("zip", "local", "", true); err == nil {
+		t.Fatal("'regolith export' didn't fail without a build")
+	}
+	if err := regolith.Run("zip", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	for i := 0; i < 2; i++ {
+		if err := regolith.Export("zip", "local", "", true); err != nil {
+			t.Fatal("'regolith export' failed:", err.Error())
+		}
+		for _, pack := range []string{"BP", "RP"} {
+			path := filepath.Join("build", pack, "manifest.json")
+			if _, err := os.Stat(path); err != nil {
+				t.Fatalf("The exported pack has no manifest.json: %s", path)
+			}
+		}
+	}
+}
+
+// TestRunThenExportCommand checks whether "regolith export" works after
+// running a profile with a single export target with the "--keep-tmp" flag,
+// and whether its error names the flag after a run without it.
+func TestRunThenExportCommand(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(archiveExportPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	// The single export target moves the packs out of the tmp directory
+	if err := regolith.Run("regenerate_uuids", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	err = regolith.Export("regenerate_uuids", "zip", "", true)
+	if err == nil {
+		t.Fatal("'regolith export' didn't fail without a kept build")
+	}
+	if !strings.Contains(err.Error(), "--keep-tmp") {
+		t.Fatalf("The error doesn't mention the \"--keep-tmp\" flag: %s", err)
+	}
+	// The "--keep-tmp" flag keeps the build for the export
+	err = regolith.Run(
+		"regenerate_uuids", regolith.RunOptions{KeepTmp: true}, true)
+	if err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	if err := regolith.Export("regenerate_uuids", "zip", "", true); err != nil {
+		t.Fatal("'regolith export' failed:", err.Error())
+	}
+	for _, pack := range []string{"bp", "rp"} {
+		path := filepath.Join("build", "regolith_test_project_"+pack+".zip")
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("The profile wasn't exported to %q: %s", path, err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"bytes"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestExportOptimization runs a test that checks whether the "optimize"
+// option of the export target minifies the JSON files and recompresses the
+// PNG files without modifying the source files, and whether it skips the
+// files marked as already optimized by the filters.
+func TestExportOptimization(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(exportOptimizationPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.Run("default", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	readFile := func(path string) []byte {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal("Unable to read the file:", err)
+		}
+		return data
+	}
+	// The JSON file is minified
+	expected := `{"format_version":"1.16.0","minecraft:entity":{"description":` +
+		`{"identifier":"test:pig","is_spawnable":true}}}`
+	actual := string(readFile("build/BP/entities/pig.json"))
+	if actual != expected {
+		t.Fatalf(
+			"Unexpected minified JSON file.\nExpected: %s\nActual: %s",
+			expected, actual)
+	}
+	// The file marked as optimized by the filter is not changed
+	source := readFile("packs/BP/items/optimized.json")
+	if !bytes.Equal(source, readFile("build/BP/items/optimized.json")) {
+		t.Fatal("The file marked as already optimized was modified.")
+	}
+	// The texture is smaller and has the same pixels
+	sourceTexture := readFile("packs/RP/textures/red.png")
+	exportedTexture := readFile("build/RP/textures/red.png")
+	if len(exportedTexture) >= len(sourceTexture) {
+		t.Fatalf(
+			"The texture wasn't recompressed.\nSource size: %d\n"+
+				"Exported size: %d",
+			len(sourceTexture), len(exportedTexture))
+	}
+	sourceImage, err := png.Decode(bytes.NewReader(sourceTexture))
+	if err != nil {
+		t.Fatal("Unable to decode the source texture:", err)
+	}
+	exportedImage, err := png.Decode(bytes.NewReader(exportedTexture))
+	if err != nil {
+		t.Fatal("Unable to decode the exported texture:", err)
+	}
+	bounds := sourceImage.Bounds()
+	if bounds != exportedImage.Bounds() {
+		t.Fatal("The size of the exported texture is different.")
+	}
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			r1, g1, b1, a1 := sourceImage.At(x, y).RGBA()
+			r2, g2, b2, a2 := exportedImage.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				t.Fatalf("The pixel (%d, %d) of the texture changed.", x, y)
+			}
+		}
+	}
+	// The source files are not modified
+	if !bytes.Contains(
+		readFile("packs/BP/entities/pig.json"),
+		[]byte("// The comments are removed by the optimizer")) {
+		t.Fatal("The source JSON file was modified.")
+	}
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestExportPathTemplates runs a profile with placeholders in the paths of
+// its export target and checks whether the packs are exported to the
+// expanded paths. It also checks whether an unknown placeholder is reported
+// when the config is loaded.
+func TestExportPathTemplates(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(exportPathTemplatesPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.Run("default", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	for _, path := range []string{
+		filepath.Join("build", "templated_project", "default", "BP"),
+		filepath.Join(
+			"build", "templated_project", time.Now().Format("2006-01-02"),
+			"RP"),
+	} {
+		if _, err := os.Stat(filepath.Join(path, "manifest.json")); err != nil {
+			t.Fatalf("The pack wasn't exported to %q.", path)
+		}
+	}
+	// Typos in the placeholders fail when the config is loaded
+	configMap, err := regolith.LoadConfigAsMap("")
+	if err != nil {
+		t.Fatal("Unable to load the config file:", err)
+	}
+	regolithObj := configMap["regolith"].(map[string]interface{})
+	profiles := regolithObj["profiles"].(map[string]interface{})
+	profile := profiles["default"].(map[string]interface{})
+	export := profile["export"].(map[string]interface{})
+	export["bpPath"] = "build/{{.Nmae}}/BP"
+	if _, err := regolith.ConfigFromObject(configMap); err == nil {
+		t.Fatal("Loading the config with an unknown placeholder didn't fail.")
+	}
+}
@@ -0,0 +1,68 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestExportTargetOverride checks whether the "--export-target" flag of
+// 'regolith run' replaces the export target of the profile and whether the
+// invalid overrides are rejected.
+func TestExportTargetOverride(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(minimalProjectPath)
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	err = regolith.Run(
+		"dev", regolith.RunOptions{ExportTarget: "path=dist"}, true)
+	if err != nil {
+		t.Fatal("'regolith run --export-target' failed:", err.Error())
+	}
+	for _, pack := range []string{"BP", "RP"} {
+		manifestPath := filepath.Join("dist", pack, "manifest.json")
+		if _, err := os.Stat(manifestPath); err != nil {
+			t.Fatalf("The pack wasn't exported to %q: %v", manifestPath, err)
+		}
+	}
+	for _, override := range []string{"dist", "target=dist", "path="} {
+		err = regolith.Run(
+			"dev", regolith.RunOptions{ExportTarget: override}, true)
+		if err == nil {
+			t.Fatalf("The invalid override %q didn't fail", override)
+		}
+	}
+}
@@ -65,7 +65,7 @@ func TestMoveFilesAcl(t *testing.T) {
 	// Switch wd to wrokingDir
 	os.Chdir(workingDir)
 	// Get the name of the config from config
-	configJson, err := regolith.LoadConfigAsMap()
+	configJson, err := regolith.LoadConfigAsMap(regolith.ConfigFilePath)
 	if err != nil {
 		t.Fatal(err.Error())
 	}
@@ -80,7 +80,7 @@ func TestMoveFilesAcl(t *testing.T) {
 		mojangDir, "development_resource_packs", config.Name+"_rp")
 	os.Chdir(workingDir)
 	// THE TEST
-	err = regolith.Run("dev", true)
+	err = regolith.Run("dev", regolith.RunOptions{}, true)
 	if err != nil {
 		t.Fatal("'regolith run' failed:", err)
 	}
@@ -0,0 +1,170 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestFailFast runs a profile with failing filters with and without the
+// "--fail-fast=false" and "--export-on-error" flags, and checks which
+// filters ran, which failures were reported and whether the project was
+// exported.
+func TestFailFast(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(failFastPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	for _, c := range []struct {
+		name     string
+		options  regolith.RunOptions
+		failures []string
+		passed   []string
+		exported bool
+	}{
+		{
+			name:     "fail fast",
+			options:  regolith.RunOptions{},
+			failures: []string{"first_failure"},
+			passed:   []string{"writer", "second_failure"},
+		},
+		{
+			name:     "continue on error",
+			options:  regolith.RunOptions{ContinueOnError: true},
+			failures: []string{"first_failure", "second_failure"},
+			passed:   []string{"writer"},
+		},
+		{
+			name: "export on error",
+			options: regolith.RunOptions{
+				ContinueOnError: true, ExportOnError: true},
+			failures: []string{"first_failure", "second_failure"},
+			passed:   []string{"writer"},
+			exported: true,
+		},
+	} {
+		os.RemoveAll("build")
+		err := regolith.Run("default", c.options, true)
+		if err == nil {
+			t.Fatalf("%s: 'regolith run' didn't fail.", c.name)
+		}
+		for _, filter := range c.failures {
+			if !strings.Contains(err.Error(), "Filter: "+filter) {
+				t.Fatalf(
+					"%s: The error doesn't report the failure of the %q "+
+						"filter:\n%s", c.name, filter, err.Error())
+			}
+		}
+		for _, filter := range c.passed {
+			if strings.Contains(err.Error(), "Filter: "+filter) {
+				t.Fatalf(
+					"%s: The error reports the failure of the %q filter:\n%s",
+					c.name, filter, err.Error())
+			}
+		}
+		_, err = os.Stat(filepath.Join("build", "BP", "writer.txt"))
+		if c.exported && err != nil {
+			t.Fatalf(
+				"%s: The result of the successful filter wasn't exported.",
+				c.name)
+		} else if !c.exported && err == nil {
+			t.Fatalf("%s: The project was exported.", c.name)
+		}
+	}
+}
+
+// TestFailFastRestoredFilter runs a profile with a cacheable filter followed
+// by a failing filter twice with the "--fail-fast=false" and
+// "--export-on-error" flags, and checks whether the filter restored from the
+// cache counts as successful, so the project is exported both times.
+func TestFailFastRestoredFilter(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(failFastPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	options := regolith.RunOptions{ContinueOnError: true, ExportOnError: true}
+	// The first run caches the output of the filter, the second one
+	// restores it
+	for _, run := range []string{"first", "second"} {
+		os.RemoveAll("build")
+		err := regolith.Run("cached", options, true)
+		if err == nil {
+			t.Fatalf("The %s run didn't fail.", run)
+		}
+		if !strings.Contains(err.Error(), "Filter: second_failure") ||
+			strings.Contains(err.Error(), "Filter: cached_writer") {
+			t.Fatalf(
+				"The %s run reported unexpected failures:\n%s",
+				run, err.Error())
+		}
+		_, err = os.Stat(filepath.Join("build", "BP", "cached_writer.txt"))
+		if err != nil {
+			t.Fatalf(
+				"The result of the successful filter wasn't exported after "+
+					"the %s run.", run)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestFilterCache runs a test that checks whether the output of a cacheable
+// filter is reused when its input doesn't change and regenerated when it
+// does, and whether the "--clean" flag of "regolith run" removes it.
+func TestFilterCache(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(filterCachePath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	timePath := filepath.Join("build", "BP", "time.txt")
+	runAndRead := func(options regolith.RunOptions) string {
+		if err := regolith.Run("default", options, true); err != nil {
+			t.Fatal("'regolith run' failed:", err.Error())
+		}
+		content, err := ioutil.ReadFile(timePath)
+		if err != nil {
+			t.Fatalf("Failed to read %q: %s", timePath, err)
+		}
+		return string(content)
+	}
+	first := runAndRead(regolith.RunOptions{})
+	if second := runAndRead(regolith.RunOptions{}); second != first {
+		t.Fatal("The output of the filter wasn't restored from the cache")
+	}
+	// Changing the input must invalidate the cache
+	err = ioutil.WriteFile(
+		filepath.Join("packs", "BP", "new_file.txt"), []byte("new"), 0644)
+	if err != nil {
+		t.Fatal("Failed to modify the behavior pack:", err)
+	}
+	third := runAndRead(regolith.RunOptions{})
+	if third == first {
+		t.Fatal("The cache was used even though the input of the filter changed")
+	}
+	// The "--clean" flag must remove the cache
+	if fourth := runAndRead(regolith.RunOptions{Clean: true}); fourth == third {
+		t.Fatal("The cache was used even though the run used the \"--clean\" flag")
+	}
+}
@@ -0,0 +1,79 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestFilterDataMerge checks whether the files from the "data" folder of a
+// remote filter with the "exportData" property are copied to the data
+// folder of the filter in the tmp directory without overwriting the files
+// from the data folder of the project.
+func TestFilterDataMerge(t *testing.T) {
+	if _, err := exec.LookPath("cp"); err != nil {
+		t.Skip("The test requires cp")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(filterDataPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	err = regolith.Run("dev", regolith.RunOptions{}, true)
+	if err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	expectedFiles := map[string]string{
+		// The file of the project is not overwritten
+		"build/BP/lang.txt": "user lang",
+		// The missing file is copied from the filter
+		"build/BP/defaults.txt": "default",
+		// The data of the filter is exported back to the project
+		"packs/data/data_filter/lang.txt":     "user lang",
+		"packs/data/data_filter/defaults.txt": "default",
+	}
+	for path, expected := range expectedFiles {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %q: %v", path, err)
+		}
+		if string(content) != expected {
+			t.Fatalf(
+				"Unexpected content of %q.\nExpected: %q\nActual: %q",
+				path, expected, string(content))
+		}
+	}
+}
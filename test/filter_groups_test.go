@@ -0,0 +1,70 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestFilterGroupRollback updates a filter group whose filters can't be
+// downloaded and checks whether the previously installed filter of the
+// group is restored and the lock file is not created.
+func TestFilterGroupRollback(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(filterGroupsPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	installedFilter := filepath.Join(
+		".regolith", "cache", "filters", "group_filter_a", "filter.json")
+	expected, err := ioutil.ReadFile(filepath.Join(tmpDir, installedFilter))
+	if err != nil {
+		t.Fatal("Unable to read the installed filter:", err)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.Update([]string{"suite"}, false, "", true); err == nil {
+		t.Fatal("'regolith update' didn't fail on a filter group that " +
+			"can't be downloaded")
+	}
+	restored, err := ioutil.ReadFile(installedFilter)
+	if err != nil {
+		t.Fatal("The installed filter of the group wasn't restored:", err)
+	}
+	if string(restored) != string(expected) {
+		t.Fatal("The restored filter doesn't match the installed filter")
+	}
+	if _, err := os.Stat("regolith-lock.json"); !os.IsNotExist(err) {
+		t.Fatal("'regolith update' created the lock file after a failure")
+	}
+}
@@ -0,0 +1,81 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestFilterLogs runs a test that checks whether "regolith run --filter-logs"
+// saves the output of the filters to their log files and points at the log
+// file of the failed filter in the error message.
+func TestFilterLogs(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(filterLogsPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	options := regolith.RunOptions{FilterLogs: true}
+	if err := regolith.Run("default", options, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	logPath := filepath.Join(".regolith", "logs", "default", "print_output.log")
+	log, err := ioutil.ReadFile(logPath)
+	if err != nil {
+		t.Fatal("Unable to read the log file of the filter:", err)
+	}
+	// The stdout and the stderr are read concurrently, so the order of
+	// their lines isn't checked
+	for _, line := range []string{
+		"Hello from stdout\n", "[stderr] Hello from stderr\n",
+	} {
+		if !strings.Contains(string(log), line) {
+			t.Fatalf(
+				"The log file doesn't contain the output of the filter.\n"+
+					"Expected line: %q\nLog file: %q", line, string(log))
+		}
+	}
+	err = regolith.Run("failing", options, true)
+	if err == nil {
+		t.Fatal("'regolith run' didn't fail with the failing filter.")
+	}
+	logPath = filepath.Join(".regolith", "logs", "failing", "print_output.log")
+	if !strings.Contains(err.Error(), logPath) {
+		t.Fatalf(
+			"The error message doesn't contain the path to the log file.\n"+
+				"Path: %s\nError: %s", logPath, err.Error())
+	}
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestFilterRename runs a test that checks whether the 'regolith filter
+// rename' command renames the filter definition and updates its references
+// in all of the profiles, and whether it refuses to overwrite another filter.
+func TestFilterRename(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(filterRenamePath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.RenameFilter("old_name", "other_filter", "", true); err == nil {
+		t.Fatal("Renaming the filter to the name of another filter didn't fail")
+	}
+	if err := regolith.RenameFilter("missing", "new_name", "", true); err == nil {
+		t.Fatal("Renaming a filter that doesn't exist didn't fail")
+	}
+	if err := regolith.RenameFilter("old_name", "new_name", "", true); err != nil {
+		t.Fatal("'regolith filter rename' failed:", err.Error())
+	}
+	configMap, err := regolith.LoadConfigAsMap(regolith.ConfigFilePath)
+	if err != nil {
+		t.Fatal("Failed to load the config file:", err)
+	}
+	config, err := regolith.ConfigFromObject(configMap)
+	if err != nil {
+		t.Fatal("The config file is invalid after renaming the filter:", err)
+	}
+	if _, ok := config.FilterDefinitions["old_name"]; ok {
+		t.Fatal("The old filter definition wasn't removed")
+	}
+	if _, ok := config.FilterDefinitions["new_name"]; !ok {
+		t.Fatal("The filter definition wasn't renamed")
+	}
+	expected := map[string][]string{
+		"default": {"new_name", "other_filter"},
+		"second":  {"new_name", ""},
+	}
+	regolithMap := configMap["regolith"].(map[string]interface{})
+	for profileName, expectedFilters := range expected {
+		profile := regolithMap["profiles"].(map[string]interface{})[profileName]
+		filters := profile.(map[string]interface{})["filters"].([]interface{})
+		actualFilters := []string{}
+		for _, filter := range filters {
+			name, _ := filter.(map[string]interface{})["filter"].(string)
+			actualFilters = append(actualFilters, name)
+		}
+		expectedJson, _ := json.Marshal(expectedFilters)
+		actualJson, _ := json.Marshal(actualFilters)
+		if string(expectedJson) != string(actualJson) {
+			t.Fatalf(
+				"Unexpected filters of the %q profile.\nExpected: %s\n"+
+					"Actual: %s", profileName, expectedJson, actualJson)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestFilterRetries runs a test that checks whether the filters with the
+// "retries" property run again after failing, starting every attempt with
+// the files from before the first attempt.
+func TestFilterRetries(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(filterRetriesPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	expectedBuildResult, err := filepath.Abs(
+		filepath.Join(filterRetriesPath, "expected_build_result"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the expected build result:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	t.Log("Testing the filter with enough retries...")
+	if err := regolith.Run("default", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	// Load expected result
+	expectedPaths, err := listPaths(expectedBuildResult, expectedBuildResult)
+	if err != nil {
+		t.Fatalf("Failed to load the expected results: %s", err)
+	}
+	// Load actual result
+	tmpDirBuild := filepath.Join(tmpDir, "build")
+	actualPaths, err := listPaths(tmpDirBuild, tmpDirBuild)
+	if err != nil {
+		t.Fatalf("Failed to load the actual results: %s", err)
+	}
+	// Compare the results
+	comparePathMaps(expectedPaths, actualPaths, t)
+	t.Log("Testing the filter without enough retries...")
+	if err := regolith.Run("not_enough_retries", regolith.RunOptions{}, true); err == nil {
+		t.Fatal("'regolith run' succeeded, but the filter failed twice.")
+	}
+}
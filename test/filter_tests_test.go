@@ -0,0 +1,75 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestRunFilterTests checks whether 'regolith test' passes the fixture with
+// the expected output of the filter and fails the fixture with a different
+// output.
+func TestRunFilterTests(t *testing.T) {
+	if _, err := exec.LookPath("cp"); err != nil {
+		t.Skip("The test requires cp")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(filterTestsPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	err = regolith.RunFilterTests("copy", []string{"pass"}, "tests", "", true)
+	if err != nil {
+		t.Fatal("'regolith test' failed on the passing fixture:", err.Error())
+	}
+	err = regolith.RunFilterTests("copy", nil, "tests", "", true)
+	if err == nil {
+		t.Fatal("'regolith test' didn't fail on the failing fixture")
+	}
+	err = regolith.RunFilterTests("copy", []string{"missing"}, "tests", "", true)
+	if err == nil {
+		t.Fatal("'regolith test' didn't fail on a missing fixture")
+	}
+	// The config file can be selected with the "--config" flag
+	if err := os.Rename("config.json", "test-config.json"); err != nil {
+		t.Fatal("Unable to rename the config file:", err)
+	}
+	err = regolith.RunFilterTests(
+		"copy", []string{"pass"}, "tests", "test-config.json", true)
+	if err != nil {
+		t.Fatal("'regolith test --config' failed:", err.Error())
+	}
+}
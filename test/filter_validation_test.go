@@ -0,0 +1,64 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestFilterValidation runs a test that checks whether the "validate"
+// property of a filter accepts the valid output of the filter and stops the
+// run when the output is invalid.
+func TestFilterValidation(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(filterValidationPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.Run("valid", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed on the valid output:", err.Error())
+	}
+	for _, profile := range []string{
+		"invalid_json", "missing_file", "failing_command",
+	} {
+		err := regolith.Run(profile, regolith.RunOptions{}, true)
+		if err == nil {
+			t.Fatalf(
+				"'regolith run' didn't fail on the invalid output.\n"+
+					"Profile: %s", profile)
+		}
+		t.Logf("Profile %q failed successfully: %s", profile, err.Error())
+	}
+}
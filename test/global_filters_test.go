@@ -0,0 +1,92 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestGlobalFilters runs a profile that uses the global filter definitions
+// and checks whether the filter definitions of the project take precedence
+// over the global ones. With the global filters disabled, the profile must
+// fail to load.
+func TestGlobalFilters(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(globalFiltersPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// Use a temporary user config directory with the global filter
+	// definitions
+	configDir, err := ioutil.TempDir("", "regolith-test-config")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(configDir)
+	for _, env := range []string{"XDG_CONFIG_HOME", "AppData", "HOME"} {
+		t.Setenv(env, configDir)
+	}
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Fatal("Unable to get the user config directory:", err)
+	}
+	err = copy.Copy(
+		filepath.Join(globalFiltersPath, "filters.json"),
+		filepath.Join(userConfigDir, "regolith", "filters.json"))
+	if err != nil {
+		t.Fatal("Unable to copy the global filter definitions:", err)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.Run("default", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	if _, err := os.Stat(filepath.Join("build", "BP", "global_filter.txt")); err != nil {
+		t.Fatal("The global filter didn't run.")
+	}
+	shared, err := ioutil.ReadFile(filepath.Join("build", "BP", "shared.txt"))
+	if err != nil {
+		t.Fatal("Unable to read the output of the shared filter:", err)
+	}
+	if string(shared) != "project" {
+		t.Fatalf(
+			"The global filter definition overrode the definition of the "+
+				"project. Output: %q", string(shared))
+	}
+	// Without the global filters the profile references an unknown filter
+	regolith.NoGlobalFilters = true
+	defer func() { regolith.NoGlobalFilters = false }()
+	if err := regolith.Run("default", regolith.RunOptions{}, true); err == nil {
+		t.Fatal("'regolith run' didn't fail without the global filters.")
+	}
+}
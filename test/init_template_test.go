@@ -0,0 +1,92 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestInitTemplate initializes a project from a local template repository
+// without a "config.json" file, which should fail and leave the project
+// directory empty, and then from the template with a valid config file.
+func TestInitTemplate(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	config, err := filepath.Abs(
+		filepath.Join(archiveExportPath, "project", "config.json"))
+	if err != nil {
+		t.Fatal("Unable to get absolute path to the test config:", err)
+	}
+	templateDir := filepath.Join(tmpDir, "template")
+	projectDir := filepath.Join(tmpDir, "project")
+	for _, dir := range []string{templateDir, projectDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal("Unable to create the directory:", err)
+		}
+	}
+	// The template without the config file
+	err = ioutil.WriteFile(
+		filepath.Join(templateDir, "README.md"), []byte("Template"), 0644)
+	if err != nil {
+		t.Fatal("Unable to create the template:", err)
+	}
+	commitTemplate := func(args ...[]string) {
+		for _, args := range args {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = templateDir
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("Unable to create the template repository: %s\n%s",
+					err, out)
+			}
+		}
+	}
+	commitArgs := []string{
+		"-c", "user.name=test", "-c", "user.email=test@example.com",
+		"commit", "-m", "Template"}
+	commitTemplate([]string{"init"}, []string{"add", "."}, commitArgs)
+
+	// THE TEST
+	os.Chdir(projectDir)
+	if err := regolith.Init(true, templateDir); err == nil {
+		t.Fatal("'regolith init' didn't fail for a template without a config")
+	}
+	if empty, err := regolith.IsDirEmpty(projectDir); err != nil {
+		t.Fatal("Unable to check the project directory:", err)
+	} else if !empty {
+		t.Fatal("The failed 'regolith init' left files in the project")
+	}
+	// The template with the config file
+	err = copy.Copy(config, filepath.Join(templateDir, "config.json"))
+	if err != nil {
+		t.Fatal("Unable to copy the config to the template:", err)
+	}
+	commitTemplate([]string{"add", "."}, commitArgs)
+	if err := regolith.Init(true, templateDir); err != nil {
+		t.Fatal("'regolith init' failed:", err.Error())
+	}
+	for _, name := range []string{"config.json", "README.md"} {
+		if _, err := os.Stat(filepath.Join(projectDir, name)); err != nil {
+			t.Errorf("The %q file of the template wasn't copied", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, ".git")); err == nil {
+		t.Error("The \".git\" folder of the template wasn't removed")
+	}
+}
@@ -0,0 +1,51 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+)
+
+// TestLoadFilterRequirements checks whether the requirements file of the
+// "regolith install --file" command is parsed correctly, skipping the
+// comments and the empty lines.
+func TestLoadFilterRequirements(t *testing.T) {
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	defer os.RemoveAll(tmpDir)
+	// THE TEST
+	path := filepath.Join(tmpDir, "filters.txt")
+	content := "# Filters of the project\n" +
+		"name_ninja==1.0.0\n" +
+		"\n" +
+		"  github.com/Bedrock-OSS/regolith-filters/json_cleaner==HEAD  \r\n" +
+		"#disabled_filter\n"
+	err = ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal("Unable to write the requirements file:", err)
+	}
+	filters, err := regolith.LoadFilterRequirements(path)
+	if err != nil {
+		t.Fatal("Failed to load the requirements file:", err)
+	}
+	expected := []string{
+		"name_ninja==1.0.0",
+		"github.com/Bedrock-OSS/regolith-filters/json_cleaner==HEAD",
+	}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Fatalf(
+			"Unexpected filters.\nExpected: %v\nActual: %v", expected, filters)
+	}
+	_, err = regolith.LoadFilterRequirements(filepath.Join(tmpDir, "missing"))
+	if err == nil {
+		t.Fatal("Loading a missing requirements file didn't fail")
+	}
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestIsolateEnv checks whether the environment variables of Regolith are
+// hidden from the filters when the environment is isolated and whether they
+// can be passed explicitly with the AllowedEnv option. The metadata of the
+// project must be passed to the filters in all cases.
+func TestIsolateEnv(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(isolateEnvPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	os.Setenv("REGOLITH_TEST_SECRET", "secret")
+	defer os.Unsetenv("REGOLITH_TEST_SECRET")
+	cases := []struct {
+		options  regolith.RunOptions
+		expected string
+	}{
+		{regolith.RunOptions{}, "secret"},
+		{regolith.RunOptions{IsolateEnv: true}, "<missing>"},
+		{
+			regolith.RunOptions{
+				IsolateEnv: true,
+				AllowedEnv: []string{"REGOLITH_TEST_SECRET"},
+			},
+			"secret",
+		},
+	}
+	for _, c := range cases {
+		if err := regolith.Run("default", c.options, true); err != nil {
+			t.Fatal("'regolith run' failed:", err.Error())
+		}
+		result, err := ioutil.ReadFile(filepath.Join("build", "BP", "env.txt"))
+		if err != nil {
+			t.Fatal("Unable to read the output of the filter:", err)
+		}
+		if string(result) != c.expected {
+			t.Fatalf(
+				"Unexpected value of the environment variable.\n"+
+					"Options: %+v\nExpected: %q\nActual: %q",
+				c.options, c.expected, string(result))
+		}
+		project, err := ioutil.ReadFile(
+			filepath.Join("build", "BP", "project.txt"))
+		if err != nil {
+			t.Fatal("Unable to read the output of the filter:", err)
+		}
+		if string(project) != "regolith_test_project|Bedrock-OSS" {
+			t.Fatalf(
+				"Unexpected project metadata passed to the filter.\n"+
+					"Options: %+v\nActual: %q",
+				c.options, string(project))
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestLinkPacks runs a test that checks whether the "linkPacks" option of
+// the export target adds the missing dependencies between the manifests of
+// the exported packs without duplicating the existing ones.
+func TestLinkPacks(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(linkPacksPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.Run("default", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	// Maps the packs to the UUIDs of their dependencies
+	expectedDependencies := map[string][]string{
+		"BP": {"6f6e3f0b-1627-488d-a9aa-2d1430ba368a"},
+		"RP": {"96b53fd2-b7a1-4d26-b74f-1b9394c8d0bc"},
+	}
+	for pack, expected := range expectedDependencies {
+		path := filepath.Join("build", pack, "manifest.json")
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal("Unable to read the exported manifest:", err)
+		}
+		var manifest struct {
+			Dependencies []struct {
+				Uuid string `json:"uuid"`
+			} `json:"dependencies"`
+		}
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			t.Fatal("Unable to parse the exported manifest:", err)
+		}
+		actual := []string{}
+		for _, dependency := range manifest.Dependencies {
+			actual = append(actual, dependency.Uuid)
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			t.Fatalf(
+				"Unexpected dependencies of the %s.\nExpected: %v\nActual: %v",
+				pack, expected, actual)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package test
 import (
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Bedrock-OSS/regolith/regolith"
@@ -40,7 +42,7 @@ func TestRegolithInit(t *testing.T) {
 		t.Fatal("Unable to change working directory:", err.Error())
 	}
 	// THE TEST
-	err = regolith.Init(true)
+	err = regolith.Init(true, "")
 	if err != nil {
 		t.Fatal("'regolith init' failed:", err.Error())
 	}
@@ -51,6 +53,69 @@ func TestRegolithInit(t *testing.T) {
 	comparePathMaps(expectedPaths, createdPaths, t)
 }
 
+// TestRegolithInitTemplate tests whether InitializeRegolithProject with a
+// template copies the files of the template repository without its ".git"
+// folder. The template is a git repository created from
+// test/testdata/minimal_project.
+func TestRegolithInitTemplate(t *testing.T) {
+	// Switching working directories in this test, make sure to go back
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Get paths expected in initialized project
+	expectedPaths, err := listPaths(minimalProjectPath, minimalProjectPath)
+	if err != nil {
+		t.Fatal("Unable to get list of expected paths:", err)
+	}
+	// Create temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary path:", tmpDir)
+	// Before removing working dir make sure the script isn't using it anymore
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Create the template repository
+	templateDir := filepath.Join(tmpDir, "template")
+	err = copy.Copy(minimalProjectPath, templateDir)
+	if err != nil {
+		t.Fatal("Unable to copy the template project:", err)
+	}
+	for _, args := range [][]string{
+		{"init"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com",
+			"commit", "-m", "Template"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = templateDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("Unable to create the template repository: %s\n%s", err, out)
+		}
+	}
+	// Change working directory to an empty project path
+	projectDir := filepath.Join(tmpDir, "project")
+	if err := os.Mkdir(projectDir, 0755); err != nil {
+		t.Fatal("Unable to create the project directory:", err)
+	}
+	if err := os.Chdir(projectDir); err != nil {
+		t.Fatal("Unable to change working directory:", err.Error())
+	}
+	// THE TEST
+	err = regolith.Init(true, templateDir)
+	if err != nil {
+		t.Fatal("'regolith init --template' failed:", err.Error())
+	}
+	createdPaths, err := listPaths(".", ".")
+	if err != nil {
+		t.Fatal("Unable to get list of created paths:", err)
+	}
+	comparePathMaps(expectedPaths, createdPaths, t)
+}
+
 // TestRegolithRunMissingRp tests the behavior of RunProfile when the packs/RP
 // directory is missing.
 func TestRegolithRunMissingRp(t *testing.T) {
@@ -86,7 +151,7 @@ func TestRegolithRunMissingRp(t *testing.T) {
 	// Switch to the working directory
 	os.Chdir(tmpDir)
 	// THE TEST
-	err = regolith.Run("dev", true)
+	err = regolith.Run("dev", regolith.RunOptions{}, true)
 	if err != nil {
 		t.Fatal("'regolith run' failed:", err)
 	}
@@ -125,11 +190,11 @@ func TestLocalRequirementsInstallAndRun(t *testing.T) {
 	// Switch to the working directory
 	os.Chdir(filepath.Join(tmpDir, "project"))
 	// THE TEST
-	err = regolith.InstallAll(false, true)
+	err = regolith.InstallAll(regolith.InstallOptions{}, true)
 	if err != nil {
 		t.Fatal("'regolith install-all' failed", err.Error())
 	}
-	if err := regolith.Run("dev", true); err != nil {
+	if err := regolith.Run("dev", regolith.RunOptions{}, true); err != nil {
 		t.Fatal("'regolith run' failed:", err.Error())
 	}
 }
@@ -175,7 +240,80 @@ func TestExeFilterRun(t *testing.T) {
 	}
 	// THE TEST
 	os.Chdir(tmpDir)
-	if err := regolith.Run("dev", true); err != nil {
+	if err := regolith.Run("dev", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	// Load expected result
+	expectedPaths, err := listPaths(expectedBuildResult, expectedBuildResult)
+	if err != nil {
+		t.Fatalf("Failed to load the expected results: %s", err)
+	}
+	// Load actual result
+	tmpDirBuild := filepath.Join(tmpDir, "build")
+	actualPaths, err := listPaths(tmpDirBuild, tmpDirBuild)
+	if err != nil {
+		t.Fatalf("Failed to load the actual results: %s", err)
+	}
+	// Compare the results
+	comparePathMaps(expectedPaths, actualPaths, t)
+}
+
+// TestShellScriptFilterRun tests running the shell filters with the "script"
+// property. The script of the valid profile writes its argument to a file, the
+// script of the invalid profile exits with a non-zero exit code.
+func TestShellScriptFilterRun(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("The test requires sh")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(
+		filepath.Join(shellScriptFilterPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	expectedBuildResult, err := filepath.Abs(
+		filepath.Join(shellScriptFilterPath, "expected_build_result"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the expected build result:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	t.Log("Running the script that fails (this should fail)")
+	if err := regolith.Run("fail", regolith.RunOptions{}, true); err == nil {
+		t.Fatal("'regolith run' didn't return an error after running a " +
+			"failing script")
+	} else if !strings.Contains(err.Error(), "Exit code: 3") {
+		t.Fatal("The error doesn't contain the exit code:", err.Error())
+	}
+	t.Log("Running the valid script")
+	if err := regolith.Run("dev", regolith.RunOptions{}, true); err != nil {
 		t.Fatal("'regolith run' failed:", err.Error())
 	}
 	// Load expected result
@@ -239,7 +377,7 @@ func TestProfileFilterRun(t *testing.T) {
 	t.Log("Running invalid profile filter with circular " +
 		"dependencies (this should fail)")
 	if err := regolith.Run(
-		"invalid_circular_profile_1", true); err == nil {
+		"invalid_circular_profile_1", regolith.RunOptions{}, true); err == nil {
 		t.Fatal("'regolith run' didn't return an error after running"+
 			" a circular profile filter:", err.Error())
 	} else {
@@ -247,7 +385,7 @@ func TestProfileFilterRun(t *testing.T) {
 	}
 	t.Log("Running valid profile filter ")
 	if err := regolith.Run(
-		"correct_nested_profile", true); err != nil {
+		"correct_nested_profile", regolith.RunOptions{}, true); err != nil {
 		t.Fatal("'regolith run' failed:", err.Error())
 	}
 	// Load expected result
@@ -0,0 +1,79 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestLocalRegistry installs a filter from the local registry, runs it,
+// modifies the source of the filter and runs it again without reinstalling.
+// The second run should use the modified filter.
+func TestLocalRegistry(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(localRegistryPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.InstallAll(regolith.InstallOptions{}, true); err != nil {
+		t.Fatal("'regolith install-all' failed:", err.Error())
+	}
+	messagePath := filepath.Join("registry", "linked_filter", "message.txt")
+	outputPath := filepath.Join("build", "BP", "out.txt")
+	for _, message := range []string{"first", "second"} {
+		err := ioutil.WriteFile(messagePath, []byte(message), 0644)
+		if err != nil {
+			t.Fatal("Unable to modify the filter:", err)
+		}
+		if err := regolith.Run("default", regolith.RunOptions{}, true); err != nil {
+			t.Fatal("'regolith run' failed:", err.Error())
+		}
+		output, err := ioutil.ReadFile(outputPath)
+		if err != nil {
+			t.Fatal("Unable to read the output of the filter:", err)
+		}
+		if string(output) != message {
+			t.Fatalf("Unexpected output of the filter: %q, expected: %q",
+				string(output), message)
+		}
+	}
+	// Reinstalling the filter must not affect its source
+	if err := regolith.InstallAll(regolith.InstallOptions{Force: true}, true); err != nil {
+		t.Fatal("'regolith install-all --force' failed:", err.Error())
+	}
+	if _, err := os.Stat(messagePath); err != nil {
+		t.Fatal("Reinstalling the filter removed its source files:", err)
+	}
+}
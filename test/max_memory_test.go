@@ -0,0 +1,68 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestMaxMemory runs a filter that allocates 256 MiB of memory with a lower
+// and a higher memory limit, and checks whether only the run with the lower
+// limit fails. The memory limit is only supported on Linux.
+func TestMaxMemory(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("The memory limit is only supported on Linux.")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(maxMemoryPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	err = regolith.Run(
+		"default", regolith.RunOptions{MaxMemory: "64M"}, true)
+	if err == nil {
+		t.Fatal("'regolith run' didn't fail with the lower memory limit.")
+	}
+	if !strings.Contains(err.Error(), "Memory limit: 64.0 MiB") {
+		t.Fatalf("The error doesn't mention the memory limit:\n%s", err.Error())
+	}
+	err = regolith.Run(
+		"default", regolith.RunOptions{MaxMemory: "1G"}, true)
+	if err != nil {
+		t.Fatal("'regolith run' failed with the higher memory limit:", err)
+	}
+}
@@ -0,0 +1,66 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestOffline runs a test that checks whether the "--offline" flag lets
+// Regolith run the profiles that use only the local filters and makes
+// 'regolith install' fail without accessing the network.
+func TestOffline(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(outputScopePath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	regolith.Offline = true
+	defer func() { regolith.Offline = false }()
+	if err := regolith.Run("default", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed in the offline mode:", err.Error())
+	}
+	err = regolith.Install(
+		[]string{"github.com/Bedrock-OSS/regolith-test-filters/" +
+			"hello-version-python-filter==1.0.0"},
+		regolith.InstallOptions{}, true)
+	if err == nil {
+		t.Fatal("'regolith install' didn't fail in the offline mode.")
+	}
+	if !strings.Contains(err.Error(), "offline mode") {
+		t.Fatal("'regolith install' failed with unexpected error:", err)
+	}
+}
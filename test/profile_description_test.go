@@ -0,0 +1,87 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestProfileDescription checks whether the descriptions of the profiles are
+// printed by "regolith list-profiles" and listed in the error of running a
+// profile that doesn't exist.
+func TestProfileDescription(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(profileDescriptionPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	expected := "- default: Development build exported to Minecraft\n" +
+		"- release: Release build for the marketplace\n" +
+		"- undocumented"
+	// The profiles are printed to the standard output
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal("Unable to create a pipe:", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	err = regolith.ListProfiles("", true)
+	os.Stdout = stdout
+	writer.Close()
+	if err != nil {
+		t.Fatal("'regolith list-profiles' failed:", err.Error())
+	}
+	output, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal("Unable to read the output:", err)
+	}
+	if actual := strings.TrimSpace(string(output)); actual != expected {
+		t.Fatalf(
+			"Unexpected list of the profiles.\nExpected:\n%s\nActual:\n%s",
+			expected, actual)
+	}
+	err = regolith.Run("missing", regolith.RunOptions{}, true)
+	if err == nil {
+		t.Fatal("'regolith run' didn't fail for a profile that doesn't exist")
+	}
+	// The lines of the error are indented
+	for _, line := range strings.Split(expected, "\n") {
+		if !strings.Contains(err.Error(), line) {
+			t.Fatalf(
+				"The error doesn't list %q.\nError: %s", line, err.Error())
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestProfileDiff runs a test that checks whether "regolith profile-diff
+// --json" reports the added, removed, moved and changed filters and the
+// changed export targets of two profiles.
+func TestProfileDiff(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(profileDiffPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	// The differences are printed to the standard output
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal("Unable to create a pipe:", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	err = regolith.ProfileDiff("a", "b", true, "", true)
+	os.Stdout = stdout
+	writer.Close()
+	if err != nil {
+		t.Fatal("'regolith profile-diff' failed:", err.Error())
+	}
+	output, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal("Unable to read the output:", err)
+	}
+	var diff struct {
+		Added          []string `json:"addedFilters"`
+		Removed        []string `json:"removedFilters"`
+		Moved          []string `json:"movedFilters"`
+		ChangedFilters []struct {
+			Filter  string `json:"filter"`
+			Changes []struct {
+				Property string `json:"property"`
+			} `json:"changes"`
+		} `json:"changedFilters"`
+		ChangedExportTargets []struct {
+			Target  string `json:"target"`
+			Changes []struct {
+				Property string      `json:"property"`
+				A        interface{} `json:"a"`
+				B        interface{} `json:"b"`
+			} `json:"changes"`
+		} `json:"changedExportTargets"`
+	}
+	if err := json.Unmarshal(output, &diff); err != nil {
+		t.Fatalf("Unable to parse the output: %s\nOutput: %s", err, output)
+	}
+	for _, list := range []struct {
+		name             string
+		expected, actual []string
+	}{
+		{"added", []string{"fourth"}, diff.Added},
+		{"removed", []string{"third"}, diff.Removed},
+		{"moved", []string{"first"}, diff.Moved},
+	} {
+		if !reflect.DeepEqual(list.expected, list.actual) {
+			t.Fatalf(
+				"Unexpected %s filters.\nExpected: %v\nActual: %v",
+				list.name, list.expected, list.actual)
+		}
+	}
+	if len(diff.ChangedFilters) != 1 ||
+		diff.ChangedFilters[0].Filter != "first" ||
+		len(diff.ChangedFilters[0].Changes) != 1 ||
+		diff.ChangedFilters[0].Changes[0].Property != "settings" {
+		t.Fatalf("Unexpected changed filters.\nOutput: %s", output)
+	}
+	if len(diff.ChangedExportTargets) != 1 ||
+		diff.ChangedExportTargets[0].Target != "export" {
+		t.Fatalf("Unexpected changed export targets.\nOutput: %s", output)
+	}
+	changes := map[string][2]interface{}{}
+	for _, change := range diff.ChangedExportTargets[0].Changes {
+		changes[change.Property] = [2]interface{}{change.A, change.B}
+	}
+	expectedChanges := map[string][2]interface{}{
+		"target":   {"development", "local"},
+		"readOnly": {false, true},
+	}
+	if !reflect.DeepEqual(expectedChanges, changes) {
+		t.Fatalf(
+			"Unexpected changes of the export target.\nExpected: %v\n"+
+				"Actual: %v", expectedChanges, changes)
+	}
+	// The profiles that don't exist are reported
+	if err := regolith.ProfileDiff("a", "missing", false, "", true); err == nil {
+		t.Fatal("'regolith profile-diff' didn't fail with a missing profile.")
+	}
+	// The config file can be selected with the "--config" flag
+	if err := os.Rename("config.json", "diff-config.json"); err != nil {
+		t.Fatal("Unable to rename the config file:", err)
+	}
+	err = regolith.ProfileDiff("a", "b", false, "diff-config.json", true)
+	if err != nil {
+		t.Fatal("'regolith profile-diff --config' failed:", err.Error())
+	}
+	if err := regolith.ProfileDiff("a", "b", false, "", true); err == nil {
+		t.Fatal("'regolith profile-diff' didn't fail without \"config.json\".")
+	}
+}
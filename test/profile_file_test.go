@@ -0,0 +1,76 @@
+package test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestProfileFile runs a profile from a file that uses a filter defined in
+// the config file. The filter should run without modifying the config
+// file. Running the profile from the file with the name of an existing
+// profile should fail.
+func TestProfileFile(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(profileFilePath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	config, err := ioutil.ReadFile("config.json")
+	if err != nil {
+		t.Fatal("Unable to read the config file:", err)
+	}
+	options := regolith.RunOptions{ProfileFile: "adhoc-profile.json"}
+	if err := regolith.Run("", options, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	_, err = os.Stat(filepath.Join("build", "BP", "project.txt"))
+	if err != nil {
+		t.Fatal("The filter of the profile from the file didn't run:", err)
+	}
+	newConfig, err := ioutil.ReadFile("config.json")
+	if err != nil {
+		t.Fatal("Unable to read the config file:", err)
+	}
+	if !bytes.Equal(config, newConfig) {
+		t.Fatal("Running the profile from the file modified the config file.")
+	}
+	if err := regolith.Run("default", options, true); err == nil {
+		t.Fatal(
+			"'regolith run' didn't fail with the profile from the file " +
+				"named like an existing profile.")
+	}
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestProfileHooks runs a test that checks whether the "preRun" and
+// "postRun" commands run around the build of the profile, and whether the
+// "postRun" commands run and receive the result even if the build fails.
+func TestProfileHooks(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(profileHooksPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	for _, c := range []struct {
+		profile  string
+		fails    bool
+		expected string
+	}{
+		{"default", false, "pre\npost true\n"},
+		{"failing", true, "pre\npost false\n"},
+	} {
+		os.Remove("hooks.txt")
+		err := regolith.Run(c.profile, regolith.RunOptions{}, true)
+		if c.fails && err == nil {
+			t.Fatalf("'regolith run %s' didn't fail.", c.profile)
+		} else if !c.fails && err != nil {
+			t.Fatalf("'regolith run %s' failed: %s", c.profile, err.Error())
+		}
+		hooks, err := ioutil.ReadFile("hooks.txt")
+		if err != nil {
+			t.Fatal("Unable to read the file created by the hooks:", err)
+		}
+		if string(hooks) != c.expected {
+			t.Fatalf(
+				"The hooks of the %q profile didn't run as expected.\n"+
+					"Expected: %q\nActual: %q",
+				c.profile, c.expected, string(hooks))
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestProfileFilterOverrides runs a profile that runs a nested profile twice
+// with different arguments and settings and checks whether they were merged
+// with the arguments and settings of the nested filters.
+func TestProfileFilterOverrides(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(profileOverridesPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.Run("release", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	type filterOutput struct {
+		Settings  map[string]interface{} `json:"settings"`
+		Arguments []string               `json:"arguments"`
+	}
+	cases := []struct {
+		file     string
+		expected filterOutput
+	}{
+		{
+			"args.json",
+			filterOutput{
+				Settings: map[string]interface{}{
+					"file": "args.json", "mode": "release"},
+				Arguments: []string{"base", "extra"},
+			},
+		},
+		{
+			"second.json",
+			filterOutput{
+				Settings: map[string]interface{}{
+					"file": "second.json", "mode": "debug"},
+				Arguments: []string{"base"},
+			},
+		},
+	}
+	for _, c := range cases {
+		data, err := ioutil.ReadFile(filepath.Join("build", "BP", c.file))
+		if err != nil {
+			t.Fatal("Unable to read the output of the filter:", err)
+		}
+		var actual filterOutput
+		if err := json.Unmarshal(data, &actual); err != nil {
+			t.Fatal("Unable to parse the output of the filter:", err)
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Fatalf(
+				"Unexpected arguments of the nested filter.\n"+
+					"File: %s\nExpected: %+v\nActual: %+v",
+				c.file, c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestProfileReport runs a test that checks whether the ProfileReport option
+// saves the execution times of the filters, also when one of the filters
+// fails.
+func TestProfileReport(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(profileReportPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	type reportEntry struct {
+		FilterId   string `json:"filterId"`
+		ExitStatus string `json:"exitStatus"`
+	}
+	type report struct {
+		Filters          []reportEntry `json:"filters"`
+		ExportDurationMs *int64        `json:"exportDurationMs"`
+	}
+	cases := []struct {
+		profile    string
+		shouldFail bool
+		expected   []reportEntry
+		hasExport  bool
+	}{
+		{
+			"default", false,
+			[]reportEntry{{"write_to_packs", "success"}},
+			true,
+		},
+		{
+			"failing", true,
+			[]reportEntry{
+				{"write_to_packs", "success"}, {"fail", "failure"}},
+			false,
+		},
+	}
+	for _, c := range cases {
+		reportPath := filepath.Join("reports", c.profile+".json")
+		err := regolith.Run(
+			c.profile, regolith.RunOptions{ProfileReport: reportPath}, true)
+		if c.shouldFail && err == nil {
+			t.Fatalf("Running the %q profile didn't fail", c.profile)
+		} else if !c.shouldFail && err != nil {
+			t.Fatal("'regolith run' failed:", err.Error())
+		}
+		data, err := ioutil.ReadFile(reportPath)
+		if err != nil {
+			t.Fatalf("Failed to read the report of the %q profile: %s", c.profile, err)
+		}
+		var actual report
+		if err := json.Unmarshal(data, &actual); err != nil {
+			t.Fatalf("Failed to parse the report of the %q profile: %s", c.profile, err)
+		}
+		expectedJson, _ := json.Marshal(c.expected)
+		actualJson, _ := json.Marshal(actual.Filters)
+		if string(expectedJson) != string(actualJson) {
+			t.Fatalf(
+				"Unexpected filters in the report of the %q profile.\n"+
+					"Expected: %s\nActual: %s", c.profile, expectedJson, actualJson)
+		}
+		if (actual.ExportDurationMs != nil) != c.hasExport {
+			t.Fatalf(
+				"Unexpected export duration in the report of the %q profile",
+				c.profile)
+		}
+	}
+}
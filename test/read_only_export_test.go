@@ -0,0 +1,80 @@
+package test
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestReadOnlyExport runs a profile that exports the packs with the
+// "readOnly" option twice. The exported files should be read-only after
+// both runs and the second run should be able to replace them.
+func TestReadOnlyExport(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(readOnlyExportPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	for i := 1; i <= 2; i++ {
+		err = regolith.Run("default", regolith.RunOptions{}, true)
+		if err != nil {
+			t.Fatalf("Run %d of 'regolith run' failed: %s", i, err.Error())
+		}
+		exported := 0
+		err = filepath.WalkDir("build", func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			exported++
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if info.Mode().Perm()&0222 != 0 {
+				t.Errorf(
+					"The exported file is writable after run %d.\n"+
+						"Path: %s\nMode: %s", i, path, info.Mode())
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal("Unable to list the exported files:", err)
+		}
+		if exported == 0 {
+			t.Fatalf("No files were exported in run %d.", i)
+		}
+	}
+}
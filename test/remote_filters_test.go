@@ -55,11 +55,11 @@ func TestInstallAllAndRun(t *testing.T) {
 	os.Chdir(workingDir)
 	// THE TEST
 	// Run InstallDependencies
-	err = regolith.InstallAll(false, true)
+	err = regolith.InstallAll(regolith.InstallOptions{}, true)
 	if err != nil {
 		t.Fatal("'regolith install-all' failed:", err)
 	}
-	err = regolith.Run("dev", true)
+	err = regolith.Run("dev", regolith.RunOptions{}, true)
 	if err != nil {
 		t.Fatal("'regolith run' failed:", err)
 	}
@@ -117,11 +117,11 @@ func TestDataModifyRemoteFilter(t *testing.T) {
 	os.Chdir(workingDir)
 	// THE TEST
 	// Run InstallDependencies
-	err = regolith.InstallAll(false, true)
+	err = regolith.InstallAll(regolith.InstallOptions{}, true)
 	if err != nil {
 		t.Fatal("'regolith install-all' failed:", err)
 	}
-	err = regolith.Run("default", true)
+	err = regolith.Run("default", regolith.RunOptions{}, true)
 	if err != nil {
 		t.Fatal("'regolith run' failed:", err)
 	}
@@ -174,7 +174,8 @@ func TestInstall(t *testing.T) {
 		expectedResultPath = filepath.Join(wd, expectedResultPath)
 		// Install the filter with given version
 		err := regolith.Install(
-			[]string{filterName + "==" + version}, true, true)
+			[]string{filterName + "==" + version},
+			regolith.InstallOptions{Force: true}, true)
 		if err != nil {
 			t.Fatal("'regolith install' failed:", err)
 		}
@@ -243,7 +244,7 @@ func TestInstallAll(t *testing.T) {
 			t.Fatal("Failed to copy config file for the test setup:", err)
 		}
 		// Run 'regolith update' / 'regolith update-all'
-		err = regolith.InstallAll(false, true)
+		err = regolith.InstallAll(regolith.InstallOptions{}, true)
 		if err != nil {
 			t.Fatal("'regolith update' failed:", err)
 		}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestReportChanges runs a profile with a filter that adds two files to the
+// BP with the ReportChanges option and checks the logged summary of the
+// changes made by the filter.
+func TestReportChanges(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(isolateEnvPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// Capture the logs
+	regolith.InitLogging(true)
+	core, logs := observer.New(zap.InfoLevel)
+	logger := regolith.Logger
+	regolith.Logger = zap.New(core).Sugar()
+	defer func() { regolith.Logger = logger }()
+	// THE TEST
+	os.Chdir(tmpDir)
+	os.Unsetenv("REGOLITH_TEST_SECRET")
+	options := regolith.RunOptions{ReportChanges: true}
+	if err := regolith.Run("default", options, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	// The filter writes "<missing>" to env.txt and the name and author of
+	// the project to project.txt (42 bytes in total)
+	expected := "Filter print_env changes: 2 added, 0 modified, 0 deleted, " +
+		"size +42 B"
+	if logs.FilterMessage(expected).Len() != 1 {
+		t.Fatalf(
+			"The summary of the changes was not logged.\nExpected: %q",
+			expected)
+	}
+}
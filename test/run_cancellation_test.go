@@ -0,0 +1,74 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestRunCancellation runs a profile with a filter that sends Ctrl+C to
+// Regolith, and checks whether the filter is allowed to finish, the next
+// filter doesn't run, the project is not exported, the tmp directory is
+// removed and the run returns regolith.ErrRunCancelled without wrapping it.
+func TestRunCancellation(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The filter of the test uses the \"kill\" command")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(runCancellationPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	err = regolith.Run("default", regolith.RunOptions{}, true)
+	if err != regolith.ErrRunCancelled {
+		t.Fatalf("'regolith run' didn't return ErrRunCancelled: %v", err)
+	}
+	// The filters write their files to the root of the project, which is not
+	// removed after the cancelled run
+	if _, err := os.Stat("interrupt.txt"); err != nil {
+		t.Fatal("The running filter wasn't allowed to finish.")
+	}
+	if _, err := os.Stat("second.txt"); !os.IsNotExist(err) {
+		t.Fatal("The filter after the cancellation was run.")
+	}
+	if _, err := os.Stat("build"); !os.IsNotExist(err) {
+		t.Fatal("The cancelled run was exported.")
+	}
+	_, err = os.Stat(filepath.Join(".regolith", "tmp"))
+	if !os.IsNotExist(err) {
+		t.Fatal("The tmp directory of the cancelled run wasn't removed.")
+	}
+}
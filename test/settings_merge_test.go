@@ -0,0 +1,76 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestSettingsMerge runs profiles that use a filter with the default
+// settings in its definition and checks whether the settings of the
+// profiles are merged with the defaults.
+func TestSettingsMerge(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("The test requires sh")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(settingsMergePath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	expectedSettings := map[string]string{
+		"defaults": `{"message":"hello","options":{"mode":"fast","scale":1},` +
+			`"tags":["a","b"]}`,
+		// The objects are merged and the arrays are replaced
+		"overrides": `{"message":"hello","options":{"mode":"fast","scale":2},` +
+			`"tags":["c"]}`,
+	}
+	for _, profile := range []string{"defaults", "overrides"} {
+		if err := regolith.Run(profile, regolith.RunOptions{}, true); err != nil {
+			t.Fatalf("'regolith run %s' failed: %s", profile, err.Error())
+		}
+		settings, err := os.ReadFile(filepath.Join("build", "BP", "settings.json"))
+		if err != nil {
+			t.Fatal("Failed to read the settings written by the filter:", err)
+		}
+		if string(settings) != expectedSettings[profile] {
+			t.Fatalf(
+				"The settings of the %q profile are different than expected."+
+					"\nExpected: %s\nActual: %s",
+				profile, expectedSettings[profile], settings)
+		}
+	}
+}
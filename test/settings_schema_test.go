@@ -0,0 +1,67 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestSettingsSchema runs a profile with a remote filter whose settings
+// match the schema from its filter.json and a profile with invalid settings.
+// The second run should fail before running the filters and report all of
+// the problems.
+func TestSettingsSchema(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(settingsSchemaPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	if err := regolith.Run("valid", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed on valid settings:", err.Error())
+	}
+	err = regolith.Run("invalid", regolith.RunOptions{}, true)
+	if err == nil {
+		t.Fatal("'regolith run' didn't fail on invalid settings")
+	}
+	for _, jsonPath := range []string{
+		"settings->language", "settings->scale", "settings->color",
+	} {
+		if !strings.Contains(err.Error(), jsonPath) {
+			t.Errorf("The error doesn't report the %q path:\n%s",
+				jsonPath, err.Error())
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestSettingsVariables runs a profile with the ${var:name} and
+// ${json:name} placeholders in the settings of a filter and checks whether
+// they're replaced with the values of the variables. The placeholders of the
+// other profiles and their filter definitions don't need the variables.
+func TestSettingsVariables(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("The test requires sh")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(
+		filepath.Join(settingsVariablesPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	t.Log("Running the profile without the required variable (this should fail)")
+	if err := regolith.Run("dev", regolith.RunOptions{}, true); err == nil {
+		t.Fatal("'regolith run' didn't fail without the required variable")
+	}
+	t.Log("Running the profile with an invalid variable (this should fail)")
+	options := regolith.RunOptions{Vars: []string{"version"}}
+	if err := regolith.Run("dev", options, true); err == nil {
+		t.Fatal("'regolith run' didn't fail with an invalid variable")
+	}
+	t.Log("Running the profile with the variables")
+	options = regolith.RunOptions{
+		Vars: []string{"build=1.10", "debug=true", "version=1.2.0"}}
+	if err := regolith.Run("dev", options, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	settings, err := os.ReadFile(filepath.Join("build", "BP", "settings.json"))
+	if err != nil {
+		t.Fatal("Failed to read the settings written by the filter:", err)
+	}
+	// The "build" variable stays a string, only the ${json:name}
+	// placeholders are parsed as JSON
+	expected := `{"build":"1.10","debug":true,"version":"v1.2.0"}`
+	if string(settings) != expected {
+		t.Fatalf(
+			"The settings are different than expected.\nExpected: %s\n"+
+				"Actual: %s", expected, settings)
+	}
+}
@@ -0,0 +1,6 @@
+'''
+Writes a message into the out.txt file of the BP.
+'''
+from pathlib import Path
+
+Path('BP/out.txt').write_text('Hello from the archive', encoding='utf8')
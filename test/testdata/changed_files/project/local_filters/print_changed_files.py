@@ -0,0 +1,15 @@
+'''
+Simple testing regolith filter which writes the value of the
+REGOLITH_CHANGED_FILES environment variable to changed_files.txt file of BP.
+'''
+import os
+from pathlib import Path
+
+BP_PATH = Path('BP')
+
+def main():
+    value = os.environ.get('REGOLITH_CHANGED_FILES', '<missing>')
+    (BP_PATH / 'changed_files.txt').write_text(value, encoding='utf8')
+
+if __name__ == "__main__":
+    main()
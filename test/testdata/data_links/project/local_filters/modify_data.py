@@ -0,0 +1,14 @@
+'''
+Simple testing regolith filter which modifies a file of the data folder in
+place and copies its content to the RP.
+'''
+from pathlib import Path
+
+def main():
+    with open('data/data.txt', 'a', encoding='utf8') as f:
+        f.write(' modified')
+    Path('RP/data.txt').write_text(
+        Path('data/data.txt').read_text(encoding='utf8'), encoding='utf8')
+
+if __name__ == "__main__":
+    main()
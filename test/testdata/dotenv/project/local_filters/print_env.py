@@ -0,0 +1,15 @@
+'''
+Simple testing regolith filter which prints the value of the
+REGOLITH_TEST_SECRET environment variable to env.txt file of BP.
+'''
+import os
+from pathlib import Path
+
+BP_PATH = Path('BP')
+
+def main():
+    value = os.environ.get('REGOLITH_TEST_SECRET', '<missing>')
+    (BP_PATH / 'env.txt').write_text(value, encoding='utf8')
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,12 @@
+'''
+Simple testing regolith filter which marks the BP/items/optimized.json file
+as already optimized.
+'''
+import json
+
+def main():
+    with open('optimized_files.json', 'w') as f:
+        json.dump(['BP/items/optimized.json'], f)
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,15 @@
+'''
+Simple testing regolith filter which creates the BP/<name>.txt file, where
+<name> is its first argument. With the "fail" argument, it fails after
+creating the file.
+'''
+import sys
+
+def main():
+    with open(f'BP/{sys.argv[1]}.txt', 'w') as f:
+        f.write(sys.argv[1])
+    if 'fail' in sys.argv[2:]:
+        sys.exit(1)
+
+if __name__ == "__main__":
+    main()
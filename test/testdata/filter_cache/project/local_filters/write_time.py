@@ -0,0 +1,12 @@
+'''
+Simple testing regolith filter which writes the current time to BP/time.txt.
+The output is different on every run unless it's restored from the cache.
+'''
+from pathlib import Path
+import time
+
+def main():
+    Path('BP/time.txt').write_text(str(time.time_ns()), encoding='utf8')
+
+if __name__ == "__main__":
+    main()
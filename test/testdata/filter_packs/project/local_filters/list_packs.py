@@ -0,0 +1,14 @@
+'''
+Simple testing regolith filter which writes the list of the packs that it can
+see to the RP/seen.txt file and tries to create the BP/out.txt file.
+'''
+from pathlib import Path
+
+def main():
+    seen = sorted(p.name for p in Path('.').iterdir() if p.is_dir())
+    Path('RP/seen.txt').write_text('\n'.join(seen), encoding='utf8')
+    Path('BP').mkdir(exist_ok=True)
+    Path('BP/out.txt').write_text('BP', encoding='utf8')
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,26 @@
+'''
+Simple testing regolith filter which fails the first two times it runs. The
+attempts are counted in the file from the first argument, in the root of the
+project. Every attempt appends a line to the BP/attempts.txt file, so the
+file has more than one line if the output of the failed attempts is not
+discarded.
+'''
+import os
+import sys
+
+def main():
+    counter_path = os.path.join(os.environ['ROOT_DIR'], sys.argv[1])
+    attempt = 1
+    if os.path.exists(counter_path):
+        with open(counter_path, 'r') as f:
+            attempt = int(f.read()) + 1
+    with open(counter_path, 'w') as f:
+        f.write(str(attempt))
+    with open('BP/attempts.txt', 'a') as f:
+        f.write(f'Attempt {attempt}\n')
+    if attempt < 3:
+        print(f'Attempt {attempt} failed.')
+        sys.exit(1)
+
+if __name__ == "__main__":
+    main()
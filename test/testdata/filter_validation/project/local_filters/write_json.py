@@ -0,0 +1,12 @@
+'''
+Simple testing regolith filter which writes a valid JSON file to the RP and
+an invalid JSON file to the BP.
+'''
+from pathlib import Path
+
+def main():
+    Path('RP/valid.json').write_text('{"valid": true}', encoding='utf8')
+    Path('BP/invalid.json').write_text('{"valid": ', encoding='utf8')
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,30 @@
+'''
+Simple testing regolith filter which reports warnings in the warnings.json
+file. It fails if the file already exists, because Regolith should remove
+the warnings of the previous filter. With the "invalid" argument, it writes
+a file with invalid warnings.
+'''
+import json
+import os
+import sys
+
+def main():
+    if os.path.exists('warnings.json'):
+        print('The warnings of the previous filter were not removed.')
+        sys.exit(1)
+    if 'invalid' in sys.argv[1:]:
+        warnings = {'message': 'Not a list'}
+    else:
+        warnings = [
+            'Simple warning',
+            {
+                'message': 'Missing texture',
+                'file': 'RP/manifest.json',
+                'line': 3
+            }
+        ]
+    with open('warnings.json', 'w') as f:
+        json.dump(warnings, f)
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,11 @@
+'''
+Simple testing regolith filter which writes "global" to the BP/global_filter.txt
+file.
+'''
+
+def main():
+    with open('BP/global_filter.txt', 'w') as f:
+        f.write('global')
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,11 @@
+'''
+Simple testing regolith filter which writes "global" to the BP/shared.txt
+file.
+'''
+
+def main():
+    with open('BP/shared.txt', 'w') as f:
+        f.write('global')
+
+if __name__ == "__main__":
+    main()
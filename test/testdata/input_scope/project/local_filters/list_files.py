@@ -0,0 +1,18 @@
+'''
+Simple testing regolith filter which writes the list of the files of the RP
+that it can see to the RP/textures/seen.txt file and tries to overwrite the
+RP/sounds/b.txt file.
+'''
+from pathlib import Path
+
+def main():
+    seen = sorted(
+        p.relative_to('RP').as_posix()
+        for p in Path('RP').rglob('*') if p.is_file())
+    Path('RP/textures/seen.txt').write_text(
+        '\n'.join(seen), encoding='utf8')
+    Path('RP/sounds').mkdir(parents=True, exist_ok=True)
+    Path('RP/sounds/b.txt').write_text('modified', encoding='utf8')
+
+if __name__ == "__main__":
+    main()
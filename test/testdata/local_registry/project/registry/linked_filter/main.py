@@ -0,0 +1,8 @@
+'''
+Copies the message.txt file of the filter into the out.txt file of the BP.
+'''
+import os
+from pathlib import Path
+
+message = Path(os.environ['FILTER_DIR']) / 'message.txt'
+Path('BP/out.txt').write_text(message.read_text(encoding='utf8'), encoding='utf8')
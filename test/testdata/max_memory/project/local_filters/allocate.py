@@ -0,0 +1,10 @@
+'''
+Simple testing regolith filter which allocates 256 MiB of memory.
+'''
+
+def main():
+    data = bytearray(256 * 1024 * 1024)
+    print(f'Allocated {len(data)} bytes')
+
+if __name__ == "__main__":
+    main()
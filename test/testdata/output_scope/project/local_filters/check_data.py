@@ -0,0 +1,15 @@
+'''
+Simple testing regolith filter which fails if the example data file is
+missing. It checks whether the data hidden by the output scope of the
+filters was restored.
+'''
+import os
+import sys
+
+def main():
+    if not os.path.exists('data/example_data_file.json'):
+        print('The data folder was not restored.')
+        sys.exit(1)
+
+if __name__ == "__main__":
+    main()
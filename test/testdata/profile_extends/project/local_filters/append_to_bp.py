@@ -0,0 +1,19 @@
+'''
+Simple testing regolith filter which appends a line to out.txt file of BP
+The text being appended is configured in the filter's config in config.json
+file.
+'''
+import sys
+import json
+from pathlib import Path
+
+BP_PATH = Path('BP')
+
+def main():
+    config = json.loads(sys.argv[1])
+    output_text = config['output_text']
+    with (BP_PATH / 'out.txt').open('a', encoding='utf8') as f:
+        f.write(output_text + '\n')
+
+if __name__ == "__main__":
+    main()
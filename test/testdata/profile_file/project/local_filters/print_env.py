@@ -0,0 +1,20 @@
+'''
+Simple testing regolith filter which prints the value of the
+REGOLITH_TEST_SECRET environment variable to env.txt file of BP and the
+project metadata passed by Regolith to project.txt file of BP.
+'''
+import os
+from pathlib import Path
+
+BP_PATH = Path('BP')
+
+def main():
+    value = os.environ.get('REGOLITH_TEST_SECRET', '<missing>')
+    (BP_PATH / 'env.txt').write_text(value, encoding='utf8')
+    project = '{}|{}'.format(
+        os.environ.get('REGOLITH_PROJECT_NAME', '<missing>'),
+        os.environ.get('REGOLITH_PROJECT_AUTHOR', '<missing>'))
+    (BP_PATH / 'project.txt').write_text(project, encoding='utf8')
+
+if __name__ == "__main__":
+    main()
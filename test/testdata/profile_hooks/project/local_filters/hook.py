@@ -0,0 +1,16 @@
+'''
+Simple hook for testing the "preRun" and "postRun" properties of the
+profiles. It appends its first argument and the value of the
+REGOLITH_RUN_SUCCESS environment variable to the hooks.txt file in the
+working directory.
+'''
+import os
+import sys
+
+def main():
+    with open('hooks.txt', 'a') as f:
+        success = os.environ.get('REGOLITH_RUN_SUCCESS', '')
+        f.write(f'{sys.argv[1]} {success}'.strip() + '\n')
+
+if __name__ == "__main__":
+    main()
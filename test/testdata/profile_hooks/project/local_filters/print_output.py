@@ -0,0 +1,14 @@
+'''
+Simple testing regolith filter which prints a line to the stdout and a line
+to the stderr. With the "fail" argument, it fails after printing them.
+'''
+import sys
+
+def main():
+    print('Hello from stdout', flush=True)
+    print('Hello from stderr', file=sys.stderr, flush=True)
+    if 'fail' in sys.argv[1:]:
+        sys.exit(1)
+
+if __name__ == "__main__":
+    main()
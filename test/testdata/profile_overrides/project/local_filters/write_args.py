@@ -0,0 +1,23 @@
+'''
+Simple testing regolith filter which writes its settings and arguments to
+the args.json file of BP. The name of the file can be changed with the
+"file" setting.
+'''
+import json
+import sys
+from pathlib import Path
+
+BP_PATH = Path('BP')
+
+def main():
+    settings = {}
+    arguments = sys.argv[1:]
+    if len(arguments) > 0 and arguments[0].startswith('{'):
+        settings = json.loads(arguments[0])
+        arguments = arguments[1:]
+    file_name = settings.get('file', 'args.json')
+    result = {'settings': settings, 'arguments': arguments}
+    (BP_PATH / file_name).write_text(json.dumps(result), encoding='utf8')
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,10 @@
+'''
+Simple testing regolith filter which always fails.
+'''
+import sys
+
+def main():
+    sys.exit(1)
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,3 @@
+#!/bin/sh
+# Writes the settings of the filter to BP/settings.json
+printf "%s" "$1" > BP/settings.json
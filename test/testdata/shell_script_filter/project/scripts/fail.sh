@@ -0,0 +1,3 @@
+#!/bin/sh
+echo "Something went wrong" >&2
+exit 3
@@ -0,0 +1,3 @@
+#!/bin/sh
+# Writes the first argument of the filter to BP/hello.txt
+printf "%s" "$1" > BP/hello.txt
@@ -0,0 +1,11 @@
+'''
+Simple testing regolith filter which writes out.txt file to both RP and BP.
+'''
+from pathlib import Path
+
+def main():
+    Path('RP/out.txt').write_text('RP', encoding='utf8')
+    Path('BP/out.txt').write_text('BP', encoding='utf8')
+
+if __name__ == "__main__":
+    main()
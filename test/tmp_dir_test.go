@@ -0,0 +1,176 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestTmpDir runs a test that checks whether the "--tmp-dir" flag moves the
+// tmp directory of the project out of the .regolith directory, without
+// changing the results of the filters.
+func TestTmpDir(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Create the directory for the tmp directory of the project
+	ramdiskDir, err := ioutil.TempDir("", "regolith-test-ramdisk")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(ramdiskDir)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(outputScopePath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	expectedBuildResult, err := filepath.Abs(
+		filepath.Join(outputScopePath, "expected_build_result"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the expected build result:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	regolith.TmpDir = ramdiskDir
+	defer func() { regolith.TmpDir = "" }()
+	if err := regolith.Run("default", regolith.RunOptions{KeepTmp: true}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	// Load expected result
+	expectedPaths, err := listPaths(expectedBuildResult, expectedBuildResult)
+	if err != nil {
+		t.Fatalf("Failed to load the expected results: %s", err)
+	}
+	// Load actual result
+	tmpDirBuild := filepath.Join(tmpDir, "build")
+	actualPaths, err := listPaths(tmpDirBuild, tmpDirBuild)
+	if err != nil {
+		t.Fatalf("Failed to load the actual results: %s", err)
+	}
+	// Compare the results
+	comparePathMaps(expectedPaths, actualPaths, t)
+	// The tmp directory must not be created in the .regolith directory
+	if _, err := os.Stat(filepath.Join(tmpDir, ".regolith", "tmp")); !os.IsNotExist(err) {
+		t.Fatal("The tmp directory was created in the .regolith directory.")
+	}
+	// The project gets its own directory in the selected directory
+	entries, err := os.ReadDir(ramdiskDir)
+	if err != nil {
+		t.Fatalf("Failed to list the selected directory: %s", err)
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		t.Fatalf(
+			"Expected one directory of the project in the selected "+
+				"directory, got %d entries.", len(entries))
+	}
+	tmpPath := filepath.Join(ramdiskDir, entries[0].Name(), "tmp")
+	keptPaths, err := listPaths(tmpPath, tmpPath)
+	if err != nil {
+		t.Fatalf("Failed to load the kept files: %s", err)
+	}
+	for path := range keptPaths {
+		if strings.HasPrefix(filepath.ToSlash(path), "data") {
+			delete(keptPaths, path)
+		}
+	}
+	comparePathMaps(expectedPaths, keptPaths, t)
+}
+
+// TestCleanTmpDir runs a test that checks whether "regolith clean" removes
+// the tmp directory of the project moved out of the .regolith directory
+// with the REGOLITH_TMP environment variable.
+func TestCleanTmpDir(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Create the directory for the tmp directory of the project
+	ramdiskDir, err := ioutil.TempDir("", "regolith-test-ramdisk")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	defer os.RemoveAll(ramdiskDir)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(outputScopePath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	t.Setenv("REGOLITH_TMP", ramdiskDir)
+	if err := regolith.Run("default", regolith.RunOptions{KeepTmp: true}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	entries, err := os.ReadDir(ramdiskDir)
+	if err != nil {
+		t.Fatalf("Failed to list the selected directory: %s", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf(
+			"Expected one directory of the project in the selected "+
+				"directory, got %d entries.", len(entries))
+	}
+	if err := regolith.Clean(true, false, false, false); err != nil {
+		t.Fatal("'regolith clean' failed:", err.Error())
+	}
+	entries, err = os.ReadDir(ramdiskDir)
+	if err != nil {
+		t.Fatalf("Failed to list the selected directory: %s", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf(
+			"The tmp directory of the project wasn't removed, got %d "+
+				"entries in the selected directory.", len(entries))
+	}
+}
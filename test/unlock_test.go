@@ -0,0 +1,74 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+)
+
+// TestUnlock checks whether 'regolith unlock' removes the session locks of
+// the processes that don't exist, and removes the locks of the running
+// processes only with the "--force" flag.
+func TestUnlock(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	sessionLockPath := filepath.Join(tmpDir, ".regolith", "session_lock")
+	if err := os.MkdirAll(filepath.Dir(sessionLockPath), 0755); err != nil {
+		t.Fatal("Unable to create the .regolith directory:", err)
+	}
+	writeLock := func(pid int) {
+		err := ioutil.WriteFile(
+			sessionLockPath, []byte(strconv.Itoa(pid)+"\n"), 0644)
+		if err != nil {
+			t.Fatal("Unable to write the session lock:", err)
+		}
+	}
+	lockExists := func() bool {
+		_, err := os.Stat(sessionLockPath)
+		return err == nil
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	// The project without a lock
+	if err := regolith.Unlock(false, "", true); err != nil {
+		t.Fatal("'regolith unlock' failed without a lock:", err.Error())
+	}
+	// The lock of a process that doesn't exist
+	writeLock(2147483646)
+	if err := regolith.Unlock(false, "", true); err != nil {
+		t.Fatal("'regolith unlock' failed to remove a stale lock:", err.Error())
+	}
+	if lockExists() {
+		t.Fatal("'regolith unlock' didn't remove the stale lock")
+	}
+	// The lock of a running process
+	writeLock(os.Getpid())
+	if err := regolith.Unlock(false, "", true); err == nil {
+		t.Fatal("'regolith unlock' removed the lock of a running process")
+	}
+	if !lockExists() {
+		t.Fatal("The lock of a running process was removed")
+	}
+	if err := regolith.Unlock(true, "", true); err != nil {
+		t.Fatal("'regolith unlock --force' failed:", err.Error())
+	}
+	if lockExists() {
+		t.Fatal("'regolith unlock --force' didn't remove the lock")
+	}
+}
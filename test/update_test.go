@@ -0,0 +1,70 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestUpdatePatterns checks whether the 'regolith update' command accepts
+// glob patterns and fails only when none of the patterns match a filter or
+// when a pattern is invalid.
+func TestUpdatePatterns(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(updatePatternsPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	cases := []struct {
+		patterns   []string
+		shouldFail bool
+	}{
+		{[]string{"team_*"}, false},
+		{[]string{"team_*", "missing_*"}, false},
+		{[]string{"other_filter"}, false},
+		{[]string{"missing_*"}, true},
+		{[]string{"team_["}, true},
+	}
+	for _, c := range cases {
+		err := regolith.Update(c.patterns, false, "", true)
+		if c.shouldFail && err == nil {
+			t.Fatalf("'regolith update' didn't fail for patterns %v", c.patterns)
+		} else if !c.shouldFail && err != nil {
+			t.Fatalf(
+				"'regolith update' failed for patterns %v: %s",
+				c.patterns, err.Error())
+		}
+	}
+}
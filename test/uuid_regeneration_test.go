@@ -0,0 +1,101 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// testManifest is the part of the manifest.json file checked by
+// TestRegenerateUuids.
+type testManifest struct {
+	Header struct {
+		Uuid string `json:"uuid"`
+	} `json:"header"`
+	Dependencies []struct {
+		Uuid string `json:"uuid"`
+	} `json:"dependencies"`
+}
+
+// loadTestManifest loads the manifest.json file from the pack.
+func loadTestManifest(t *testing.T, packPath string) testManifest {
+	var result testManifest
+	data, err := ioutil.ReadFile(filepath.Join(packPath, "manifest.json"))
+	if err != nil {
+		t.Fatal("Unable to read the manifest:", err)
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatal("Unable to parse the manifest:", err)
+	}
+	return result
+}
+
+// TestRegenerateUuids runs a profile with the "regenerateUuids" export
+// option and checks whether the exported manifests have new UUIDs that are
+// stable between the runs, while the dependency of the behavior pack still
+// points to the resource pack and the source manifests stay unchanged.
+func TestRegenerateUuids(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test project to the working directory
+	project, err := filepath.Abs(filepath.Join(archiveExportPath, "project"))
+	if err != nil {
+		t.Fatal(
+			"Unable to get absolute path to the test project:", err)
+	}
+	err = copy.Copy(
+		project,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			project, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(tmpDir)
+	sourceBp := loadTestManifest(t, "packs/BP")
+	sourceRp := loadTestManifest(t, "packs/RP")
+	if err := regolith.Run("regenerate_uuids", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	bp := loadTestManifest(t, "build/BP")
+	rp := loadTestManifest(t, "build/RP")
+	if bp.Header.Uuid == sourceBp.Header.Uuid ||
+		rp.Header.Uuid == sourceRp.Header.Uuid {
+		t.Fatal("The UUIDs of the exported manifests weren't regenerated")
+	}
+	if len(bp.Dependencies) != 1 || bp.Dependencies[0].Uuid != rp.Header.Uuid {
+		t.Fatal("The behavior pack doesn't depend on the exported resource pack")
+	}
+	if loadTestManifest(t, "packs/BP").Header.Uuid != sourceBp.Header.Uuid {
+		t.Fatal("The source manifest of the behavior pack was modified")
+	}
+	// The UUIDs must be the same in the next run
+	if err := regolith.Run("regenerate_uuids", regolith.RunOptions{}, true); err != nil {
+		t.Fatal("'regolith run' failed:", err.Error())
+	}
+	if loadTestManifest(t, "build/BP").Header.Uuid != bp.Header.Uuid ||
+		loadTestManifest(t, "build/RP").Header.Uuid != rp.Header.Uuid {
+		t.Fatal("The regenerated UUIDs changed between the runs")
+	}
+}
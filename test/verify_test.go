@@ -0,0 +1,157 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Bedrock-OSS/regolith/regolith"
+	"github.com/otiai10/copy"
+)
+
+// TestVerify tests the 'regolith verify' command on a project with a valid
+// filter cache and on a project with an invalid filter cache.
+func TestVerify(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test projects to the working directory
+	source, err := filepath.Abs(verifyPath)
+	if err != nil {
+		t.Fatal("Unable to get absolute path to the test projects:", err)
+	}
+	err = copy.Copy(
+		source,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			source, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(filepath.Join(tmpDir, "valid_project"))
+	if err := regolith.Verify("", true); err != nil {
+		t.Fatal("'regolith verify' failed on a valid project:", err.Error())
+	}
+	os.Chdir(filepath.Join(tmpDir, "invalid_project"))
+	if err := regolith.Verify("", true); err == nil {
+		t.Fatal("'regolith verify' didn't return an error on an invalid project")
+	} else {
+		t.Log("Task failed successfully")
+	}
+}
+
+// TestDryInstallAll tests the 'regolith install-all --dry-install' command on
+// a project with a valid filter cache and on a project with an invalid filter
+// cache.
+func TestDryInstallAll(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test projects to the working directory
+	source, err := filepath.Abs(verifyPath)
+	if err != nil {
+		t.Fatal("Unable to get absolute path to the test projects:", err)
+	}
+	err = copy.Copy(
+		source,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			source, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(filepath.Join(tmpDir, "valid_project"))
+	if err := regolith.DryInstallAll(false, "", true); err != nil {
+		t.Fatal("'regolith install-all --dry-install' failed on a valid project:", err.Error())
+	}
+	os.Chdir(filepath.Join(tmpDir, "invalid_project"))
+	if err := regolith.DryInstallAll(false, "", true); err == nil {
+		t.Fatal("'regolith install-all --dry-install' didn't return an error on an invalid project")
+	} else {
+		t.Log("Task failed successfully")
+	}
+	// The dry install must not modify the cache
+	_, err = os.Stat(".regolith/cache/filters/orphaned_filter")
+	if err != nil {
+		t.Fatal("'regolith install-all --dry-install' modified the cache:", err.Error())
+	}
+}
+
+// TestWhich tests the 'regolith which' command on a project with an
+// installed filter and with a filter that is not on the filter definitions
+// list.
+func TestWhich(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unable to get current working directory")
+	}
+	defer os.Chdir(wd)
+	// Create a temporary directory
+	tmpDir, err := ioutil.TempDir("", "regolith-test")
+	if err != nil {
+		t.Fatal("Unable to create temporary directory:", err)
+	}
+	t.Log("Created temporary directory:", tmpDir)
+	// Before deleting "workingDir" the test must stop using it
+	defer os.RemoveAll(tmpDir)
+	defer os.Chdir(wd)
+	// Copy the test projects to the working directory
+	source, err := filepath.Abs(verifyPath)
+	if err != nil {
+		t.Fatal("Unable to get absolute path to the test projects:", err)
+	}
+	err = copy.Copy(
+		source,
+		tmpDir,
+		copy.Options{PreserveTimes: false, Sync: false},
+	)
+	if err != nil {
+		t.Fatalf(
+			"Failed to copy test files from %q into the working directory %q",
+			source, tmpDir,
+		)
+	}
+	// THE TEST
+	os.Chdir(filepath.Join(tmpDir, "valid_project"))
+	for _, jsonOutput := range []bool{false, true} {
+		if err := regolith.Which("hello_version", jsonOutput, "", true); err != nil {
+			t.Fatal("'regolith which' failed on an installed filter:", err.Error())
+		}
+	}
+	if err := regolith.Which("missing_filter", false, "", true); err == nil {
+		t.Fatal("'regolith which' didn't return an error on a missing filter")
+	} else {
+		t.Log("Task failed successfully")
+	}
+}